@@ -12,6 +12,7 @@ import (
 
 	_ "github.com/micro-plat/hydra/hydra/cmds/conf"
 	_ "github.com/micro-plat/hydra/hydra/cmds/install"
+	_ "github.com/micro-plat/hydra/hydra/cmds/openapi"
 	_ "github.com/micro-plat/hydra/hydra/cmds/remove"
 	_ "github.com/micro-plat/hydra/hydra/cmds/run"
 	_ "github.com/micro-plat/hydra/hydra/cmds/update"
@@ -22,7 +23,9 @@ import (
 
 	_ "github.com/micro-plat/hydra/hydra/cmds/restart"
 
+	_ "github.com/micro-plat/hydra/registry/registry/consul"
 	_ "github.com/micro-plat/hydra/registry/registry/dbr"
+	_ "github.com/micro-plat/hydra/registry/registry/etcd"
 	_ "github.com/micro-plat/hydra/registry/registry/filesystem"
 	_ "github.com/micro-plat/hydra/registry/registry/localmemory"
 	_ "github.com/micro-plat/hydra/registry/registry/redis"
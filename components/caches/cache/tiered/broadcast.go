@@ -0,0 +1,201 @@
+package tiered
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	rds "github.com/go-redis/redis"
+	"github.com/micro-plat/hydra/components/pkgs/redis"
+	"github.com/micro-plat/hydra/components/queues/mq"
+	"github.com/micro-plat/hydra/conf"
+	"github.com/micro-plat/hydra/conf/app"
+	"github.com/micro-plat/hydra/conf/vars/cache/cacheredis"
+	vartiered "github.com/micro-plat/hydra/conf/vars/cache/tiered"
+	varredis "github.com/micro-plat/hydra/conf/vars/redis"
+)
+
+//IBroadcaster 失效通知广播，向所有服务器发送消息
+type IBroadcaster interface {
+	Publish(message string) error
+	//Subscribe 订阅消息，订阅中断期间的消息可能丢失，恢复后调用reset
+	Subscribe(callback func(message string), reset func()) error
+	Close() error
+}
+
+//newBroadcaster 根据配置构建失效通知广播
+func newBroadcaster(cfg *vartiered.Tiered, remoteProto string, remoteRaw string) (IBroadcaster, error) {
+	if cfg.Broadcast == "queue" {
+		proto, raw, err := getVarConf("queue", cfg.Queue)
+		if err != nil {
+			return nil, err
+		}
+		return newQueueBroadcaster(proto, raw, cfg.Channel)
+	}
+	if cfg.Redis != "" {
+		_, raw, err := getVarConf("redis", cfg.Redis)
+		if err != nil {
+			return nil, err
+		}
+		return newRedisBroadcaster(varredis.NewByRaw(raw), cfg.Channel)
+	}
+	if remoteProto != "redis" {
+		return nil, fmt.Errorf("远程缓存%s不支持发布订阅，请设置redis或使用消息队列通知", remoteProto)
+	}
+	return newRedisBroadcaster(varredis.NewByRaw(cacheredis.NewByRaw(remoteRaw).GetRaw()), cfg.Channel)
+}
+
+func getVarConf(tp string, name string) (proto string, raw string, err error) {
+	varConf, err := app.Cache.GetVarConf()
+	if err != nil {
+		return "", "", err
+	}
+	js, err := varConf.GetConf(tp, name)
+	if errors.Is(err, conf.ErrNoSetting) {
+		return "", "", fmt.Errorf("未配置：/var/%s/%s", tp, name)
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return js.GetString("proto"), string(js.GetRaw()), nil
+}
+
+//redisBroadcaster 使用redis发布订阅发送失效通知
+type redisBroadcaster struct {
+	client  *redis.Client
+	channel string
+	pubsub  *rds.PubSub
+	done    chan struct{}
+}
+
+//pingInterval 未收到消息时检查订阅连接的间隔
+const pingInterval = 30 * time.Second
+
+//retryInterval 订阅连接出错后的重试间隔
+var retryInterval = time.Second
+
+func newRedisBroadcaster(cfg *varredis.Redis, channel string) (*redisBroadcaster, error) {
+	client, err := redis.NewByConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &redisBroadcaster{client: client, channel: channel}, nil
+}
+
+//Publish 发布消息
+func (b *redisBroadcaster) Publish(message string) error {
+	return b.client.Publish(b.channel, message).Err()
+}
+
+//Subscribe 订阅消息，连接断开后自动重新订阅，
+//断开期间的消息无法补发，连接出错或重新订阅时调用reset
+func (b *redisBroadcaster) Subscribe(callback func(message string), reset func()) error {
+	b.pubsub = b.client.Subscribe(b.channel)
+	if _, err := b.pubsub.Receive(); err != nil {
+		b.pubsub.Close()
+		return err
+	}
+	b.done = make(chan struct{})
+	go b.receive(callback, reset)
+	return nil
+}
+
+func (b *redisBroadcaster) receive(callback func(message string), reset func()) {
+	for {
+		msg, err := b.pubsub.ReceiveTimeout(pingInterval)
+		if err != nil {
+			if b.closed() {
+				return
+			}
+			var nerr net.Error
+			if errors.As(err, &nerr) && nerr.Timeout() {
+				if err = b.pubsub.Ping(); err == nil {
+					continue
+				}
+			}
+			reset()
+			select {
+			case <-b.done:
+				return
+			case <-time.After(retryInterval):
+			}
+			continue
+		}
+		switch m := msg.(type) {
+		case *rds.Subscription:
+			//连接断开后重新订阅成功
+			reset()
+		case *rds.Message:
+			callback(m.Payload)
+		}
+	}
+}
+
+func (b *redisBroadcaster) closed() bool {
+	select {
+	case <-b.done:
+		return true
+	default:
+		return false
+	}
+}
+
+//Close 关闭订阅与连接
+func (b *redisBroadcaster) Close() error {
+	if b.pubsub != nil {
+		close(b.done)
+		b.pubsub.Close()
+	}
+	return b.client.Close()
+}
+
+//fanoutProtos 支持广播(每个订阅者都能收到消息)的消息队列协议，
+//redis、lmq、dmq等队列中的每条消息只被一个消费者处理，无法通知所有服务器
+var fanoutProtos = map[string]bool{"mqtt": true}
+
+//queueBroadcaster 使用消息队列发送失效通知，只支持可广播的协议(如mqtt)
+type queueBroadcaster struct {
+	producer mq.IMQP
+	consumer mq.IMQC
+	channel  string
+}
+
+func newQueueBroadcaster(proto string, raw string, channel string) (*queueBroadcaster, error) {
+	if !fanoutProtos[proto] {
+		return nil, fmt.Errorf("消息队列%s不支持广播，无法发送缓存失效通知，请使用mqtt或redis发布订阅", proto)
+	}
+	producer, err := mq.NewMQP(proto, raw)
+	if err != nil {
+		return nil, err
+	}
+	consumer, err := mq.NewMQC(proto, raw)
+	if err != nil {
+		producer.Close()
+		return nil, err
+	}
+	return &queueBroadcaster{producer: producer, consumer: consumer, channel: channel}, nil
+}
+
+//Publish 发布消息
+func (b *queueBroadcaster) Publish(message string) error {
+	return b.producer.Push(b.channel, message)
+}
+
+//Subscribe 订阅消息，由消息队列客户端负责重连，不调用reset
+func (b *queueBroadcaster) Subscribe(callback func(message string), reset func()) error {
+	if err := b.consumer.Connect(); err != nil {
+		return err
+	}
+	return b.consumer.Consume(b.channel, 1, func(m mq.IMQCMessage) {
+		callback(m.GetMessage())
+		m.Ack()
+	})
+}
+
+//Close 关闭连接
+func (b *queueBroadcaster) Close() error {
+	b.consumer.UnConsume(b.channel)
+	b.consumer.Close()
+	return b.producer.Close()
+}
@@ -0,0 +1,57 @@
+package tiered
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	varredis "github.com/micro-plat/hydra/conf/vars/redis"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func TestRedisBroadcaster_Reconnect(t *testing.T) {
+	retryInterval = time.Millisecond * 50
+	s, err := miniredis.Run()
+	assert.Equal(t, nil, err, "启动redis")
+	defer s.Close()
+
+	b, err := newRedisBroadcaster(varredis.New(s.Addr()), "tiered")
+	assert.Equal(t, nil, err, "构建广播")
+	defer b.Close()
+
+	msgs := make(chan string, 10)
+	var resets int32
+	err = b.Subscribe(func(m string) { msgs <- m }, func() { atomic.AddInt32(&resets, 1) })
+	assert.Equal(t, nil, err, "订阅消息")
+
+	assert.Equal(t, nil, b.Publish("1"), "发布消息")
+	assert.Equal(t, "1", wait(t, msgs), "收到消息")
+	assert.Equal(t, int32(0), atomic.LoadInt32(&resets), "连接正常时不清除本地缓存")
+
+	s.Close()
+	assert.Equal(t, nil, s.Restart(), "重启redis")
+
+	deadline := time.Now().Add(time.Second * 3)
+	for time.Now().Before(deadline) && (s.PubSubNumSub("tiered")["tiered"] == 0 || atomic.LoadInt32(&resets) == 0) {
+		time.Sleep(time.Millisecond * 20)
+	}
+	assert.Equal(t, true, atomic.LoadInt32(&resets) > 0, "重新订阅后清除本地缓存")
+
+	//发布连接池中的旧连接已断开，首次发布可能失败
+	if err = b.Publish("2"); err != nil {
+		err = b.Publish("2")
+	}
+	assert.Equal(t, nil, err, "重连后发布消息")
+	assert.Equal(t, "2", wait(t, msgs), "重连后收到消息")
+}
+
+func wait(t *testing.T, msgs chan string) string {
+	select {
+	case m := <-msgs:
+		return m
+	case <-time.After(time.Second * 3):
+		t.Fatal("未收到消息")
+		return ""
+	}
+}
@@ -0,0 +1,41 @@
+package tiered
+
+import "sync"
+
+//call 正在执行的加载任务
+type call struct {
+	wg  sync.WaitGroup
+	val string
+	err error
+}
+
+//group 合并相同key的并发加载，同一时刻每个key只执行一次加载
+type group struct {
+	lock  sync.Mutex
+	calls map[string]*call
+}
+
+//Do 执行加载，已有相同key的加载任务时等待其完成并共用结果
+func (g *group) Do(key string, fn func() (string, error)) (string, error) {
+	g.lock.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call)
+	}
+	if c, ok := g.calls[key]; ok {
+		g.lock.Unlock()
+		c.wg.Wait()
+		return c.val, c.err
+	}
+	c := &call{}
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.lock.Unlock()
+
+	c.val, c.err = fn()
+	c.wg.Done()
+
+	g.lock.Lock()
+	delete(g.calls, key)
+	g.lock.Unlock()
+	return c.val, c.err
+}
@@ -0,0 +1,315 @@
+package tiered
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/micro-plat/hydra/components/caches/cache"
+	_ "github.com/micro-plat/hydra/components/caches/cache/memcached"
+	_ "github.com/micro-plat/hydra/components/caches/cache/redis"
+	vartiered "github.com/micro-plat/hydra/conf/vars/cache/tiered"
+	"github.com/micro-plat/lib4go/logger"
+	gocache "github.com/zkfy/go-cache"
+)
+
+//Proto Proto
+const Proto = vartiered.Proto
+
+//message 失效通知消息
+type message struct {
+	ID   string   `json:"id"`
+	Keys []string `json:"keys"`
+}
+
+//Client 二级缓存，读取时优先使用本地缓存，本地不存在时从远程缓存加载(相同key并发加载只请求一次)，
+//写入、删除时同时更新远程缓存与本地缓存，并广播通知其它服务器清除本地缓存
+type Client struct {
+	id          string
+	local       *gocache.Cache
+	remote      cache.ICache
+	broadcaster IBroadcaster
+	loader      group
+	expire      time.Duration
+	ttls        []*vartiered.TTL
+	versions    versions
+	log         logger.ILogger
+}
+
+//NewByOpts 根据配置构建二级缓存
+func NewByOpts(remote interface{}, opts ...vartiered.Option) (*Client, error) {
+	return NewByConfig(vartiered.New(remote, opts...))
+}
+
+//NewByConfig 根据配置构建二级缓存，连接远程缓存并订阅失效通知
+func NewByConfig(cfg *vartiered.Tiered) (*Client, error) {
+	proto, raw, err := cfg.GetRemote()
+	if err != nil {
+		return nil, err
+	}
+	remote, err := cache.New(proto, raw)
+	if err != nil {
+		return nil, err
+	}
+	broadcaster, err := newBroadcaster(cfg, proto, raw)
+	if err != nil {
+		remote.Close()
+		return nil, err
+	}
+	c, err := NewByCache(remote, broadcaster, cfg)
+	if err != nil {
+		remote.Close()
+		broadcaster.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+//NewByCache 使用已有的远程缓存与失效通知广播构建二级缓存，broadcaster为空时不发送失效通知
+func NewByCache(remote cache.ICache, broadcaster IBroadcaster, cfg *vartiered.Tiered) (*Client, error) {
+	c := &Client{
+		id:          logger.CreateSession(),
+		local:       gocache.New(time.Duration(cfg.LocalExpire)*time.Second, time.Minute),
+		remote:      remote,
+		broadcaster: broadcaster,
+		expire:      time.Duration(cfg.LocalExpire) * time.Second,
+		ttls:        cfg.TTLs,
+		log:         logger.GetSession("cache.tiered", logger.CreateSession()),
+	}
+	if broadcaster == nil {
+		return c, nil
+	}
+	if err := broadcaster.Subscribe(c.recv, c.flush); err != nil {
+		return nil, fmt.Errorf("订阅缓存失效通知失败:%w", err)
+	}
+	return c, nil
+}
+
+//GetServers 获取服务器列表
+func (c *Client) GetServers() []string {
+	if ext, ok := c.remote.(cache.ICacheExt); ok {
+		return ext.GetServers()
+	}
+	return nil
+}
+
+//GetProto 获取服务类型
+func (c *Client) GetProto() string {
+	return Proto
+}
+
+//Get 获取数据，本地不存在时从远程缓存加载
+func (c *Client) Get(key string) (string, error) {
+	if v, ok := c.local.Get(key); ok {
+		return v.(string), nil
+	}
+	return c.loader.Do(key, func() (string, error) {
+		version := c.versions.Begin(key)
+		defer c.versions.End(key)
+		v, err := c.remote.Get(key)
+		if err != nil || v == "" {
+			return v, err
+		}
+		c.setLocal(key, v, 0, version)
+		return v, nil
+	})
+}
+
+//Load 获取数据，本地与远程缓存都不存在时调用loader加载并写入缓存，相同key并发加载只调用一次loader
+func (c *Client) Load(key string, expiresAt int, loader func() (string, error)) (string, error) {
+	if v, ok := c.local.Get(key); ok {
+		return v.(string), nil
+	}
+	return c.loader.Do(key, func() (string, error) {
+		version := c.versions.Begin(key)
+		defer c.versions.End(key)
+		v, err := c.remote.Get(key)
+		if err != nil {
+			return "", err
+		}
+		if v == "" {
+			if v, err = loader(); err != nil {
+				return "", err
+			}
+			if err = c.remote.Set(key, v, expiresAt); err != nil {
+				return "", err
+			}
+		}
+		c.setLocal(key, v, expiresAt, version)
+		return v, nil
+	})
+}
+
+//Gets 获取多条数据
+func (c *Client) Gets(key ...string) (r []string, err error) {
+	r = make([]string, 0, len(key))
+	for _, k := range key {
+		v, err := c.Get(k)
+		if err != nil {
+			return nil, err
+		}
+		r = append(r, v)
+	}
+	return r, nil
+}
+
+//Add 添加数据，远程缓存已存在时返回错误
+func (c *Client) Add(key string, value string, expiresAt int) error {
+	if err := c.remote.Add(key, value, expiresAt); err != nil {
+		return err
+	}
+	return c.update(key, value, expiresAt)
+}
+
+//Set 更新数据，没有则添加
+func (c *Client) Set(key string, value string, expiresAt int) error {
+	if err := c.remote.Set(key, value, expiresAt); err != nil {
+		return err
+	}
+	return c.update(key, value, expiresAt)
+}
+
+//Delete 删除数据，支持*模糊匹配(远程缓存支持时)
+func (c *Client) Delete(key string) error {
+	if err := c.remote.Delete(key); err != nil {
+		return err
+	}
+	return c.invalidate(key)
+}
+
+//Exists 查询key是否存在
+func (c *Client) Exists(key string) bool {
+	if _, ok := c.local.Get(key); ok {
+		return true
+	}
+	return c.remote.Exists(key)
+}
+
+//Delay 延长数据在远程缓存中的时间，本地缓存将重新加载
+func (c *Client) Delay(key string, expiresAt int) error {
+	if err := c.remote.Delay(key, expiresAt); err != nil {
+		return err
+	}
+	return c.invalidate(key)
+}
+
+//Increment 增加变量的值
+func (c *Client) Increment(key string, delta int64) (n int64, err error) {
+	if n, err = c.remote.Increment(key, delta); err != nil {
+		return
+	}
+	return n, c.invalidate(key)
+}
+
+//Decrement 减少变量的值
+func (c *Client) Decrement(key string, delta int64) (n int64, err error) {
+	if n, err = c.remote.Decrement(key, delta); err != nil {
+		return
+	}
+	return n, c.invalidate(key)
+}
+
+//Close 关闭失效通知与远程缓存连接
+func (c *Client) Close() error {
+	if c.broadcaster != nil {
+		c.broadcaster.Close()
+	}
+	return c.remote.Close()
+}
+
+//update 更新本地缓存，并通知其它服务器清除本地缓存
+func (c *Client) update(key string, value string, expiresAt int) error {
+	c.clear(key)
+	version := c.versions.Begin(key)
+	c.setLocal(key, value, expiresAt, version)
+	c.versions.End(key)
+	return c.publish(key)
+}
+
+//invalidate 清除本地缓存，并通知其它服务器清除本地缓存
+func (c *Client) invalidate(key string) error {
+	c.clear(key)
+	return c.publish(key)
+}
+
+func (c *Client) publish(keys ...string) error {
+	if c.broadcaster == nil {
+		return nil
+	}
+	buff, _ := json.Marshal(&message{ID: c.id, Keys: keys})
+	if err := c.broadcaster.Publish(string(buff)); err != nil {
+		return fmt.Errorf("发送缓存失效通知失败:%w", err)
+	}
+	return nil
+}
+
+//recv 接收其它服务器的失效通知
+func (c *Client) recv(msg string) {
+	m := &message{}
+	if err := json.Unmarshal([]byte(msg), m); err != nil {
+		c.log.Error("缓存失效通知格式有误", msg, err)
+		return
+	}
+	if m.ID == c.id {
+		return
+	}
+	c.clear(m.Keys...)
+}
+
+//clear 清除本地缓存，key支持*模糊匹配，清除后正在加载的相同key的数据不再写入本地缓存
+func (c *Client) clear(keys ...string) {
+	c.versions.Incr(keys...)
+	for _, key := range keys {
+		if !strings.Contains(key, "*") {
+			c.local.Delete(key)
+			continue
+		}
+		for k := range c.local.Items() {
+			if ok, _ := path.Match(key, k); ok {
+				c.local.Delete(k)
+			}
+		}
+	}
+}
+
+//flush 清除全部本地缓存，失效通知可能丢失(如订阅连接中断)时调用
+func (c *Client) flush() {
+	c.versions.IncrAll()
+	c.local.Flush()
+}
+
+//setLocal 写入本地缓存，加载期间key的本地缓存已被清除时不写入
+func (c *Client) setLocal(key string, value string, expiresAt int, version uint64) {
+	expire := c.ttl(key, expiresAt)
+	if expire <= 0 || c.versions.Changed(key, version) {
+		return
+	}
+	c.local.Set(key, value, expire)
+}
+
+//ttl 获取key的本地缓存时长，不超过数据在远程缓存中的时长
+func (c *Client) ttl(key string, expiresAt int) time.Duration {
+	expire := c.expire
+	for _, t := range c.ttls {
+		if ok, _ := path.Match(t.Key, key); ok {
+			expire = time.Duration(t.Expire) * time.Second
+			break
+		}
+	}
+	if expiresAt > 0 && time.Duration(expiresAt)*time.Second < expire {
+		expire = time.Duration(expiresAt) * time.Second
+	}
+	return expire
+}
+
+type cacheResolver struct {
+}
+
+func (s *cacheResolver) Resolve(conf string) (cache.ICache, error) {
+	return NewByConfig(vartiered.NewByRaw(conf))
+}
+func init() {
+	cache.Register(Proto, &cacheResolver{})
+}
@@ -0,0 +1,202 @@
+package tiered
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/micro-plat/hydra/components/caches/cache"
+	"github.com/micro-plat/hydra/components/caches/cache/gocache"
+	vartiered "github.com/micro-plat/hydra/conf/vars/cache/tiered"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+//hub 测试用失效通知广播，同步发送给所有订阅者
+type hub struct {
+	lock      sync.Mutex
+	callbacks []func(string)
+	resets    []func()
+}
+
+func (h *hub) Publish(message string) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	for _, cb := range h.callbacks {
+		cb(message)
+	}
+	return nil
+}
+
+func (h *hub) Subscribe(callback func(message string), reset func()) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.callbacks = append(h.callbacks, callback)
+	h.resets = append(h.resets, reset)
+	return nil
+}
+
+func (h *hub) Close() error {
+	return nil
+}
+
+func newTestClient(t *testing.T, remote cache.ICache, h *hub, opts ...vartiered.Option) *Client {
+	c, err := NewByCache(remote, h, vartiered.New(map[string]interface{}{"proto": "gocache"}, opts...))
+	assert.Equal(t, nil, err, "构建二级缓存")
+	return c
+}
+
+func TestClient_Get(t *testing.T) {
+	remote, _ := gocache.NewByOpts()
+	c := newTestClient(t, remote, &hub{})
+
+	remote.Set("a", "1", 0)
+	v, err := c.Get("a")
+	assert.Equal(t, nil, err, "从远程缓存加载")
+	assert.Equal(t, "1", v, "远程缓存数据")
+
+	remote.Set("a", "2", 0)
+	v, _ = c.Get("a")
+	assert.Equal(t, "1", v, "优先使用本地缓存")
+
+	v, err = c.Get("none")
+	assert.Equal(t, nil, err, "获取不存在的数据")
+	assert.Equal(t, "", v, "不存在的数据")
+	_, ok := c.local.Get("none")
+	assert.Equal(t, false, ok, "不存在的数据不缓存到本地")
+}
+
+func TestClient_Invalidate(t *testing.T) {
+	remote, _ := gocache.NewByOpts()
+	h := &hub{}
+	a := newTestClient(t, remote, h)
+	b := newTestClient(t, remote, h)
+
+	assert.Equal(t, nil, a.Set("k", "1", 0), "写入数据")
+	v, _ := b.Get("k")
+	assert.Equal(t, "1", v, "其它服务器加载数据")
+
+	assert.Equal(t, nil, a.Set("k", "2", 0), "更新数据")
+	v, _ = b.Get("k")
+	assert.Equal(t, "2", v, "收到失效通知后重新加载")
+	_, ok := a.local.Get("k")
+	assert.Equal(t, true, ok, "不处理自己发出的失效通知")
+
+	assert.Equal(t, nil, a.Delete("k"), "删除数据")
+	v, _ = b.Get("k")
+	assert.Equal(t, "", v, "删除后其它服务器不再返回数据")
+
+	b.Set("user:1", "x", 0)
+	b.Set("user:2", "y", 0)
+	a.Get("user:1")
+	a.Get("user:2")
+	b.clear("user:*")
+	b.publish("user:*")
+	_, ok = a.local.Get("user:1")
+	assert.Equal(t, false, ok, "按通配符清除本地缓存")
+
+	a.Set("n", "1", 0)
+	b.Get("n")
+	n, err := a.Increment("n", 2)
+	assert.Equal(t, nil, err, "增加变量的值")
+	assert.Equal(t, int64(3), n, "变量的值")
+	v, _ = b.Get("n")
+	assert.Equal(t, "3", v, "变更后其它服务器重新加载")
+}
+
+func TestClient_TTL(t *testing.T) {
+	remote, _ := gocache.NewByOpts()
+	c := newTestClient(t, remote, &hub{}, vartiered.WithLocalExpire(60), vartiered.WithTTL("hot:*", 300), vartiered.WithTTL("nocache:*", 0))
+
+	assert.Equal(t, time.Minute, c.ttl("a", 0), "默认本地缓存时长")
+	assert.Equal(t, 5*time.Minute, c.ttl("hot:1", 0), "按key设置本地缓存时长")
+	assert.Equal(t, 10*time.Second, c.ttl("hot:1", 10), "不超过远程缓存时长")
+	assert.Equal(t, time.Duration(0), c.ttl("nocache:1", 0), "不缓存到本地")
+
+	assert.Equal(t, nil, c.Set("nocache:1", "1", 0), "写入数据")
+	_, ok := c.local.Get("nocache:1")
+	assert.Equal(t, false, ok, "本地缓存时长为0时不缓存到本地")
+	v, _ := c.Get("nocache:1")
+	assert.Equal(t, "1", v, "从远程缓存获取")
+}
+
+func TestClient_Load(t *testing.T) {
+	remote, _ := gocache.NewByOpts()
+	c := newTestClient(t, remote, &hub{})
+
+	var calls int32
+	loader := func() (string, error) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(time.Millisecond * 50)
+		return "loaded", nil
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := c.Load("x", 60, loader)
+			assert.Equal(t, nil, err, "加载数据")
+			assert.Equal(t, "loaded", v, "加载的数据")
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "并发加载只调用一次")
+	v, _ := remote.Get("x")
+	assert.Equal(t, "loaded", v, "加载的数据写入远程缓存")
+
+	c.clear("x")
+	v, _ = c.Load("x", 60, loader)
+	assert.Equal(t, "loaded", v, "从远程缓存加载")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "远程缓存存在时不调用loader")
+}
+
+func TestClient_ClearWhileLoading(t *testing.T) {
+	remote, _ := gocache.NewByOpts()
+	c := newTestClient(t, remote, &hub{})
+
+	//加载期间执行clear，返回加载结果并检查是否写入本地缓存
+	loadWith := func(key string, clear string) bool {
+		c.local.Flush()
+		v, err := c.Load(key, 60, func() (string, error) {
+			c.clear(clear)
+			return "loaded", nil
+		})
+		assert.Equal(t, nil, err, "加载数据")
+		assert.Equal(t, "loaded", v, "加载的数据")
+		remote.Delete(key)
+		_, ok := c.local.Get(key)
+		return ok
+	}
+	assert.Equal(t, true, loadWith("user:1", "user:2"), "清除其它key不影响加载中的数据")
+	assert.Equal(t, false, loadWith("user:1", "user:1"), "加载期间被清除的数据不写入本地缓存")
+	assert.Equal(t, false, loadWith("user:1", "user:*"), "按通配符清除加载中的数据")
+	assert.Equal(t, true, loadWith("order:1", "user:*"), "通配符不匹配时写入本地缓存")
+	assert.Equal(t, 0, len(c.versions.loads), "加载完成后不保留版本")
+}
+
+func TestNewQueueBroadcaster(t *testing.T) {
+	for _, proto := range []string{"redis", "lmq", "dmq"} {
+		_, err := newQueueBroadcaster(proto, "{}", "hydra:cache:invalidate")
+		assert.NotEqual(t, nil, err, "不支持广播的消息队列:"+proto)
+	}
+}
+
+func TestClient_Reset(t *testing.T) {
+	remote, _ := gocache.NewByOpts()
+	h := &hub{}
+	c := newTestClient(t, remote, h)
+
+	remote.Set("user/1", "1", 0)
+	remote.Set("b", "1", 0)
+	c.Get("user/1")
+	c.Get("b")
+	remote.Set("user/1", "2", 0)
+	remote.Set("b", "2", 0)
+
+	h.resets[0]()
+	v, _ := c.Get("user/1")
+	assert.Equal(t, "2", v, "订阅中断后清除全部本地缓存")
+	v, _ = c.Get("b")
+	assert.Equal(t, "2", v, "订阅中断后清除全部本地缓存")
+}
@@ -0,0 +1,84 @@
+package tiered
+
+import (
+	"path"
+	"strings"
+	"sync"
+)
+
+//loading 正在加载的key，version在加载期间被清除时增加
+type loading struct {
+	refs    int
+	version uint64
+}
+
+//versions 按key记录正在加载的数据版本，只保留加载中的key，清除某个key不影响其它key的加载
+type versions struct {
+	lock  sync.Mutex
+	loads map[string]*loading
+}
+
+//Begin 开始加载key，返回加载开始时的版本
+func (v *versions) Begin(key string) uint64 {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	if v.loads == nil {
+		v.loads = make(map[string]*loading)
+	}
+	l, ok := v.loads[key]
+	if !ok {
+		l = &loading{}
+		v.loads[key] = l
+	}
+	l.refs++
+	return l.version
+}
+
+//End 结束加载key，没有其它加载任务时不再记录
+func (v *versions) End(key string) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	l, ok := v.loads[key]
+	if !ok {
+		return
+	}
+	if l.refs--; l.refs <= 0 {
+		delete(v.loads, key)
+	}
+}
+
+//Changed 加载期间key是否已被清除
+func (v *versions) Changed(key string, version uint64) bool {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	l, ok := v.loads[key]
+	return !ok || l.version != version
+}
+
+//Incr 清除key时增加正在加载的key的版本，key支持*模糊匹配
+func (v *versions) Incr(keys ...string) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	for _, key := range keys {
+		if !strings.Contains(key, "*") {
+			if l, ok := v.loads[key]; ok {
+				l.version++
+			}
+			continue
+		}
+		for k, l := range v.loads {
+			if ok, _ := path.Match(key, k); ok {
+				l.version++
+			}
+		}
+	}
+}
+
+//IncrAll 清除全部key时增加所有正在加载的key的版本
+func (v *versions) IncrAll() {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	for _, l := range v.loads {
+		l.version++
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/micro-plat/hydra/global"
 	"github.com/micro-plat/hydra/registry"
 
+	_ "github.com/micro-plat/hydra/components/queues/mq/dmq"
 	_ "github.com/micro-plat/hydra/components/queues/mq/lmq"
 	_ "github.com/micro-plat/hydra/components/queues/mq/mqtt"
 	_ "github.com/micro-plat/hydra/components/queues/mq/redis"
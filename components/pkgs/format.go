@@ -40,7 +40,7 @@ func GetStringByHeader(name string, content interface{}, hd ...string) string {
 	header := make(map[string]string, 0)
 	if len(hd)%2 == 0 {
 		for i := 0; i < len(hd)/2; i++ {
-			header[fmt.Sprint(hd[i])] = hd[i+1]
+			header[fmt.Sprint(hd[i*2])] = hd[i*2+1]
 		}
 	}
 
@@ -6,6 +6,7 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
@@ -24,10 +25,56 @@ func (c *Client) Request(method string, url string, params string, charset strin
 // Request 发送http请求, method:http请求方法包括:get,post,delete,put等 url: 请求的HTTP地址,不包括参数,params:请求参数,
 // header,http请求头多个用/n分隔,每个键值之前用=号连接
 func (c *Client) HRequest(method string, url string, params string, charset string, header http.Header, cookies ...*http.Cookie) (content []byte, rspHeader http.Header, status int, err error) {
+	response, err := c.do(method, url, params, charset, header, cookies...)
+	if response != nil {
+		defer response.Body.Close()
+	}
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	rawBody, err := getBody(response)
+	if err != nil {
+		return nil, nil, status, err
+	}
+
+	body, err := ioutil.ReadAll(rawBody)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("body ReadAll err:%v", err)
+	}
+	rspHeader = response.Header
+	status = response.StatusCode
+	ct, err := encoding.DecodeBytes(body, charset)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("body charset err:%v", err)
+	}
+	content = ct
+	return
+}
+
+//Stream 发送http请求，返回未读取的响应内容，内容不进行字符集转换，由调用方负责关闭
+func (c *Client) Stream(method string, url string, params string, charset string, header http.Header, cookies ...*http.Cookie) (body io.ReadCloser, status int, err error) {
+	response, err := c.do(method, url, params, charset, header, cookies...)
+	if err != nil {
+		if response != nil {
+			response.Body.Close()
+		}
+		return nil, 0, err
+	}
+	rawBody, err := getBody(response)
+	if err != nil {
+		response.Body.Close()
+		return nil, response.StatusCode, err
+	}
+	return &responseBody{Reader: rawBody, Closer: response.Body}, response.StatusCode, nil
+}
+
+//do 构建并发送http请求
+func (c *Client) do(method string, url string, params string, charset string, header http.Header, cookies ...*http.Cookie) (*http.Response, error) {
 	method = strings.ToUpper(method)
 	req, err := http.NewRequest(method, url, encoding.GetEncodeReader([]byte(params), charset))
 	if err != nil {
-		return
+		return nil, err
 	}
 
 	for _, cookie := range cookies {
@@ -46,36 +93,33 @@ func (c *Client) HRequest(method string, url string, params string, charset stri
 
 	if ctx, ok := context.GetContext(); ok {
 		req.Header.Set(context.XRequestID, ctx.User().GetTraceID())
+		if tp := context.GetTraceParent(ctx); tp != "" {
+			req.Header.Set(context.XTraceParent, tp)
+		}
 	}
 	response, err := c.client.Do(req)
-	if response != nil {
-		defer response.Body.Close()
-	}
 	if err != nil {
-		return nil, nil, 0, fmt.Errorf("client.Do err:%v", err)
-	}
-
-	rawBody := response.Body
-	if strings.EqualFold(response.Header.Get("Content-Encoding"), "gzip") {
-		rawBody, err = gzip.NewReader(response.Body)
-		if err != nil {
-			err = fmt.Errorf("http resp unzip is failed,err: %w", err)
-			return nil, nil, status, err
-		}
+		return response, fmt.Errorf("client.Do err:%v", err)
 	}
+	return response, nil
+}
 
-	body, err := ioutil.ReadAll(rawBody)
-	if err != nil {
-		return nil, nil, 0, fmt.Errorf("body ReadAll err:%v", err)
+//getBody 获取响应内容，gzip压缩的内容自动解压
+func getBody(response *http.Response) (io.Reader, error) {
+	if !strings.EqualFold(response.Header.Get("Content-Encoding"), "gzip") {
+		return response.Body, nil
 	}
-	rspHeader = response.Header
-	status = response.StatusCode
-	ct, err := encoding.DecodeBytes(body, charset)
+	rawBody, err := gzip.NewReader(response.Body)
 	if err != nil {
-		return nil, nil, 0, fmt.Errorf("body charset err:%v", err)
+		return nil, fmt.Errorf("http resp unzip is failed,err: %w", err)
 	}
-	content = ct
-	return
+	return rawBody, nil
+}
+
+//responseBody 读取解压后的内容，关闭时关闭原始响应
+type responseBody struct {
+	io.Reader
+	io.Closer
 }
 
 func getCert(c *varhttp.HTTPConf) (*tls.Config, error) {
@@ -0,0 +1,166 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//PrometheusContentType prometheus文本格式
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+var quantiles = []float64{0.5, 0.75, 0.95, 0.99, 0.999}
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+//family 同名指标
+type family struct {
+	tp      string
+	samples []string
+}
+
+type families map[string]*family
+
+func (f families) add(name string, tp string, labels string, value float64) {
+	f.addSample(name, tp, name, labels, value)
+}
+
+//addSample 添加指标样本，summary的_sum与_count样本属于同一指标
+func (f families) addSample(fname string, tp string, name string, labels string, value float64) {
+	if _, ok := f[fname]; !ok {
+		f[fname] = &family{tp: tp}
+	}
+	f[fname].samples = append(f[fname].samples, name+labels+" "+formatFloat(value))
+}
+
+//PrometheusHandler 以prometheus文本格式输出统计数据
+func PrometheusHandler(r Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", PrometheusContentType)
+		WritePrometheus(w, r)
+	})
+}
+
+//WritePrometheus 将统计数据及运行时信息以prometheus文本格式输出，指标名称由统计项路径生成，
+//统计窗口作为标签输出，输出过程不修改统计数据
+func WritePrometheus(w io.Writer, r Registry) error {
+	f := make(families)
+	r.Each(func(name string, obj interface{}) {
+		rname, tags := splitGroup(name)
+		rname = promName(rname)
+		labels := promLabels(tags, "", "")
+		switch metric := obj.(type) {
+		case IQPS:
+			f.add(rname, "gauge", promLabels(tags, "window", "1m"), float64(metric.M1()))
+			f.add(rname, "gauge", promLabels(tags, "window", "5m"), float64(metric.M5()))
+			f.add(rname, "gauge", promLabels(tags, "window", "15m"), float64(metric.M15()))
+		case Counter:
+			f.add(rname, "gauge", labels, float64(metric.Count()))
+		case Gauge:
+			f.add(rname, "gauge", labels, float64(metric.Value()))
+		case GaugeFloat64:
+			f.add(rname, "gauge", labels, metric.Value())
+		case Histogram:
+			ms := metric.Snapshot()
+			writeSummary(f, rname, tags, ms.Percentiles(quantiles), float64(ms.Sum()), ms.Count(), 1)
+		case Meter:
+			ms := metric.Snapshot()
+			f.add(rname+"_total", "counter", labels, float64(ms.Count()))
+			f.add(rname+"_rate", "gauge", promLabels(tags, "window", "1m"), ms.Rate1())
+		case Timer:
+			ms := metric.Snapshot()
+			scale := float64(time.Second)
+			writeSummary(f, rname+"_seconds", tags, ms.Percentiles(quantiles), float64(ms.Sum())/scale, ms.Count(), scale)
+		}
+	})
+	writeRuntime(f)
+
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	bw := bufio.NewWriter(w)
+	for _, name := range names {
+		fmt.Fprintf(bw, "# TYPE %s %s\n", name, f[name].tp)
+		sort.Strings(f[name].samples)
+		for _, s := range f[name].samples {
+			bw.WriteString(s)
+			bw.WriteByte('\n')
+		}
+	}
+	return bw.Flush()
+}
+
+//writeSummary 以summary格式输出分位数，scale为分位数值的换算比例
+func writeSummary(f families, name string, tags map[string]string, ps []float64, sum float64, count int64, scale float64) {
+	for i, q := range quantiles {
+		f.add(name, "summary", promLabels(tags, "quantile", formatFloat(q)), ps[i]/scale)
+	}
+	f.addSample(name, "summary", name+"_sum", promLabels(tags, "", ""), sum)
+	f.addSample(name, "summary", name+"_count", promLabels(tags, "", ""), float64(count))
+}
+
+//writeRuntime 输出go运行时信息
+func writeRuntime(f families) {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	f.add("go_goroutines", "gauge", "", float64(runtime.NumGoroutine()))
+	f.add("go_memstats_alloc_bytes", "gauge", "", float64(ms.Alloc))
+	f.add("go_memstats_sys_bytes", "gauge", "", float64(ms.Sys))
+	f.add("go_memstats_heap_inuse_bytes", "gauge", "", float64(ms.HeapInuse))
+	f.add("go_memstats_heap_objects", "gauge", "", float64(ms.HeapObjects))
+	f.add("go_memstats_mallocs_total", "counter", "", float64(ms.Mallocs))
+	f.add("go_memstats_frees_total", "counter", "", float64(ms.Frees))
+	f.add("go_gc_cycles_total", "counter", "", float64(ms.NumGC))
+	f.add("go_gc_pause_seconds_total", "counter", "", float64(ms.PauseTotalNs)/float64(time.Second))
+}
+
+//promName 转换为prometheus指标名称
+func promName(name string) string {
+	name = invalidNameChars.ReplaceAllString(name, "_")
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
+
+//promLabels 构建标签，k不为空时追加到标签末尾
+func promLabels(tags map[string]string, k string, v string) string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	items := make([]string, 0, len(keys)+1)
+	for _, key := range keys {
+		items = append(items, fmt.Sprintf(`%s="%s"`, promName(key), labelEscaper.Replace(tags[key])))
+	}
+	if k != "" {
+		items = append(items, fmt.Sprintf(`%s="%s"`, k, labelEscaper.Replace(v)))
+	}
+	if len(items) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(items, ",") + "}"
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
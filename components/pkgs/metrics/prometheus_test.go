@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	r := NewRegistry()
+	GetOrRegisterCounter(MakeName("api.server.request", WORKING, "server", "api", "url", "/order/query"), r).Inc(2)
+	GetOrRegisterMeter(MakeName("api.server.response", METER, "url", "/order/query", "status", "200"), r).Mark(3)
+	GetOrRegisterTimer(MakeName("api.server.request", TIMER, "url", "/order/query"), r).Update(time.Millisecond * 100)
+	GetOrRegisterQPS(MakeName("api.server.request", QPS, "url", `/a"b`), r).Mark(1)
+
+	var buff bytes.Buffer
+	if err := WritePrometheus(&buff, r); err != nil {
+		t.Fatal(err)
+	}
+	out := buff.String()
+	for _, line := range []string{
+		"# TYPE api_server_request_working gauge",
+		`api_server_request_working{server="api",url="/order/query"} 2`,
+		"# TYPE api_server_response_meter_total counter",
+		`api_server_response_meter_total{status="200",url="/order/query"} 3`,
+		"# TYPE api_server_request_timer_seconds summary",
+		`api_server_request_timer_seconds{url="/order/query",quantile="0.5"} 0.1`,
+		`api_server_request_timer_seconds_sum{url="/order/query"} 0.1`,
+		`api_server_request_timer_seconds_count{url="/order/query"} 1`,
+		"# TYPE api_server_request_qps gauge",
+		`api_server_request_qps{url="/a\"b",window="1m"} 1`,
+		`api_server_request_qps{url="/a\"b",window="15m"} 1`,
+		`api_server_response_meter_rate{status="200",url="/order/query",window="1m"} 0`,
+		"# TYPE go_goroutines gauge",
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("输出中未包含:%s\n%s", line, out)
+		}
+	}
+	if strings.Contains(out, "# TYPE api_server_request_timer_seconds_sum") {
+		t.Error("summary的_sum不应单独声明类型")
+	}
+}
+
+func TestWritePrometheus_Scrape(t *testing.T) {
+	r := NewRegistry()
+	qps := GetOrRegisterQPS(MakeName("api.server.request", QPS, "url", "/order/query"), r)
+	qps.Mark(3)
+
+	//多次拉取不修改统计数据
+	var b1, b2 bytes.Buffer
+	WritePrometheus(&b1, r)
+	WritePrometheus(&b2, r)
+	line := `api_server_request_qps{url="/order/query",window="1m"} 3`
+	if !strings.Contains(b1.String(), line) || !strings.Contains(b2.String(), line) {
+		t.Errorf("输出中未包含:%s\n%s", line, b2.String())
+	}
+	if strings.Contains(b1.String(), "_m1") {
+		t.Error("统计窗口应作为标签输出")
+	}
+}
+
+func TestQPSC_value(t *testing.T) {
+	c := NewQPSC(60, 70)
+	c.mark(2, 100)
+	c.mark(3, 130)
+	if v := c.value(130); v != 5 {
+		t.Errorf("窗口内计数应为5,实际:%d", v)
+	}
+	if v := c.value(165); v != 3 {
+		t.Errorf("过期的计数不应统计,实际:%d", v)
+	}
+	if v := c.value(200); v != 0 {
+		t.Errorf("全部过期后计数应为0,实际:%d", v)
+	}
+	if c.counter != 5 {
+		t.Errorf("获取计数不应修改计数器,实际:%d", c.counter)
+	}
+}
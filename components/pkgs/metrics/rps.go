@@ -44,11 +44,17 @@ func (s *StandardRPS) Mark(i int32) {
 	s.lock.Unlock()
 }
 func (s *StandardRPS) M1() int32 {
-	return s.m1.counter
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.m1.Value()
 }
 func (s *StandardRPS) M5() int32 {
-	return s.m5.counter
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.m5.Value()
 }
 func (s *StandardRPS) M15() int32 {
-	return s.m15.counter
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.m15.Value()
 }
@@ -32,12 +32,34 @@ func (r *QPSC) Mark(new int32) {
 //mark 记录上次执行时间，超过时间间隔则清除counter
 //每一跳需清除中间秒数
 func (r *QPSC) mark(new int32, currentStep int64) {
-	lastStep := r.lastTicker
+	lastStep := atomic.LoadInt64(&r.lastTicker)
 	current := int(currentStep % int64(r.total))
 	atomic.AddInt32(&r.counter, -r.clear(lastStep, currentStep)) //6, 8(clear,1,2,7,8)
 	atomic.AddInt32(&r.counter, new)
 	atomic.AddInt32(&r.slots[current], new)
-	r.lastTicker = currentStep
+	atomic.StoreInt64(&r.lastTicker, currentStep)
+}
+
+//Value 获取截止到当前时间窗口内的计数，不修改计数器
+func (r *QPSC) Value() int32 {
+	return r.value(time.Now().Unix())
+}
+
+//value 累加[currentStep-length+1,lastTicker]时间段内的计数
+func (r *QPSC) value(currentStep int64) int32 {
+	last := atomic.LoadInt64(&r.lastTicker)
+	begin := currentStep - int64(r.length) + 1
+	if last < begin {
+		return 0
+	}
+	if last > currentStep {
+		last = currentStep
+	}
+	v := int32(0)
+	for i := begin; i <= last; i++ {
+		v += atomic.LoadInt32(&r.slots[int(i%int64(r.total))])
+	}
+	return v
 }
 
 func (r *QPSC) clear(l int64, n int64) (clearCounter int32) { //1-5:1,10:1,10 //2:1,3:1
@@ -33,12 +33,16 @@ func newQueue(proto string, confRaw string) (q *queue, err error) {
 
 //Send 发送消息
 func (q *queue) Send(key string, value interface{}, requestID ...string) error {
-	hd := make([]string, 0, 2)
+	hd := make([]string, 0, 4)
+	ctx, ok := context.GetContext()
 	if len(requestID) > 0 {
 		hd = append(hd, context.XRequestID, requestID[0])
-	} else {
-		if ctx, ok := context.GetContext(); ok {
-			hd = append(hd, context.XRequestID, ctx.User().GetTraceID())
+	} else if ok {
+		hd = append(hd, context.XRequestID, ctx.User().GetTraceID())
+	}
+	if ok {
+		if tp := context.GetTraceParent(ctx); tp != "" {
+			hd = append(hd, context.XTraceParent, tp)
 		}
 	}
 	return q.q.Push(global.MQConf.GetQueueName(key), pkgs.GetStringByHeader(key, value, hd...))
@@ -0,0 +1,151 @@
+package dmq
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/micro-plat/hydra/components/queues/mq"
+	"github.com/micro-plat/hydra/conf/vars/queue/dmq"
+	"github.com/micro-plat/lib4go/concurrent/cmap"
+	"github.com/micro-plat/lib4go/types"
+)
+
+//Consumer 基于本地磁盘的消息消费者，消息确认后消费位置才向后移动，
+//未确认的消息在重启或重新注册消费后重新投递
+type Consumer struct {
+	store   *store
+	queues  cmap.ConcurrentMap
+	closeCh chan struct{}
+	once    sync.Once
+}
+
+//NewConsumerByRaw 通过json原串创建消费者
+func NewConsumerByRaw(raw string) (*Consumer, error) {
+	conf, err := dmq.NewByRaw(raw)
+	if err != nil {
+		return nil, err
+	}
+	return NewConsumer(conf)
+}
+
+//NewConsumer 创建消费者
+func NewConsumer(conf *dmq.DMQ) (*Consumer, error) {
+	store, err := getStore(conf)
+	if err != nil {
+		return nil, err
+	}
+	return &Consumer{
+		store:   store,
+		queues:  cmap.New(4),
+		closeCh: make(chan struct{}),
+	}, nil
+}
+
+//Connect  连接服务器
+func (consumer *Consumer) Connect() (err error) {
+	return nil
+}
+
+//Consume 注册消费信息
+func (consumer *Consumer) Consume(queue string, concurrency int, callback func(mq.IMQCMessage)) (err error) {
+	if strings.EqualFold(queue, "") {
+		return errors.New("队列名字不能为空")
+	}
+	if callback == nil {
+		return errors.New("回调函数不能为nil")
+	}
+	q, err := consumer.store.getQueue(queue)
+	if err != nil {
+		return err
+	}
+	_, _, err = consumer.queues.SetIfAbsentCb(queue, func(input ...interface{}) (c interface{}, err error) {
+		//取消消费时未确认的消息从消费位置开始重新投递
+		if err := q.rewind(); err != nil {
+			return nil, err
+		}
+		unconsumeCh := make(chan struct{}, 1)
+		nconcurrency := types.GetMax(concurrency, 10)
+		msgChan := make(chan *Message, nconcurrency)
+		for i := 0; i < nconcurrency; i++ {
+			go func() {
+				for message := range msgChan {
+					if concurrency == 0 {
+						go callback(message)
+					} else {
+						callback(message)
+					}
+				}
+			}()
+		}
+		go func() {
+			defer close(msgChan)
+			for {
+				notify := q.wait()
+				offset, data, ok, err := q.next()
+				if err != nil {
+					consumer.store.log.Errorf("读取队列%s消息失败:%v", queue, err)
+					select {
+					case <-consumer.closeCh:
+						return
+					case <-unconsumeCh:
+						return
+					case <-time.After(time.Second):
+					}
+					continue
+				}
+				if ok {
+					select {
+					case msgChan <- newMessage(q, offset, data):
+						continue
+					case <-consumer.closeCh:
+						return
+					case <-unconsumeCh:
+						q.rewind() //已读取未投递的消息由下次消费重新投递
+						return
+					}
+				}
+				select {
+				case <-consumer.closeCh:
+					return
+				case <-unconsumeCh:
+					return
+				case <-notify:
+				}
+			}
+		}()
+		return unconsumeCh, nil
+	}, queue)
+	return
+}
+
+//UnConsume 取消注册消费
+func (consumer *Consumer) UnConsume(queue string) {
+	if c, ok := consumer.queues.Get(queue); ok {
+		close(c.(chan struct{}))
+	}
+	consumer.queues.Remove(queue)
+}
+
+//Close 关闭当前连接
+func (consumer *Consumer) Close() {
+	consumer.once.Do(func() {
+		close(consumer.closeCh)
+		consumer.queues.RemoveIterCb(func(key string, value interface{}) bool {
+			close(value.(chan struct{}))
+			return true
+		})
+		consumer.store.release()
+	})
+}
+
+type consumerResolver struct {
+}
+
+func (s *consumerResolver) Resolve(confRaw string) (mq.IMQC, error) {
+	return NewConsumerByRaw(confRaw)
+}
+func init() {
+	mq.RegisterConsumer("dmq", &consumerResolver{})
+}
@@ -0,0 +1,28 @@
+package dmq
+
+//Message 持久化队列消息
+type Message struct {
+	Message string
+	offset  int64
+	queue   *logQueue
+}
+
+//Ack 确定消息，消费位置向后移动
+func (m *Message) Ack() error {
+	return m.queue.ack(m.offset)
+}
+
+//Nack 取消消息，消息重新追加到队列尾部
+func (m *Message) Nack() error {
+	return m.queue.nack(m.offset, []byte(m.Message))
+}
+
+//GetMessage 获取消息
+func (m *Message) GetMessage() string {
+	return m.Message
+}
+
+//newMessage 创建消息
+func newMessage(queue *logQueue, offset int64, data []byte) *Message {
+	return &Message{Message: string(data), offset: offset, queue: queue}
+}
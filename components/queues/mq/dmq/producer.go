@@ -0,0 +1,78 @@
+package dmq
+
+import (
+	"github.com/micro-plat/hydra/components/queues/mq"
+	"github.com/micro-plat/hydra/conf/vars/queue/dmq"
+)
+
+//Producer 基于本地磁盘的消息生产者
+type Producer struct {
+	store *store
+}
+
+//NewProducerByRaw 通过json原串创建消息生产者
+func NewProducerByRaw(raw string) (*Producer, error) {
+	conf, err := dmq.NewByRaw(raw)
+	if err != nil {
+		return nil, err
+	}
+	return NewProducer(conf)
+}
+
+//NewProducer 创建消息生产者
+func NewProducer(conf *dmq.DMQ) (*Producer, error) {
+	store, err := getStore(conf)
+	if err != nil {
+		return nil, err
+	}
+	return &Producer{store: store}, nil
+}
+
+// Push 向队列尾部追加消息
+func (c *Producer) Push(key string, value string) error {
+	q, err := c.store.getQueue(key)
+	if err != nil {
+		return err
+	}
+	return q.append([]byte(value))
+}
+
+// Pop 取出并确认队列中的第一条未投递消息，队列为空时返回mq.Nil
+func (c *Producer) Pop(key string) (string, error) {
+	q, err := c.store.getQueue(key)
+	if err != nil {
+		return "", err
+	}
+	data, ok, err := q.pop()
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", mq.Nil
+	}
+	return string(data), nil
+}
+
+// Count 队列中未投递的消息数
+func (c *Producer) Count(key string) (int64, error) {
+	q, err := c.store.getQueue(key)
+	if err != nil {
+		return 0, err
+	}
+	return q.count(), nil
+}
+
+// Close 释放资源
+func (c *Producer) Close() error {
+	return c.store.release()
+}
+
+type producerResolver struct {
+}
+
+func (s *producerResolver) Resolve(confRaw string) (mq.IMQP, error) {
+	return NewProducerByRaw(confRaw)
+}
+func init() {
+	mq.RegisterProducer("dmq", &producerResolver{})
+}
@@ -0,0 +1,375 @@
+package dmq
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/micro-plat/hydra/conf/vars/queue/dmq"
+	"github.com/micro-plat/lib4go/logger"
+)
+
+//offsetFile 消费位置文件名
+const offsetFile = "offset"
+
+//logQueue 基于分段日志的持久化队列，消息按序号追加写入，
+//消费位置为最小未确认消息的序号，重启后从消费位置开始重新投递
+type logQueue struct {
+	lock      sync.Mutex
+	name      string
+	dir       string
+	conf      *dmq.DMQ
+	segments  []*segment
+	end       int64
+	cursor    int64
+	rseg      int
+	rpos      int64
+	commit    int64
+	committed int64
+	acked     map[int64]bool
+	dirty     bool
+	closed    bool
+	notify    chan struct{}
+	log       logger.ILogger
+}
+
+//openQueue 打开队列目录，恢复日志分段与消费位置
+func openQueue(dir string, name string, conf *dmq.DMQ, log logger.ILogger) (*logQueue, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建队列目录%s失败:%w", dir, err)
+	}
+	q := &logQueue{
+		name:   name,
+		dir:    dir,
+		conf:   conf,
+		acked:  make(map[int64]bool),
+		notify: make(chan struct{}),
+		log:    log,
+	}
+	commit, err := q.readOffset()
+	if err != nil {
+		return nil, err
+	}
+	bases, err := listSegments(dir)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(bases, func(i, j int) bool { return bases[i] < bases[j] })
+	if len(bases) == 0 {
+		bases = append(bases, commit)
+	}
+	for _, base := range bases {
+		s, err := openSegment(dir, base)
+		if err != nil {
+			q.closeSegments()
+			return nil, err
+		}
+		q.segments = append(q.segments, s)
+	}
+
+	last := q.segments[len(q.segments)-1]
+	q.end = last.end()
+	if first := q.segments[0].base; commit < first {
+		commit = first
+	}
+	if commit > q.end {
+		commit = q.end
+	}
+	q.commit, q.committed = commit, commit
+	if err := q.seek(commit); err != nil {
+		q.closeSegments()
+		return nil, err
+	}
+	return q, nil
+}
+
+//seek 将读取位置移动到指定序号
+func (q *logQueue) seek(offset int64) error {
+	q.rseg = len(q.segments) - 1
+	for i, s := range q.segments {
+		if offset < s.end() {
+			q.rseg = i
+			break
+		}
+	}
+	s := q.segments[q.rseg]
+	q.cursor, q.rpos = s.base, 0
+	for q.cursor < offset && q.cursor < s.end() {
+		_, next, err := s.read(q.rpos)
+		if err != nil {
+			return err
+		}
+		q.rpos = next
+		q.cursor++
+	}
+	return nil
+}
+
+//append 追加消息，当前分段超过大小限制时创建新分段
+func (q *logQueue) append(data []byte) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if q.closed {
+		return fmt.Errorf("队列%s已关闭", q.name)
+	}
+	last := q.segments[len(q.segments)-1]
+	if last.size >= q.conf.GetSegmentSize() && last.count > 0 {
+		if q.conf.GetSync() != dmq.SyncNone {
+			last.sync()
+		}
+		s, err := openSegment(q.dir, q.end)
+		if err != nil {
+			return err
+		}
+		q.segments = append(q.segments, s)
+		last = s
+	}
+	if err := last.append(data); err != nil {
+		return err
+	}
+	q.end++
+	if q.conf.GetSync() == dmq.SyncAlways {
+		if err := last.sync(); err != nil {
+			return err
+		}
+	} else {
+		q.dirty = true
+	}
+	close(q.notify)
+	q.notify = make(chan struct{})
+	return nil
+}
+
+//wait 获取新消息通知
+func (q *logQueue) wait() <-chan struct{} {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return q.notify
+}
+
+//next 读取下一条未投递的消息
+func (q *logQueue) next() (int64, []byte, bool, error) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return q.nextLocked()
+}
+
+func (q *logQueue) nextLocked() (int64, []byte, bool, error) {
+	if q.closed {
+		return 0, nil, false, fmt.Errorf("队列%s已关闭", q.name)
+	}
+	for q.cursor < q.end {
+		s := q.segments[q.rseg]
+		if q.cursor >= s.end() || q.rpos >= s.size {
+			if q.rseg >= len(q.segments)-1 {
+				break
+			}
+			q.rseg++
+			q.cursor, q.rpos = q.segments[q.rseg].base, 0
+			continue
+		}
+		data, next, err := s.read(q.rpos)
+		if err != nil {
+			return 0, nil, false, err
+		}
+		offset := q.cursor
+		q.rpos = next
+		q.cursor++
+		if q.acked[offset] { //重新投递时跳过已确认的消息
+			continue
+		}
+		return offset, data, true, nil
+	}
+	return 0, nil, false, nil
+}
+
+//rewind 将读取位置移回消费位置，重新投递已投递但未确认的消息
+func (q *logQueue) rewind() error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if q.closed {
+		return fmt.Errorf("队列%s已关闭", q.name)
+	}
+	return q.seek(q.commit)
+}
+
+//pop 读取并确认下一条消息
+func (q *logQueue) pop() ([]byte, bool, error) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	offset, data, ok, err := q.nextLocked()
+	if !ok || err != nil {
+		return nil, ok, err
+	}
+	q.ackLocked(offset)
+	return data, true, nil
+}
+
+//ack 确认消息，消费位置移动到最小未确认消息
+func (q *logQueue) ack(offset int64) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.ackLocked(offset)
+	if q.conf.GetSync() == dmq.SyncAlways && !q.closed {
+		return q.writeOffset()
+	}
+	return nil
+}
+
+func (q *logQueue) ackLocked(offset int64) {
+	if offset < q.commit {
+		return
+	}
+	q.acked[offset] = true
+	for q.acked[q.commit] {
+		delete(q.acked, q.commit)
+		q.commit++
+	}
+}
+
+//nack 将消息重新追加到队列尾部，并确认原消息
+func (q *logQueue) nack(offset int64, data []byte) error {
+	if err := q.append(data); err != nil {
+		return err
+	}
+	return q.ack(offset)
+}
+
+//count 未投递的消息数
+func (q *logQueue) count() int64 {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return q.end - q.cursor
+}
+
+//flush 将已写入的消息刷盘，并保存消费位置
+func (q *logQueue) flush() error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if q.closed {
+		return nil
+	}
+	return q.flushLocked()
+}
+
+func (q *logQueue) flushLocked() error {
+	if q.dirty && q.conf.GetSync() != dmq.SyncNone {
+		if err := q.segments[len(q.segments)-1].sync(); err != nil {
+			return err
+		}
+	}
+	q.dirty = false
+	return q.writeOffset()
+}
+
+//clean 清理已消费的分段，及超出保留大小或保留时长的分段，当前写入分段不清理
+func (q *logQueue) clean() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if q.closed {
+		return
+	}
+	var total int64
+	for _, s := range q.segments {
+		total += s.size
+	}
+	age := q.conf.GetRetentionAge()
+	for len(q.segments) > 1 {
+		s := q.segments[0]
+		consumed := s.end() <= q.commit
+		expired := age > 0 && time.Since(s.modTime) > age
+		oversize := q.conf.RetentionSize > 0 && total > q.conf.RetentionSize
+		if !consumed && !expired && !oversize {
+			return
+		}
+		if !consumed {
+			q.log.Warnf("队列%s的分段%s超出保留策略，清除未消费的消息%d条", q.name, filepath.Base(s.path), s.end()-q.commit)
+		}
+		if err := s.remove(); err != nil {
+			q.log.Errorf("删除分段%s失败:%v", s.path, err)
+		}
+		q.segments = q.segments[1:]
+		total -= s.size
+		first := q.segments[0].base
+		if q.commit < first {
+			for offset := range q.acked {
+				if offset < first {
+					delete(q.acked, offset)
+				}
+			}
+			q.commit = first
+		}
+		if q.rseg--; q.rseg < 0 {
+			q.rseg, q.rpos, q.cursor = 0, 0, first
+		}
+	}
+}
+
+//close 刷盘并关闭所有分段
+func (q *logQueue) close() error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if q.closed {
+		return nil
+	}
+	err := q.flushLocked()
+	q.closed = true
+	q.closeSegments()
+	close(q.notify)
+	return err
+}
+
+func (q *logQueue) closeSegments() {
+	for _, s := range q.segments {
+		s.close()
+	}
+}
+
+//readOffset 读取已保存的消费位置
+func (q *logQueue) readOffset() (int64, error) {
+	buff, err := ioutil.ReadFile(filepath.Join(q.dir, offsetFile))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	offset, err := strconv.ParseInt(strings.TrimSpace(string(buff)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("消费位置文件有误:%w", err)
+	}
+	return offset, nil
+}
+
+//writeOffset 保存消费位置，先写入临时文件再替换，避免写入中断导致文件损坏
+func (q *logQueue) writeOffset() error {
+	if q.commit == q.committed {
+		return nil
+	}
+	path := filepath.Join(q.dir, offsetFile)
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0664)
+	if err != nil {
+		return err
+	}
+	if _, err = f.WriteString(strconv.FormatInt(q.commit, 10)); err == nil && q.conf.GetSync() != dmq.SyncNone {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	q.committed = q.commit
+	return nil
+}
@@ -0,0 +1,180 @@
+package dmq
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/micro-plat/hydra/components/queues/mq"
+	"github.com/micro-plat/hydra/conf/vars/queue/dmq"
+	"github.com/micro-plat/lib4go/assert"
+	"github.com/micro-plat/lib4go/logger"
+)
+
+func TestProducer_Replay(t *testing.T) {
+	path := t.TempDir()
+	conf := dmq.New(path, dmq.WithSync(dmq.SyncAlways))
+	p, err := NewProducer(conf)
+	assert.Equal(t, nil, err, "创建生产者")
+	for _, v := range []string{"a", "b", "c"} {
+		assert.Equal(t, nil, p.Push("order:pay", v), "写入消息")
+	}
+	v, err := p.Pop("order:pay")
+	assert.Equal(t, nil, err, "取出消息")
+	assert.Equal(t, "a", v, "取出第一条消息")
+
+	//读取但未确认的消息在重启后重新投递
+	q, _ := p.store.getQueue("order:pay")
+	offset, data, ok, _ := q.next()
+	assert.Equal(t, true, ok, "读取消息")
+	assert.Equal(t, "b", string(data), "读取第二条消息")
+	assert.Equal(t, int64(1), offset, "消息序号")
+	assert.Equal(t, nil, p.Close(), "关闭生产者")
+
+	p, _ = NewProducer(conf)
+	defer p.Close()
+	n, _ := p.Count("order:pay")
+	assert.Equal(t, int64(2), n, "重启后未确认消息数")
+	v, _ = p.Pop("order:pay")
+	assert.Equal(t, "b", v, "重新投递未确认消息")
+	v, _ = p.Pop("order:pay")
+	assert.Equal(t, "c", v, "第三条消息")
+	_, err = p.Pop("order:pay")
+	assert.Equal(t, mq.Nil, err, "队列为空")
+}
+
+func TestQueue_Recover(t *testing.T) {
+	dir := t.TempDir()
+	conf := dmq.New(dir, dmq.WithSync(dmq.SyncNone))
+	q, err := openQueue(dir, "recover", conf, logger.New("dmq"))
+	assert.Equal(t, nil, err, "打开队列")
+	q.append([]byte("hello"))
+	q.append([]byte("world"))
+	q.close()
+
+	//模拟写入过程中崩溃，末尾消息不完整
+	f, _ := os.OpenFile(filepath.Join(dir, "00000000000000000000.log"), os.O_WRONLY|os.O_APPEND, 0664)
+	f.Write([]byte{0, 0, 0, 9, 1, 2})
+	f.Close()
+
+	q, err = openQueue(dir, "recover", conf, logger.New("dmq"))
+	assert.Equal(t, nil, err, "恢复队列")
+	defer q.close()
+	assert.Equal(t, int64(2), q.count(), "截断不完整的消息")
+	assert.Equal(t, nil, q.append([]byte("again")), "继续写入")
+	for _, want := range []string{"hello", "world", "again"} {
+		data, ok, err := q.pop()
+		assert.Equal(t, nil, err, "读取消息")
+		assert.Equal(t, true, ok, "存在消息")
+		assert.Equal(t, want, string(data), "消息内容")
+	}
+}
+
+func TestQueue_Clean(t *testing.T) {
+	dir := t.TempDir()
+	conf := dmq.New(dir, dmq.WithSegmentSize(10), dmq.WithRetention(40, 0))
+	q, _ := openQueue(dir, "clean", conf, logger.New("dmq"))
+	defer q.close()
+	for i := 0; i < 6; i++ {
+		q.append([]byte("0123456789"))
+	}
+	assert.Equal(t, 6, len(q.segments), "按大小分段")
+
+	//乱序确认，消费位置只移动到最小未确认消息
+	o1, _, _, _ := q.next()
+	o2, _, _, _ := q.next()
+	q.ack(o2)
+	assert.Equal(t, int64(0), q.commit, "存在未确认消息")
+	q.ack(o1)
+	assert.Equal(t, int64(2), q.commit, "确认后移动消费位置")
+
+	//已消费的分段被删除，超出保留大小的分段即使未消费也被删除
+	q.clean()
+	assert.Equal(t, 2, len(q.segments), "保留的分段数")
+	assert.Equal(t, int64(4), q.commit, "消费位置移动到保留的首个分段")
+	data, ok, _ := q.pop()
+	assert.Equal(t, true, ok, "读取保留的消息")
+	assert.Equal(t, "0123456789", string(data), "消息内容")
+	assert.Equal(t, int64(1), q.count(), "剩余消息数")
+}
+
+func TestConsumer_Consume(t *testing.T) {
+	path := t.TempDir()
+	conf := dmq.New(path)
+	c, err := NewConsumer(conf)
+	assert.Equal(t, nil, err, "创建消费者")
+	defer c.Close()
+	p, _ := NewProducer(conf)
+	defer p.Close()
+
+	recv := make(chan string, 2)
+	nacked := false
+	c.Consume("notify", 1, func(m mq.IMQCMessage) {
+		if !nacked {
+			nacked = true
+			m.Nack()
+			return
+		}
+		recv <- m.GetMessage()
+		m.Ack()
+	})
+	p.Push("notify", "msg")
+	select {
+	case v := <-recv:
+		assert.Equal(t, "msg", v, "重新投递的消息")
+	case <-time.After(3 * time.Second):
+		t.Fatal("未收到消息")
+	}
+}
+
+func TestConsumer_ReConsume(t *testing.T) {
+	path := t.TempDir()
+	conf := dmq.New(path)
+	c, err := NewConsumer(conf)
+	assert.Equal(t, nil, err, "创建消费者")
+	defer c.Close()
+	p, _ := NewProducer(conf)
+	defer p.Close()
+	for _, v := range []string{"a", "b", "c"} {
+		p.Push("reconsume", v)
+	}
+
+	recv := make(chan mq.IMQCMessage, 3)
+	c.Consume("reconsume", 1, func(m mq.IMQCMessage) { recv <- m })
+	msgs := make(map[string]mq.IMQCMessage)
+	for i := 0; i < 3; i++ {
+		select {
+		case m := <-recv:
+			msgs[m.GetMessage()] = m
+		case <-time.After(3 * time.Second):
+			t.Fatal("未收到消息")
+		}
+	}
+	msgs["b"].Ack()
+	c.UnConsume("reconsume")
+
+	//重新注册消费后，已投递未确认的消息重新投递，已确认的消息不再投递
+	got := make(chan string, 3)
+	c.Consume("reconsume", 1, func(m mq.IMQCMessage) {
+		got <- m.GetMessage()
+		m.Ack()
+	})
+	redelivered := make([]string, 0, 2)
+	for i := 0; i < 2; i++ {
+		select {
+		case v := <-got:
+			redelivered = append(redelivered, v)
+		case <-time.After(3 * time.Second):
+			t.Fatal("未重新投递消息")
+		}
+	}
+	sort.Strings(redelivered)
+	assert.Equal(t, []string{"a", "c"}, redelivered, "重新投递未确认的消息")
+	select {
+	case v := <-got:
+		t.Fatalf("重复投递已确认的消息:%s", v)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
@@ -0,0 +1,150 @@
+package dmq
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//headerSize 消息头长度，4字节消息长度+4字节crc32校验码
+const headerSize = 8
+
+//segmentExt 日志分段文件扩展名
+const segmentExt = ".log"
+
+//segment 日志分段，文件名为分段中第一条消息的序号，消息按追加方式写入
+type segment struct {
+	base    int64
+	count   int64
+	size    int64
+	path    string
+	file    *os.File
+	modTime time.Time
+}
+
+//openSegment 打开或创建日志分段，并截断末尾未完整写入的消息
+func openSegment(dir string, base int64) (*segment, error) {
+	path := filepath.Join(dir, fmt.Sprintf("%020d%s", base, segmentExt))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0664)
+	if err != nil {
+		return nil, err
+	}
+	s := &segment{base: base, path: path, file: file}
+	if err := s.recover(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("恢复日志分段%s失败:%w", path, err)
+	}
+	return s, nil
+}
+
+//recover 扫描分段中的消息，校验失败或不完整的消息及其后数据将被截断
+func (s *segment) recover() error {
+	info, err := s.file.Stat()
+	if err != nil {
+		return err
+	}
+	s.modTime = info.ModTime()
+	for {
+		_, next, err := s.readAt(s.size, info.Size())
+		if err != nil {
+			break
+		}
+		s.size = next
+		s.count++
+	}
+	if s.size < info.Size() {
+		return s.file.Truncate(s.size)
+	}
+	return nil
+}
+
+//append 追加消息
+func (s *segment) append(data []byte) error {
+	buff := make([]byte, headerSize+len(data))
+	binary.BigEndian.PutUint32(buff[0:4], uint32(len(data)))
+	binary.BigEndian.PutUint32(buff[4:8], crc32.ChecksumIEEE(data))
+	copy(buff[headerSize:], data)
+	if _, err := s.file.WriteAt(buff, s.size); err != nil {
+		return err
+	}
+	s.size += int64(len(buff))
+	s.count++
+	s.modTime = time.Now()
+	return nil
+}
+
+//read 读取指定位置的消息，并返回下一条消息的位置
+func (s *segment) read(pos int64) ([]byte, int64, error) {
+	return s.readAt(pos, s.size)
+}
+
+//readAt 读取指定位置的消息，消息不能超出limit位置
+func (s *segment) readAt(pos int64, limit int64) ([]byte, int64, error) {
+	if pos+headerSize > limit {
+		return nil, pos, io.EOF
+	}
+	header := make([]byte, headerSize)
+	if _, err := s.file.ReadAt(header, pos); err != nil {
+		return nil, pos, err
+	}
+	size := int64(binary.BigEndian.Uint32(header[0:4]))
+	if pos+headerSize+size > limit {
+		return nil, pos, io.ErrUnexpectedEOF
+	}
+	data := make([]byte, size)
+	if _, err := s.file.ReadAt(data, pos+headerSize); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, pos, err
+	}
+	if crc32.ChecksumIEEE(data) != binary.BigEndian.Uint32(header[4:8]) {
+		return nil, pos, fmt.Errorf("消息校验失败:%s(%d)", s.path, pos)
+	}
+	return data, pos + headerSize + size, nil
+}
+
+//end 分段中最后一条消息之后的序号
+func (s *segment) end() int64 {
+	return s.base + s.count
+}
+
+func (s *segment) sync() error {
+	return s.file.Sync()
+}
+
+func (s *segment) close() error {
+	return s.file.Close()
+}
+
+//remove 关闭并删除分段文件
+func (s *segment) remove() error {
+	s.file.Close()
+	return os.Remove(s.path)
+}
+
+//listSegments 获取目录中所有日志分段的起始序号
+func listSegments(dir string) ([]int64, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	bases := make([]int64, 0, len(files))
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), segmentExt) {
+			continue
+		}
+		base, err := strconv.ParseInt(strings.TrimSuffix(f.Name(), segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		bases = append(bases, base)
+	}
+	return bases, nil
+}
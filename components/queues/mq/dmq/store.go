@@ -0,0 +1,121 @@
+package dmq
+
+import (
+	"net/url"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/micro-plat/hydra/conf/vars/queue/dmq"
+	"github.com/micro-plat/lib4go/logger"
+)
+
+//cleanInterval 分段清理间隔
+const cleanInterval = 30 * time.Second
+
+//store 存储目录，同一目录的生产者与消费者共用队列
+type store struct {
+	lock    sync.Mutex
+	path    string
+	conf    *dmq.DMQ
+	queues  map[string]*logQueue
+	refs    int
+	closeCh chan struct{}
+	log     logger.ILogger
+}
+
+var stores = make(map[string]*store)
+var storeLock sync.Mutex
+
+//getStore 获取存储目录，引用计数加1
+func getStore(conf *dmq.DMQ) (*store, error) {
+	path, err := filepath.Abs(conf.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	storeLock.Lock()
+	defer storeLock.Unlock()
+	if s, ok := stores[path]; ok {
+		s.refs++
+		return s, nil
+	}
+	s := &store{
+		path:    path,
+		conf:    conf,
+		queues:  make(map[string]*logQueue),
+		refs:    1,
+		closeCh: make(chan struct{}),
+		log:     logger.GetSession("mq.dmq", logger.CreateSession()),
+	}
+	stores[path] = s
+	go s.loop()
+	return s, nil
+}
+
+//getQueue 获取或打开队列
+func (s *store) getQueue(name string) (*logQueue, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if q, ok := s.queues[name]; ok {
+		return q, nil
+	}
+	q, err := openQueue(filepath.Join(s.path, url.QueryEscape(name)), name, s.conf, s.log)
+	if err != nil {
+		return nil, err
+	}
+	s.queues[name] = q
+	return q, nil
+}
+
+//all 获取所有已打开的队列
+func (s *store) all() []*logQueue {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	list := make([]*logQueue, 0, len(s.queues))
+	for _, q := range s.queues {
+		list = append(list, q)
+	}
+	return list
+}
+
+//loop 定时刷盘并清理过期分段
+func (s *store) loop() {
+	flush := time.NewTicker(s.conf.GetSyncInterval())
+	clean := time.NewTicker(cleanInterval)
+	defer flush.Stop()
+	defer clean.Stop()
+	for {
+		select {
+		case <-s.closeCh:
+			return
+		case <-flush.C:
+			for _, q := range s.all() {
+				if err := q.flush(); err != nil {
+					s.log.Errorf("队列%s刷盘失败:%v", q.name, err)
+				}
+			}
+		case <-clean.C:
+			for _, q := range s.all() {
+				q.clean()
+			}
+		}
+	}
+}
+
+//release 引用计数减1，无引用时关闭所有队列
+func (s *store) release() error {
+	storeLock.Lock()
+	defer storeLock.Unlock()
+	if s.refs--; s.refs > 0 {
+		return nil
+	}
+	delete(stores, s.path)
+	close(s.closeCh)
+	var err error
+	for _, q := range s.all() {
+		if cerr := q.close(); cerr != nil {
+			err = cerr
+		}
+	}
+	return err
+}
@@ -1,6 +1,7 @@
 package redis
 
 import (
+	"fmt"
 	"strings"
 	"sync"
 	"time"
@@ -10,10 +11,12 @@ import (
 	"github.com/micro-plat/hydra/components/pkgs/redis"
 	"github.com/micro-plat/hydra/components/queues/mq"
 	"github.com/micro-plat/hydra/conf/vars/queue/queueredis"
+	"github.com/micro-plat/hydra/global"
 	varredis "github.com/micro-plat/hydra/conf/vars/redis"
 
 	"github.com/micro-plat/lib4go/concurrent/cmap"
 	"github.com/micro-plat/lib4go/logger"
+	"github.com/micro-plat/lib4go/types"
 	"github.com/zkfy/stompngo"
 )
 
@@ -35,17 +38,33 @@ type Consumer struct {
 	once       sync.Once
 	log        logger.ILogger
 	ConfOpts   *varredis.Redis
+	id         string
+	aliveTTL   time.Duration
+}
+
+//Option 消费者配置选项
+type Option func(*Consumer)
+
+//WithReliable 启用可靠消费模式，ttl为消费者心跳超时时长，超时后其处理中的消息将被其它消费者回收
+func WithReliable(ttl time.Duration) Option {
+	return func(c *Consumer) {
+		c.aliveTTL = ttl
+	}
 }
 
 //NewConsumerByRaw 创建新的Consumer
-func NewConsumerByRaw(cfg string) (consumer *Consumer, err error) {
-	return NewConsumerByConfig(varredis.NewByRaw(cfg))
+func NewConsumerByRaw(cfg string, opts ...Option) (consumer *Consumer, err error) {
+	return NewConsumerByConfig(varredis.NewByRaw(cfg), opts...)
 }
 
 //NewConsumerByConfig 创建新的Consumer
-func NewConsumerByConfig(cfg *varredis.Redis) (consumer *Consumer, err error) {
+func NewConsumerByConfig(cfg *varredis.Redis, opts ...Option) (consumer *Consumer, err error) {
 	consumer = &Consumer{log: logger.GetSession("mq.redis", logger.CreateSession())}
 	consumer.ConfOpts = cfg
+	consumer.id = fmt.Sprintf("%s_%s", global.LocalIP(), logger.CreateSession())
+	for _, opt := range opts {
+		opt(consumer)
+	}
 
 	consumer.closeCh = make(chan struct{})
 	consumer.queues = cmap.New(2)
@@ -75,6 +94,15 @@ func (consumer *Consumer) Consume(queue string, concurrency int, callback func(m
 			nconcurrency = 10
 		}
 		msgChan := make(chan *RedisMessage, nconcurrency)
+		fetch := consumer.pop
+		if consumer.aliveTTL > 0 {
+			if consumer.client == nil {
+				return nil, errors.New("可靠消费模式需先连接redis服务器")
+			}
+			rl := newReliable(consumer.client, consumer.id, consumer.aliveTTL, consumer.log)
+			fetch = rl.fetch
+			go rl.run(queue, consumer.closeCh, unconsumeCh)
+		}
 		for i := 0; i < nconcurrency; i++ {
 			go func() {
 			START:
@@ -96,6 +124,8 @@ func (consumer *Consumer) Consume(queue string, concurrency int, callback func(m
 		}
 
 		go func() {
+			interval := time.Millisecond * (time.Duration((1000 / nconcurrency / 2)) + 1)
+			wait := interval
 		START:
 			for {
 				select {
@@ -103,18 +133,24 @@ func (consumer *Consumer) Consume(queue string, concurrency int, callback func(m
 					break START
 				case <-unconsumeCh:
 					break START
-				case <-time.After(time.Millisecond * (time.Duration((1000 / nconcurrency / 2)) + 1)):
+				case <-time.After(wait):
 					if consumer.client != nil && !consumer.done {
-						cmd := consumer.client.BLPop(time.Second, queue)
-						if err := cmd.Err(); err != nil {
+						message, err := fetch(queue)
+						if err != nil {
 							if !consumer.done && err.Error() != "redis: nil" {
 								consumer.log.Error("从redis中获取消息失败:%w", err)
 							}
 							continue
 						}
-						message := NewRedisMessage(cmd)
 						if message.Has() {
+							wait = interval
 							msgChan <- message
+							continue
+						}
+
+						//可靠消费模式的获取不阻塞，队列为空时逐步延长获取间隔
+						if consumer.aliveTTL > 0 {
+							wait = idleWait(wait)
 						}
 					}
 
@@ -127,6 +163,26 @@ func (consumer *Consumer) Consume(queue string, concurrency int, callback func(m
 	return
 }
 
+//maxIdleWait 可靠消费模式下队列为空时的最大获取间隔
+const maxIdleWait = time.Second
+
+//idleWait 队列为空时获取间隔加倍，不超过maxIdleWait
+func idleWait(wait time.Duration) time.Duration {
+	if wait *= 2; wait > maxIdleWait {
+		return maxIdleWait
+	}
+	return wait
+}
+
+//pop 以阻塞方式从队列头部取出消息
+func (consumer *Consumer) pop(queue string) (*RedisMessage, error) {
+	cmd := consumer.client.BLPop(time.Second, queue)
+	if err := cmd.Err(); err != nil {
+		return nil, err
+	}
+	return NewRedisMessage(cmd), nil
+}
+
 //UnConsume 取消注册消费
 func (consumer *Consumer) UnConsume(queue string) {
 	if consumer.client == nil {
@@ -161,7 +217,12 @@ type cresolver struct {
 }
 
 func (s *cresolver) Resolve(confRaw string) (mq.IMQC, error) {
-	return NewConsumerByRaw(queueredis.NewByRaw(confRaw).GetRaw())
+	qconf := queueredis.NewByRaw(confRaw)
+	opts := make([]Option, 0, 1)
+	if qconf.Reliable {
+		opts = append(opts, WithReliable(time.Duration(types.GetInt(qconf.AliveTimeout, 30))*time.Second))
+	}
+	return NewConsumerByRaw(qconf.GetRaw(), opts...)
 }
 func init() {
 	mq.RegisterConsumer(Proto, &cresolver{})
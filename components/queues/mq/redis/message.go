@@ -6,18 +6,26 @@ import (
 
 //RedisMessage reids消息
 type RedisMessage struct {
-	Message string
-	HasData bool
+	Message  string
+	HasData  bool
+	queue    string
+	reliable *reliable
 }
 
-//Ack 确定消息
+//Ack 确定消息，可靠消费模式下从处理中列表删除
 func (m *RedisMessage) Ack() error {
-	return nil
+	if m.reliable == nil {
+		return nil
+	}
+	return m.reliable.ack(m.queue, m.Message)
 }
 
-//Nack 取消消息
+//Nack 取消消息，可靠消费模式下重新放回队列
 func (m *RedisMessage) Nack() error {
-	return nil
+	if m.reliable == nil {
+		return nil
+	}
+	return m.reliable.nack(m.queue, m.Message)
 }
 
 //GetMessage 获取消息
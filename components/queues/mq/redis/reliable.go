@@ -0,0 +1,150 @@
+package redis
+
+import (
+	"fmt"
+	"time"
+
+	rds "github.com/go-redis/redis"
+	"github.com/micro-plat/hydra/components/pkgs/redis"
+	"github.com/micro-plat/lib4go/logger"
+)
+
+//fetchScript 从队列头部取出消息并放入当前消费者的处理中列表
+var fetchScript = rds.NewScript(`
+local v = redis.call('LPOP', KEYS[1])
+if v then
+	redis.call('RPUSH', KEYS[2], v)
+end
+return v`)
+
+//nackScript 将消息从处理中列表移除并重新放回队列尾部
+var nackScript = rds.NewScript(`
+local n = redis.call('LREM', KEYS[2], 1, ARGV[1])
+if n > 0 then
+	redis.call('RPUSH', KEYS[1], ARGV[1])
+end
+return n`)
+
+//reclaimScript 将已失效消费者处理中的消息全部放回队列头部
+var reclaimScript = rds.NewScript(`
+local n = 0
+while true do
+	local v = redis.call('RPOP', KEYS[2])
+	if not v then
+		break
+	end
+	redis.call('LPUSH', KEYS[1], v)
+	n = n + 1
+end
+redis.call('SREM', KEYS[3], ARGV[1])
+return n`)
+
+//reliable 可靠消费模式，消息被取出时转移到消费者的处理中列表，确认后删除，
+//消费者失效后由其它消费者回收并重新放回队列
+type reliable struct {
+	client *redis.Client
+	id     string
+	ttl    time.Duration
+	log    logger.ILogger
+}
+
+func newReliable(client *redis.Client, id string, ttl time.Duration, log logger.ILogger) *reliable {
+	return &reliable{client: client, id: id, ttl: ttl, log: log}
+}
+
+//processingKey 消费者处理中列表，使用{queue}哈希标签保证集群模式下与队列位于同一slot
+func processingKey(queue string, id string) string {
+	return fmt.Sprintf("{%s}:processing:%s", queue, id)
+}
+
+//consumersKey 队列的消费者集合
+func consumersKey(queue string) string {
+	return fmt.Sprintf("{%s}:consumers", queue)
+}
+
+//aliveKey 消费者心跳
+func aliveKey(queue string, id string) string {
+	return fmt.Sprintf("{%s}:alive:%s", queue, id)
+}
+
+//heartbeat 注册当前消费者并刷新心跳
+func (r *reliable) heartbeat(queue string) error {
+	pipe := r.client.TxPipeline()
+	pipe.SAdd(consumersKey(queue), r.id)
+	pipe.Set(aliveKey(queue, r.id), time.Now().Unix(), r.ttl)
+	_, err := pipe.Exec()
+	return err
+}
+
+//fetch 获取一条消息，队列为空时返回空消息
+func (r *reliable) fetch(queue string) (*RedisMessage, error) {
+	v, err := fetchScript.Run(r.client, []string{queue, processingKey(queue, r.id)}).Result()
+	if err == rds.Nil || v == nil {
+		return &RedisMessage{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	msg := fmt.Sprint(v)
+	return &RedisMessage{Message: msg, HasData: true, queue: queue, reliable: r}, nil
+}
+
+//ack 从处理中列表删除消息
+func (r *reliable) ack(queue string, msg string) error {
+	return r.client.LRem(processingKey(queue, r.id), 1, msg).Err()
+}
+
+//nack 将消息重新放回队列
+func (r *reliable) nack(queue string, msg string) error {
+	return nackScript.Run(r.client, []string{queue, processingKey(queue, r.id)}, msg).Err()
+}
+
+//reap 回收心跳已过期的消费者正在处理的消息
+func (r *reliable) reap(queue string) {
+	ids, err := r.client.SMembers(consumersKey(queue)).Result()
+	if err != nil {
+		r.log.Errorf("获取队列%s的消费者失败:%v", queue, err)
+		return
+	}
+	for _, id := range ids {
+		if id == r.id {
+			continue
+		}
+		n, err := r.client.Exists(aliveKey(queue, id)).Result()
+		if err != nil || n > 0 {
+			continue
+		}
+		c, err := reclaimScript.Run(r.client, []string{queue, processingKey(queue, id), consumersKey(queue)}, id).Int64()
+		if err != nil {
+			r.log.Errorf("回收消费者%s的消息失败:%v", id, err)
+			continue
+		}
+		if c > 0 {
+			r.log.Warnf("消费者%s已失效,回收%d条消息到队列%s", id, c, queue)
+		}
+	}
+}
+
+//run 定时刷新心跳并回收失效消费者的消息，直到关闭或取消消费
+func (r *reliable) run(queue string, closeCh <-chan struct{}, unconsumeCh <-chan struct{}) {
+	if err := r.heartbeat(queue); err != nil {
+		r.log.Errorf("注册消费者心跳失败:%v", err)
+	}
+	r.reap(queue)
+	tk := time.NewTicker(r.ttl / 3)
+	defer tk.Stop()
+	for {
+		select {
+		case <-closeCh:
+			return
+		case <-unconsumeCh:
+			return
+		case <-tk.C:
+			if err := r.heartbeat(queue); err != nil {
+				r.log.Errorf("刷新消费者心跳失败:%v", err)
+				continue
+			}
+			r.reap(queue)
+		}
+	}
+}
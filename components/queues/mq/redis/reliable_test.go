@@ -0,0 +1,129 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/micro-plat/hydra/components/pkgs/redis"
+	"github.com/micro-plat/hydra/components/queues/mq"
+	varredis "github.com/micro-plat/hydra/conf/vars/redis"
+	"github.com/micro-plat/lib4go/assert"
+	"github.com/micro-plat/lib4go/logger"
+)
+
+func runRedis(t *testing.T) *miniredis.Miniredis {
+	s, err := miniredis.Run()
+	assert.Equal(t, nil, err, "启动redis")
+	return s
+}
+
+func newTestReliable(t *testing.T, addr string, id string) *reliable {
+	client, err := redis.NewByOpts(varredis.WithAddrs(addr))
+	assert.Equal(t, nil, err, "创建redis客户端")
+	return newReliable(client, id, time.Second*3, logger.New("reliable"))
+}
+
+func TestReliable_Ack(t *testing.T) {
+	s := runRedis(t)
+	defer s.Close()
+	r := newTestReliable(t, s.Addr(), "c1")
+	s.RPush("q", "m1")
+
+	m, err := r.fetch("q")
+	assert.Equal(t, nil, err, "获取消息")
+	assert.Equal(t, true, m.Has(), "获取消息")
+	assert.Equal(t, "m1", m.GetMessage(), "获取消息")
+	list, _ := s.List(processingKey("q", "c1"))
+	assert.Equal(t, []string{"m1"}, list, "消息进入处理中列表")
+
+	assert.Equal(t, nil, m.Ack(), "确认消息")
+	assert.Equal(t, false, s.Exists(processingKey("q", "c1")), "确认后从处理中列表删除")
+	assert.Equal(t, false, s.Exists("q"), "确认后不再放回队列")
+
+	m, err = r.fetch("q")
+	assert.Equal(t, nil, err, "获取空队列")
+	assert.Equal(t, false, m.Has(), "获取空队列")
+}
+
+func TestReliable_Nack(t *testing.T) {
+	s := runRedis(t)
+	defer s.Close()
+	r := newTestReliable(t, s.Addr(), "c1")
+	s.RPush("q", "m1")
+	s.RPush("q", "m2")
+
+	m, err := r.fetch("q")
+	assert.Equal(t, nil, err, "获取消息")
+	assert.Equal(t, nil, m.Nack(), "取消消息")
+	assert.Equal(t, false, s.Exists(processingKey("q", "c1")), "取消后从处理中列表删除")
+	list, _ := s.List("q")
+	assert.Equal(t, []string{"m2", "m1"}, list, "取消后放回队列尾部")
+
+	//重复取消不会重复放回队列
+	assert.Equal(t, nil, m.Nack(), "重复取消消息")
+	list, _ = s.List("q")
+	assert.Equal(t, []string{"m2", "m1"}, list, "重复取消")
+}
+
+func TestReliable_Reap(t *testing.T) {
+	s := runRedis(t)
+	defer s.Close()
+	dead := newTestReliable(t, s.Addr(), "c1")
+	alive := newTestReliable(t, s.Addr(), "c2")
+	s.RPush("q", "m1")
+	s.RPush("q", "m2")
+	s.RPush("q", "m3")
+
+	assert.Equal(t, nil, dead.heartbeat("q"), "注册消费者")
+	assert.Equal(t, nil, alive.heartbeat("q"), "注册消费者")
+	dead.fetch("q")
+	dead.fetch("q")
+
+	//心跳未过期时不回收
+	alive.reap("q")
+	list, _ := s.List(processingKey("q", "c1"))
+	assert.Equal(t, []string{"m1", "m2"}, list, "心跳有效时不回收")
+
+	//心跳过期后回收到队列头部并移除消费者
+	s.FastForward(time.Second * 4)
+	assert.Equal(t, nil, alive.heartbeat("q"), "刷新心跳")
+	alive.reap("q")
+	assert.Equal(t, false, s.Exists(processingKey("q", "c1")), "回收处理中列表")
+	list, _ = s.List("q")
+	assert.Equal(t, []string{"m1", "m2", "m3"}, list, "回收后保持原有顺序")
+	ok, _ := s.SIsMember(consumersKey("q"), "c1")
+	assert.Equal(t, false, ok, "移除失效消费者")
+	ok, _ = s.SIsMember(consumersKey("q"), "c2")
+	assert.Equal(t, true, ok, "保留有效消费者")
+}
+
+func TestConsumer_ReliableIdle(t *testing.T) {
+	s := runRedis(t)
+	defer s.Close()
+	c, err := NewConsumerByConfig(varredis.New(s.Addr()), WithReliable(time.Second*30))
+	assert.Equal(t, nil, err, "创建消费者")
+	assert.Equal(t, nil, c.Connect(), "连接redis")
+	defer c.Close()
+
+	recv := make(chan string, 1)
+	err = c.Consume("q", 10, func(m mq.IMQCMessage) {
+		m.Ack()
+		recv <- m.GetMessage()
+	})
+	assert.Equal(t, nil, err, "注册消费")
+
+	//队列为空时逐步延长获取间隔，不以固定频率轮询
+	time.Sleep(time.Millisecond * 1500)
+	n := s.CommandCount()
+	time.Sleep(time.Second)
+	assert.Equal(t, true, s.CommandCount()-n <= 3, "空闲队列的获取频率")
+
+	s.RPush("q", "m1")
+	select {
+	case m := <-recv:
+		assert.Equal(t, "m1", m, "获取消息")
+	case <-time.After(time.Second * 2):
+		t.Fatal("未获取到消息")
+	}
+}
@@ -0,0 +1,49 @@
+package balancer
+
+import (
+	"context"
+
+	"google.golang.org/grpc/attributes"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/resolver"
+)
+
+//DefaultWeight 服务节点未发布权重时使用的默认权重
+const DefaultWeight = 10
+
+type weightKey struct{}
+
+type hashKey struct{}
+
+//newAddress 构建服务地址，权重通过地址属性传递给负载均衡器
+func newAddress(addr string, weight int) resolver.Address {
+	return resolver.Address{
+		Addr:       addr,
+		Type:       resolver.Backend,
+		Attributes: attributes.New(weightKey{}, weight),
+	}
+}
+
+//getWeight 获取服务节点权重
+func getWeight(info base.SubConnInfo) int {
+	if info.Address.Attributes != nil {
+		if w, ok := info.Address.Attributes.Value(weightKey{}).(int); ok && w > 0 {
+			return w
+		}
+	}
+	return DefaultWeight
+}
+
+//WithHashKey 设置一致性哈希的键，相同键的请求固定请求到同一服务节点
+func WithHashKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, hashKey{}, key)
+}
+
+//getHashKey 获取一致性哈希的键
+func getHashKey(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	key, _ := ctx.Value(hashKey{}).(string)
+	return key
+}
@@ -0,0 +1,68 @@
+package balancer
+
+import (
+	"fmt"
+	"hash/crc32"
+	"sort"
+	"sync/atomic"
+
+	rpcconf "github.com/micro-plat/hydra/conf/vars/rpc"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+)
+
+//virtualNodes 每个服务节点在哈希环上的虚拟节点数
+const virtualNodes = 160
+
+func init() {
+	balancer.Register(base.NewBalancerBuilder(rpcconf.ConsistentHash, &chPickerBuilder{}, base.Config{HealthCheck: true}))
+}
+
+type chPickerBuilder struct{}
+
+func (builder *chPickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+	p := &chPicker{
+		ring:     make([]uint32, 0, len(info.ReadySCs)*virtualNodes),
+		subConns: make(map[uint32]balancer.SubConn, len(info.ReadySCs)*virtualNodes),
+	}
+	for sc, ifv := range info.ReadySCs {
+		p.scs = append(p.scs, sc)
+
+		//虚拟节点以地址计算，节点增减时仅影响相邻区间的请求
+		for i := 0; i < virtualNodes; i++ {
+			h := crc32.ChecksumIEEE([]byte(fmt.Sprintf("%s#%d", ifv.Address.Addr, i)))
+			if _, ok := p.subConns[h]; ok {
+				continue
+			}
+			p.subConns[h] = sc
+			p.ring = append(p.ring, h)
+		}
+	}
+	sort.Slice(p.ring, func(i, j int) bool { return p.ring[i] < p.ring[j] })
+	return p
+}
+
+//chPicker 根据请求的哈希键在哈希环上选择节点，未指定哈希键时轮流选择
+type chPicker struct {
+	ring     []uint32
+	subConns map[uint32]balancer.SubConn
+	scs      []balancer.SubConn
+	next     uint32
+}
+
+func (p *chPicker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
+	key := getHashKey(info.Ctx)
+	if key == "" {
+		n := atomic.AddUint32(&p.next, 1)
+		return balancer.PickResult{SubConn: p.scs[int(n)%len(p.scs)]}, nil
+	}
+	h := crc32.ChecksumIEEE([]byte(key))
+	i := sort.Search(len(p.ring), func(i int) bool { return p.ring[i] >= h })
+	if i == len(p.ring) {
+		i = 0
+	}
+	return balancer.PickResult{SubConn: p.subConns[p.ring[i]]}, nil
+}
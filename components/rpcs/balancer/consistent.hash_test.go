@@ -0,0 +1,49 @@
+package balancer
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/micro-plat/lib4go/assert"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+)
+
+func TestConsistentHash(t *testing.T) {
+	scs := map[balancer.SubConn]base.SubConnInfo{
+		&mockSubConn{Addr: "a"}: {Address: newAddress("192.168.0.1:8090", 0)},
+		&mockSubConn{Addr: "b"}: {Address: newAddress("192.168.0.2:8090", 0)},
+		&mockSubConn{Addr: "c"}: {Address: newAddress("192.168.0.3:8090", 0)},
+	}
+	picker := (&chPickerBuilder{}).Build(base.PickerBuildInfo{ReadySCs: scs})
+
+	pick := func(p balancer.Picker, key string) string {
+		r, err := p.Pick(balancer.PickInfo{Ctx: WithHashKey(context.Background(), key)})
+		assert.Equal(t, nil, err, "选择节点")
+		return fmt.Sprint(r.SubConn)
+	}
+
+	used := map[string]bool{}
+	tenants := map[string]string{}
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("tenant-%d", i)
+		tenants[key] = pick(picker, key)
+		assert.Equal(t, tenants[key], pick(picker, key), "相同键请求到同一节点")
+		used[tenants[key]] = true
+	}
+	assert.Equal(t, 3, len(used), "请求分布到所有节点")
+
+	//移除一个节点后，原请求到其它节点的键保持不变
+	for sc := range scs {
+		if fmt.Sprint(sc) == "c" {
+			delete(scs, sc)
+		}
+	}
+	picker = (&chPickerBuilder{}).Build(base.PickerBuildInfo{ReadySCs: scs})
+	for key, node := range tenants {
+		if node != "c" {
+			assert.Equal(t, node, pick(picker, key), "未移除节点的请求不变")
+		}
+	}
+}
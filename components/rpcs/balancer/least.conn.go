@@ -0,0 +1,76 @@
+package balancer
+
+import (
+	"sync"
+	"sync/atomic"
+
+	rpcconf "github.com/micro-plat/hydra/conf/vars/rpc"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+)
+
+func init() {
+	balancer.Register(base.NewBalancerBuilder(rpcconf.LeastConn, newLCPickerBuilder(), base.Config{HealthCheck: true}))
+}
+
+//lcPickerBuilder 未完成请求数在重建picker时保留，避免节点状态变化后计数清零
+type lcPickerBuilder struct {
+	mu       sync.Mutex
+	inflight map[balancer.SubConn]*int64
+}
+
+func newLCPickerBuilder() *lcPickerBuilder {
+	return &lcPickerBuilder{inflight: make(map[balancer.SubConn]*int64)}
+}
+
+func (builder *lcPickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+	builder.mu.Lock()
+	defer builder.mu.Unlock()
+
+	//多个客户端共用builder，仅清理已无未完成请求的失效节点
+	for sc, n := range builder.inflight {
+		if _, ok := info.ReadySCs[sc]; !ok && atomic.LoadInt64(n) <= 0 {
+			delete(builder.inflight, sc)
+		}
+	}
+	nodes := make([]*lcNode, 0, len(info.ReadySCs))
+	for sc := range info.ReadySCs {
+		n, ok := builder.inflight[sc]
+		if !ok {
+			n = new(int64)
+			builder.inflight[sc] = n
+		}
+		nodes = append(nodes, &lcNode{subConn: sc, inflight: n})
+	}
+	return &lcPicker{nodes: nodes}
+}
+
+type lcNode struct {
+	subConn  balancer.SubConn
+	inflight *int64
+}
+
+//lcPicker 选择未完成请求数最少的节点，数量相同时轮流选择
+type lcPicker struct {
+	nodes []*lcNode
+	next  uint32
+}
+
+func (p *lcPicker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
+	start := int(atomic.AddUint32(&p.next, 1)) % len(p.nodes)
+	best := p.nodes[start]
+	min := atomic.LoadInt64(best.inflight)
+	for i := 1; i < len(p.nodes); i++ {
+		n := p.nodes[(start+i)%len(p.nodes)]
+		if c := atomic.LoadInt64(n.inflight); c < min {
+			best, min = n, c
+		}
+	}
+	atomic.AddInt64(best.inflight, 1)
+	return balancer.PickResult{SubConn: best.subConn, Done: func(balancer.DoneInfo) {
+		atomic.AddInt64(best.inflight, -1)
+	}}, nil
+}
@@ -0,0 +1,39 @@
+package balancer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/micro-plat/lib4go/assert"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+)
+
+func TestLeastConn(t *testing.T) {
+	a, b := &mockSubConn{Addr: "a"}, &mockSubConn{Addr: "b"}
+	scs := map[balancer.SubConn]base.SubConnInfo{
+		a: {Address: newAddress("192.168.0.1:8090", 0)},
+		b: {Address: newAddress("192.168.0.2:8090", 0)},
+	}
+	builder := newLCPickerBuilder()
+	picker := builder.Build(base.PickerBuildInfo{ReadySCs: scs})
+
+	first, err := picker.Pick(balancer.PickInfo{})
+	assert.Equal(t, nil, err, "选择节点")
+	second, err := picker.Pick(balancer.PickInfo{})
+	assert.Equal(t, nil, err, "选择节点")
+	assert.NotEqual(t, fmt.Sprint(first.SubConn), fmt.Sprint(second.SubConn), "优先选择无请求的节点")
+
+	//第一个节点请求完成后，新请求应选择该节点
+	first.Done(balancer.DoneInfo{})
+	for i := 0; i < 3; i++ {
+		r, _ := picker.Pick(balancer.PickInfo{})
+		assert.Equal(t, fmt.Sprint(first.SubConn), fmt.Sprint(r.SubConn), "选择请求数最少的节点")
+		r.Done(balancer.DoneInfo{})
+	}
+
+	//重建picker时保留未完成请求数
+	picker = builder.Build(base.PickerBuildInfo{ReadySCs: scs})
+	r, _ := picker.Pick(balancer.PickInfo{})
+	assert.Equal(t, fmt.Sprint(first.SubConn), fmt.Sprint(r.SubConn), "重建后保留请求数")
+}
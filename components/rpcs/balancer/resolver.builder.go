@@ -1,6 +1,7 @@
 package balancer
 
 import (
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
@@ -10,10 +11,10 @@ import (
 	"github.com/micro-plat/hydra/global"
 	"github.com/micro-plat/hydra/registry"
 	"github.com/micro-plat/lib4go/logger"
+	"github.com/micro-plat/lib4go/types"
 
 	//"google.golang.org/grpc/naming"
 
-	"google.golang.org/grpc/attributes"
 	"google.golang.org/grpc/resolver"
 	"google.golang.org/grpc/resolver/manual"
 )
@@ -39,7 +40,7 @@ type ResolverBuilder struct {
 	plat        string
 	service     string
 	sortPrefix  string
-	caches      map[string]bool
+	caches      map[string]int
 	logger      *logger.Logger
 	regst       registry.IRegistry
 	orgResolver *manual.Resolver
@@ -67,10 +68,11 @@ func NewResolverBuilder(address, plat, service, sortPrefix string) (*ResolverBui
 		proto:      proto,
 		logger:     logging,
 		closeChan:  make(chan struct{}),
-		caches:     map[string]bool{},
+		caches:     map[string]int{},
 	}
 
 	addresses := []string{addr}
+	weights := map[string]int{}
 	//兼容直接传服务器ip来进行访问
 	if len(plat) > 0 {
 		regst, err := registry.GetRegistry(address, logging)
@@ -79,14 +81,14 @@ func NewResolverBuilder(address, plat, service, sortPrefix string) (*ResolverBui
 		}
 
 		builder.regst = regst
-		addresses, err = builder.getGrpcAddress()
+		addresses, weights, err = builder.getGrpcAddress()
 		if err != nil {
 			return nil, fmt.Errorf("rpc.client.resolver target err:%v", err)
 		}
 		go builder.watchChildren()
 	}
 
-	builder.buildManualResolver(proto, addresses)
+	builder.buildManualResolver(proto, addresses, weights)
 	return builder, nil
 }
 
@@ -100,40 +102,58 @@ func (b *ResolverBuilder) Scheme() string {
 	return b.proto
 }
 
-func (b *ResolverBuilder) buildManualResolver(proto string, address []string) {
+func (b *ResolverBuilder) buildManualResolver(proto string, address []string, weights map[string]int) {
 	rb := manual.NewBuilderWithScheme(proto)
 	rb.ResolveNowCallback = func(o resolver.ResolveNowOptions) {}
 	var grpcAddrs []resolver.Address
 	for i := range address {
-		grpcAddrs = append(grpcAddrs, resolver.Address{
-			Addr:       address[i],
-			Type:       resolver.Backend,
-			Attributes: attributes.New(),
-		})
-		b.caches[address[i]] = true
+		grpcAddrs = append(grpcAddrs, newAddress(address[i], weights[address[i]]))
+		b.caches[address[i]] = weights[address[i]]
 	}
 
 	rb.InitialState(resolver.State{Addresses: grpcAddrs})
 	b.orgResolver = rb
 }
 
-func (b *ResolverBuilder) getGrpcAddress() (addrs []string, err error) {
+func (b *ResolverBuilder) getGrpcAddress() (addrs []string, weights map[string]int, err error) {
 
 	rpath, err := b.getRealPath()
 	if err != nil {
-		return []string{}, err
+		return []string{}, nil, err
 	}
 
 	//获取所有rpc服务下的子节点
 	children, _, err := b.regst.GetChildren(rpath)
 	if err != nil {
-		return []string{}, fmt.Errorf("GetChildren服务地址出错 %s %w", rpath, err)
+		return []string{}, nil, fmt.Errorf("GetChildren服务地址出错 %s %w", rpath, err)
 	}
 
 	addrs = b.extractAddrs(children)
+	weights = b.extractWeights(rpath, children)
 	return
 }
 
+//extractWeights 获取服务节点发布的权重，未发布权重的节点使用默认权重
+func (b *ResolverBuilder) extractWeights(rpath string, children []string) map[string]int {
+	weights := make(map[string]int, len(children))
+	for _, v := range children {
+		buff, _, err := b.regst.GetValue(registry.Join(rpath, v))
+		if err != nil {
+			continue
+		}
+		node := struct {
+			Weight interface{} `json:"weight"`
+		}{}
+		if err := json.Unmarshal(buff, &node); err != nil {
+			continue
+		}
+		if w := types.GetInt(node.Weight); w > 0 {
+			weights[strings.SplitN(v, "_", 2)[0]] = w
+		}
+	}
+	return weights
+}
+
 func (b *ResolverBuilder) extractAddrs(resp []string) []string {
 	addrs := make([]string, 0, len(resp))
 	for _, v := range resp {
@@ -264,36 +284,32 @@ func (b *ResolverBuilder) updateAddrs() {
 	b.lock.Lock()
 	defer b.lock.Unlock()
 
-	address, err := b.getGrpcAddress()
+	address, weights, err := b.getGrpcAddress()
 	if err != nil {
 		b.logger.Errorf("获取grpc地址错误:%+v", err)
 		return
 	}
 
-	if !b.checkUpdate(address) {
+	if !b.checkUpdate(address, weights) {
 		return
 	}
 
 	var grpcAddrs []resolver.Address
 	for i := range address {
-		grpcAddrs = append(grpcAddrs, resolver.Address{
-			Addr:       address[i],
-			Type:       resolver.Backend,
-			Attributes: attributes.New(),
-		})
+		grpcAddrs = append(grpcAddrs, newAddress(address[i], weights[address[i]]))
 	}
 	b.orgResolver.CC.UpdateState(resolver.State{Addresses: grpcAddrs})
 }
 
-func (b *ResolverBuilder) checkUpdate(address []string) bool {
+func (b *ResolverBuilder) checkUpdate(address []string, weights map[string]int) bool {
 	var needUpdate = false
 	if len(address) != len(b.caches) {
 		needUpdate = true
 	}
-	newCache := make(map[string]bool)
+	newCache := make(map[string]int)
 	for i := 0; i < len(address); i++ {
-		newCache[address[i]] = true
-		if _, ok := b.caches[address[i]]; !ok {
+		newCache[address[i]] = weights[address[i]]
+		if w, ok := b.caches[address[i]]; !ok || w != weights[address[i]] {
 			needUpdate = true
 		}
 	}
@@ -0,0 +1,54 @@
+package balancer
+
+import (
+	"sync"
+
+	rpcconf "github.com/micro-plat/hydra/conf/vars/rpc"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+)
+
+func init() {
+	balancer.Register(base.NewBalancerBuilder(rpcconf.WeightedRoundRobin, &wrrPickerBuilder{}, base.Config{HealthCheck: true}))
+}
+
+type wrrPickerBuilder struct{}
+
+func (builder *wrrPickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+	nodes := make([]*wrrNode, 0, len(info.ReadySCs))
+	for sc, ifv := range info.ReadySCs {
+		nodes = append(nodes, &wrrNode{subConn: sc, weight: getWeight(ifv)})
+	}
+	return &wrrPicker{nodes: nodes}
+}
+
+type wrrNode struct {
+	subConn balancer.SubConn
+	weight  int
+	current int
+}
+
+//wrrPicker 平滑加权轮询，权重高的节点分配更多请求，且请求在各节点间均匀穿插
+type wrrPicker struct {
+	nodes []*wrrNode
+	mu    sync.Mutex
+}
+
+func (p *wrrPicker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	total := 0
+	var best *wrrNode
+	for _, n := range p.nodes {
+		n.current += n.weight
+		total += n.weight
+		if best == nil || n.current > best.current {
+			best = n
+		}
+	}
+	best.current -= total
+	return balancer.PickResult{SubConn: best.subConn}, nil
+}
@@ -0,0 +1,29 @@
+package balancer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/micro-plat/lib4go/assert"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+)
+
+func TestWeightedRoundRobin(t *testing.T) {
+	scs := map[balancer.SubConn]base.SubConnInfo{
+		&mockSubConn{Addr: "a"}: {Address: newAddress("192.168.0.1:8090", 5)},
+		&mockSubConn{Addr: "b"}: {Address: newAddress("192.168.0.2:8090", 1)},
+		&mockSubConn{Addr: "c"}: {Address: newAddress("192.168.0.3:8090", 0)},
+	}
+	picker := (&wrrPickerBuilder{}).Build(base.PickerBuildInfo{ReadySCs: scs})
+
+	counts := map[string]int{}
+	for i := 0; i < 160; i++ {
+		result, err := picker.Pick(balancer.PickInfo{})
+		assert.Equal(t, nil, err, "选择节点")
+		counts[fmt.Sprint(result.SubConn)]++
+	}
+	assert.Equal(t, 50, counts["a"], "权重5的节点")
+	assert.Equal(t, 10, counts["b"], "权重1的节点")
+	assert.Equal(t, 100, counts["c"], "未设置权重使用默认权重")
+}
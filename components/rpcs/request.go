@@ -106,6 +106,11 @@ func (r *Request) RequestByCtx(ctx context.Context, service string, input interf
 			nopts = append(opts, rpc.WithTraceID(ctx.User().GetTraceID()))
 		}
 	}
+	if hctx, ok := rc.GetContext(); ok {
+		if tp := rc.GetTraceParent(hctx); tp != "" {
+			nopts = append(nopts, rpc.WithHeader(rc.XTraceParent, tp))
+		}
+	}
 	fm := pkgs.GetString(input)
 	return client.RequestByString(ctx, rservice, fm, nopts...)
 }
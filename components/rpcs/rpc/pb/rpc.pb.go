@@ -123,6 +123,8 @@ const _ = grpc.SupportPackageIsVersion4
 
 type RPCClient interface {
 	Request(ctx context.Context, in *RequestContext, opts ...grpc.CallOption) (*ResponseContext, error)
+	ServerStream(ctx context.Context, in *RequestContext, opts ...grpc.CallOption) (RPC_ServerStreamClient, error)
+	BidiStream(ctx context.Context, opts ...grpc.CallOption) (RPC_BidiStreamClient, error)
 }
 
 type rPCClient struct {
@@ -142,10 +144,75 @@ func (c *rPCClient) Request(ctx context.Context, in *RequestContext, opts ...grp
 	return out, nil
 }
 
+func (c *rPCClient) ServerStream(ctx context.Context, in *RequestContext, opts ...grpc.CallOption) (RPC_ServerStreamClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_RPC_serviceDesc.Streams[0], c.cc, "/pb.RPC/ServerStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &rPCServerStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type RPC_ServerStreamClient interface {
+	Recv() (*ResponseContext, error)
+	grpc.ClientStream
+}
+
+type rPCServerStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *rPCServerStreamClient) Recv() (*ResponseContext, error) {
+	m := new(ResponseContext)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *rPCClient) BidiStream(ctx context.Context, opts ...grpc.CallOption) (RPC_BidiStreamClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_RPC_serviceDesc.Streams[1], c.cc, "/pb.RPC/BidiStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &rPCBidiStreamClient{stream}
+	return x, nil
+}
+
+type RPC_BidiStreamClient interface {
+	Send(*RequestContext) error
+	Recv() (*ResponseContext, error)
+	grpc.ClientStream
+}
+
+type rPCBidiStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *rPCBidiStreamClient) Send(m *RequestContext) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *rPCBidiStreamClient) Recv() (*ResponseContext, error) {
+	m := new(ResponseContext)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // Server API for RPC service
 
 type RPCServer interface {
 	Request(context.Context, *RequestContext) (*ResponseContext, error)
+	ServerStream(*RequestContext, RPC_ServerStreamServer) error
+	BidiStream(RPC_BidiStreamServer) error
 }
 
 func RegisterRPCServer(s *grpc.Server, srv RPCServer) {
@@ -170,6 +237,53 @@ func _RPC_Request_Handler(srv interface{}, ctx context.Context, dec func(interfa
 	return interceptor(ctx, in, info, handler)
 }
 
+func _RPC_ServerStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RequestContext)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RPCServer).ServerStream(m, &rPCServerStreamServer{stream})
+}
+
+type RPC_ServerStreamServer interface {
+	Send(*ResponseContext) error
+	grpc.ServerStream
+}
+
+type rPCServerStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *rPCServerStreamServer) Send(m *ResponseContext) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _RPC_BidiStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(RPCServer).BidiStream(&rPCBidiStreamServer{stream})
+}
+
+type RPC_BidiStreamServer interface {
+	Send(*ResponseContext) error
+	Recv() (*RequestContext, error)
+	grpc.ServerStream
+}
+
+type rPCBidiStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *rPCBidiStreamServer) Send(m *ResponseContext) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *rPCBidiStreamServer) Recv() (*RequestContext, error) {
+	m := new(RequestContext)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 var _RPC_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "pb.RPC",
 	HandlerType: (*RPCServer)(nil),
@@ -179,25 +293,39 @@ var _RPC_serviceDesc = grpc.ServiceDesc{
 			Handler:    _RPC_Request_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ServerStream",
+			Handler:       _RPC_ServerStream_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "BidiStream",
+			Handler:       _RPC_BidiStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
 	Metadata: "rpc.proto",
 }
 
 func init() { proto.RegisterFile("rpc.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 198 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x54, 0x90, 0xcd, 0x8a, 0xc2, 0x30,
-	0x14, 0x85, 0xa7, 0xed, 0x34, 0xa5, 0x77, 0x31, 0x03, 0x99, 0xa1, 0x04, 0x57, 0xd2, 0x95, 0xab,
-	0x2e, 0xd4, 0x9d, 0xcb, 0xbe, 0x80, 0x64, 0xe7, 0xb2, 0x3f, 0x17, 0x5a, 0xd0, 0x26, 0x26, 0x37,
-	0xe2, 0xe3, 0x8b, 0x49, 0x0b, 0x76, 0xf9, 0x7d, 0x81, 0x73, 0x72, 0x2e, 0xe4, 0x46, 0x77, 0x95,
-	0x36, 0x8a, 0x14, 0x8f, 0x75, 0x5b, 0x6a, 0xf8, 0x91, 0x78, 0x77, 0x68, 0xa9, 0x56, 0x13, 0xe1,
-	0x93, 0xb8, 0x80, 0xcc, 0xa2, 0x79, 0x8c, 0x1d, 0x8a, 0x68, 0x1b, 0xed, 0x72, 0xb9, 0x20, 0x2f,
-	0x80, 0xdd, 0x90, 0x06, 0xd5, 0x8b, 0xd8, 0x3f, 0xcc, 0xf4, 0xf6, 0x03, 0x36, 0x3d, 0x1a, 0x91,
-	0x04, 0x1f, 0x88, 0xff, 0x43, 0x3a, 0x4e, 0xda, 0x91, 0xf8, 0xf6, 0x3a, 0x40, 0x79, 0x81, 0x5f,
-	0x89, 0x56, 0xab, 0xc9, 0xe2, 0x52, 0x59, 0x00, 0xb3, 0xd4, 0x90, 0xb3, 0xbe, 0x31, 0x95, 0x33,
-	0x7d, 0x04, 0xc7, 0xab, 0xe0, 0x02, 0x98, 0x41, 0xeb, 0xae, 0xb4, 0x14, 0x06, 0xda, 0x9f, 0x20,
-	0x91, 0xe7, 0x9a, 0x1f, 0x21, 0x9b, 0x37, 0x71, 0x5e, 0xe9, 0xb6, 0x5a, 0x0f, 0xdc, 0xfc, 0x05,
-	0xb7, 0xfa, 0x42, 0xf9, 0xd5, 0x32, 0x7f, 0x94, 0xc3, 0x2b, 0x00, 0x00, 0xff, 0xff, 0xf6, 0xfe,
-	0x8f, 0x11, 0x21, 0x01, 0x00, 0x00,
+	// 228 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x91, 0xc1, 0x4a, 0xc3, 0x40,
+	0x10, 0x86, 0xdd, 0xd4, 0xa6, 0x74, 0x10, 0x85, 0x51, 0xc2, 0xe2, 0x49, 0x72, 0xea, 0x29, 0x14,
+	0xf5, 0xd6, 0x9b, 0x7d, 0x01, 0xd9, 0x9e, 0x3c, 0x26, 0xcd, 0x40, 0x03, 0x36, 0xbb, 0xce, 0xce,
+	0x16, 0x5f, 0xcc, 0xf7, 0x13, 0x77, 0x13, 0x30, 0xc7, 0x1c, 0xbf, 0x6f, 0x99, 0xf9, 0xf7, 0x67,
+	0x60, 0xcd, 0xee, 0x58, 0x39, 0xb6, 0x62, 0x31, 0x73, 0x4d, 0xe9, 0xe0, 0xd6, 0xd0, 0x57, 0x20,
+	0x2f, 0x7b, 0xdb, 0x0b, 0x7d, 0x0b, 0x6a, 0x58, 0x79, 0xe2, 0x4b, 0x77, 0x24, 0xad, 0x9e, 0xd4,
+	0x66, 0x6d, 0x46, 0xc4, 0x02, 0xf2, 0x33, 0xc9, 0xc9, 0xb6, 0x3a, 0x8b, 0x0f, 0x03, 0xfd, 0xf9,
+	0x13, 0xd5, 0x2d, 0xb1, 0x5e, 0x24, 0x9f, 0x08, 0x1f, 0x60, 0xd9, 0xf5, 0x2e, 0x88, 0xbe, 0x8e,
+	0x3a, 0x41, 0xf9, 0x01, 0x77, 0x86, 0xbc, 0xb3, 0xbd, 0xa7, 0x31, 0xb2, 0x80, 0xdc, 0x4b, 0x2d,
+	0xc1, 0xc7, 0xc4, 0xa5, 0x19, 0xe8, 0xdf, 0xe2, 0x6c, 0xb2, 0xb8, 0x80, 0x9c, 0xc9, 0x87, 0x4f,
+	0x19, 0x03, 0x13, 0x3d, 0xff, 0x28, 0x58, 0x98, 0xf7, 0x3d, 0xbe, 0xc2, 0x6a, 0x28, 0x85, 0x58,
+	0xb9, 0xa6, 0x9a, 0x36, 0x7c, 0xbc, 0x4f, 0x6e, 0xf2, 0x87, 0xf2, 0x0a, 0x77, 0x70, 0x73, 0x20,
+	0xbe, 0x10, 0x1f, 0x84, 0xa9, 0x3e, 0xcf, 0x18, 0xdd, 0x2a, 0xdc, 0x01, 0xbc, 0x75, 0x6d, 0x37,
+	0x7b, 0x74, 0xa3, 0xb6, 0xaa, 0xc9, 0xe3, 0x3d, 0x5e, 0x7e, 0x07, 0x00, 0xc3, 0x64, 0x7e, 0x5f,
+	0x9c, 0x01, 0x00, 0x00,
 }
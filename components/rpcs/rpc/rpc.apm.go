@@ -9,18 +9,10 @@ import (
 
 func (c *Client) clientRequest(ctx context.Context, o *requestOption, form string) (response *pb.ResponseContext, err error) {
 
-	h, err := o.getData(o.headers)
+	request, err := o.getRequest(form)
 	if err != nil {
 		return nil, err
 	}
-
-	return c.client.Request(ctx,
-		&pb.RequestContext{
-			Method:  o.method,
-			Service: o.service,
-			Header:  string(h),
-			Input:   form,
-		},
-		grpc.FailFast(o.failFast))
+	return c.client.Request(c.getContext(ctx, o), request, grpc.FailFast(o.failFast))
 
 }
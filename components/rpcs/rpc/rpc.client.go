@@ -73,6 +73,16 @@ func (c *Client) Request(ctx context.Context, service string, form map[string]in
 //RequestByString 发送Request请求
 func (c *Client) RequestByString(ctx context.Context, service string, form string, opts ...RequestOption) (res *pkgs.Rspns, err error) {
 	//处理可选参数
+	o := newRequestOption(service, opts...)
+	response, err := c.clientRequest(ctx, o, form)
+	if err != nil {
+		return pkgs.NewRspns(err), err
+	}
+	return pkgs.NewRspnsByHD(int(response.Status), response.GetHeader(), response.GetResult()), err
+}
+
+//newRequestOption 构建请求选项
+func newRequestOption(service string, opts ...RequestOption) *requestOption {
 	o := newOption()
 	for _, opt := range opts {
 		opt(o)
@@ -84,13 +94,22 @@ func (c *Client) RequestByString(ctx context.Context, service string, form strin
 			break
 		}
 	}
-
 	o.service = service
-	response, err := c.clientRequest(ctx, o, form)
-	if err != nil {
-		return pkgs.NewRspns(err), err
+	return o
+}
+
+//getContext 使用一致性哈希负载时，将请求头中的哈希键传递给负载均衡器
+func (c *Client) getContext(ctx context.Context, o *requestOption) context.Context {
+	if c.Balancer != rpcconf.ConsistentHash {
+		return ctx
 	}
-	return pkgs.NewRspnsByHD(int(response.Status), response.GetHeader(), response.GetResult()), err
+	header := c.GetHashHeader()
+	for k, v := range o.headers {
+		if strings.EqualFold(k, header) && v != "" {
+			return balancer.WithHashKey(ctx, v)
+		}
+	}
+	return ctx
 }
 
 //Close 关闭RPC客户端连接
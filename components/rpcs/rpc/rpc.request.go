@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/micro-plat/hydra/components/rpcs/rpc/pb"
 	"github.com/micro-plat/hydra/global"
 	"github.com/micro-plat/hydra/pkgs"
 	"golang.org/x/net/context"
@@ -35,6 +36,20 @@ func (r *requestOption) getData(v interface{}) ([]byte, error) {
 	return buff, nil
 }
 
+//getRequest 构建请求内容
+func (r *requestOption) getRequest(form string) (*pb.RequestContext, error) {
+	h, err := r.getData(r.headers)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.RequestContext{
+		Method:  r.method,
+		Service: r.service,
+		Header:  string(h),
+		Input:   form,
+	}, nil
+}
+
 //RequestOption 客户端配置选项
 type RequestOption func(*requestOption)
 
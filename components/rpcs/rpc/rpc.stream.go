@@ -0,0 +1,119 @@
+package rpc
+
+import (
+	"errors"
+	"io"
+
+	"github.com/micro-plat/hydra/components/rpcs/rpc/pb"
+	"github.com/micro-plat/hydra/pkgs"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+//Stream 流式请求，通过Recv依次读取服务端推送的响应，读取完成后返回io.EOF
+type Stream struct {
+	stream grpc.ClientStream
+	bidi   pb.RPC_BidiStreamClient
+	recv   func() (*pb.ResponseContext, error)
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+//Stream 发送服务端流请求，服务端处理过程中推送的消息及处理结果依次通过Stream.Recv返回
+func (c *Client) Stream(ctx context.Context, service string, form string, opts ...RequestOption) (*Stream, error) {
+	o := newRequestOption(service, opts...)
+	request, err := o.getRequest(form)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(c.getContext(ctx, o))
+	s, err := c.client.ServerStream(ctx, request, grpc.FailFast(o.failFast))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &Stream{stream: s, recv: s.Recv, ctx: ctx, cancel: cancel}, nil
+}
+
+//BidiStream 发送双向流请求，form作为首条消息用于服务路由，后续消息通过Stream.Send发送
+func (c *Client) BidiStream(ctx context.Context, service string, form string, opts ...RequestOption) (*Stream, error) {
+	o := newRequestOption(service, opts...)
+	request, err := o.getRequest(form)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(c.getContext(ctx, o))
+	s, err := c.client.BidiStream(ctx, grpc.FailFast(o.failFast))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if err := s.Send(request); err != nil {
+		cancel()
+		return nil, err
+	}
+	return &Stream{stream: s, bidi: s, recv: s.Recv, ctx: ctx, cancel: cancel}, nil
+}
+
+//Send 向服务端发送一条消息，仅双向流可用
+func (s *Stream) Send(form string) error {
+	if s.bidi == nil {
+		return errors.New("服务端流不支持发送消息")
+	}
+	return s.bidi.Send(&pb.RequestContext{Input: form})
+}
+
+//CloseSend 结束发送，服务端Recv将返回io.EOF
+func (s *Stream) CloseSend() error {
+	return s.stream.CloseSend()
+}
+
+//Close 结束请求，不再读取后续响应时应调用，用于释放连接及Chan的读取协程
+func (s *Stream) Close() {
+	s.cancel()
+}
+
+//Recv 读取下一条响应，服务端处理完成后返回io.EOF
+func (s *Stream) Recv() (*pkgs.Rspns, error) {
+	r, err := s.read()
+	if err != nil {
+		s.cancel()
+	}
+	return r, err
+}
+
+func (s *Stream) read() (*pkgs.Rspns, error) {
+	response, err := s.recv()
+	if err != nil {
+		return nil, err
+	}
+	return pkgs.NewRspnsByHD(int(response.Status), response.GetHeader(), response.GetResult()), nil
+}
+
+//Chan 以通道方式读取响应，读取完成后关闭通道，读取失败时最后一条为错误响应。
+//提前停止读取时需调用Close或撤销请求上下文，否则读取协程无法退出
+func (s *Stream) Chan() <-chan *pkgs.Rspns {
+	ch := make(chan *pkgs.Rspns)
+	go func() {
+		defer close(ch)
+		defer s.cancel()
+		for {
+			r, err := s.read()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				r = pkgs.NewRspns(err)
+			}
+			select {
+			case ch <- r:
+			case <-s.ctx.Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,138 @@
+package rpc
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/micro-plat/hydra/components/rpcs/rpc/pb"
+	rpcconf "github.com/micro-plat/hydra/conf/vars/rpc"
+	"github.com/micro-plat/lib4go/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+//streamServer 测试用流式服务，按输入推送指定数量的消息，双向流原样返回收到的消息
+type streamServer struct {
+	done chan struct{}
+}
+
+func (s *streamServer) Request(ctx context.Context, r *pb.RequestContext) (*pb.ResponseContext, error) {
+	return &pb.ResponseContext{Status: 200, Result: r.Input}, nil
+}
+
+func (s *streamServer) ServerStream(r *pb.RequestContext, ss pb.RPC_ServerStreamServer) error {
+	defer close(s.done)
+	count := 3
+	if r.Input == "endless" {
+		count = 1 << 30
+	}
+	for i := 0; i < count; i++ {
+		if err := ss.Send(&pb.ResponseContext{Status: 200, Result: r.Input}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *streamServer) BidiStream(bs pb.RPC_BidiStreamServer) error {
+	defer close(s.done)
+	for {
+		r, err := bs.Recv()
+		if err == io.EOF {
+			return bs.Send(&pb.ResponseContext{Status: 200, Result: "end"})
+		}
+		if err != nil {
+			return err
+		}
+		if err := bs.Send(&pb.ResponseContext{Status: 200, Result: r.Input}); err != nil {
+			return err
+		}
+	}
+}
+
+func newStreamClient(t *testing.T) (*Client, *streamServer, func()) {
+	l := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	ss := &streamServer{done: make(chan struct{})}
+	pb.RegisterRPCServer(srv, ss)
+	go srv.Serve(l)
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+		return l.Dial()
+	}))
+	assert.Equal(t, nil, err, "连接服务器失败")
+	c := &Client{conn: conn, client: pb.NewRPCClient(conn), RPCConf: rpcconf.New()}
+	return c, ss, func() {
+		conn.Close()
+		srv.Stop()
+	}
+}
+
+func TestClient_Stream(t *testing.T) {
+	c, _, stop := newStreamClient(t)
+	defer stop()
+
+	s, err := c.Stream(context.Background(), "/order/query", "abc")
+	assert.Equal(t, nil, err, "发送流请求失败")
+	n := 0
+	for r := range s.Chan() {
+		assert.Equal(t, 200, r.GetStatus(), "响应状态码不一致")
+		assert.Equal(t, "abc", r.GetResult(), "响应内容不一致")
+		n++
+	}
+	assert.Equal(t, 3, n, "响应数量不一致")
+}
+
+func TestClient_BidiStream(t *testing.T) {
+	c, _, stop := newStreamClient(t)
+	defer stop()
+
+	s, err := c.BidiStream(context.Background(), "/order/chat", "first")
+	assert.Equal(t, nil, err, "发送双向流请求失败")
+	r, err := s.Recv()
+	assert.Equal(t, nil, err, "读取首条消息失败")
+	assert.Equal(t, "first", r.GetResult(), "首条消息不一致")
+
+	assert.Equal(t, nil, s.Send("second"), "发送消息失败")
+	r, err = s.Recv()
+	assert.Equal(t, nil, err, "读取消息失败")
+	assert.Equal(t, "second", r.GetResult(), "消息不一致")
+
+	assert.Equal(t, nil, s.CloseSend(), "结束发送失败")
+	r, err = s.Recv()
+	assert.Equal(t, nil, err, "读取结束消息失败")
+	assert.Equal(t, "end", r.GetResult(), "结束消息不一致")
+	_, err = s.Recv()
+	assert.Equal(t, io.EOF, err, "读取完成后应返回EOF")
+}
+
+func TestStream_ChanClose(t *testing.T) {
+	c, ss, stop := newStreamClient(t)
+	defer stop()
+
+	s, err := c.Stream(context.Background(), "/order/query", "endless")
+	assert.Equal(t, nil, err, "发送流请求失败")
+	ch := s.Chan()
+	<-ch
+
+	//停止读取后关闭请求，读取协程退出并关闭通道，服务端请求被撤销
+	s.Close()
+	timeout := time.After(time.Second * 5)
+	for {
+		select {
+		case _, ok := <-ch:
+			if !ok {
+				select {
+				case <-ss.done:
+				case <-timeout:
+					t.Fatal("服务端请求未撤销")
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatal("读取协程未退出")
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+)
+
+//ChangeType 配置变更类型
+type ChangeType string
+
+const (
+	//Added 新增节点
+	Added ChangeType = "+"
+
+	//Removed 删除节点
+	Removed ChangeType = "-"
+
+	//Modified 修改节点
+	Modified ChangeType = "~"
+)
+
+//Change 两个快照间单个节点的差异
+type Change struct {
+	Type ChangeType
+	Path string
+	Old  string
+	New  string
+}
+
+//Diff 比较两个快照的配置，返回按路径排序的差异节点
+func Diff(from *Snapshot, to *Snapshot) []*Change {
+	changes := make([]*Change, 0, 1)
+	for path, v := range to.Confs {
+		old, ok := from.Confs[path]
+		switch {
+		case !ok:
+			changes = append(changes, &Change{Type: Added, Path: path, New: v})
+		case !equal(old, v):
+			changes = append(changes, &Change{Type: Modified, Path: path, Old: old, New: v})
+		}
+	}
+	for path, v := range from.Confs {
+		if _, ok := to.Confs[path]; !ok {
+			changes = append(changes, &Change{Type: Removed, Path: path, Old: v})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
+
+//Indent 格式化json配置便于比较，非json数据原样返回
+func Indent(v string) string {
+	var buff bytes.Buffer
+	if err := json.Indent(&buff, []byte(v), "", "  "); err != nil {
+		return v
+	}
+	return buff.String()
+}
+
+//equal json配置忽略格式差异进行比较
+func equal(a string, b string) bool {
+	if a == b {
+		return true
+	}
+	var x, y interface{}
+	if json.Unmarshal([]byte(a), &x) != nil || json.Unmarshal([]byte(b), &y) != nil {
+		return false
+	}
+	bx, _ := json.Marshal(x)
+	by, _ := json.Marshal(y)
+	return bytes.Equal(bx, by)
+}
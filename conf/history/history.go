@@ -0,0 +1,482 @@
+package history
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/lib4go/types"
+)
+
+//maxRetry 并发记录快照时版本冲突的最大重试次数
+const maxRetry = 10
+
+//ActionPublish 发布配置
+const ActionPublish = "publish"
+
+//ActionRollback 回滚配置
+const ActionRollback = "rollback"
+
+//ActionRotate 密钥轮换
+const ActionRotate = "rotate"
+
+//Snapshot 配置快照，记录一次配置写入的所有节点
+type Snapshot struct {
+	Version int64             `json:"version"`
+	Action  string            `json:"action"`
+	Author  string            `json:"author"`
+	Time    string            `json:"time"`
+	Hash    string            `json:"hash"`
+	Comment string            `json:"comment,omitempty"`
+	Paths   []string          `json:"paths"`
+	Confs   map[string]string `json:"-"`
+}
+
+//confsNode 快照配置节点名，快照中的所有配置保存为一个节点，避免事务操作数超出注册中心限制
+const confsNode = "confs"
+
+//DefaultMaxVersions 默认保留的快照个数
+const DefaultMaxVersions = 100
+
+//History 系统的配置历史，快照保存在注册中心/[platName]/history/[sysName]/[version]节点下，
+//[sysName]节点值为最新版本号，快照包含系统各集群的服务器配置及平台var配置，
+//所有配置保存在快照的confs子节点中
+type History struct {
+	r           registry.IRegistry
+	platName    string
+	sysName     string
+	maxVersions int
+}
+
+//Option 配置选项
+type Option func(*History)
+
+//WithMaxVersions 设置保留的快照个数，超出时删除最早的快照，小于1时不删除
+func WithMaxVersions(n int) Option {
+	return func(h *History) {
+		h.maxVersions = n
+	}
+}
+
+//NewHistory 构建配置历史
+func NewHistory(r registry.IRegistry, platName string, sysName string, opts ...Option) *History {
+	h := &History{r: r, platName: platName, sysName: sysName, maxVersions: DefaultMaxVersions}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+//GetRoot 获取历史记录根路径
+func (h *History) GetRoot() string {
+	return registry.Join(h.platName, "history", h.sysName)
+}
+
+//Record 读取系统所有配置节点及平台var配置记录为快照，版本号在已有版本上递增，并删除超出保留个数的快照
+func (h *History) Record(action string, author string, comment string) (*Snapshot, error) {
+	nodes, err := h.capture()
+	if err != nil {
+		return nil, err
+	}
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+	confs := make(map[string]string, len(nodes))
+	for path, n := range nodes {
+		confs[path] = n.value
+	}
+	s, err := h.save(action, author, comment, confs)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.prune(s.Version); err != nil {
+		return s, fmt.Errorf("配置快照%d已保存，删除过期快照出错:%w", s.Version, err)
+	}
+	return s, nil
+}
+
+//save 保存配置快照，并发记录时通过history节点版本检查重试
+func (h *History) save(action string, author string, comment string, confs map[string]string) (*Snapshot, error) {
+	root := h.GetRoot()
+	if b, err := h.r.Exists(root); err != nil {
+		return nil, err
+	} else if !b {
+		for _, path := range []string{registry.Join(h.platName, "history"), root} {
+			if b, err := h.r.Exists(path); err != nil {
+				return nil, err
+			} else if b {
+				continue
+			}
+			if err := h.r.Txn(registry.CreateOp(path, "0")); err != nil && !registry.IsConflict(err) {
+				return nil, fmt.Errorf("创建配置历史节点%s出错:%w", path, err)
+			}
+		}
+	}
+
+	s := &Snapshot{
+		Action:  action,
+		Author:  author,
+		Time:    time.Now().Format("2006-01-02 15:04:05"),
+		Hash:    Hash(confs),
+		Comment: comment,
+		Paths:   sortedPaths(confs),
+		Confs:   confs,
+	}
+	for i := 0; i < maxRetry; i++ {
+		buff, version, err := h.r.GetValue(root)
+		if err != nil {
+			return nil, err
+		}
+		s.Version = types.GetInt64(string(buff)) + 1
+		ops, err := h.snapshotOps(s)
+		if err != nil {
+			return nil, err
+		}
+		ops = append([]registry.Op{registry.UpdateOp(root, strconv.FormatInt(s.Version, 10), version)}, ops...)
+		err = h.r.Txn(ops...)
+		if err == nil {
+			return s, nil
+		}
+		if !registry.IsConflict(err) {
+			return nil, fmt.Errorf("保存配置快照%d出错:%w", s.Version, err)
+		}
+	}
+	return nil, fmt.Errorf("保存配置快照失败，配置历史正在被频繁修改(%s)", root)
+}
+
+//List 获取所有快照信息(不含配置值)，按版本号升序排列
+func (h *History) List() ([]*Snapshot, error) {
+	root := h.GetRoot()
+	if b, err := h.r.Exists(root); err != nil || !b {
+		return nil, err
+	}
+	children, _, err := h.r.GetChildren(root)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*Snapshot, 0, len(children))
+	for _, c := range children {
+		v, err := strconv.ParseInt(c, 10, 64)
+		if err != nil {
+			continue
+		}
+		s, err := h.getMeta(v)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Version < list[j].Version
+	})
+	return list, nil
+}
+
+//Get 获取指定版本的快照
+func (h *History) Get(version int64) (*Snapshot, error) {
+	s, err := h.getMeta(version)
+	if err != nil {
+		return nil, err
+	}
+	path := registry.Join(h.getPath(version), confsNode)
+	buff, _, err := h.r.GetValue(path)
+	if err != nil {
+		return nil, fmt.Errorf("获取快照节点%s出错:%w", path, err)
+	}
+	s.Confs = make(map[string]string, len(s.Paths))
+	if err := json.Unmarshal(buff, &s.Confs); err != nil {
+		return nil, fmt.Errorf("快照节点%s数据有误:%w", path, err)
+	}
+	if hash := Hash(s.Confs); hash != s.Hash {
+		return nil, fmt.Errorf("快照%d数据已损坏，hash不一致(%s!=%s)", version, hash, s.Hash)
+	}
+	return s, nil
+}
+
+//Rollback 将系统的服务器配置恢复为快照中的配置，删除快照中不存在的节点，平台var配置由多个系统共用，不回滚；
+//操作检查节点在读取后未被修改，超出单个事务的操作数时分批提交，
+//首批提交失败时不修改任何配置，后续批次失败时已提交的配置不撤销，并记录为新的快照
+func (h *History) Rollback(version int64, author string) (*Snapshot, error) {
+	s, err := h.Get(version)
+	if err != nil {
+		return nil, err
+	}
+	current, err := h.capture()
+	if err != nil {
+		return nil, err
+	}
+	ops := rollbackOps(h.sysConfs(s.Confs), h.sysNodes(current))
+	n, err := registry.TxnBatch(h.r, ops...)
+	if err == nil {
+		return h.Record(ActionRollback, author, fmt.Sprintf("回滚到版本%d", version))
+	}
+	if registry.IsConflict(err) {
+		err = fmt.Errorf("回滚配置到版本%d出错，配置已被其它程序修改，请重试:%w", version, err)
+	} else {
+		err = fmt.Errorf("回滚配置到版本%d出错:%w", version, err)
+	}
+	if n == 0 {
+		return nil, err
+	}
+	if _, herr := h.Record(ActionRollback, author, fmt.Sprintf("回滚到版本%d未完成(%d/%d):%v", version, n, len(ops), err)); herr != nil {
+		return nil, fmt.Errorf("%w(记录配置历史失败:%v)", err, herr)
+	}
+	return nil, fmt.Errorf("%w(已执行%d/%d个操作)", err, n, len(ops))
+}
+
+//sysConfs 获取快照中属于当前系统的配置
+func (h *History) sysConfs(confs map[string]string) map[string]string {
+	prefix := registry.Join(h.platName, h.sysName) + "/"
+	sys := make(map[string]string, len(confs))
+	for path, v := range confs {
+		if strings.HasPrefix(path, prefix) {
+			sys[path] = v
+		}
+	}
+	return sys
+}
+
+//sysNodes 获取属于当前系统的配置节点
+func (h *History) sysNodes(nodes map[string]*confNode) map[string]*confNode {
+	prefix := registry.Join(h.platName, h.sysName) + "/"
+	sys := make(map[string]*confNode, len(nodes))
+	for path, n := range nodes {
+		if strings.HasPrefix(path, prefix) {
+			sys[path] = n
+		}
+	}
+	return sys
+}
+
+//rollbackOps 根据当前节点的版本构建回滚操作，父节点先于子节点创建，子节点先于父节点删除
+func rollbackOps(confs map[string]string, current map[string]*confNode) []registry.Op {
+	ops := make([]registry.Op, 0, len(confs)+len(current))
+	for _, path := range sortedPaths(confs) {
+		n, ok := current[path]
+		switch {
+		case !ok:
+			ops = append(ops, registry.CreateOp(path, confs[path]))
+		case n.value != confs[path]:
+			ops = append(ops, registry.UpdateOp(path, confs[path], n.version))
+		default:
+			ops = append(ops, registry.CheckOp(path, n.version))
+		}
+	}
+	removed := make([]string, 0, 1)
+	for path := range current {
+		if _, ok := confs[path]; !ok {
+			removed = append(removed, path)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(removed)))
+	for _, path := range removed {
+		ops = append(ops, registry.DeleteOp(path, current[path].version))
+	}
+	return ops
+}
+
+//confNode 配置节点的值与版本
+type confNode struct {
+	value   string
+	version int32
+}
+
+//capture 读取系统所有配置节点，包括各集群的服务器配置/[platName]/[sysName]/[type]/[cluster]/conf与var配置/[platName]/var
+func (h *History) capture() (map[string]*confNode, error) {
+	roots, err := h.getConfRoots()
+	if err != nil {
+		return nil, err
+	}
+	nodes := make(map[string]*confNode)
+	for _, root := range roots {
+		if err := h.readTree(root, nodes); err != nil {
+			return nil, err
+		}
+	}
+	return nodes, nil
+}
+
+//getConfRoots 获取系统所有配置根节点及平台var配置根节点
+func (h *History) getConfRoots() ([]string, error) {
+	roots := []string{registry.Join(h.platName, "var")}
+	sys := registry.Join(h.platName, h.sysName)
+	tps, err := h.getChildren(sys)
+	if err != nil {
+		return nil, err
+	}
+	for _, tp := range tps {
+		clusters, err := h.getChildren(registry.Join(sys, tp))
+		if err != nil {
+			return nil, err
+		}
+		for _, cluster := range clusters {
+			if b, err := h.hasChild(registry.Join(sys, tp, cluster), "conf"); err != nil {
+				return nil, err
+			} else if b {
+				roots = append(roots, registry.Join(sys, tp, cluster, "conf"))
+			}
+		}
+	}
+	sort.Strings(roots)
+	return roots, nil
+}
+
+//readTree 读取节点及所有子节点的值与版本，部分注册中心的中间节点不存在时只读取子节点
+func (h *History) readTree(path string, nodes map[string]*confNode) error {
+	if b, err := h.r.Exists(path); err != nil {
+		return err
+	} else if b {
+		buff, version, err := h.r.GetValue(path)
+		if err != nil {
+			return fmt.Errorf("获取配置节点%s出错:%w", path, err)
+		}
+		nodes[path] = &confNode{value: string(buff), version: version}
+	}
+	children, err := h.getChildren(path)
+	if err != nil {
+		return err
+	}
+	for _, c := range children {
+		if err := h.readTree(registry.Join(path, c), nodes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//hasChild 检查是否包含指定子节点
+func (h *History) hasChild(path string, name string) (bool, error) {
+	children, err := h.getChildren(path)
+	if err != nil {
+		return false, err
+	}
+	for _, c := range children {
+		if c == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+//getChildren 获取子节点，节点不存在时返回空
+func (h *History) getChildren(path string) ([]string, error) {
+	children, _, err := h.r.GetChildren(path)
+	if err == nil {
+		return children, nil
+	}
+	if b, eerr := h.r.Exists(path); eerr == nil && !b {
+		return nil, nil
+	}
+	return nil, fmt.Errorf("获取%s子节点出错:%w", path, err)
+}
+
+//prune 删除超出保留个数的快照
+func (h *History) prune(latest int64) error {
+	if h.maxVersions < 1 || latest <= int64(h.maxVersions) {
+		return nil
+	}
+	children, err := h.getChildren(h.GetRoot())
+	if err != nil {
+		return err
+	}
+	for _, c := range children {
+		v, err := strconv.ParseInt(c, 10, 64)
+		if err != nil || v > latest-int64(h.maxVersions) {
+			continue
+		}
+		path := h.getPath(v)
+		nodes, err := h.getChildren(path)
+		if err != nil {
+			return err
+		}
+		ops := make([]registry.Op, 0, len(nodes)+1)
+		for _, n := range nodes {
+			ops = append(ops, registry.DeleteOp(registry.Join(path, n), registry.AnyVersion))
+		}
+		ops = append(ops, registry.DeleteOp(path, registry.AnyVersion))
+		if _, err := registry.TxnBatch(h.r, ops...); err != nil && !registry.IsConflict(err) {
+			return fmt.Errorf("删除快照%d出错:%w", v, err)
+		}
+	}
+	return nil
+}
+
+func (h *History) getPath(version int64) string {
+	return registry.Join(h.GetRoot(), strconv.FormatInt(version, 10))
+}
+
+func (h *History) getMeta(version int64) (*Snapshot, error) {
+	path := h.getPath(version)
+	b, err := h.r.Exists(path)
+	if err != nil {
+		return nil, err
+	}
+	if !b {
+		return nil, fmt.Errorf("配置版本%d不存在", version)
+	}
+	buff, _, err := h.r.GetValue(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &Snapshot{}
+	if err := json.Unmarshal(buff, s); err != nil {
+		return nil, fmt.Errorf("快照%s数据有误:%w", path, err)
+	}
+	return s, nil
+}
+
+//snapshotOps 构建创建快照及其配置节点的操作
+func (h *History) snapshotOps(s *Snapshot) ([]registry.Op, error) {
+	path := h.getPath(s.Version)
+	meta, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	confs, err := json.Marshal(s.Confs)
+	if err != nil {
+		return nil, err
+	}
+	return []registry.Op{
+		registry.CreateOp(path, string(meta)),
+		registry.CreateOp(registry.Join(path, confsNode), string(confs)),
+	}, nil
+}
+
+//Hash 计算配置的sha256值，按路径排序后依次计算路径与配置值
+func Hash(confs map[string]string) string {
+	h := sha256.New()
+	for _, p := range sortedPaths(confs) {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+		h.Write([]byte(confs[p]))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+//CurrentAuthor 获取当前操作人，格式为[用户名]@[主机名]
+func CurrentAuthor() string {
+	name := os.Getenv("USER")
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	host, _ := os.Hostname()
+	return fmt.Sprintf("%s@%s", name, host)
+}
+
+func sortedPaths(confs map[string]string) []string {
+	paths := make([]string, 0, len(confs))
+	for k := range confs {
+		paths = append(paths, k)
+	}
+	sort.Strings(paths)
+	return paths
+}
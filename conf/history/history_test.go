@@ -0,0 +1,192 @@
+package history
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/hydra/registry/registry/localmemory"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func TestHistory_Record(t *testing.T) {
+	r := localmemory.NewLocalMemory()
+	h := NewHistory(r, "hydra", "sys")
+
+	s, err := h.Record(ActionPublish, "colin", "")
+	assert.Equal(t, nil, err, "无配置")
+	assert.Equal(t, true, s == nil, "无配置时不记录快照")
+
+	r.CreatePersistentNode("/hydra/sys/api/prod/conf", `{"address":":8080"}`)
+	r.CreatePersistentNode("/hydra/sys/api/prod/conf/router", `{"routers":[]}`)
+	r.CreatePersistentNode("/hydra/var/db/db", `{"conn":"a"}`)
+	r.CreatePersistentNode("/hydra/sys/api/prod/servers/192.168.0.1", "{}")
+	r.CreatePersistentNode("/hydra/services/api/order/providers/192.168.0.1", "{}")
+	confs := map[string]string{
+		"/hydra/sys/api/prod/conf":        `{"address":":8080"}`,
+		"/hydra/sys/api/prod/conf/router": `{"routers":[]}`,
+		"/hydra/var":                      "{}",
+		"/hydra/var/db":                   "{}",
+		"/hydra/var/db/db":                `{"conn":"a"}`,
+	}
+	s1, err := h.Record(ActionPublish, "colin", "")
+	assert.Equal(t, nil, err, "记录第一个快照")
+	assert.Equal(t, int64(1), s1.Version, "第一个快照版本")
+	assert.Equal(t, confs, s1.Confs, "快照包含所有配置节点，不含服务器与服务节点")
+	assert.Equal(t, Hash(confs), s1.Hash, "快照hash")
+
+	r.Update("/hydra/sys/api/prod/conf", `{"address":":9090"}`)
+	r.Delete("/hydra/var/db/db")
+	s2, err := h.Record(ActionPublish, "colin", "")
+	assert.Equal(t, nil, err, "记录第二个快照")
+	assert.Equal(t, int64(2), s2.Version, "版本号递增")
+
+	list, err := h.List()
+	assert.Equal(t, nil, err, "获取快照列表")
+	assert.Equal(t, 2, len(list), "快照个数")
+	assert.Equal(t, "colin", list[0].Author, "快照操作人")
+	assert.Equal(t, []string{"/hydra/sys/api/prod/conf", "/hydra/sys/api/prod/conf/router", "/hydra/var", "/hydra/var/db", "/hydra/var/db/db"}, list[0].Paths, "快照节点")
+
+	v1, err := h.Get(1)
+	assert.Equal(t, nil, err, "获取快照")
+	assert.Equal(t, confs, v1.Confs, "快照配置")
+	_, err = h.Get(3)
+	assert.NotEqual(t, nil, err, "获取不存在的快照")
+
+	changes := Diff(v1, s2)
+	assert.Equal(t, 2, len(changes), "差异节点个数")
+	assert.Equal(t, Modified, changes[0].Type, "修改节点")
+	assert.Equal(t, Removed, changes[1].Type, "删除节点")
+}
+
+func TestHistory_Prune(t *testing.T) {
+	r := localmemory.NewLocalMemory()
+	h := NewHistory(r, "hydra", "sys", WithMaxVersions(2))
+	r.CreatePersistentNode("/hydra/sys/api/prod/conf", `{"address":":8080"}`)
+	for i := 0; i < 4; i++ {
+		r.Update("/hydra/sys/api/prod/conf", fmt.Sprintf(`{"address":":%d"}`, 8080+i))
+		_, err := h.Record(ActionPublish, "colin", "")
+		assert.Equal(t, nil, err, "记录快照")
+	}
+	list, err := h.List()
+	assert.Equal(t, nil, err, "获取快照列表")
+	assert.Equal(t, 2, len(list), "只保留最新的快照")
+	assert.Equal(t, int64(3), list[0].Version, "删除最早的快照")
+	ok, _ := r.Exists("/hydra/history/sys/1/confs")
+	assert.Equal(t, false, ok, "快照的配置节点已删除")
+	s, err := h.Get(4)
+	assert.Equal(t, nil, err, "获取最新快照")
+	assert.Equal(t, `{"address":":8083"}`, s.Confs["/hydra/sys/api/prod/conf"], "最新快照配置")
+}
+
+func TestHistory_Rollback(t *testing.T) {
+	r := localmemory.NewLocalMemory()
+	h := NewHistory(r, "hydra", "sys")
+
+	r.CreatePersistentNode("/hydra/sys/api/prod/conf", `{"address":":8080"}`)
+	r.CreatePersistentNode("/hydra/sys/api/prod/conf/router", `{"routers":[]}`)
+	h.Record(ActionPublish, "colin", "")
+	r.Update("/hydra/sys/api/prod/conf", `{"address":":9090"}`)
+	r.Delete("/hydra/sys/api/prod/conf/router")
+	r.CreatePersistentNode("/hydra/sys/api/prod/conf/metric", `{"host":"a"}`)
+	h.Record(ActionPublish, "colin", "")
+
+	s, err := h.Rollback(1, "lily")
+	assert.Equal(t, nil, err, "回滚配置")
+	assert.Equal(t, int64(3), s.Version, "回滚生成新版本")
+	assert.Equal(t, ActionRollback, s.Action, "回滚操作")
+	buff, _, _ := r.GetValue("/hydra/sys/api/prod/conf")
+	assert.Equal(t, `{"address":":8080"}`, string(buff), "配置已回滚")
+	ok, _ := r.Exists("/hydra/sys/api/prod/conf/router")
+	assert.Equal(t, true, ok, "恢复已删除的节点")
+	ok, _ = r.Exists("/hydra/sys/api/prod/conf/metric")
+	assert.Equal(t, false, ok, "删除快照中不存在的节点")
+	v1, _ := h.Get(1)
+	assert.Equal(t, v1.Hash, s.Hash, "回滚后配置与快照一致")
+
+	_, err = h.Rollback(5, "lily")
+	assert.NotEqual(t, nil, err, "回滚不存在的版本")
+}
+
+//limitRegistry 限制事务操作数的注册中心
+type limitRegistry struct {
+	registry.IRegistry
+}
+
+func (r limitRegistry) Txn(ops ...registry.Op) error {
+	if len(ops) > registry.MaxTxnOps {
+		return fmt.Errorf("事务操作数%d超出限制", len(ops))
+	}
+	return r.IRegistry.Txn(ops...)
+}
+
+func TestHistory_RollbackScope(t *testing.T) {
+	r := limitRegistry{IRegistry: localmemory.NewLocalMemory()}
+	h := NewHistory(r, "hydra", "sys")
+
+	r.CreatePersistentNode("/hydra/sys/api/prod/conf", `{"address":":8080"}`)
+	for i := 0; i < registry.MaxTxnOps*2; i++ {
+		r.CreatePersistentNode(fmt.Sprintf("/hydra/sys/api/prod/conf/app%03d", i), "1")
+	}
+	r.CreatePersistentNode("/hydra/var/db/db", `{"conn":"a"}`)
+	r.CreatePersistentNode("/hydra/other/api/prod/conf", `{"address":":7070"}`)
+	s1, err := h.Record(ActionPublish, "colin", "")
+	assert.Equal(t, nil, err, "节点数超出事务限制时记录快照")
+	_, ok := s1.Confs["/hydra/other/api/prod/conf"]
+	assert.Equal(t, false, ok, "快照不包含其它系统的配置")
+
+	for i := 0; i < registry.MaxTxnOps*2; i++ {
+		r.Update(fmt.Sprintf("/hydra/sys/api/prod/conf/app%03d", i), "2")
+	}
+	r.Update("/hydra/var/db/db", `{"conn":"b"}`)
+	r.Update("/hydra/other/api/prod/conf", `{"address":":6060"}`)
+	h.Record(ActionPublish, "colin", "")
+
+	_, err = h.Rollback(s1.Version, "lily")
+	assert.Equal(t, nil, err, "节点数超出事务限制时分批回滚")
+	buff, _, _ := r.GetValue(fmt.Sprintf("/hydra/sys/api/prod/conf/app%03d", registry.MaxTxnOps*2-1))
+	assert.Equal(t, "1", string(buff), "系统配置已回滚")
+	buff, _, _ = r.GetValue("/hydra/var/db/db")
+	assert.Equal(t, `{"conn":"b"}`, string(buff), "不回滚平台var配置")
+	buff, _, _ = r.GetValue("/hydra/other/api/prod/conf")
+	assert.Equal(t, `{"address":":6060"}`, string(buff), "不回滚其它系统的配置")
+	ok, _ = r.Exists("/hydra/other/api/prod/conf")
+	assert.Equal(t, true, ok, "不删除其它系统的配置")
+}
+
+func TestRollbackOps(t *testing.T) {
+	confs := map[string]string{"/a": "1", "/a/b": "2", "/a/c": "3"}
+	current := map[string]*confNode{
+		"/a":     {value: "1", version: 1},
+		"/a/b":   {value: "x", version: 2},
+		"/a/d":   {value: "4", version: 3},
+		"/a/d/e": {value: "5", version: 4},
+	}
+	ops := rollbackOps(confs, current)
+	assert.Equal(t, []registry.Op{
+		registry.CheckOp("/a", 1),
+		registry.UpdateOp("/a/b", "2", 2),
+		registry.CreateOp("/a/c", "3"),
+		registry.DeleteOp("/a/d/e", 4),
+		registry.DeleteOp("/a/d", 3),
+	}, ops, "使用读取时的版本构建回滚操作")
+
+	//回滚期间节点被修改
+	r := localmemory.NewLocalMemory()
+	r.CreatePersistentNode("/a", "1")
+	_, version, _ := r.GetValue("/a")
+	r.Update("/a", "2")
+	err := r.Txn(rollbackOps(map[string]string{"/a": "0"}, map[string]*confNode{"/a": {value: "1", version: version}})...)
+	assert.Equal(t, true, registry.IsConflict(err), "节点在读取后被修改时回滚失败")
+}
+
+func TestDiff(t *testing.T) {
+	from := &Snapshot{Confs: map[string]string{"/a": `{"a":1,"b":2}`, "/b": "x"}}
+	to := &Snapshot{Confs: map[string]string{"/a": `{"b":2, "a":1}`, "/c": "y"}}
+	changes := Diff(from, to)
+	assert.Equal(t, 2, len(changes), "json格式差异不视为修改")
+	assert.Equal(t, Removed, changes[0].Type, "删除节点")
+	assert.Equal(t, "/b", changes[0].Path, "删除节点路径")
+	assert.Equal(t, Added, changes[1].Type, "新增节点")
+	assert.Equal(t, "/c", changes[1].Path, "新增节点路径")
+}
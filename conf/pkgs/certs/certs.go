@@ -0,0 +1,85 @@
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+const (
+	//ClientAuthNone 不验证客户端证书
+	ClientAuthNone = "none"
+
+	//ClientAuthRequest 客户端提供证书时进行验证
+	ClientAuthRequest = "request"
+
+	//ClientAuthRequire 客户端必须提供有效证书
+	ClientAuthRequire = "require"
+)
+
+//TLS 服务器证书配置，证书、私钥与CA证书可以是pem内容或文件路径
+type TLS struct {
+	Cert       string `json:"cert,omitempty" toml:"cert,omitempty" label:"证书(pem内容或文件路径)"`
+	Key        string `json:"key,omitempty" toml:"key,omitempty" label:"证书私钥(pem内容或文件路径)"`
+	ClientCA   string `json:"clientCA,omitempty" toml:"clientCA,omitempty" label:"客户端CA证书(pem内容或文件路径)"`
+	ClientAuth string `json:"clientAuth,omitempty" valid:"in(none|request|require)" toml:"clientAuth,omitempty" label:"客户端证书验证方式"`
+}
+
+//IsTLS 是否启用TLS
+func (t TLS) IsTLS() bool {
+	return t.Cert != "" && t.Key != ""
+}
+
+//GetTLSConfig 构建TLS配置
+func (t TLS) GetTLSConfig() (*tls.Config, error) {
+	if !t.IsTLS() {
+		return nil, fmt.Errorf("未配置证书或私钥")
+	}
+	cert, err := load(t.Cert)
+	if err != nil {
+		return nil, fmt.Errorf("加载证书失败:%w", err)
+	}
+	key, err := load(t.Key)
+	if err != nil {
+		return nil, fmt.Errorf("加载证书私钥失败:%w", err)
+	}
+	pair, err := tls.X509KeyPair(cert, key)
+	if err != nil {
+		return nil, fmt.Errorf("证书或私钥格式有误:%w", err)
+	}
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{pair},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	switch t.ClientAuth {
+	case ClientAuthRequest:
+		cfg.ClientAuth = tls.VerifyClientCertIfGiven
+	case ClientAuthRequire:
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return cfg, nil
+	}
+	if t.ClientCA == "" {
+		return nil, fmt.Errorf("启用客户端证书验证时必须配置客户端CA证书")
+	}
+	ca, err := load(t.ClientCA)
+	if err != nil {
+		return nil, fmt.Errorf("加载客户端CA证书失败:%w", err)
+	}
+	cfg.ClientCAs = x509.NewCertPool()
+	if !cfg.ClientCAs.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("客户端CA证书格式有误")
+	}
+	return cfg, nil
+}
+
+//load 加载pem内容，不是pem内容时作为文件路径读取
+func load(v string) ([]byte, error) {
+	if strings.Contains(v, "-----BEGIN") {
+		return []byte(v), nil
+	}
+	return ioutil.ReadFile(v)
+}
@@ -0,0 +1,115 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/micro-plat/lib4go/assert"
+)
+
+//newCert 生成证书，parent为空时生成自签名CA证书
+func newCert(t *testing.T, cn string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Equal(t, nil, err, "生成私钥失败")
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		tpl.IsCA = true
+		tpl.BasicConstraintsValid = true
+		tpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+		parent, parentKey = tpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, parent, &key.PublicKey, parentKey)
+	assert.Equal(t, nil, err, "生成证书失败")
+	cert, _ := x509.ParseCertificate(der)
+	kder, _ := x509.MarshalECPrivateKey(key)
+	return cert, key,
+		string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: kder}))
+}
+
+func TestTLS_GetTLSConfig(t *testing.T) {
+	ca, caKey, caPem, _ := newCert(t, "hydra-ca", nil, nil)
+	_, _, certPem, keyPem := newCert(t, "server", ca, caKey)
+
+	_, err := TLS{}.GetTLSConfig()
+	assert.Equal(t, true, err != nil, "未配置证书时应返回错误")
+
+	//pem内容
+	cfg, err := TLS{Cert: certPem, Key: keyPem}.GetTLSConfig()
+	assert.Equal(t, nil, err, "加载pem内容失败")
+	assert.Equal(t, 1, len(cfg.Certificates), "证书数量不一致")
+	assert.Equal(t, tls.NoClientCert, cfg.ClientAuth, "默认不验证客户端证书")
+
+	//文件路径
+	dir, _ := ioutil.TempDir("", "certs")
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, "server.crt"), []byte(certPem), 0600)
+	ioutil.WriteFile(filepath.Join(dir, "server.key"), []byte(keyPem), 0600)
+	ioutil.WriteFile(filepath.Join(dir, "ca.crt"), []byte(caPem), 0600)
+	cfg, err = TLS{
+		Cert:       filepath.Join(dir, "server.crt"),
+		Key:        filepath.Join(dir, "server.key"),
+		ClientCA:   filepath.Join(dir, "ca.crt"),
+		ClientAuth: ClientAuthRequire,
+	}.GetTLSConfig()
+	assert.Equal(t, nil, err, "加载证书文件失败")
+	assert.Equal(t, tls.RequireAndVerifyClientCert, cfg.ClientAuth, "客户端证书验证方式不一致")
+
+	_, err = TLS{Cert: certPem, Key: keyPem, ClientAuth: ClientAuthRequest}.GetTLSConfig()
+	assert.Equal(t, true, err != nil, "验证客户端证书时必须配置CA证书")
+
+	_, err = TLS{Cert: filepath.Join(dir, "none.crt"), Key: keyPem}.GetTLSConfig()
+	assert.Equal(t, true, err != nil, "证书文件不存在时应返回错误")
+}
+
+func TestTLS_ClientAuth(t *testing.T) {
+	ca, caKey, caPem, _ := newCert(t, "hydra-ca", nil, nil)
+	_, _, certPem, keyPem := newCert(t, "server", ca, caKey)
+	_, _, clientPem, clientKeyPem := newCert(t, "client-01", ca, caKey)
+
+	cfg, err := TLS{Cert: certPem, Key: keyPem, ClientCA: caPem, ClientAuth: ClientAuthRequire}.GetTLSConfig()
+	assert.Equal(t, nil, err, "构建TLS配置失败")
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.VerifiedChains[0][0].Subject.String()))
+	}))
+	srv.TLS = cfg
+	srv.StartTLS()
+	defer srv.Close()
+
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM([]byte(caPem))
+
+	//未提供客户端证书
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+	_, err = client.Get(srv.URL)
+	assert.Equal(t, true, err != nil, "未提供客户端证书时应拒绝连接")
+
+	pair, _ := tls.X509KeyPair([]byte(clientPem), []byte(clientKeyPem))
+	client = &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool, Certificates: []tls.Certificate{pair}}}}
+	resp, err := client.Get(srv.URL)
+	assert.Equal(t, nil, err, "请求失败")
+	defer resp.Body.Close()
+	buff, _ := ioutil.ReadAll(resp.Body)
+	assert.Equal(t, "CN=client-01", string(buff), "客户端证书主题不一致")
+}
@@ -1,16 +1,16 @@
 package security
 
 type IEncrypt interface {
-	Encrypt(input []byte) string
+	Encrypt(input []byte) (string, error)
 }
 
 type ConfEncrypt struct {
 	EnableEncryption bool `json:"-"`
 }
 
-func (c ConfEncrypt) Encrypt(input []byte) string {
+func (c ConfEncrypt) Encrypt(input []byte) (string, error) {
 	if c.EnableEncryption {
 		return Encrypt(input)
 	}
-	return string(input)
+	return string(input), nil
 }
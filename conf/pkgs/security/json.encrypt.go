@@ -1,8 +1,13 @@
 package security
 
 import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"io"
+	"strings"
 
 	"github.com/micro-plat/lib4go/security/des"
 )
@@ -12,26 +17,104 @@ const confIV = "#*---iv*"
 const hd = "encrypt"
 const mode = "cbc/pkcs5"
 
-//Encrypt 对加密内容进行des加密，并增加加密头
-func Encrypt(input []byte) string {
-	v, _ := des.EncryptBytes(input, confKey, []byte(confIV), mode)
-	return fmt.Sprintf("%s:%s:%s", hd, mode, hex.EncodeToString(v))
+//gcmMode AES-256-GCM加密模式，加密头格式为encrypt:aes-gcm:密钥编号:密文
+const gcmMode = "aes-gcm"
+
+//Encrypt 使用当前密钥对内容进行AES-GCM加密，并增加加密头。
+//内置des密钥仅用于解密旧版本数据，未配置密钥或密钥有误时返回错误
+func Encrypt(input []byte) (string, error) {
+	p := GetProvider()
+	if r, ok := p.(*keyRing); ok && r.err != nil {
+		return "", r.err
+	}
+	id := p.GetCurrentID()
+	if id == "" {
+		return "", fmt.Errorf("未配置加密密钥,请通过环境变量%s或%s指定", EnvConfKeys, EnvConfKeyFile)
+	}
+	return EncryptBy(id, input)
+}
+
+//EncryptBy 使用指定编号的密钥对内容进行AES-GCM加密，并增加加密头
+func EncryptBy(id string, input []byte) (string, error) {
+	gcm, err := getGCM(id)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	v := gcm.Seal(nonce, nonce, input, []byte(id))
+	return fmt.Sprintf("%s:%s:%s:%s", hd, gcmMode, id, hex.EncodeToString(v)), nil
+}
+
+//IsEncrypted 是否包含加密头
+func IsEncrypted(data []byte) bool {
+	return len(data) > len(hd)+len(mode)+2 && string(data[0:len(hd)]) == hd
+}
+
+//GetKeyID 获取加密数据使用的密钥编号，旧版des加密的数据返回空
+func GetKeyID(data []byte) string {
+	if !IsEncrypted(data) {
+		return ""
+	}
+	parts := strings.SplitN(string(data), ":", 4)
+	if len(parts) == 4 && parts[1] == gcmMode {
+		return parts[2]
+	}
+	return ""
 }
 
 //Decrypt 检查是否包含加密头，报含则根据加密头数据解密数据
 func Decrypt(data []byte) ([]byte, error) {
-	lheader := len(hd)
-	lmode := len(mode)
-	if len(data) <= lheader+lmode+2 {
+	if !IsEncrypted(data) {
 		return data, nil
 	}
-	if string(data[0:lheader]) != hd {
-		return data, nil
+	parts := strings.SplitN(string(data), ":", 3)
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("加密数据格式有误")
+	}
+	if parts[1] != gcmMode {
+		src, err := hex.DecodeString(parts[2])
+		if err != nil {
+			return nil, err
+		}
+		return des.DecryptBytes(src, confKey, []byte(confIV), parts[1])
+	}
+
+	kv := strings.SplitN(parts[2], ":", 2)
+	if len(kv) != 2 {
+		return nil, fmt.Errorf("加密数据格式有误,未包含密钥编号")
+	}
+	src, err := hex.DecodeString(kv[1])
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := getGCM(kv[0])
+	if err != nil {
+		return nil, err
+	}
+	if len(src) < gcm.NonceSize() {
+		return nil, fmt.Errorf("加密数据长度有误")
+	}
+	v, err := gcm.Open(nil, src[:gcm.NonceSize()], src[gcm.NonceSize():], []byte(kv[0]))
+	if err != nil {
+		return nil, fmt.Errorf("使用密钥%s解密失败:%w", kv[0], err)
+	}
+	if v == nil {
+		v = []byte{}
+	}
+	return v, nil
+}
+
+func getGCM(id string) (cipher.AEAD, error) {
+	key, err := GetProvider().GetKey(id)
+	if err != nil {
+		return nil, err
 	}
-	mode := string(data[lheader+1 : lheader+lmode+1])
-	src := make([]byte, (len(data)-lheader-lmode-2)/2)
-	if _, err := hex.Decode(src, data[(lheader+lmode+2):]); err != nil {
+	block, err := aes.NewCipher(key)
+	if err != nil {
 		return nil, err
 	}
-	return des.DecryptBytes(src, confKey, []byte(confIV), mode)
+	return cipher.NewGCM(block)
 }
@@ -1,13 +1,38 @@
 package security
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/micro-plat/lib4go/assert"
+	"github.com/micro-plat/lib4go/security/des"
 )
 
+//legacyEncrypt 使用内置des密钥加密，用于构建旧版本数据
+func legacyEncrypt(input []byte) string {
+	v, _ := des.EncryptBytes(input, confKey, []byte(confIV), mode)
+	return fmt.Sprintf("%s:%s:%s", hd, mode, hex.EncodeToString(v))
+}
+
+//withTestKeys 设置测试密钥
+func withTestKeys(t *testing.T) func() {
+	p, err := newKeyRing("k1:"+base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, KeySize)), "test")
+	assert.Equal(t, nil, err, "加载密钥失败")
+	SetProvider(p)
+	return func() { SetProvider(nil) }
+}
+
 func BenchmarkEncrypt(b *testing.B) {
+	p, _ := newKeyRing("k1:"+base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, KeySize)), "test")
+	SetProvider(p)
+	defer SetProvider(nil)
 	b.ResetTimer()
 	var input = []byte("taosytaosytaosytaosytaosytaosytaosy")
 	for i := 0; i < b.N; i++ {
@@ -31,23 +56,47 @@ func Test_encrypt(t *testing.T) {
 		{name: "1. conf-encrypt-空数据加密", input: []byte{}},
 		{name: "2. conf-encrypt-数据加密", input: []byte("taosytaosytaosytaosytaosytaosytaosy")},
 	}
+	defer withTestKeys(t)()
 	for _, tt := range tests {
-		got := Encrypt(tt.input)
+		got, err := Encrypt(tt.input)
+		assert.Equal(t, nil, err, tt.name+".err")
 		list := strings.Split(got, ":")
-		assert.Equal(t, len(list), 3, tt.name+",len")
-		if len(list) >= 2 {
+		assert.Equal(t, len(list), 4, tt.name+",len")
+		if len(list) >= 3 {
 			assert.Equal(t, list[0], hd, tt.name+".hd")
-			assert.Equal(t, list[1], mode, tt.name+",mode")
-
+			assert.Equal(t, list[1], gcmMode, tt.name+",mode")
+			assert.Equal(t, list[2], "k1", tt.name+",id")
 		}
 	}
 }
 
+func Test_encryptNoKey(t *testing.T) {
+	//未配置密钥时返回错误，不使用内置des密钥加密
+	r, _ := newKeyRing("", "test")
+	SetProvider(r)
+	defer SetProvider(nil)
+	_, err := Encrypt([]byte("abc"))
+	assert.Equal(t, true, err != nil, "未配置密钥时应返回错误")
+
+	//密钥配置有误时返回加载错误
+	_, lerr := newKeyRing("k1:abc", "test")
+	SetProvider(&keyRing{err: lerr})
+	_, err = Encrypt([]byte("abc"))
+	assert.Equal(t, lerr, err, "密钥配置有误时应返回加载错误")
+
+	_, err = ConfEncrypt{EnableEncryption: true}.Encrypt([]byte("abc"))
+	assert.Equal(t, lerr, err, "配置加密时应返回加载错误")
+	v, err := ConfEncrypt{}.Encrypt([]byte("abc"))
+	assert.Equal(t, nil, err, "未启用加密")
+	assert.Equal(t, "abc", v, "未启用加密时返回原内容")
+}
+
 func Test_decrypt(t *testing.T) {
+	defer withTestKeys(t)()
 	input := []byte{}
-	nildata := Encrypt(input)
+	nildata, _ := Encrypt(input)
 	input1 := []byte("encryptapsytsetetapsytsetetapsytsetetapsytsete")
-	data1 := Encrypt(input1)
+	data1, _ := Encrypt(input1)
 
 	tests := []struct {
 		name    string
@@ -67,3 +116,60 @@ func Test_decrypt(t *testing.T) {
 		assert.Equal(t, tt.want, got, tt.name+",res")
 	}
 }
+
+func Test_gcmEncrypt(t *testing.T) {
+	os.Setenv("TEST_HYDRA_CONF_KEYS", "k1:"+base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, KeySize))+
+		",k2:"+hex.EncodeToString(bytes.Repeat([]byte{2}, KeySize)))
+	p, err := NewEnvProvider("TEST_HYDRA_CONF_KEYS")
+	assert.Equal(t, nil, err, "加载密钥失败")
+	assert.Equal(t, "k2", p.GetCurrentID(), "当前密钥应为最后一个")
+	SetProvider(p)
+	defer SetProvider(nil)
+
+	input := []byte(`{"address":":8080"}`)
+	v, err := Encrypt(input)
+	assert.Equal(t, nil, err, "加密失败")
+	assert.Equal(t, true, strings.HasPrefix(v, "encrypt:aes-gcm:k2:"), "应使用当前密钥加密")
+	assert.Equal(t, "k2", GetKeyID([]byte(v)), "密钥编号不一致")
+	got, err := Decrypt([]byte(v))
+	assert.Equal(t, nil, err, "解密失败")
+	assert.Equal(t, input, got, "解密结果不一致")
+
+	//旧密钥加密的内容仍可解密
+	v1, err := EncryptBy("k1", input)
+	assert.Equal(t, nil, err, "使用旧密钥加密失败")
+	got, _ = Decrypt([]byte(v1))
+	assert.Equal(t, input, got, "旧密钥解密结果不一致")
+
+	//旧版des加密的内容仍可解密
+	got, err = Decrypt([]byte(legacyEncrypt(input)))
+	assert.Equal(t, nil, err, "旧版加密数据解密失败")
+	assert.Equal(t, input, got, "旧版加密数据解密结果不一致")
+
+	//篡改密钥编号
+	_, err = Decrypt([]byte(strings.Replace(v1, ":k1:", ":k2:", 1)))
+	assert.Equal(t, true, err != nil, "密钥编号被篡改时应解密失败")
+
+	_, err = EncryptBy("k3", input)
+	assert.Equal(t, true, err != nil, "密钥不存在时应加密失败")
+}
+
+func Test_keyRing(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "keys")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "conf.keys")
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{3}, KeySize))
+	ioutil.WriteFile(path, []byte("#密钥列表\nv1:"+key+"\n\nv2:"+key+"\n"), 0600)
+	p, err := NewFileProvider(path)
+	assert.Equal(t, nil, err, "加载密钥文件失败")
+	assert.Equal(t, "v2", p.GetCurrentID(), "当前密钥应为最后一个")
+	_, err = p.GetKey("v1")
+	assert.Equal(t, nil, err, "获取密钥失败")
+
+	_, err = newKeyRing("v1:abc", "test")
+	assert.Equal(t, true, err != nil, "密钥长度有误时应返回错误")
+	_, err = newKeyRing("v:1:"+key, "test")
+	assert.Equal(t, true, err != nil, "密钥格式有误时应返回错误")
+	r, _ := newKeyRing("", "test")
+	assert.Equal(t, "", r.GetCurrentID(), "未配置密钥")
+}
@@ -0,0 +1,152 @@
+package security
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const (
+	//EnvConfKeys 配置加密密钥环境变量，格式为"编号:密钥,编号:密钥"，最后一个为当前密钥
+	EnvConfKeys = "HYDRA_CONF_KEYS"
+
+	//EnvConfKeyFile 配置加密密钥文件路径环境变量，文件中每行一个"编号:密钥"
+	EnvConfKeyFile = "HYDRA_CONF_KEY_FILE"
+)
+
+//KeySize AES-256密钥长度
+const KeySize = 32
+
+var keyIDRegexp = regexp.MustCompile(`^[\w\-\.]+$`)
+
+//IKeyProvider 密钥提供程序
+type IKeyProvider interface {
+
+	//GetKey 获取指定编号的密钥
+	GetKey(id string) ([]byte, error)
+
+	//GetCurrentID 获取当前用于加密的密钥编号，未配置密钥时返回空
+	GetCurrentID() string
+}
+
+var provider IKeyProvider
+var plock sync.RWMutex
+
+//SetProvider 设置密钥提供程序
+func SetProvider(p IKeyProvider) {
+	plock.Lock()
+	defer plock.Unlock()
+	provider = p
+}
+
+//GetProvider 获取密钥提供程序，未设置时根据环境变量从密钥文件或环境变量加载
+func GetProvider() IKeyProvider {
+	plock.RLock()
+	p := provider
+	plock.RUnlock()
+	if p != nil {
+		return p
+	}
+	plock.Lock()
+	defer plock.Unlock()
+	if provider != nil {
+		return provider
+	}
+	if path := os.Getenv(EnvConfKeyFile); path != "" {
+		fp, err := NewFileProvider(path)
+		if err != nil {
+			return &keyRing{err: err}
+		}
+		provider = fp
+		return provider
+	}
+	ep, err := NewEnvProvider(EnvConfKeys)
+	if err != nil {
+		return &keyRing{err: err}
+	}
+	provider = ep
+	return provider
+}
+
+//keyRing 密钥环，保存所有可用于解密的密钥
+type keyRing struct {
+	keys    map[string][]byte
+	current string
+	err     error
+}
+
+//NewEnvProvider 从环境变量中加载密钥
+func NewEnvProvider(name string) (IKeyProvider, error) {
+	return newKeyRing(os.Getenv(name), fmt.Sprintf("环境变量%s", name))
+}
+
+//NewFileProvider 从文件中加载密钥
+func NewFileProvider(path string) (IKeyProvider, error) {
+	buff, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取密钥文件%s失败:%w", path, err)
+	}
+	return newKeyRing(string(buff), fmt.Sprintf("密钥文件%s", path))
+}
+
+//newKeyRing 解析"编号:密钥"列表，以逗号或换行分隔，#开头的行为注释
+func newKeyRing(content string, source string) (*keyRing, error) {
+	r := &keyRing{keys: make(map[string][]byte)}
+	lines := strings.FieldsFunc(content, func(c rune) bool { return c == ',' || c == '\n' || c == '\r' })
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		kv := strings.SplitN(line, ":", 2)
+		if len(kv) != 2 || !keyIDRegexp.MatchString(kv[0]) {
+			return nil, fmt.Errorf("%s中的密钥格式有误,应为'编号:密钥'", source)
+		}
+		key, err := decodeKey(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return nil, fmt.Errorf("%s中的密钥%s有误:%w", source, kv[0], err)
+		}
+		r.keys[kv[0]] = key
+		r.current = kv[0]
+	}
+	return r, nil
+}
+
+//decodeKey 解析base64或hex编码的密钥
+func decodeKey(v string) ([]byte, error) {
+	if len(v) == KeySize*2 {
+		if key, err := hex.DecodeString(v); err == nil {
+			return key, nil
+		}
+	}
+	key, err := base64.StdEncoding.DecodeString(v)
+	if err != nil {
+		return nil, fmt.Errorf("密钥应为base64或hex编码")
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("密钥长度应为%d字节", KeySize)
+	}
+	return key, nil
+}
+
+//GetKey 获取指定编号的密钥
+func (r *keyRing) GetKey(id string) ([]byte, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	key, ok := r.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("未找到配置加密密钥:%s", id)
+	}
+	return key, nil
+}
+
+//GetCurrentID 获取当前密钥编号
+func (r *keyRing) GetCurrentID() string {
+	return r.current
+}
@@ -53,6 +53,16 @@ func WithFallback() RuleOption {
 	}
 }
 
+//WithCluster 启用集群限流，redis为/var/redis下的配置名称，未指定时使用默认配置
+func WithCluster(redis ...string) RuleOption {
+	return func(a *Rule) {
+		a.Mode = ModeCluster
+		if len(redis) > 0 {
+			a.Redis = redis[0]
+		}
+	}
+}
+
 //WithReponse 设置响应内容
 func WithReponse(status int, content string) RuleOption {
 	return func(a *Rule) {
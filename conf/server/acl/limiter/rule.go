@@ -13,6 +13,17 @@ type Resp struct {
 	Content string `json:"content" valid:"required" toml:"content,omitempty" label:"限流返回内容"`
 }
 
+const (
+	//ModeLocal 本地限流，每个服务器节点单独计算
+	ModeLocal = "local"
+
+	//ModeCluster 集群限流，通过redis在集群所有节点间共享令牌桶
+	ModeCluster = "cluster"
+
+	//DefaultRedisName 集群限流默认使用的redis配置名称(/var/redis/redis)
+	DefaultRedisName = "redis"
+)
+
 //Rule 按请求设定的限流器
 type Rule struct {
 	Path     string `json:"path" valid:"ascii,required" toml:"path,omitempty" label:"限流路径"`
@@ -20,6 +31,8 @@ type Rule struct {
 	MaxWait  int    `json:"maxWait,omitempty"  toml:"maxWait,omitempty"`
 	Fallback bool   `json:"fallback,omitempty"  toml:"fallback,omitempty"`
 	Resp     *Resp  `json:"resp,omitempty" valid:"required" toml:"resp,omitempty"`
+	Mode     string `json:"mode,omitempty" valid:"in(local|cluster)" toml:"mode,omitempty" label:"限流模式"`
+	Redis    string `json:"redis,omitempty" valid:"ascii" toml:"redis,omitempty" label:"集群限流使用的redis配置名称"`
 	limiter  *rate.Limiter
 }
 
@@ -42,6 +55,19 @@ func (l *Rule) GetLimiter() *rate.Limiter {
 	return l.limiter
 }
 
+//IsCluster 是否启用集群限流
+func (l *Rule) IsCluster() bool {
+	return l.Mode == ModeCluster
+}
+
+//GetRedisName 获取集群限流使用的redis配置名称
+func (l *Rule) GetRedisName() string {
+	if l.Redis == "" {
+		return DefaultRedisName
+	}
+	return l.Redis
+}
+
 //GetDelay 获取延迟等待时长
 func (l *Rule) GetDelay() time.Duration {
 	return time.Second * time.Duration(l.MaxWait)
@@ -1,6 +1,9 @@
 package api
 
-import "github.com/micro-plat/hydra/conf/server/router"
+import (
+	"github.com/micro-plat/hydra/conf/pkgs/certs"
+	"github.com/micro-plat/hydra/conf/server/router"
+)
 
 //WithEncoding 添加编码
 var WithEncoding = router.WithEncoding
@@ -76,3 +79,22 @@ func WithEnableEncryption() Option {
 		a.EnableEncryption = true
 	}
 }
+
+//WithTLS 设置服务器证书与私钥，可以是pem内容或文件路径
+func WithTLS(cert string, key string) Option {
+	return func(a *Server) {
+		a.Cert = cert
+		a.Key = key
+	}
+}
+
+//WithClientCA 设置客户端CA证书，并启用客户端证书验证(默认为require)
+func WithClientCA(ca string, auth ...string) Option {
+	return func(a *Server) {
+		a.ClientCA = ca
+		a.ClientAuth = certs.ClientAuthRequire
+		if len(auth) > 0 {
+			a.ClientAuth = auth[0]
+		}
+	}
+}
@@ -12,6 +12,14 @@ import (
 //TypeNodeName APM配置节点名
 const TypeNodeName = "apm"
 
+const (
+	//TracerSkyWalking 使用skywalking grpc上报
+	TracerSkyWalking = "skywalking"
+
+	//TracerOTLP 使用OpenTelemetry OTLP/HTTP(JSON)上报
+	TracerOTLP = "otlp"
+)
+
 type IAPM interface {
 	GetConf() (*APM, bool)
 }
@@ -19,6 +27,7 @@ type IAPM interface {
 //APM APM
 type APM struct {
 	security.ConfEncrypt
+	Tracer  string `json:"tracer,omitempty" valid:"in(skywalking|otlp)" toml:"tracer,omitempty" label:"链路跟踪类型"`
 	Address string `json:"address,omitempty" valid:"required" toml:"address,omitempty" label:"应用程序性能监控地址"`
 	Version int32  `json:"-"`
 	Disable bool   `json:"disable,omitempty" toml:"disable,omitempty"`
@@ -35,6 +44,14 @@ func New(address string, opts ...Option) *APM {
 	return m
 }
 
+//GetTracer 获取链路跟踪类型，默认为skywalking
+func (a *APM) GetTracer() string {
+	if a.Tracer == "" {
+		return TracerSkyWalking
+	}
+	return a.Tracer
+}
+
 //GetConf 设置APM
 func GetConf(cnf conf.IServerConf) (apm *APM, err error) {
 	apm = &APM{}
@@ -3,6 +3,13 @@ package apm
 //Option 配置选项
 type Option func(*APM)
 
+//WithOTLP 使用OpenTelemetry OTLP/HTTP上报，address为collector地址，如http://127.0.0.1:4318
+func WithOTLP() Option {
+	return func(a *APM) {
+		a.Tracer = TracerOTLP
+	}
+}
+
 //WithDisable 禁用配置
 func WithDisable() Option {
 	return func(a *APM) {
@@ -7,11 +7,23 @@ import (
 	"github.com/asaskevich/govalidator"
 	"github.com/micro-plat/hydra/conf"
 	"github.com/micro-plat/hydra/conf/pkgs/security"
+	"github.com/micro-plat/hydra/global"
 )
 
 //TypeNodeName metric配置节点名
 const TypeNodeName = "metric"
 
+const (
+	//ReporterInfluxDB 定时推送到influxdb
+	ReporterInfluxDB = "influxdb"
+
+	//ReporterPrometheus 提供prometheus拉取接口
+	ReporterPrometheus = "prometheus"
+
+	//DefaultPath prometheus拉取接口默认路径
+	DefaultPath = "/metrics"
+)
+
 type IMetric interface {
 	GetConf() (*Metric, bool)
 }
@@ -19,11 +31,14 @@ type IMetric interface {
 //Metric Metric
 type Metric struct {
 	security.ConfEncrypt
-	Host     string `json:"host,omitempty" valid:"requrl,required" toml:"host,omitempty" label:"监控主机地址"`
-	DataBase string `json:"dataBase,omitempty" valid:"ascii,required" toml:"dataBase,omitempty" label:"监控主机数据库"`
-	Cron     string `json:"cron,omitempty" valid:"ascii,required" toml:"cron,omitempty" label:"监控主机cron"`
+	Reporter string `json:"reporter,omitempty" valid:"in(influxdb|prometheus)" toml:"reporter,omitempty" label:"上报方式"`
+	Host     string `json:"host,omitempty" valid:"requrl" toml:"host,omitempty" label:"监控主机地址"`
+	DataBase string `json:"dataBase,omitempty" valid:"ascii" toml:"dataBase,omitempty" label:"监控主机数据库"`
+	Cron     string `json:"cron,omitempty" valid:"ascii" toml:"cron,omitempty" label:"监控主机cron"`
 	UserName string `json:"userName,omitempty" valid:"ascii" toml:"userName,omitempty" label:"监控主机用户名"`
 	Password string `json:"password,omitempty" valid:"ascii" toml:"password,omitempty" label:"监控主机用密码"`
+	Path     string `json:"path,omitempty" valid:"ascii" toml:"path,omitempty" label:"prometheus拉取路径"`
+	Port     string `json:"port,omitempty" valid:"port" toml:"port,omitempty" label:"prometheus拉取独立端口"`
 	Disable  bool   `json:"disable,omitempty" toml:"disable,omitempty"`
 }
 
@@ -40,6 +55,36 @@ func New(host string, db string, cron string, opts ...Option) *Metric {
 	return m
 }
 
+//NewPrometheus 构建prometheus拉取配置，未指定端口时使用当前服务器端口提供拉取接口(仅api,web,ws服务器)
+func NewPrometheus(opts ...Option) *Metric {
+	m := &Metric{
+		Reporter: ReporterPrometheus,
+		Path:     DefaultPath,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+//IsPrometheus 是否使用prometheus拉取方式
+func (m *Metric) IsPrometheus() bool {
+	return m.Reporter == ReporterPrometheus
+}
+
+//GetPath 获取prometheus拉取路径
+func (m *Metric) GetPath() string {
+	if m.Path == "" {
+		return DefaultPath
+	}
+	return m.Path
+}
+
+//isHTTPServer 是否是可直接提供prometheus拉取接口的http类服务器
+func isHTTPServer(tp string) bool {
+	return tp == global.API || tp == global.Web || tp == global.WS
+}
+
 //GetConf 设置metric
 func GetConf(cnf conf.IServerConf) (metric *Metric, err error) {
 	metric = &Metric{}
@@ -55,5 +100,11 @@ func GetConf(cnf conf.IServerConf) (metric *Metric, err error) {
 	if b, err := govalidator.ValidateStruct(metric); !b {
 		return nil, fmt.Errorf("metric配置数据有误:%v", err)
 	}
+	if !metric.IsPrometheus() && (metric.Host == "" || metric.DataBase == "" || metric.Cron == "") {
+		return nil, fmt.Errorf("metric配置数据有误:influxdb的host,dataBase,cron不能为空")
+	}
+	if metric.IsPrometheus() && metric.Port == "" && !isHTTPServer(cnf.GetServerType()) {
+		return nil, fmt.Errorf("metric配置数据有误:%s服务器使用prometheus时port不能为空", cnf.GetServerType())
+	}
 	return
 }
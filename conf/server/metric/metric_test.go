@@ -0,0 +1,45 @@
+package metric
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/micro-plat/hydra/conf"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+type testServerConf struct {
+	conf.IServerConf
+	tp     string
+	metric *Metric
+}
+
+func (s *testServerConf) GetServerType() string {
+	return s.tp
+}
+
+func (s *testServerConf) GetSubObject(name string, out interface{}) (int32, error) {
+	buff, _ := json.Marshal(s.metric)
+	return 0, json.Unmarshal(buff, out)
+}
+
+func TestGetConf_Prometheus(t *testing.T) {
+	tests := []struct {
+		name    string
+		tp      string
+		metric  *Metric
+		wantErr bool
+	}{
+		{name: "api服务器未指定端口", tp: global.API, metric: NewPrometheus()},
+		{name: "ws服务器未指定端口", tp: global.WS, metric: NewPrometheus()},
+		{name: "cron服务器未指定端口", tp: global.CRON, metric: NewPrometheus(), wantErr: true},
+		{name: "mqc服务器未指定端口", tp: global.MQC, metric: NewPrometheus(), wantErr: true},
+		{name: "rpc服务器未指定端口", tp: global.RPC, metric: NewPrometheus(), wantErr: true},
+		{name: "cron服务器指定端口", tp: global.CRON, metric: NewPrometheus(WithPort("9100"))},
+	}
+	for _, tt := range tests {
+		_, err := GetConf(&testServerConf{tp: tt.tp, metric: tt.metric})
+		assert.Equal(t, tt.wantErr, err != nil, tt.name, err)
+	}
+}
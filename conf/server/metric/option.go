@@ -11,6 +11,20 @@ func WithUPName(userName string, password string) Option {
 	}
 }
 
+//WithPath 设置prometheus拉取路径
+func WithPath(path string) Option {
+	return func(a *Metric) {
+		a.Path = path
+	}
+}
+
+//WithPort 设置prometheus拉取独立端口
+func WithPort(port string) Option {
+	return func(a *Metric) {
+		a.Port = port
+	}
+}
+
 //WithDisable 禁用配置
 func WithDisable() Option {
 	return func(a *Metric) {
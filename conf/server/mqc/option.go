@@ -66,6 +66,11 @@ func WithLMQ() string {
 	return fmt.Sprintf("%s://.", global.ProtoLMQ)
 }
 
+//WithDMQ 返回本地磁盘持久化队列地址名称
+func WithDMQ(name string) string {
+	return fmt.Sprintf("%s://%s", global.ProtoDMQ, name)
+}
+
 //WithEnableEncryption 启用加密设置
 func WithEnableEncryption() Option {
 	return func(a *Server) {
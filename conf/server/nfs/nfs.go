@@ -23,6 +23,8 @@ type NFS struct {
 	Endpoint  string `json:"endpoint,omitempty" toml:"endpoint,omitempty"`
 	AccessKey string `json:"ak,omitempty" toml:"ak,omitempty"`
 	SecretKey string `json:"sk,omitempty" toml:"sk,omitempty"`
+	Region    string `json:"region,omitempty" toml:"region,omitempty"`
+	PathStyle bool   `json:"pathStyle,omitempty" toml:"pathStyle,omitempty"`
 
 	AllowDownload   bool   `json:"allowDownload,omitempty" toml:"allowDownload,omitempty"`
 	DownloadService string `json:"downloadService,omitempty" toml:"downloadService,omitempty"`
@@ -30,6 +32,11 @@ type NFS struct {
 	UploadService string `json:"uploadService,omitempty" toml:"uploadService,omitempty"`
 	DiableUpload  bool   `json:"diableUpload,omitempty" toml:"diableUpload,omitempty"`
 
+	ChunkUploadService string `json:"chunkUploadService,omitempty" toml:"chunkUploadService,omitempty"`
+	AllowChunkUpload   bool   `json:"allowChunkUpload,omitempty" toml:"allowChunkUpload,omitempty"`
+	ChunkTemp          string `json:"chunkTemp,omitempty" toml:"chunkTemp,omitempty"`
+	ChunkExpire        int    `json:"chunkExpire,omitempty" toml:"chunkExpire,omitempty"`
+
 	ListFileService string `json:"listFileService,omitempty" toml:"listFileService,omitempty"`
 	AllowListFile   bool   `json:"allowListFile,omitempty" toml:"allowListFile,omitempty"`
 
@@ -48,6 +55,17 @@ type NFS struct {
 	RenameDirService string `json:"renameDirService,omitempty" toml:"renameDirService,omitempty"`
 	AllowRenameDir   bool   `json:"allowRenameDir,omitempty" toml:"allowRenameDir,omitempty"`
 
+	DeleteService string `json:"deleteService,omitempty" toml:"deleteService,omitempty"`
+	AllowDelete   bool   `json:"allowDelete,omitempty" toml:"allowDelete,omitempty"`
+
+	MoveService string `json:"moveService,omitempty" toml:"moveService,omitempty"`
+	AllowMove   bool   `json:"allowMove,omitempty" toml:"allowMove,omitempty"`
+
+	//ScrubInterval 本地文件巡检间隔(秒)，为0时使用默认间隔
+	ScrubInterval int `json:"scrubInterval,omitempty" toml:"scrubInterval,omitempty"`
+
+	Retentions []*Retention `json:"retentions,omitempty" toml:"retentions,omitempty" label:"目录保留规则"`
+
 	Excludes []string `json:"excludes,omitempty"  label:"排除目录"`
 
 	Includes []string `json:"includes,omitempty"  label:"目录"`
@@ -55,6 +73,18 @@ type NFS struct {
 	Disable bool `json:"disable,omitempty" toml:"disable,omitempty"`
 }
 
+//Retention 目录文件保留规则，超过保留时长或目录总大小超过上限时从最早的文件开始删除
+type Retention struct {
+	//Dir 目录，为空时表示所有文件
+	Dir string `json:"dir,omitempty" toml:"dir,omitempty"`
+
+	//MaxAge 文件最长保留时长(秒)，为0时不限制
+	MaxAge int `json:"maxAge,omitempty" toml:"maxAge,omitempty"`
+
+	//MaxSize 目录文件总大小上限(字节)，为0时不限制
+	MaxSize int64 `json:"maxSize,omitempty" toml:"maxSize,omitempty"`
+}
+
 //New 构建mqc NFS配置，默认为对等模式
 func New(local string, opts ...Option) *NFS {
 	s := &NFS{Local: local}
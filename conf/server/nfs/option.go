@@ -31,6 +31,22 @@ func WithUpload(service string) Option {
 	}
 }
 
+//WithChunkUpload 允许分片上传，service为服务前缀
+func WithChunkUpload(service string) Option {
+	return func(a *NFS) {
+		a.AllowChunkUpload = true
+		a.ChunkUploadService = service
+	}
+}
+
+//WithChunkTemp 设置分片临时目录与未完成上传任务的有效期(秒)
+func WithChunkTemp(path string, expire int) Option {
+	return func(a *NFS) {
+		a.ChunkTemp = path
+		a.ChunkExpire = expire
+	}
+}
+
 //WithScaleImage 压缩图片文件服务
 func WithScaleImage(service string) Option {
 	return func(a *NFS) {
@@ -87,6 +103,36 @@ func WithRenameDir(service string) Option {
 	}
 }
 
+//WithDelete 允许删除文件
+func WithDelete(service string) Option {
+	return func(a *NFS) {
+		a.AllowDelete = true
+		a.DeleteService = service
+	}
+}
+
+//WithMove 允许移动文件
+func WithMove(service string) Option {
+	return func(a *NFS) {
+		a.AllowMove = true
+		a.MoveService = service
+	}
+}
+
+//WithRetention 设置目录文件保留规则，maxAge为最长保留时长(秒)，maxSize为目录文件总大小上限(字节)
+func WithRetention(dir string, maxAge int, maxSize int64) Option {
+	return func(a *NFS) {
+		a.Retentions = append(a.Retentions, &Retention{Dir: dir, MaxAge: maxAge, MaxSize: maxSize})
+	}
+}
+
+//WithScrubInterval 设置本地文件巡检间隔(秒)
+func WithScrubInterval(interval int) Option {
+	return func(a *NFS) {
+		a.ScrubInterval = interval
+	}
+}
+
 //WithHWOBS 使用华为OBS
 func WithHWOBS(ak string, sk string, bucket string, endpoint string) Option {
 	return func(a *NFS) {
@@ -98,6 +144,19 @@ func WithHWOBS(ak string, sk string, bucket string, endpoint string) Option {
 	}
 }
 
+//WithS3 使用S3兼容对象存储(AWS S3、MinIO、Ceph等)，pathStyle为true时使用 endpoint/bucket/key 格式地址
+func WithS3(ak string, sk string, bucket string, endpoint string, region string, pathStyle bool) Option {
+	return func(a *NFS) {
+		a.CloudNFS = "S3"
+		a.AccessKey = ak
+		a.SecretKey = sk
+		a.Local = bucket
+		a.Endpoint = endpoint
+		a.Region = region
+		a.PathStyle = pathStyle
+	}
+}
+
 //WithInclude 只包含目录
 func WithIncludes(name ...string) Option {
 	return func(a *NFS) {
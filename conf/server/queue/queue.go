@@ -1,11 +1,21 @@
 package queue
 
+import (
+	"time"
+
+	"github.com/micro-plat/lib4go/types"
+)
+
 //Queue 配置参数
 type Queue struct {
 	Queue       string `json:"queue,omitempty" valid:"ascii,required" toml:"queue,omitempty" label:"队列名"`
 	Service     string `json:"service,omitempty" valid:"ascii,spath,required" toml:"service,omitempty" label:"队列服务"`
 	Concurrency int    `json:"concurrency,omitempty" toml:"concurrency,omitempty"`
 	Disable     bool   `json:"disable,omitempty" toml:"disable,omitempty"`
+	MaxAttempts int    `json:"max_attempts,omitempty" toml:"max_attempts,omitempty" label:"最大处理次数"`
+	Backoff     int    `json:"backoff,omitempty" toml:"backoff,omitempty" label:"首次重试间隔(秒)"`
+	MaxBackoff  int    `json:"max_backoff,omitempty" toml:"max_backoff,omitempty" label:"最大重试间隔(秒)"`
+	DeadLetter  string `json:"dead_letter,omitempty" valid:"ascii" toml:"dead_letter,omitempty" label:"死信队列名"`
 }
 
 //NewQueue 构建queue任务信息
@@ -20,6 +30,20 @@ func NewQueue(queue string, service string, opts ...Option) *Queue {
 	return q
 }
 
+//GetDelay 获取第attempt次处理失败后的重试间隔，每次失败后间隔加倍，不超过最大间隔
+func (q *Queue) GetDelay(attempt int) time.Duration {
+	backoff := time.Duration(types.GetMax(q.Backoff, 1)) * time.Second
+	max := time.Duration(types.GetMax(q.MaxBackoff, types.GetMax(q.Backoff, 1))) * time.Second
+	delay := backoff
+	for i := 1; i < attempt && delay < max; i++ {
+		delay = delay * 2
+	}
+	if delay > max {
+		return max
+	}
+	return delay
+}
+
 //Option Option
 type Option func(q *Queue)
 
@@ -43,3 +67,20 @@ func WithEnable() Option {
 		q.Disable = false
 	}
 }
+
+//WithRetry 处理失败时重新投递，maxAttempts为最大处理次数，backoff为首次重试间隔(秒)，
+//之后每次间隔加倍，maxBackoff为最大重试间隔(秒)
+func WithRetry(maxAttempts int, backoff int, maxBackoff ...int) Option {
+	return func(q *Queue) {
+		q.MaxAttempts = maxAttempts
+		q.Backoff = backoff
+		q.MaxBackoff = types.GetIntByIndex(maxBackoff, 0, backoff)
+	}
+}
+
+//WithDeadLetter 超过最大处理次数后将消息放入死信队列
+func WithDeadLetter(queue string) Option {
+	return func(q *Queue) {
+		q.DeadLetter = queue
+	}
+}
@@ -31,22 +31,33 @@ func NewQueues(queue ...*Queue) *Queues {
 	return q
 }
 
-//Append 增加任务列表
+//Append 增加任务列表，已存在的队列以新的队列对象替换，不修改正在使用的队列对象
 func (q *Queues) Append(queues ...*Queue) (*Queues, []*Queue) {
-	keyMap := map[string]*Queue{}
-	for _, v := range q.Queues {
-		keyMap[v.Queue] = v
+	keyMap := map[string]int{}
+	for i, v := range q.Queues {
+		keyMap[v.Queue] = i
 	}
 	notifyQueues := []*Queue{}
 	for _, v := range queues {
-		if queue, ok := keyMap[v.Queue]; ok {
-			if queue.Disable != v.Disable || queue.Concurrency != v.Concurrency {
-				notifyQueues = append(notifyQueues, v)
-				queue.Disable = v.Disable
-				queue.Concurrency = v.Concurrency
+		if i, ok := keyMap[v.Queue]; ok {
+			queue := *q.Queues[i]
+			changed := queue.Disable != v.Disable || queue.Concurrency != v.Concurrency ||
+				queue.MaxAttempts != v.MaxAttempts || queue.Backoff != v.Backoff ||
+				queue.MaxBackoff != v.MaxBackoff || queue.DeadLetter != v.DeadLetter
+			if !changed {
+				continue
 			}
+			queue.Disable = v.Disable
+			queue.Concurrency = v.Concurrency
+			queue.MaxAttempts = v.MaxAttempts
+			queue.Backoff = v.Backoff
+			queue.MaxBackoff = v.MaxBackoff
+			queue.DeadLetter = v.DeadLetter
+			q.Queues[i] = &queue
+			notifyQueues = append(notifyQueues, &queue)
 			continue
 		}
+		keyMap[v.Queue] = len(q.Queues)
 		notifyQueues = append(notifyQueues, v)
 		q.Queues = append(q.Queues, v)
 	}
@@ -0,0 +1,23 @@
+package queue
+
+import (
+	"testing"
+
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func TestQueues_Append(t *testing.T) {
+	old := NewQueue("order", "/order/pay")
+	q := NewQueues(old)
+
+	_, notify := q.Append(NewQueue("order", "/order/pay"))
+	assert.Equal(t, 0, len(notify), "配置未变化时不通知")
+
+	_, notify = q.Append(NewQueue("order", "/order/pay", WithRetry(3, 2), WithDeadLetter("order_dlq")))
+	assert.Equal(t, 1, len(notify), "重试策略变化时通知")
+	assert.Equal(t, 0, old.MaxAttempts, "不修改正在使用的队列对象")
+	assert.Equal(t, "", old.DeadLetter, "不修改正在使用的队列对象")
+	assert.Equal(t, notify[0], q.Queues[0], "替换为新的队列对象")
+	assert.Equal(t, 3, q.Queues[0].MaxAttempts, "新的重试次数")
+	assert.Equal(t, "order_dlq", q.Queues[0].DeadLetter, "新的死信队列")
+}
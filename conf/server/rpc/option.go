@@ -54,3 +54,10 @@ func WithEnableEncryption() Option {
 		a.EnableEncryption = true
 	}
 }
+
+//WithWeight 设置服务权重，发布到注册中心供加权轮询负载使用
+func WithWeight(weight int) Option {
+	return func(a *Server) {
+		a.Weight = weight
+	}
+}
@@ -27,7 +27,7 @@ const DefaultMaxSendMsgSize = 1024 * 1024 * 20
 const DefaultRPCAddress = ":8090"
 
 //MainConfName 主配置中的关键配置名
-var MainConfName = []string{"address", "status", "rTimeout", "wTimeout", "rhTimeout", "dn"}
+var MainConfName = []string{"address", "status", "rTimeout", "wTimeout", "rhTimeout", "dn", "weight"}
 
 //SubConfName 子配置中的关键配置名
 var SubConfName = []string{"router", "metric"}
@@ -42,6 +42,7 @@ type Server struct {
 	Trace          bool   `json:"trace,omitempty" toml:"trace,omitempty"`
 	MaxRecvMsgSize int    `json:"maxRecvMsgSize,omitempty" toml:"maxRecvMsgSize,omitempty"`
 	MaxSendMsgSize int    `json:"maxSendMsgSize,omitempty" toml:"maxSendMsgSize,omitempty"`
+	Weight         int    `json:"weight,omitempty" valid:"range(0|1000)" toml:"weight,omitempty" label:"服务权重"`
 }
 
 //New 构建rpc server配置信息
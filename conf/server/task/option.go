@@ -16,3 +16,24 @@ func WithEnable() Option {
 		a.Disable = false
 	}
 }
+
+//WithTimeout 设置任务超时时长(秒)，超时后撤销任务上下文
+func WithTimeout(second int) Option {
+	return func(a *Task) {
+		a.Timeout = second
+	}
+}
+
+//WithConcurrencyPolicy 设置任务并发策略(allow|forbid|replace)
+func WithConcurrencyPolicy(policy string) Option {
+	return func(a *Task) {
+		a.ConcurrencyPolicy = policy
+	}
+}
+
+//WithMisfire 设置错过执行策略(skip|fire-once|fire-all)
+func WithMisfire(policy string) Option {
+	return func(a *Task) {
+		a.Misfire = policy
+	}
+}
@@ -2,6 +2,7 @@ package task
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/asaskevich/govalidator"
 	"github.com/micro-plat/lib4go/security/md5"
@@ -13,11 +14,36 @@ const CronExecuteImmediately = "@immediately"
 //CronExecuteNow 立即执行
 const CronExecuteNow = "@now"
 
+const (
+	//ConcurrencyAllow 允许上次执行未完成时启动新的执行
+	ConcurrencyAllow = "allow"
+
+	//ConcurrencyForbid 上次执行未完成时跳过本次执行
+	ConcurrencyForbid = "forbid"
+
+	//ConcurrencyReplace 撤销未完成的执行后启动新的执行
+	ConcurrencyReplace = "replace"
+)
+
+const (
+	//MisfireSkip 跳过暂停期间错过的执行
+	MisfireSkip = "skip"
+
+	//MisfireFireOnce 恢复后补执行一次
+	MisfireFireOnce = "fire-once"
+
+	//MisfireFireAll 恢复后依次补执行所有错过的次数
+	MisfireFireAll = "fire-all"
+)
+
 //Task cron任务的task明细
 type Task struct {
-	Cron    string `json:"cron,omitempty" valid:"ascii,required" toml:"cron,omitempty" label:"任务名称"`
-	Service string `json:"service,omitempty" valid:"ascii,spath,required" toml:"service,omitempty" label:"任务服务"`
-	Disable bool   `json:"disable,omitempty" toml:"disable,omitempty"`
+	Cron              string `json:"cron,omitempty" valid:"ascii,required" toml:"cron,omitempty" label:"任务名称"`
+	Service           string `json:"service,omitempty" valid:"ascii,spath,required" toml:"service,omitempty" label:"任务服务"`
+	Timeout           int    `json:"timeout,omitempty" valid:"range(0|86400)" toml:"timeout,omitempty" label:"任务超时时长(秒)"`
+	ConcurrencyPolicy string `json:"concurrencyPolicy,omitempty" valid:"in(allow|forbid|replace)" toml:"concurrencyPolicy,omitempty" label:"任务并发策略"`
+	Misfire           string `json:"misfire,omitempty" valid:"in(skip|fire-once|fire-all)" toml:"misfire,omitempty" label:"错过执行策略"`
+	Disable           bool   `json:"disable,omitempty" toml:"disable,omitempty"`
 }
 
 //NewTask 创建任务信息
@@ -38,6 +64,27 @@ func (t *Task) GetUNQ() string {
 	return md5.Encrypt(fmt.Sprintf("%s(%s)", t.Service, t.Cron))
 }
 
+//GetTimeout 获取任务超时时长，未设置时返回0
+func (t *Task) GetTimeout() time.Duration {
+	return time.Duration(t.Timeout) * time.Second
+}
+
+//GetConcurrencyPolicy 获取任务并发策略，默认为forbid
+func (t *Task) GetConcurrencyPolicy() string {
+	if t.ConcurrencyPolicy == "" {
+		return ConcurrencyForbid
+	}
+	return t.ConcurrencyPolicy
+}
+
+//GetMisfire 获取错过执行策略，默认为skip
+func (t *Task) GetMisfire() string {
+	if t.Misfire == "" {
+		return MisfireSkip
+	}
+	return t.Misfire
+}
+
 //IsImmediately 是否立即
 func (t *Task) IsImmediately() bool {
 	return t.Cron == CronExecuteNow || t.Cron == CronExecuteImmediately
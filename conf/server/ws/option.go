@@ -64,3 +64,57 @@ func WithEnableEncryption() Option {
 		a.EnableEncryption = true
 	}
 }
+
+//WithAllowedOrigins 设置允许建立连接的请求来源，"*"允许所有来源
+func WithAllowedOrigins(origins ...string) Option {
+	return func(a *Server) {
+		a.AllowedOrigins = origins
+	}
+}
+
+//WithSubprotocols 设置服务器支持的子协议，按顺序与客户端协商
+func WithSubprotocols(protocols ...string) Option {
+	return func(a *Server) {
+		a.Subprotocols = protocols
+	}
+}
+
+//WithBufferSize 设置读写缓冲区大小
+func WithBufferSize(read int, write int) Option {
+	return func(a *Server) {
+		a.ReadBufferSize = read
+		a.WriteBufferSize = write
+	}
+}
+
+//WithMaxMessageSize 设置单条消息最大长度
+func WithMaxMessageSize(size int64) Option {
+	return func(a *Server) {
+		a.MaxMessageSize = size
+	}
+}
+
+//WithPingPong 设置ping消息发送间隔与等待pong消息的超时时长(秒)
+func WithPingPong(ping int, pong int) Option {
+	return func(a *Server) {
+		a.PingInterval = ping
+		a.PongTimeout = pong
+	}
+}
+
+//WithIdleTimeout 设置连接空闲超时时长(秒)，超时未收到客户端消息则断开连接
+func WithIdleTimeout(timeout int) Option {
+	return func(a *Server) {
+		a.IdleTimeout = timeout
+	}
+}
+
+//WithRateLimit 设置每个连接每秒允许的消息数及突发数
+func WithRateLimit(limit int, burst ...int) Option {
+	return func(a *Server) {
+		a.RateLimit = limit
+		if len(burst) > 0 {
+			a.RateBurst = burst[0]
+		}
+	}
+}
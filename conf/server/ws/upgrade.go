@@ -0,0 +1,129 @@
+package ws
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	//DefaultBufferSize 默认读写缓冲区大小
+	DefaultBufferSize = 1024
+
+	//DefaultMaxMessageSize 默认单条消息最大长度
+	DefaultMaxMessageSize = 512
+
+	//DefaultPongTimeout 默认等待pong消息的超时时长(秒)
+	DefaultPongTimeout = 60
+
+	//DefaultWriteTimeout 默认写入超时时长(秒)
+	DefaultWriteTimeout = 10
+)
+
+//Upgrade ws连接升级与读写限制配置
+type Upgrade struct {
+	AllowedOrigins  []string `json:"allowedOrigins,omitempty" toml:"allowedOrigins,omitempty"`
+	Subprotocols    []string `json:"subprotocols,omitempty" toml:"subprotocols,omitempty"`
+	ReadBufferSize  int      `json:"readBufferSize,omitempty" valid:"range(0|1048576)" toml:"readBufferSize,omitzero"`
+	WriteBufferSize int      `json:"writeBufferSize,omitempty" valid:"range(0|1048576)" toml:"writeBufferSize,omitzero"`
+	MaxMessageSize  int64    `json:"maxMessageSize,omitempty" toml:"maxMessageSize,omitzero"`
+	PingInterval    int      `json:"pingInterval,omitempty" toml:"pingInterval,omitzero"`
+	PongTimeout     int      `json:"pongTimeout,omitempty" toml:"pongTimeout,omitzero"`
+	IdleTimeout     int      `json:"idleTimeout,omitempty" toml:"idleTimeout,omitzero"`
+	RateLimit       int      `json:"rateLimit,omitempty" toml:"rateLimit,omitzero"`
+	RateBurst       int      `json:"rateBurst,omitempty" toml:"rateBurst,omitzero"`
+}
+
+//CheckOrigin 检查请求来源是否允许建立连接，未配置时只允许同源请求，"*"允许所有来源，"*.domain"允许子域名
+func (u *Upgrade) CheckOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	o, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if len(u.AllowedOrigins) == 0 {
+		return strings.EqualFold(o.Host, r.Host)
+	}
+	for _, allow := range u.AllowedOrigins {
+		switch {
+		case allow == "*":
+			return true
+		case strings.EqualFold(allow, origin), strings.EqualFold(allow, o.Host):
+			return true
+		case strings.HasPrefix(allow, "*."):
+			if strings.HasSuffix(strings.ToLower(o.Hostname()), strings.ToLower(allow[1:])) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//GetReadBufferSize 获取读缓冲区大小
+func (u *Upgrade) GetReadBufferSize() int {
+	if u.ReadBufferSize <= 0 {
+		return DefaultBufferSize
+	}
+	return u.ReadBufferSize
+}
+
+//GetWriteBufferSize 获取写缓冲区大小
+func (u *Upgrade) GetWriteBufferSize() int {
+	if u.WriteBufferSize <= 0 {
+		return DefaultBufferSize
+	}
+	return u.WriteBufferSize
+}
+
+//GetMaxMessageSize 获取单条消息最大长度
+func (u *Upgrade) GetMaxMessageSize() int64 {
+	if u.MaxMessageSize <= 0 {
+		return DefaultMaxMessageSize
+	}
+	return u.MaxMessageSize
+}
+
+//GetPongTimeout 获取等待pong消息的超时时长
+func (u *Upgrade) GetPongTimeout() time.Duration {
+	if u.PongTimeout <= 0 {
+		return DefaultPongTimeout * time.Second
+	}
+	return time.Duration(u.PongTimeout) * time.Second
+}
+
+//GetPingInterval 获取ping消息发送间隔，须小于pong超时时长
+func (u *Upgrade) GetPingInterval() time.Duration {
+	pong := u.GetPongTimeout()
+	if u.PingInterval <= 0 || time.Duration(u.PingInterval)*time.Second >= pong {
+		return pong * 9 / 10
+	}
+	return time.Duration(u.PingInterval) * time.Second
+}
+
+//GetWriteTimeout 获取消息写入超时时长
+func (u *Upgrade) GetWriteTimeout() time.Duration {
+	return DefaultWriteTimeout * time.Second
+}
+
+//GetIdleTimeout 获取连接空闲超时时长，为0时不限制
+func (u *Upgrade) GetIdleTimeout() time.Duration {
+	if u.IdleTimeout <= 0 {
+		return 0
+	}
+	return time.Duration(u.IdleTimeout) * time.Second
+}
+
+//GetRateLimit 获取每个连接每秒允许的消息数及突发数，为0时不限制
+func (u *Upgrade) GetRateLimit() (int, int) {
+	if u.RateLimit <= 0 {
+		return 0, 0
+	}
+	if u.RateBurst < u.RateLimit {
+		return u.RateLimit, u.RateLimit
+	}
+	return u.RateLimit, u.RateBurst
+}
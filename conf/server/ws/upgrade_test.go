@@ -0,0 +1,54 @@
+package ws
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func TestUpgrade_CheckOrigin(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		host    string
+		origin  string
+		want    bool
+	}{
+		{name: "无来源头", host: "ws.a.com", want: true},
+		{name: "未配置同源", host: "ws.a.com", origin: "http://ws.a.com", want: true},
+		{name: "未配置跨域", host: "ws.a.com", origin: "http://evil.com", want: false},
+		{name: "允许所有", allowed: []string{"*"}, host: "ws.a.com", origin: "http://evil.com", want: true},
+		{name: "完整来源", allowed: []string{"https://app.a.com"}, host: "ws.a.com", origin: "https://app.a.com", want: true},
+		{name: "主机名", allowed: []string{"app.a.com"}, host: "ws.a.com", origin: "https://app.a.com", want: true},
+		{name: "子域名", allowed: []string{"*.a.com"}, host: "ws.a.com", origin: "https://x.app.a.com:8080", want: true},
+		{name: "子域名不匹配", allowed: []string{"*.a.com"}, host: "ws.a.com", origin: "https://evila.com", want: false},
+		{name: "来源不在列表", allowed: []string{"app.a.com"}, host: "ws.a.com", origin: "https://ws.a.com", want: false},
+	}
+	for _, tt := range tests {
+		u := &Upgrade{AllowedOrigins: tt.allowed}
+		r, _ := http.NewRequest(http.MethodGet, "http://"+tt.host+"/ws", nil)
+		if tt.origin != "" {
+			r.Header.Set("Origin", tt.origin)
+		}
+		assert.Equal(t, tt.want, u.CheckOrigin(r), tt.name)
+	}
+}
+
+func TestUpgrade_Default(t *testing.T) {
+	s := New("8070")
+	assert.Equal(t, DefaultBufferSize, s.GetReadBufferSize(), "读缓冲区")
+	assert.Equal(t, int64(DefaultMaxMessageSize), s.GetMaxMessageSize(), "消息大小")
+	assert.Equal(t, 54*time.Second, s.GetPingInterval(), "ping间隔")
+	assert.Equal(t, time.Duration(0), s.GetIdleTimeout(), "空闲超时")
+	limit, _ := s.GetRateLimit()
+	assert.Equal(t, 0, limit, "限流")
+
+	s = New("8070", WithPingPong(90, 30), WithRateLimit(10, 5), WithIdleTimeout(300))
+	assert.Equal(t, 27*time.Second, s.GetPingInterval(), "ping间隔须小于pong超时")
+	limit, burst := s.GetRateLimit()
+	assert.Equal(t, 10, limit, "限流")
+	assert.Equal(t, 10, burst, "突发数不小于限流数")
+	assert.Equal(t, 300*time.Second, s.GetIdleTimeout(), "空闲超时")
+}
@@ -19,7 +19,7 @@ const (
 //Server api server配置信息
 type Server struct {
 	security.ConfEncrypt
-	Address   string `json:"address,omitempty" valid:"dialstring" toml:"address,omitempty" label:"ws服务地址"`
+	Address   string `json:"address,omitempty" valid:"port" toml:"address,omitempty" label:"ws服务地址"`
 	Status    string `json:"status,omitempty" valid:"in(start|stop)" toml:"status,omitempty"`
 	RTimeout  int    `json:"rTimeout,omitempty" toml:"rTimeout,omitzero"`
 	WTimeout  int    `json:"wTimeout,omitempty" toml:"wTimeout,omitzero"`
@@ -27,6 +27,7 @@ type Server struct {
 	Host      string `json:"host,omitempty" toml:"host,omitempty"`
 	Domain    string `json:"dns,omitempty" toml:"dns,omitempty"`
 	Trace     bool   `json:"trace,omitempty" toml:"trace,omitempty"`
+	Upgrade
 }
 
 //New 构建websocket server配置信息
@@ -0,0 +1,52 @@
+package tiered
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+//Option 配置选项
+type Option func(*Tiered)
+
+//WithLocalExpire 设置本地缓存默认时长(秒)
+func WithLocalExpire(expire int) Option {
+	return func(o *Tiered) {
+		o.LocalExpire = expire
+	}
+}
+
+//WithTTL 设置指定key的本地缓存时长(秒)，key支持*通配符，expire为0时不缓存到本地
+func WithTTL(key string, expire int) Option {
+	return func(o *Tiered) {
+		o.TTLs = append(o.TTLs, &TTL{Key: key, Expire: expire})
+	}
+}
+
+//WithRedisBroadcast 使用redis发布订阅通知其它服务器，redis为/var/redis下的配置名称，为空时使用远程redis缓存的连接
+func WithRedisBroadcast(channel string, redis ...string) Option {
+	return func(o *Tiered) {
+		o.Broadcast = "redis"
+		o.Channel = channel
+		if len(redis) > 0 {
+			o.Redis = redis[0]
+		}
+	}
+}
+
+//WithQueueBroadcast 使用消息队列通知其它服务器，queue为/var/queue下的配置名称
+func WithQueueBroadcast(queue string, channel string) Option {
+	return func(o *Tiered) {
+		o.Broadcast = "queue"
+		o.Queue = queue
+		o.Channel = channel
+	}
+}
+
+//WithRaw 通过json原串初始化
+func WithRaw(raw string) Option {
+	return func(o *Tiered) {
+		if err := json.Unmarshal([]byte(raw), o); err != nil {
+			panic(fmt.Errorf("tiered.WithRaw:%w", err))
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package tiered
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/micro-plat/hydra/conf/vars/cache"
+	"github.com/micro-plat/lib4go/types"
+)
+
+//Proto 二级缓存协议名称
+const Proto = "tiered"
+
+//TTL 指定key的本地缓存时长
+type TTL struct {
+	//Key key匹配规则，支持*通配符
+	Key string `json:"key" toml:"key" valid:"required"`
+
+	//Expire 本地缓存时长(秒)，为0时不缓存到本地
+	Expire int `json:"expire" toml:"expire"`
+}
+
+//Tiered 二级缓存配置，本地gocache缓存远程缓存(redis、memcached)的数据
+type Tiered struct {
+	*cache.Cache
+
+	//Remote 远程缓存配置，如cacheredis.New(...)
+	Remote interface{} `json:"remote" toml:"remote"`
+
+	//LocalExpire 本地缓存默认时长(秒)
+	LocalExpire int `json:"local_expire,omitempty" toml:"local_expire,omitempty"`
+
+	//TTLs 按key设置本地缓存时长，优先于默认时长
+	TTLs []*TTL `json:"ttls,omitempty" toml:"ttls,omitempty"`
+
+	//Broadcast 失效通知方式，redis:使用redis发布订阅，queue:使用消息队列
+	Broadcast string `json:"broadcast,omitempty" toml:"broadcast,omitempty" valid:"in(redis|queue)"`
+
+	//Channel 失效通知的频道(或队列)名称
+	Channel string `json:"channel,omitempty" toml:"channel,omitempty"`
+
+	//Redis 发布订阅使用的redis配置名称(/var/redis/{name})，未设置时使用远程redis缓存的连接
+	Redis string `json:"redis,omitempty" toml:"redis,omitempty"`
+
+	//Queue 失效通知使用的消息队列配置名称(/var/queue/{name})，只支持可广播的协议(mqtt)，
+	//redis、lmq、dmq等点对点队列在构建缓存时返回错误
+	Queue string `json:"queue,omitempty" toml:"queue,omitempty"`
+}
+
+//New 构建二级缓存配置
+func New(remote interface{}, opts ...Option) *Tiered {
+	org := &Tiered{
+		Cache:       &cache.Cache{Proto: Proto},
+		Remote:      remote,
+		LocalExpire: 60,
+		Broadcast:   "redis",
+		Channel:     "hydra:cache:invalidate",
+	}
+	for i := range opts {
+		opts[i](org)
+	}
+	if b, err := govalidator.ValidateStruct(org); !b {
+		panic(fmt.Errorf("tiered配置数据有误:%v %+v", err, org))
+	}
+	if org.Remote == nil {
+		panic(fmt.Errorf("tiered配置数据有误:remote不能为空 %+v", org))
+	}
+	if org.Broadcast == "queue" && org.Queue == "" {
+		panic(fmt.Errorf("tiered配置数据有误:使用消息队列通知时queue不能为空 %+v", org))
+	}
+	return org
+}
+
+//NewByRaw 通过json原串初始化
+func NewByRaw(raw string) *Tiered {
+	return New(nil, WithRaw(raw))
+}
+
+//GetRemote 获取远程缓存协议与配置(json)
+func (t *Tiered) GetRemote() (proto string, raw string, err error) {
+	buff, err := json.Marshal(t.Remote)
+	if err != nil {
+		return "", "", fmt.Errorf("tiered远程缓存配置有误:%w", err)
+	}
+	mp := make(map[string]interface{})
+	if err := json.Unmarshal(buff, &mp); err != nil {
+		return "", "", fmt.Errorf("tiered远程缓存配置有误:%w", err)
+	}
+	proto = types.GetString(mp["proto"])
+	if proto == "" || proto == Proto {
+		return "", "", fmt.Errorf("tiered远程缓存协议有误:%s", proto)
+	}
+	return proto, string(buff), nil
+}
@@ -0,0 +1,113 @@
+package dmq
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/micro-plat/hydra/conf/vars/queue"
+)
+
+const (
+	//SyncAlways 每条消息写入后立即刷盘
+	SyncAlways = "always"
+
+	//SyncInterval 按时间间隔刷盘
+	SyncInterval = "interval"
+
+	//SyncNone 由操作系统决定刷盘时机
+	SyncNone = "none"
+)
+
+const (
+	//DefaultPath 默认存储目录
+	DefaultPath = "./.dmq"
+
+	//DefaultSyncInterval 默认刷盘间隔(毫秒)
+	DefaultSyncInterval = 1000
+
+	//DefaultSegmentSize 默认日志分段大小(64M)
+	DefaultSegmentSize = 64 * 1024 * 1024
+)
+
+//DMQ 基于本地磁盘的持久化消息队列配置
+type DMQ struct {
+	*queue.Queue
+	Path          string `json:"path,omitempty" toml:"path,omitempty" label:"存储目录"`
+	Sync          string `json:"sync,omitempty" toml:"sync,omitempty" valid:"in(always|interval|none)" label:"刷盘策略"`
+	SyncInterval  int    `json:"sync_interval,omitempty" toml:"sync_interval,omitempty" label:"刷盘间隔(毫秒)"`
+	SegmentSize   int64  `json:"segment_size,omitempty" toml:"segment_size,omitempty" label:"日志分段大小(字节)"`
+	RetentionSize int64  `json:"retention_size,omitempty" toml:"retention_size,omitempty" label:"每个队列最大保留大小(字节)"`
+	RetentionAge  int    `json:"retention_age,omitempty" toml:"retention_age,omitempty" label:"日志分段最长保留时长(秒)"`
+}
+
+//New 构建持久化消息队列配置
+func New(path string, opts ...Option) *DMQ {
+	r := &DMQ{
+		Queue: &queue.Queue{Proto: "dmq"},
+		Path:  path,
+		Sync:  SyncInterval,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if b, err := govalidator.ValidateStruct(r); !b {
+		panic(fmt.Errorf("dmq配置数据有误:%v %+v", err, r))
+	}
+	return r
+}
+
+//NewByRaw 通过json原串初始化
+func NewByRaw(raw string) (*DMQ, error) {
+	r := &DMQ{Queue: &queue.Queue{Proto: "dmq"}}
+	if raw != "" {
+		if err := json.Unmarshal([]byte(raw), r); err != nil {
+			return nil, fmt.Errorf("dmq配置数据有误:%w", err)
+		}
+	}
+	if b, err := govalidator.ValidateStruct(r); !b {
+		return nil, fmt.Errorf("dmq配置数据有误:%v %+v", err, r)
+	}
+	return r, nil
+}
+
+//GetPath 获取存储目录
+func (d *DMQ) GetPath() string {
+	if d.Path == "" {
+		return DefaultPath
+	}
+	return d.Path
+}
+
+//GetSync 获取刷盘策略
+func (d *DMQ) GetSync() string {
+	if d.Sync == "" {
+		return SyncInterval
+	}
+	return d.Sync
+}
+
+//GetSyncInterval 获取刷盘间隔
+func (d *DMQ) GetSyncInterval() time.Duration {
+	if d.SyncInterval <= 0 {
+		return DefaultSyncInterval * time.Millisecond
+	}
+	return time.Duration(d.SyncInterval) * time.Millisecond
+}
+
+//GetSegmentSize 获取日志分段大小
+func (d *DMQ) GetSegmentSize() int64 {
+	if d.SegmentSize <= 0 {
+		return DefaultSegmentSize
+	}
+	return d.SegmentSize
+}
+
+//GetRetentionAge 获取日志分段最长保留时长，为0时不限制
+func (d *DMQ) GetRetentionAge() time.Duration {
+	if d.RetentionAge <= 0 {
+		return 0
+	}
+	return time.Duration(d.RetentionAge) * time.Second
+}
@@ -0,0 +1,48 @@
+package dmq
+
+import "encoding/json"
+
+//Option 配置选项
+type Option func(*DMQ)
+
+//WithSync 设置刷盘策略(always,interval,none)及刷盘间隔(毫秒)
+func WithSync(policy string, interval ...int) Option {
+	return func(a *DMQ) {
+		a.Sync = policy
+		if len(interval) > 0 {
+			a.SyncInterval = interval[0]
+		}
+	}
+}
+
+//WithSegmentSize 设置日志分段大小(字节)
+func WithSegmentSize(size int64) Option {
+	return func(a *DMQ) {
+		a.SegmentSize = size
+	}
+}
+
+//WithRetention 设置每个队列最大保留大小(字节)及日志分段最长保留时长(秒)，
+//超出后未消费的消息也将被清除
+func WithRetention(size int64, age int) Option {
+	return func(a *DMQ) {
+		a.RetentionSize = size
+		a.RetentionAge = age
+	}
+}
+
+//WithRaw 通过json原串初始化
+func WithRaw(raw string) Option {
+	return func(o *DMQ) {
+		if err := json.Unmarshal([]byte(raw), o); err != nil {
+			panic(err)
+		}
+	}
+}
+
+//WithEnableEncryption 启用加密设置
+func WithEnableEncryption() Option {
+	return func(a *DMQ) {
+		a.EnableEncryption = true
+	}
+}
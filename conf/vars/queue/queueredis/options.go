@@ -60,3 +60,13 @@ func WithEnableEncryption() Option {
 		a.EnableEncryption = true
 	}
 }
+
+//WithReliable 启用可靠消费模式，消息确认后才从队列删除，aliveTimeout为消费者心跳超时时长(秒)
+func WithReliable(aliveTimeout ...int) Option {
+	return func(a *Redis) {
+		a.Reliable = true
+		if len(aliveTimeout) > 0 {
+			a.AliveTimeout = aliveTimeout[0]
+		}
+	}
+}
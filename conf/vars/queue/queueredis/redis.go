@@ -23,6 +23,8 @@ type Redis struct {
 	ReadTimeout  int      `json:"read_timeout,omitempty" toml:"read_timeout,omitempty"`
 	WriteTimeout int      `json:"write_timeout,omitempty" toml:"write_timeout,omitempty"`
 	PoolSize     int      `json:"pool_size,omitempty" toml:"pool_size,omitempty"`
+	Reliable     bool     `json:"reliable,omitempty" toml:"reliable,omitempty" label:"可靠消费模式"`
+	AliveTimeout int      `json:"alive_timeout,omitempty" toml:"alive_timeout,omitempty" label:"消费者心跳超时时长(秒)"`
 
 	ConfigName string `json:"config_name,omitempty"  toml:"config_name,omitempty" valid:"ascii"`
 }
@@ -70,6 +72,8 @@ func (org *Redis) GetRaw() string {
 	if err := json.Unmarshal(js.GetRaw(), tmp); err != nil {
 		panic(fmt.Errorf("cacheredis.WithRaw:%w", err))
 	}
+	tmp.Reliable = org.Reliable
+	tmp.AliveTimeout = org.AliveTimeout
 	bytes, _ := json.Marshal(tmp)
 	return string(bytes)
 }
@@ -0,0 +1,40 @@
+package rlog
+
+import (
+	"encoding/json"
+)
+
+//FieldsTag 日志扩展字段的标签名，字段序列化为json保存在日志标签中
+const FieldsTag = "fields"
+
+//日志扩展字段名
+const (
+	FieldTraceID    = "trace_id"
+	FieldServerType = "server_type"
+	FieldService    = "service"
+	FieldClientIP   = "client_ip"
+	FieldUser       = "user"
+)
+
+//Fields 日志扩展字段
+type Fields map[string]string
+
+//Encode 序列化为日志标签值
+func (f Fields) Encode() string {
+	buff, _ := json.Marshal(map[string]string(f))
+	return string(buff)
+}
+
+//Tags 转换为日志组件的标签参数
+func (f Fields) Tags() []string {
+	return []string{FieldsTag, f.Encode()}
+}
+
+//GetFields 从日志标签中获取扩展字段
+func GetFields(tags map[string]string) Fields {
+	f := Fields{}
+	if v, ok := tags[FieldsTag]; ok && v != "" {
+		json.Unmarshal([]byte(v), &f)
+	}
+	return f
+}
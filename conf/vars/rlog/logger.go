@@ -18,13 +18,41 @@ const LogName = "rlog"
 //TypeNodeName 分类节点名
 const TypeNodeName = "app"
 
+//FormatText 按layout模板输出日志
+const FormatText = "text"
+
+//FormatJSON 输出包含扩展字段的json日志
+const FormatJSON = "json"
+
+//SinkRPC 通过rpc服务保存日志
+const SinkRPC = "rpc"
+
+//SinkFile 写入本地文件，按大小滚动
+const SinkFile = "file"
+
+//SinkSyslog 写入syslog
+const SinkSyslog = "syslog"
+
+//SinkHTTP 批量提交到http服务
+const SinkHTTP = "http"
+
 //Layout 日志配置
 type Layout struct {
 	security.ConfEncrypt
-	Level   string `json:"level"  valid:"in(Off|Info|Warn|Error|Fatal|Debug|All)" toml:"level"`
-	Service string `json:"service,omitempty" toml:"service"`
-	Layout  string `json:"layout" toml:"layout"`
-	Disable bool   `json:"disable,omitempty" toml:"disable,omitempty"`
+	Level         string `json:"level"  valid:"in(Off|Info|Warn|Error|Fatal|Debug|All)" toml:"level"`
+	Service       string `json:"service,omitempty" toml:"service"`
+	Layout        string `json:"layout" toml:"layout"`
+	Format        string `json:"format,omitempty" valid:"in(text|json)" toml:"format,omitempty"`
+	Sink          string `json:"sink,omitempty" valid:"in(rpc|file|syslog|http)" toml:"sink,omitempty"`
+	Path          string `json:"path,omitempty" toml:"path,omitempty"`
+	MaxSize       int    `json:"max_size,omitempty" toml:"max_size,omitempty"`
+	MaxBackups    int    `json:"max_backups,omitempty" toml:"max_backups,omitempty"`
+	Address       string `json:"address,omitempty" toml:"address,omitempty"`
+	BufferSize    int    `json:"buffer_size,omitempty" toml:"buffer_size,omitempty"`
+	BatchSize     int    `json:"batch_size,omitempty" toml:"batch_size,omitempty"`
+	FlushInterval int    `json:"flush_interval,omitempty" toml:"flush_interval,omitempty"`
+	Drop          bool   `json:"drop,omitempty" toml:"drop,omitempty"`
+	Disable       bool   `json:"disable,omitempty" toml:"disable,omitempty"`
 }
 
 const DefaultLayout = `{"server-ip":"%ip","time":"%datetime.%ms","level":"%level","session":"%session","content":"%content"}`
@@ -42,6 +70,41 @@ func New(service string, opts ...Option) *Layout {
 	return l
 }
 
+//GetFormat 获取日志格式，默认按layout模板输出
+func (l *Layout) GetFormat() string {
+	if l.Format == "" {
+		return FormatText
+	}
+	return l.Format
+}
+
+//GetSink 获取日志输出目标，默认使用rpc服务
+func (l *Layout) GetSink() string {
+	if l.Sink == "" {
+		return SinkRPC
+	}
+	return l.Sink
+}
+
+//Check 检查输出目标所需的参数
+func (l *Layout) Check() error {
+	switch l.GetSink() {
+	case SinkRPC:
+		if l.Service == "" {
+			return fmt.Errorf("rlog使用rpc输出时必须配置service")
+		}
+	case SinkFile:
+		if l.Path == "" {
+			return fmt.Errorf("rlog使用file输出时必须配置path")
+		}
+	case SinkHTTP:
+		if l.Address == "" {
+			return fmt.Errorf("rlog使用http输出时必须配置address")
+		}
+	}
+	return nil
+}
+
 //ToLoggerLayout 转换为logger.Layout
 func (l *Layout) ToLoggerLayout() *logger.Layout {
 	return &logger.Layout{
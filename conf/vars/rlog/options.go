@@ -79,3 +79,52 @@ func WithEnableEncryption() Option {
 		a.EnableEncryption = true
 	}
 }
+
+//WithJSON 输出包含链路编号、服务器类型、服务、客户端IP、用户等字段的json日志
+func WithJSON() Option {
+	return func(a *Layout) {
+		a.Format = FormatJSON
+	}
+}
+
+//WithFile 写入本地文件，文件超过maxSize(MB)时滚动，最多保留maxBackups个历史文件
+func WithFile(path string, maxSize int, maxBackups int) Option {
+	return func(a *Layout) {
+		a.Sink = SinkFile
+		a.Path = path
+		a.MaxSize = maxSize
+		a.MaxBackups = maxBackups
+	}
+}
+
+//WithSyslog 写入syslog，地址为空时写入本机syslog，否则格式为udp://host:514
+func WithSyslog(address string) Option {
+	return func(a *Layout) {
+		a.Sink = SinkSyslog
+		a.Address = address
+	}
+}
+
+//WithHTTP 批量提交到http服务
+func WithHTTP(url string) Option {
+	return func(a *Layout) {
+		a.Sink = SinkHTTP
+		a.Address = url
+	}
+}
+
+//WithBuffer 设置缓冲区大小，drop为true时缓冲区满则丢弃日志，否则阻塞写入
+func WithBuffer(size int, drop bool) Option {
+	return func(a *Layout) {
+		a.BufferSize = size
+		a.Drop = drop
+	}
+}
+
+//WithBatch 设置每批提交的日志条数与提交间隔(毫秒)
+func WithBatch(size int, interval int) Option {
+	return func(a *Layout) {
+		a.BatchSize = size
+		a.FlushInterval = interval
+	}
+}
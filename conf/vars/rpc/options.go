@@ -52,6 +52,30 @@ func WithLocalFirst() Option {
 	}
 }
 
+//WithWeightedRoundRobin 配置为加权轮询负载均衡器，权重由服务节点发布
+func WithWeightedRoundRobin() Option {
+	return func(o *RPCConf) {
+		o.Balancer = WeightedRoundRobin
+	}
+}
+
+//WithLeastConn 配置为最少请求负载均衡器
+func WithLeastConn() Option {
+	return func(o *RPCConf) {
+		o.Balancer = LeastConn
+	}
+}
+
+//WithConsistentHash 配置为一致性哈希负载均衡器，header为哈希使用的请求头，默认为X-Hash-Key
+func WithConsistentHash(header ...string) Option {
+	return func(o *RPCConf) {
+		o.Balancer = ConsistentHash
+		if len(header) > 0 {
+			o.HashHeader = header[0]
+		}
+	}
+}
+
 //WithRaw 根据json串设置配置信息
 func WithRaw(raw []byte) Option {
 	return func(o *RPCConf) {
@@ -14,6 +14,18 @@ const LocalFirst = "localfirst"
 //RoundRobin RoundRobin
 const RoundRobin = "round_robin"
 
+//WeightedRoundRobin 按服务节点发布的权重轮询
+const WeightedRoundRobin = "weighted_round_robin"
+
+//LeastConn 优先选择未完成请求数最少的节点
+const LeastConn = "least_conn"
+
+//ConsistentHash 根据请求头进行一致性哈希，相同请求头值固定请求到同一节点
+const ConsistentHash = "consistent_hash"
+
+//DefaultHashHeader 一致性哈希默认使用的请求头
+const DefaultHashHeader = "X-Hash-Key"
+
 //RPCConf http客户端配置对象
 type RPCConf struct {
 	security.ConfEncrypt
@@ -21,7 +33,8 @@ type RPCConf struct {
 	Log          string   `json:"log"`
 	SortPrefix   string   `json:"sortPrefix"`
 	Tls          []string `json:"tls"`
-	Balancer     string   `json:"balancer"` //负载类型 localfirst:本地服务优先  round_robin:论寻负载
+	Balancer     string   `json:"balancer"`             //负载类型 localfirst:本地服务优先  round_robin:论寻负载 weighted_round_robin:加权轮询 least_conn:最少请求 consistent_hash:一致性哈希
+	HashHeader   string   `json:"hashHeader,omitempty"` //一致性哈希使用的请求头
 }
 
 //New 构建http 客户端配置信息
@@ -36,3 +49,11 @@ func New(opts ...Option) *RPCConf {
 
 	return rpcConf
 }
+
+//GetHashHeader 获取一致性哈希使用的请求头
+func (c *RPCConf) GetHashHeader() string {
+	if c.HashHeader == "" {
+		return DefaultHashHeader
+	}
+	return c.HashHeader
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/micro-plat/hydra/conf/app"
 	"github.com/micro-plat/hydra/conf/server/router"
 	"github.com/micro-plat/hydra/pkgs"
+	"github.com/micro-plat/lib4go/errs"
 	"github.com/micro-plat/lib4go/logger"
 	"github.com/micro-plat/lib4go/types"
 )
@@ -20,6 +21,9 @@ const (
 
 	XRequestID = "X-Request-Id"
 
+	//XTraceParent W3C链路跟踪上下文头
+	XTraceParent = "traceparent"
+
 	JSONF  = "application/json; charset=%s"
 	XMLF   = "application/xml; charset=%s"
 	YAMLF  = "text/yaml; charset=%s"
@@ -54,6 +58,18 @@ func (h VoidHandler) Handle(c IContext) interface{} {
 	return EmptyReponseResult
 }
 
+//StreamHandler 流式处理Handler，通过IStream推送或接收消息，返回值作为最后一条消息
+type StreamHandler func(IContext, IStream) interface{}
+
+//Handle 处理业务流程，非流式请求返回错误
+func (h StreamHandler) Handle(c IContext) interface{} {
+	s, ok := GetStream(c)
+	if !ok {
+		return errs.NewError(http.StatusNotAcceptable, "服务仅支持流式请求")
+	}
+	return h(c, s)
+}
+
 //IHandler 业务处理接口
 type IHandler interface {
 	//Handle 业务处理
@@ -301,6 +317,9 @@ type IUser interface {
 	//GetTraceID 获取链路跟踪编号
 	GetTraceID() string
 
+	//GetCertSubject 获取已验证的客户端证书主题，未启用客户端证书验证时返回空
+	GetCertSubject() string
+
 	//Auth 认证信息
 	Auth() IAuth
 }
@@ -347,6 +366,47 @@ type ITracer interface {
 	Root() ITraceSpan
 }
 
+//ITraceParent 支持W3C traceparent传播的跟踪器
+type ITraceParent interface {
+
+	//GetTraceParent 获取传递给下游服务的traceparent
+	GetTraceParent() string
+}
+
+//GetTraceParent 获取当前请求传递给下游服务的traceparent，未启用跟踪时返回空
+func GetTraceParent(ctx IContext) string {
+	if ctx == nil {
+		return ""
+	}
+	if p, ok := ctx.Tracer().(ITraceParent); ok {
+		return p.GetTraceParent()
+	}
+	return ""
+}
+
+//IStream 流式请求的消息通道
+type IStream interface {
+
+	//Send 向调用方推送一条响应消息，处理函数的返回值作为最后一条消息
+	Send(result interface{}) error
+
+	//Recv 接收调用方发送的下一条消息(双向流)，调用方结束发送后返回io.EOF
+	Recv() (string, error)
+}
+
+//GetStream 获取当前请求的消息流，非流式请求返回false
+func GetStream(ctx IContext) (IStream, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	if s, ok := ctx.(IStreamContext); ok {
+		if stream := s.GetStream(); stream != nil {
+			return stream, true
+		}
+	}
+	return nil, false
+}
+
 //ITraceSpan 跟踪处理器
 type ITraceSpan interface {
 	IEnd
@@ -1,6 +1,7 @@
 package context
 
 import (
+	r "context"
 	"io"
 	"net/http"
 	"net/url"
@@ -34,3 +35,13 @@ type IInnerContext interface {
 	GetHTTPReqResp() (*http.Request, http.ResponseWriter)
 	ClearAuth(c ...bool) bool
 }
+
+//IParentContext 可提供父级上下文的请求，父级上下文撤销或超时后请求上下文同步撤销
+type IParentContext interface {
+	GetParentContext() r.Context
+}
+
+//IStreamContext 支持流式处理的请求
+type IStreamContext interface {
+	GetStream() IStream
+}
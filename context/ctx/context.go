@@ -8,6 +8,7 @@ import (
 
 	"github.com/micro-plat/hydra/conf"
 	"github.com/micro-plat/hydra/conf/app"
+	"github.com/micro-plat/hydra/conf/vars/rlog"
 	"github.com/micro-plat/hydra/context"
 	"github.com/micro-plat/hydra/context/ctx/internal"
 	"github.com/micro-plat/hydra/global"
@@ -54,11 +55,16 @@ func NewCtx(c context.IInnerContext, tp string) *Ctx {
 	ctx.user = NewUser(c, ctx.meta)
 	context.Cache(ctx)
 	ctx.request = NewRequest(c, ctx.appConf, ctx.meta)
-	ctx.log = logger.GetSession(ctx.appConf.GetServerConf().GetServerName(), ctx.User().GetTraceID())
+	ctx.log = newCtxLogger(ctx.appConf.GetServerConf().GetServerName(), ctx.user.GetTraceID(), rlog.Fields{
+		rlog.FieldTraceID:    ctx.user.GetTraceID(),
+		rlog.FieldServerType: ctx.appConf.GetServerConf().GetServerType(),
+		rlog.FieldService:    ctx.request.Path().GetService(),
+		rlog.FieldClientIP:   ctx.user.GetClientIP(),
+	}, ctx.user.GetUserName)
 	ctx.response = NewResponse(c, ctx.appConf, ctx.log, ctx.meta)
 	timeout := time.Duration(ctx.appConf.GetServerConf().GetMainConf().GetInt("", 30))
-	ctx.ctx, ctx.cancelFunc = r.WithTimeout(r.WithValue(r.Background(), "X-Request-Id", ctx.user.GetTraceID()), time.Second*timeout)
-	ctx.tracer = newTracer(c.GetURL().Path, ctx.log, ctx.appConf)
+	ctx.ctx, ctx.cancelFunc = r.WithTimeout(r.WithValue(getParentContext(c), "X-Request-Id", ctx.user.GetTraceID()), time.Second*timeout)
+	ctx.tracer = newTracer(c.GetURL().Path, ctx.log, ctx.appConf, c.GetHeaders())
 	return ctx
 }
 
@@ -140,3 +146,21 @@ func (c *Ctx) Close() {
 
 	contextPool.Put(c)
 }
+
+//GetStream 获取流式请求的消息流，非流式请求返回nil
+func (c *Ctx) GetStream() context.IStream {
+	if s, ok := c.context.(context.IStreamContext); ok {
+		return s.GetStream()
+	}
+	return nil
+}
+
+//getParentContext 获取请求的父级上下文，未提供时使用Background
+func getParentContext(c context.IInnerContext) r.Context {
+	if p, ok := c.(context.IParentContext); ok {
+		if parent := p.GetParentContext(); parent != nil {
+			return parent
+		}
+	}
+	return r.Background()
+}
@@ -0,0 +1,198 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/lib4go/concurrent/cmap"
+)
+
+const (
+	//otlpTracesPath OTLP/HTTP链路上报路径
+	otlpTracesPath = "/v1/traces"
+
+	//otlpBatchSize 单次上报的最大跨度数
+	otlpBatchSize = 256
+
+	//otlpFlushInterval 定时上报间隔
+	otlpFlushInterval = time.Second
+)
+
+var exporters = cmap.New(3)
+
+//exporter 批量将跨度以OTLP/HTTP(JSON)格式上报到collector
+type exporter struct {
+	url    string
+	client *http.Client
+	spans  chan *OTLPSpan
+	flush  chan chan struct{}
+}
+
+//getExporter 获取指定collector地址的上报器
+func getExporter(address string) (*exporter, error) {
+	_, v, err := exporters.SetIfAbsentCb(address, func(i ...interface{}) (interface{}, error) {
+		return newExporter(address)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*exporter), nil
+}
+
+func newExporter(address string) (*exporter, error) {
+	if address == "" {
+		return nil, fmt.Errorf("未指定otlp collector地址")
+	}
+	url := strings.TrimSuffix(address, "/")
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		url = "http://" + url
+	}
+	if !strings.HasSuffix(url, otlpTracesPath) {
+		url += otlpTracesPath
+	}
+	e := &exporter{
+		url:    url,
+		client: &http.Client{Timeout: time.Second * 5},
+		spans:  make(chan *OTLPSpan, otlpBatchSize*4),
+		flush:  make(chan chan struct{}),
+	}
+	go e.loop()
+	return e, nil
+}
+
+//send 放入待上报队列，队列已满时丢弃
+func (e *exporter) send(s *OTLPSpan) {
+	select {
+	case e.spans <- s:
+	default:
+	}
+}
+
+//Flush 立即上报队列中的跨度
+func (e *exporter) Flush() {
+	done := make(chan struct{})
+	e.flush <- done
+	<-done
+}
+
+func (e *exporter) loop() {
+	tk := time.NewTicker(otlpFlushInterval)
+	defer tk.Stop()
+	batch := make([]*OTLPSpan, 0, otlpBatchSize)
+	for {
+		select {
+		case s := <-e.spans:
+			batch = append(batch, s)
+			if len(batch) >= otlpBatchSize {
+				e.export(batch)
+				batch = batch[:0]
+			}
+		case <-tk.C:
+			if len(batch) > 0 {
+				e.export(batch)
+				batch = batch[:0]
+			}
+		case done := <-e.flush:
+			for n := len(e.spans); n > 0; n-- {
+				batch = append(batch, <-e.spans)
+			}
+			if len(batch) > 0 {
+				e.export(batch)
+				batch = batch[:0]
+			}
+			close(done)
+		}
+	}
+}
+
+func (e *exporter) export(spans []*OTLPSpan) {
+	buff, err := json.Marshal(toOTLP(spans))
+	if err != nil {
+		global.Def.Log().Errorf("otlp数据转换失败:%v", err)
+		return
+	}
+	resp, err := e.client.Post(e.url, "application/json", bytes.NewReader(buff))
+	if err != nil {
+		global.Def.Log().Errorf("otlp上报失败:%s %v", e.url, err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		global.Def.Log().Errorf("otlp上报失败:%s(%d) %s", e.url, resp.StatusCode, body)
+	}
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpAttr struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpSpan struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	ParentSpanID      string     `json:"parentSpanId,omitempty"`
+	Name              string     `json:"name"`
+	Kind              int        `json:"kind"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+	Attributes        []otlpAttr `json:"attributes,omitempty"`
+}
+
+type otlpScopeSpans struct {
+	Scope struct {
+		Name string `json:"name"`
+	} `json:"scope"`
+	Spans []*otlpSpan `json:"spans"`
+}
+
+type otlpResourceSpans struct {
+	Resource struct {
+		Attributes []otlpAttr `json:"attributes"`
+	} `json:"resource"`
+	ScopeSpans []*otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpRequest struct {
+	ResourceSpans []*otlpResourceSpans `json:"resourceSpans"`
+}
+
+//toOTLP 按服务名称分组转换为OTLP请求
+func toOTLP(spans []*OTLPSpan) *otlpRequest {
+	req := &otlpRequest{}
+	groups := make(map[string]*otlpScopeSpans)
+	for _, s := range spans {
+		scope, ok := groups[s.service]
+		if !ok {
+			rs := &otlpResourceSpans{}
+			rs.Resource.Attributes = []otlpAttr{{Key: "service.name", Value: otlpValue{StringValue: s.service}}}
+			scope = &otlpScopeSpans{}
+			scope.Scope.Name = "hydra"
+			rs.ScopeSpans = []*otlpScopeSpans{scope}
+			req.ResourceSpans = append(req.ResourceSpans, rs)
+			groups[s.service] = scope
+		}
+		scope.Spans = append(scope.Spans, &otlpSpan{
+			TraceID:           s.traceID,
+			SpanID:            s.spanID,
+			ParentSpanID:      s.parentID,
+			Name:              s.operator,
+			Kind:              s.kind,
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+			Attributes:        []otlpAttr{{Key: "host.ip", Value: otlpValue{StringValue: global.LocalIP()}}},
+		})
+	}
+	return req
+}
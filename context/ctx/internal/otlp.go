@@ -0,0 +1,130 @@
+package internal
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/micro-plat/hydra/context"
+)
+
+const (
+	spanKindInternal = 1
+	spanKindServer   = 2
+)
+
+//OTLPSpan 基于OpenTelemetry模型的事务处理跨度
+type OTLPSpan struct {
+	exporter *exporter
+	service  string
+	operator string
+	kind     int
+	traceID  string
+	spanID   string
+	parentID string
+	sampled  bool
+	start    time.Time
+	end      time.Time
+	subs     []*OTLPSpan
+	lock     sync.Mutex
+	once     sync.Once
+}
+
+//newOTLPRoot 创建根跨度，traceparent有效时延续上游的链路
+func newOTLPRoot(exp *exporter, service string, operator string, traceparent string) *OTLPSpan {
+	s := &OTLPSpan{exporter: exp, service: service, operator: operator, kind: spanKindServer, spanID: newID(8), sampled: true}
+	if traceID, parentID, sampled, ok := ParseTraceParent(traceparent); ok {
+		s.traceID, s.parentID, s.sampled = traceID, parentID, sampled
+		return s
+	}
+	s.traceID = newID(16)
+	return s
+}
+
+//Start 启动任务
+func (s *OTLPSpan) Start() context.IEnd {
+	s.start = time.Now()
+	return s
+}
+
+//NewSpan 创建子跨度
+func (s *OTLPSpan) NewSpan(operator string) context.ITraceSpan {
+	sub := &OTLPSpan{
+		exporter: s.exporter,
+		service:  s.service,
+		operator: operator,
+		kind:     spanKindInternal,
+		traceID:  s.traceID,
+		spanID:   newID(8),
+		parentID: s.spanID,
+		sampled:  s.sampled,
+	}
+	s.lock.Lock()
+	s.subs = append(s.subs, sub)
+	s.lock.Unlock()
+	return sub
+}
+
+//Available 是否可用
+func (s *OTLPSpan) Available() bool {
+	return true
+}
+
+//GetTraceParent 获取传递给下游服务的traceparent
+func (s *OTLPSpan) GetTraceParent() string {
+	flags := "00"
+	if s.sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%s-%s-%s", s.traceID, s.spanID, flags)
+}
+
+//End 处理完成，子跨度一并结束并上报
+func (s *OTLPSpan) End() {
+	s.once.Do(func() {
+		s.lock.Lock()
+		subs := s.subs
+		s.lock.Unlock()
+		for _, v := range subs {
+			v.End()
+		}
+		if s.start.IsZero() {
+			return
+		}
+		s.end = time.Now()
+		if s.sampled {
+			s.exporter.send(s)
+		}
+	})
+}
+
+//ParseTraceParent 解析W3C traceparent: version-traceid-parentid-flags
+func ParseTraceParent(v string) (traceID string, parentID string, sampled bool, ok bool) {
+	parts := strings.Split(strings.TrimSpace(v), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" ||
+		len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return "", "", false, false
+	}
+	if parts[0] == "00" && len(parts) != 4 {
+		return "", "", false, false
+	}
+	for _, p := range parts[:4] {
+		if _, err := hex.DecodeString(p); err != nil || strings.ToLower(p) != p {
+			return "", "", false, false
+		}
+	}
+	if strings.Trim(parts[1], "0") == "" || strings.Trim(parts[2], "0") == "" {
+		return "", "", false, false
+	}
+	flags, _ := hex.DecodeString(parts[3])
+	return parts[1], parts[2], flags[0]&0x01 == 0x01, true
+}
+
+func newID(n int) string {
+	buff := make([]byte, n)
+	rand.Read(buff)
+	return hex.EncodeToString(buff)
+}
@@ -0,0 +1,105 @@
+package internal
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       string
+		traceID string
+		spanID  string
+		sampled bool
+		ok      bool
+	}{
+		{name: "有效值", v: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", traceID: "4bf92f3577b34da6a3ce929d0e0e4736", spanID: "00f067aa0ba902b7", sampled: true, ok: true},
+		{name: "未采样", v: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", traceID: "4bf92f3577b34da6a3ce929d0e0e4736", spanID: "00f067aa0ba902b7", ok: true},
+		{name: "空值", v: ""},
+		{name: "版本ff", v: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{name: "全零traceid", v: "00-00000000000000000000000000000000-00f067aa0ba902b7-01"},
+		{name: "大写", v: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01"},
+		{name: "长度错误", v: "00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01"},
+	}
+	for _, tt := range tests {
+		traceID, spanID, sampled, ok := ParseTraceParent(tt.v)
+		assert.Equal(t, tt.ok, ok, tt.name)
+		assert.Equal(t, tt.traceID, traceID, tt.name)
+		assert.Equal(t, tt.spanID, spanID, tt.name)
+		assert.Equal(t, tt.sampled, sampled, tt.name)
+	}
+}
+
+func TestOTLPExport(t *testing.T) {
+	received := make(chan *otlpRequest, 1)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, otlpTracesPath, r.URL.Path, "上报路径")
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"), "上报格式")
+		buff, _ := ioutil.ReadAll(r.Body)
+		req := &otlpRequest{}
+		if err := json.Unmarshal(buff, req); err != nil {
+			t.Error(err)
+		}
+		received <- req
+	}))
+	defer collector.Close()
+
+	exp, err := newExporter(collector.URL)
+	assert.Equal(t, nil, err, "创建上报器")
+
+	parent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	root := newOTLPRoot(exp, "apiserver", "/order/query", parent)
+	root.Start()
+	sub := root.NewSpan("db.query")
+	sub.Start()
+	root.End()
+
+	tp := root.GetTraceParent()
+	traceID, spanID, sampled, ok := ParseTraceParent(tp)
+	assert.Equal(t, true, ok, "传播的traceparent")
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID, "延续上游traceid")
+	assert.Equal(t, root.spanID, spanID, "当前跨度作为下游父跨度")
+	assert.Equal(t, true, sampled, "采样标识")
+
+	exp.Flush()
+	req := <-received
+	assert.Equal(t, 1, len(req.ResourceSpans), "资源数")
+	rs := req.ResourceSpans[0]
+	assert.Equal(t, "service.name", rs.Resource.Attributes[0].Key, "服务属性")
+	assert.Equal(t, "apiserver", rs.Resource.Attributes[0].Value.StringValue, "服务名称")
+	spans := rs.ScopeSpans[0].Spans
+	assert.Equal(t, 2, len(spans), "跨度数")
+	for _, s := range spans {
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", s.TraceID, "traceid")
+		switch s.Name {
+		case "/order/query":
+			assert.Equal(t, "00f067aa0ba902b7", s.ParentSpanID, "根跨度父节点")
+			assert.Equal(t, spanKindServer, s.Kind, "根跨度类型")
+		case "db.query":
+			assert.Equal(t, root.spanID, s.ParentSpanID, "子跨度父节点")
+			assert.Equal(t, spanKindInternal, s.Kind, "子跨度类型")
+		default:
+			t.Errorf("未知跨度:%s", s.Name)
+		}
+	}
+}
+
+func TestOTLPNotSampled(t *testing.T) {
+	exp := &exporter{spans: make(chan *OTLPSpan, 1)}
+	root := newOTLPRoot(exp, "apiserver", "/order/query", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	root.Start()
+	root.End()
+	assert.Equal(t, 0, len(exp.spans), "未采样的跨度不上报")
+
+	root = newOTLPRoot(exp, "apiserver", "/order/query", "")
+	assert.Equal(t, 32, len(root.traceID), "无上游时创建traceid")
+	assert.Equal(t, "", root.parentID, "无上游时无父跨度")
+	root.End()
+	assert.Equal(t, 0, len(exp.spans), "未启动的跨度不上报")
+}
@@ -2,39 +2,53 @@ package internal
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/SkyAPM/go2sky"
 	"github.com/SkyAPM/go2sky/reporter"
-	"github.com/micro-plat/hydra/conf/app"
 	"github.com/micro-plat/hydra/conf/server/apm"
-	"github.com/micro-plat/lib4go/concurrent/cmap"
 )
 
-var reporters = cmap.New(3)
+//skyTracer skywalking上报服务及跟踪器
+type skyTracer struct {
+	key      string
+	reporter go2sky.Reporter
+	tracer   *go2sky.Tracer
+}
 
-func getReporter(c app.IAPPConf) (go2sky.Reporter, error) {
-	conf, err := c.GetAPMConf()
-	if err != nil || conf.Disable {
-		return nil, err
+//skyTracers 按服务器类型缓存skywalking跟踪器，apm配置版本变化时重建
+var skyTracers = map[string]*skyTracer{}
+var skyLock sync.RWMutex
+
+//getSkyTracer 获取skywalking跟踪器，apm配置未变化时复用已创建的上报服务，变化时关闭原上报服务
+func getSkyTracer(tp string, service string, conf *apm.APM) (*go2sky.Tracer, error) {
+	key := fmt.Sprintf("%s:%s:%d", service, conf.Address, conf.Version)
+
+	skyLock.RLock()
+	t, ok := skyTracers[tp]
+	skyLock.RUnlock()
+	if ok && t.key == key {
+		return t.tracer, nil
 	}
-	key := fmt.Sprintf("apm:%s", conf.Address)
-	_, v, err := reporters.SetIfAbsentCb(key, func(v ...interface{}) (i interface{}, err error) {
-		apm := v[0].(*apm.APM)
-		report, err := reporter.NewGRPCReporter(apm.Address, reporter.WithCheckInterval(time.Second))
-		return report, err
 
-	}, conf)
-	go remove(key)
-	if err != nil || v == nil {
+	skyLock.Lock()
+	defer skyLock.Unlock()
+	if t, ok = skyTracers[tp]; ok && t.key == key {
+		return t.tracer, nil
+	}
+	report, err := reporter.NewGRPCReporter(conf.Address, reporter.WithCheckInterval(time.Second))
+	if err != nil {
 		return nil, err
 	}
-
-	return v.(go2sky.Reporter), nil
-}
-func remove(key string) {
-	reporters.RemoveIterCb(func(k string, v interface{}) bool {
-		return key != k
-	})
-
+	tracer, err := go2sky.NewTracer(service, go2sky.WithReporter(report))
+	if err != nil {
+		report.Close()
+		return nil, err
+	}
+	if ok {
+		t.reporter.Close()
+	}
+	skyTracers[tp] = &skyTracer{key: key, reporter: report, tracer: tracer}
+	return tracer, nil
 }
@@ -0,0 +1,28 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/micro-plat/hydra/conf/server/apm"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func TestGetSkyTracer(t *testing.T) {
+	conf := apm.New("127.0.0.1:11800")
+	conf.Version = 1
+	t1, err := getSkyTracer("api", "apiserver", conf)
+	assert.Equal(t, nil, err, "创建跟踪器")
+	t2, err := getSkyTracer("api", "apiserver", conf)
+	assert.Equal(t, nil, err, "获取跟踪器")
+	assert.Equal(t, true, t1 == t2, "配置未变化时复用跟踪器")
+	r1 := skyTracers["api"].reporter
+
+	conf.Version = 2
+	t3, err := getSkyTracer("api", "apiserver", conf)
+	assert.Equal(t, nil, err, "配置变化后创建跟踪器")
+	assert.Equal(t, true, t1 != t3, "配置变化时重建跟踪器")
+	assert.Equal(t, true, r1 != skyTracers["api"].reporter, "配置变化时重建上报服务")
+	assert.Equal(t, 1, len(skyTracers), "每个服务器只保留一个上报服务")
+	skyTracers["api"].reporter.Close()
+	delete(skyTracers, "api")
+}
@@ -1,49 +1,53 @@
 package internal
 
 import (
-	"context"
+	r "context"
 
-	"github.com/SkyAPM/go2sky"
-	"github.com/SkyAPM/go2sky/reporter"
 	"github.com/micro-plat/hydra/conf/app"
-	"github.com/micro-plat/lib4go/concurrent/cmap"
+	"github.com/micro-plat/hydra/conf/server/apm"
+	"github.com/micro-plat/hydra/context"
 )
 
 //Tracer 跟踪器
 type Tracer struct {
-	reporter go2sky.Reporter
-	*Span
+	context.ITraceSpan
 }
 
-var tracers = cmap.New(8)
-
 //Empty 空跟踪器
-var Empty = &Tracer{Span: New(context.Background(), nil, "")}
+var Empty = &Tracer{ITraceSpan: New(r.Background(), nil, "")}
 
-//GetTracer 创建跟踪器
-func GetTracer(service string, c app.IAPPConf) (*Tracer, error) {
+//GetTracer 根据apm配置创建跟踪器，traceparent为上游传入的W3C链路上下文
+func GetTracer(operator string, traceparent string, c app.IAPPConf) (*Tracer, error) {
 	conf, err := c.GetAPMConf()
 	if err != nil || conf.Disable {
-		return nil, err
-	}
-	reporter, err := reporter.NewGRPCReporter(conf.Address)
-	if reporter == nil || err != nil {
-		return &Tracer{Span: New(context.Background(), nil, service)}, err
+		return Empty, err
 	}
-	tracer, err := go2sky.NewTracer(service, go2sky.WithReporter(reporter))
-	if err != nil {
-		return &Tracer{Span: New(context.Background(), nil, service)}, err
+	service := c.GetServerConf().GetServerName()
+	switch conf.GetTracer() {
+	case apm.TracerOTLP:
+		exp, err := getExporter(conf.Address)
+		if err != nil {
+			return Empty, err
+		}
+		return &Tracer{ITraceSpan: newOTLPRoot(exp, service, operator, traceparent)}, nil
+	default:
+		tracer, err := getSkyTracer(c.GetServerConf().GetServerType(), service, conf)
+		if err != nil {
+			return Empty, err
+		}
+		return &Tracer{ITraceSpan: New(r.Background(), tracer, operator)}, nil
 	}
-	return &Tracer{reporter: reporter, Span: New(context.Background(), tracer, service)}, nil
 }
 
 //Root 根节点
-func (t *Tracer) Root() *Span {
-	return t.Span
+func (t *Tracer) Root() context.ITraceSpan {
+	return t.ITraceSpan
 }
 
-//End 结束跟踪
-func (t *Tracer) End() {
-	t.reporter.Close()
-	t.Span.End()
+//GetTraceParent 获取传递给下游服务的traceparent
+func (t *Tracer) GetTraceParent() string {
+	if p, ok := t.ITraceSpan.(context.ITraceParent); ok {
+		return p.GetTraceParent()
+	}
+	return ""
 }
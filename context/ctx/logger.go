@@ -0,0 +1,108 @@
+package ctx
+
+import (
+	"sync"
+
+	"github.com/micro-plat/hydra/conf/vars/rlog"
+	"github.com/micro-plat/lib4go/logger"
+)
+
+var _ logger.ILogger = &ctxLogger{}
+
+//ctxLogger 请求日志组件，日志标签中携带链路编号、服务器类型、服务、客户端IP与用户，
+//用户在认证后才能获取，用户变化时重新构建日志组件
+type ctxLogger struct {
+	logger.ILogger
+	name     string
+	session  string
+	fields   rlog.Fields
+	user     func() string
+	lastUser string
+	paused   bool
+	lock     sync.Mutex
+}
+
+func newCtxLogger(name string, session string, fields rlog.Fields, user func() string) *ctxLogger {
+	l := &ctxLogger{name: name, session: session, fields: fields, user: user}
+	l.current()
+	return l
+}
+
+//current 获取当前用户对应的日志组件
+func (l *ctxLogger) current() logger.ILogger {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	user := l.user()
+	if l.ILogger != nil && user == l.lastUser {
+		return l.ILogger
+	}
+	fields := make(rlog.Fields, len(l.fields)+1)
+	for k, v := range l.fields {
+		fields[k] = v
+	}
+	if user != "" {
+		fields[rlog.FieldUser] = user
+	}
+	l.lastUser = user
+	l.ILogger = logger.GetSession(l.name, l.session, fields.Tags()...)
+	if l.paused {
+		l.ILogger.Pause()
+	}
+	return l.ILogger
+}
+
+//Pause 暂停记录
+func (l *ctxLogger) Pause() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.paused = true
+	l.ILogger.Pause()
+}
+
+//Resume 恢复记录
+func (l *ctxLogger) Resume() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.paused = false
+	l.ILogger.Resume()
+}
+
+func (l *ctxLogger) Printf(format string, content ...interface{}) {
+	l.current().Printf(format, content...)
+}
+func (l *ctxLogger) Print(content ...interface{}) {
+	l.current().Print(content...)
+}
+func (l *ctxLogger) Println(content ...interface{}) {
+	l.current().Println(content...)
+}
+func (l *ctxLogger) Infof(format string, content ...interface{}) {
+	l.current().Infof(format, content...)
+}
+func (l *ctxLogger) Info(content ...interface{}) {
+	l.current().Info(content...)
+}
+func (l *ctxLogger) Errorf(format string, content ...interface{}) {
+	l.current().Errorf(format, content...)
+}
+func (l *ctxLogger) Error(content ...interface{}) {
+	l.current().Error(content...)
+}
+func (l *ctxLogger) Debugf(format string, content ...interface{}) {
+	l.current().Debugf(format, content...)
+}
+func (l *ctxLogger) Debug(content ...interface{}) {
+	l.current().Debug(content...)
+}
+func (l *ctxLogger) Fatalf(format string, content ...interface{}) {
+	l.current().Fatalf(format, content...)
+}
+func (l *ctxLogger) Fatal(content ...interface{}) {
+	l.current().Fatal(content...)
+}
+func (l *ctxLogger) Warnf(format string, content ...interface{}) {
+	l.current().Warnf(format, content...)
+}
+func (l *ctxLogger) Warn(content ...interface{}) {
+	l.current().Warn(content...)
+}
@@ -1,6 +1,8 @@
 package ctx
 
 import (
+	"strings"
+
 	"github.com/micro-plat/hydra/conf/app"
 	"github.com/micro-plat/hydra/context"
 	"github.com/micro-plat/hydra/context/ctx/internal"
@@ -12,9 +14,13 @@ type tracer struct {
 	l logger.ILogger
 }
 
-func newTracer(path string, l logger.ILogger, c app.IAPPConf) *tracer {
+func newTracer(path string, l logger.ILogger, c app.IAPPConf, headers map[string][]string) *tracer {
+	t, err := internal.GetTracer(path, getTraceParent(headers), c)
+	if err != nil {
+		l.Errorf("创建链路跟踪器失败:%v", err)
+	}
 	return &tracer{
-		Tracer: internal.Empty,
+		Tracer: t,
 		l:      l,
 	}
 }
@@ -23,3 +29,13 @@ func newTracer(path string, l logger.ILogger, c app.IAPPConf) *tracer {
 func (t *tracer) Root() context.ITraceSpan {
 	return t.Tracer.Root()
 }
+
+//getTraceParent 从请求头中获取traceparent(http请求头会被转换为Traceparent)
+func getTraceParent(headers map[string][]string) string {
+	for k, v := range headers {
+		if strings.EqualFold(k, context.XTraceParent) && len(v) > 0 {
+			return v[0]
+		}
+	}
+	return ""
+}
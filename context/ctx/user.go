@@ -50,6 +50,15 @@ func (c *user) GetClientIP() string {
 	return ip
 }
 
+//GetCertSubject 获取已验证的客户端证书主题
+func (c *user) GetCertSubject() string {
+	req, _ := c.ctx.GetHTTPReqResp()
+	if req == nil || req.TLS == nil || len(req.TLS.VerifiedChains) == 0 || len(req.TLS.VerifiedChains[0]) == 0 {
+		return ""
+	}
+	return req.TLS.VerifiedChains[0][0].Subject.String()
+}
+
 //Auth 用户认证信息
 func (c *user) Auth() context.IAuth {
 	return c.auth
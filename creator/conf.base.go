@@ -46,6 +46,12 @@ func (b BaseBuilder) Metric(host string, db string, cron string, opts ...metric.
 	return b
 }
 
+//Prometheus 监控配置，提供prometheus拉取接口
+func (b BaseBuilder) Prometheus(opts ...metric.Option) BaseBuilder {
+	b[metric.TypeNodeName] = metric.NewPrometheus(opts...)
+	return b
+}
+
 //APM 构建APM配置
 func (b BaseBuilder) APM(address string) BaseBuilder {
 	b[apm.TypeNodeName] = apm.New(address)
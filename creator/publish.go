@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"reflect"
 
+	"github.com/micro-plat/hydra/conf/history"
 	"github.com/micro-plat/hydra/conf/pkgs/security"
 	"github.com/micro-plat/hydra/conf/server"
 	varpub "github.com/micro-plat/hydra/conf/vars"
@@ -34,8 +35,36 @@ func (c *conf) Pub(platName string, systemName string, clusterName string, regis
 	if err != nil {
 		return err
 	}
-	confs := make(map[string]interface{})
 	cache := types.XMap{}
+	written := false //是否已写入节点，已写入时无论发布是否完成均记录配置快照
+	write := func(path string, v interface{}) error {
+		saved, err := save(r, path, v, cache)
+		written = written || saved
+		return err
+	}
+	err = c.pub(platName, systemName, clusterName, input, write)
+	if !written {
+		return err
+	}
+
+	//记录发布后的配置快照
+	comment := ""
+	if err != nil {
+		comment = fmt.Sprintf("发布未完成:%v", err)
+	}
+	if _, herr := history.NewHistory(r, platName, systemName).Record(history.ActionPublish, history.CurrentAuthor(), comment); herr != nil {
+		if err != nil {
+			return fmt.Errorf("%w(记录配置历史失败:%v)", err, herr)
+		}
+		return fmt.Errorf("配置已发布，记录配置历史失败:%w", herr)
+	}
+	return err
+}
+
+//pub 依次发布server配置、var配置及项目未配置的导入配置项
+func (c *conf) pub(platName string, systemName string, clusterName string, input types.XMap, write func(path string, v interface{}) error) error {
+	confs := make(map[string]interface{})
+
 	//加入server配置
 	for tp, subs := range c.data {
 		pub := server.NewServerPub(platName, systemName, tp, clusterName)
@@ -45,7 +74,7 @@ func (c *conf) Pub(platName string, systemName string, clusterName string, regis
 			return err
 		}
 		//先发布main节点配置
-		if err := publish(r, path, value, cache); err != nil {
+		if err := write(path, value); err != nil {
 			return err
 		}
 		confs[path] = value
@@ -60,7 +89,7 @@ func (c *conf) Pub(platName string, systemName string, clusterName string, regis
 			if err != nil {
 				return err
 			}
-			if err := publish(r, path, value, cache); err != nil {
+			if err := write(path, value); err != nil {
 				return err
 			}
 			confs[path] = value
@@ -77,7 +106,7 @@ func (c *conf) Pub(platName string, systemName string, clusterName string, regis
 				return err
 			}
 			confs[path] = value
-			if err := publish(r, path, value, cache); err != nil {
+			if err := write(path, value); err != nil {
 				return err
 			}
 		}
@@ -86,44 +115,67 @@ func (c *conf) Pub(platName string, systemName string, clusterName string, regis
 	//加入项目未配置的导入配置项
 	for k, v := range input {
 		if _, ok := confs[k]; !ok {
-			if err := publish(r, k, v, cache); err != nil {
+			if err := write(k, v); err != nil {
 				return err
 			}
 		}
 	}
-
 	return nil
 }
 
 func publish(r registry.IRegistry, path string, v interface{}, input types.XMap) error {
+	_, err := save(r, path, v, input)
+	return err
+}
+
+//save 保存节点配置，未覆盖已有配置时saved为false
+func save(r registry.IRegistry, path string, v interface{}, input types.XMap) (saved bool, err error) {
 
-	delete := false
+	cover := false
+	var version int32
 	if b, _ := r.Exists(path); b {
-		buff, _, err := r.GetValue(path)
+		buff, ver, err := r.GetValue(path)
 		if err != nil {
-			return err
+			return false, err
 		}
 		if !checkCover(path, string(buff), v) { //不覆盖配置则退出
-			return nil
+			return false, nil
 		}
-		delete = true
+		cover, version = true, ver
 	}
 
 	value, err := getJSON(path, v, input) //获取节点值
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	if delete { //覆盖值之前先删除节点
-		if err := deleteAll(r, path); err != nil {
-			return err
+	//覆盖值时删除所有子节点，并检查节点在读取后未被其它程序修改，
+	//子节点过多时分批提交，首批先检查节点版本
+	ops := []registry.Op{registry.CreateOp(path, value)}
+	if cover {
+		list, err := getAllPath(r, path)
+		if err != nil {
+			return false, err
+		}
+		ops = make([]registry.Op, 0, len(list)+1)
+		if len(list) > registry.MaxTxnOps {
+			ops = append(ops, registry.CheckOp(path, version))
 		}
+		for _, p := range list[:len(list)-1] {
+			ops = append(ops, registry.DeleteOp(p, registry.AnyVersion))
+		}
+		ops = append(ops, registry.UpdateOp(path, value, version))
 	}
-
-	if err := r.CreatePersistentNode(path, value); err != nil {
-		return fmt.Errorf("创建配置节点%s %s出错:%w", path, value, err)
+	n, err := registry.TxnBatch(r, ops...)
+	if err != nil {
+		if registry.IsConflict(err) {
+			err = fmt.Errorf("配置节点%s已被其它程序修改，请确认后重新发布:%w", path, err)
+		} else {
+			err = fmt.Errorf("创建配置节点%s %s出错:%w", path, value, err)
+		}
+		return n > 0, err
 	}
-	return nil
+	return true, nil
 }
 
 func deleteAll(r registry.IRegistry, path string) error {
@@ -184,7 +236,7 @@ func getJSON(path string, v interface{}, input types.XMap) (value string, err er
 
 	switch en := v.(type) {
 	case security.IEncrypt:
-		return en.Encrypt(buff), nil
+		return en.Encrypt(buff)
 	default:
 		return string(buff), nil
 	}
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/micro-plat/hydra/conf/history"
 	"github.com/micro-plat/hydra/conf/server/acl/whitelist"
 
 	"github.com/micro-plat/hydra/conf/server"
@@ -330,3 +331,28 @@ func Test_getJSON(t *testing.T) {
 		assert.Equal(t, tt.wantValue, got, tt.name+",value")
 	}
 }
+
+func Test_conf_PubHistory(t *testing.T) {
+	global.Def.ServerTypes = []string{}
+	coverAll = true
+	defer func() { coverAll = false }()
+	c := &conf{
+		data: map[string]iCustomerBuilder{"api": BaseBuilder{"main": "123456"}},
+		vars: map[string]map[string]interface{}{"db": map[string]interface{}{"dcc": "545454"}},
+	}
+	err := c.Pub("platHistory", "sys", "prod", "lm://.", nil)
+	assert.Equal(t, nil, err, "发布配置")
+
+	//导入配置无法序列化，发布中途失败
+	c.vars = map[string]map[string]interface{}{"db": map[string]interface{}{"dcc": "565656"}}
+	err = c.Pub("platHistory", "sys", "prod", "lm://.", types.XMap{"/platHistory/var/custom/x": make(chan int)})
+	assert.NotEqual(t, nil, err, "发布中途失败")
+
+	rgt, _ := registry.GetRegistry("lm://.", global.Def.Log())
+	list, err := history.NewHistory(rgt, "platHistory", "sys").List()
+	assert.Equal(t, nil, err, "获取配置历史")
+	assert.Equal(t, 2, len(list), "发布中途失败时仍记录已写入的配置")
+	assert.Equal(t, true, strings.HasPrefix(list[1].Comment, "发布未完成"), "记录发布失败原因")
+	s, _ := history.NewHistory(rgt, "platHistory", "sys").Get(list[1].Version)
+	assert.Equal(t, "565656", s.Confs["/platHistory/var/db/dcc"], "快照为失败时注册中心中的配置")
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/micro-plat/hydra/conf/server/processor"
 	"github.com/micro-plat/hydra/conf/server/render"
 	"github.com/micro-plat/hydra/conf/server/static"
+	"github.com/micro-plat/hydra/conf/server/ws"
 )
 
 type httpBuilder struct {
@@ -120,3 +121,21 @@ func (b *httpBuilder) NFS(local string, opts ...nfs.Option) *httpBuilder {
 	b.BaseBuilder[nfs.TypeNodeName] = nfs.New(local, opts...)
 	return b
 }
+
+//Upgrade ws服务器的连接升级与读写限制配置(来源检查、子协议、消息大小、心跳、空闲超时及限流)
+func (b *httpBuilder) Upgrade(opts ...ws.Option) *httpBuilder {
+	main, ok := b.BaseBuilder[ServerMainNodeName].(*api.Server)
+	if !ok {
+		if m, ok := b.BaseBuilder[ServerMainNodeName].(*wsMain); ok {
+			main = m.Server
+		}
+	}
+	b.BaseBuilder[ServerMainNodeName] = &wsMain{Server: main, Upgrade: &ws.New("", opts...).Upgrade}
+	return b
+}
+
+//wsMain ws服务器主配置
+type wsMain struct {
+	*api.Server
+	*ws.Upgrade
+}
@@ -2,6 +2,7 @@ package creator
 
 import (
 	"github.com/micro-plat/hydra/conf/vars/queue"
+	queuedmq "github.com/micro-plat/hydra/conf/vars/queue/dmq"
 	queuelmq "github.com/micro-plat/hydra/conf/vars/queue/lmq"
 	queuemqtt "github.com/micro-plat/hydra/conf/vars/queue/mqtt"
 	"github.com/micro-plat/hydra/conf/vars/queue/queueredis"
@@ -34,6 +35,11 @@ func (c *Varqueue) LMQ(nodeName string) vars {
 	return c.Custom(nodeName, queuelmq.New())
 }
 
+//DMQ 添加本地磁盘作为持久化消息队列
+func (c *Varqueue) DMQ(nodeName string, path string, opts ...queuedmq.Option) vars {
+	return c.Custom(nodeName, queuedmq.New(path, opts...))
+}
+
 //Custom 用户自定义消息队列
 func (c *Varqueue) Custom(nodeName string, q interface{}) vars {
 	if _, ok := c.vars[queue.TypeNodeName]; !ok {
@@ -64,6 +64,9 @@ type global struct {
 	//IPMask 设置获取本地IP的掩码
 	IPMask string
 
+	//OpenAPIPath debug模式下OpenAPI文档的访问路径
+	OpenAPIPath string
+
 	//isClose 是否关闭当前应用程序
 	isClose bool
 
@@ -161,6 +164,14 @@ func (m *global) GetTracePort() string {
 	return m.TracePort
 }
 
+//GetOpenAPIPath 获取OpenAPI文档的访问路径，未设置时为/openapi.json
+func (m *global) GetOpenAPIPath() string {
+	if m.OpenAPIPath == "" {
+		return "/openapi.json"
+	}
+	return m.OpenAPIPath
+}
+
 //ClosingNotify 获取系统关闭通知
 func (m *global) ClosingNotify() chan struct{} {
 	return m.close
@@ -15,6 +15,7 @@ const (
 	ProtoLM      = "lm"
 	ProtoFS      = "fs"
 	ProtoLMQ     = "lmq"
+	ProtoDMQ     = "dmq"
 	ProtoREDIS   = "redis"
 	ProtoMQTT    = "mqtt"
 	ProtoInvoker = "ivk"
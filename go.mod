@@ -5,6 +5,7 @@ go 1.16
 require (
 	github.com/BurntSushi/toml v0.3.1
 	github.com/SkyAPM/go2sky v0.6.0
+	github.com/alicebob/miniredis/v2 v2.14.3
 	github.com/asaskevich/govalidator v0.0.0-20200907205600-7a23bdc65eef
 	github.com/bradfitz/gomemcache v0.0.0-20190913173617-a41fca850d0b
 	github.com/clbanning/mxj v1.8.4
@@ -32,6 +33,7 @@ require (
 	github.com/pkg/profile v1.2.1
 	github.com/pkg/sftp v1.12.0
 	github.com/robfig/cron/v3 v3.0.1
+	github.com/samuel/go-zookeeper v0.0.0-20200724154423-2164a8ac840e
 	github.com/sergi/go-diff v1.2.0
 	github.com/stretchr/testify v1.6.1
 	github.com/ugorji/go/codec v1.2.2
@@ -35,6 +35,32 @@ func init() {
 					Action: exportNow,
 					Flags:  getExportFlags(),
 				},
+				{
+					Name:   "rotate",
+					Usage:  "-密钥轮换，使用指定密钥重新加密注册中心中已加密的配置",
+					Action: rotateNow,
+					Flags:  getRotateFlags(),
+				},
+				{
+					Name:   "history",
+					Usage:  "-查看当前系统的配置历史，列出每次配置写入的版本、操作人、时间与hash",
+					Action: historyNow,
+					Flags:  getHistoryFlags(),
+				},
+				{
+					Name:      "diff",
+					Usage:     "-比较两个配置版本的差异",
+					ArgsUsage: "<v1> <v2>",
+					Action:    diffNow,
+					Flags:     getHistoryFlags(),
+				},
+				{
+					Name:      "rollback",
+					Usage:     "-回滚配置，将当前系统指定版本的服务器配置重新写入注册中心，平台var配置不回滚",
+					ArgsUsage: "<v>",
+					Action:    rollbackNow,
+					Flags:     getHistoryFlags(),
+				},
 			},
 		}
 	})
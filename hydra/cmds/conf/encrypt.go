@@ -11,11 +11,40 @@ func encrypt(c *cli.Context) (err error) {
 	if len(orgData) == 0 {
 		return fmt.Errorf("未指定加密的内容")
 	}
+	id, err := getEncryptKeyID()
+	if err != nil {
+		return err
+	}
 
-	cipherData := security.Encrypt([]byte(orgData))
+	//已加密的内容先解密，再使用指定密钥重新加密(密钥轮换)
+	data := []byte(orgData)
+	if security.IsEncrypted(data) {
+		if data, err = security.Decrypt(data); err != nil {
+			return fmt.Errorf("解密原加密内容失败:%w", err)
+		}
+	}
+	cipherData, err := security.EncryptBy(id, data)
+	if err != nil {
+		return err
+	}
 	fmt.Println("原始内容：")
 	fmt.Println(orgData)
 	fmt.Println("加密结果：")
 	fmt.Println(cipherData)
 	return nil
 }
+
+//getEncryptKeyID 获取加密使用的密钥编号，未指定时使用当前密钥
+func getEncryptKeyID() (string, error) {
+	id := encryptKeyID
+	if id == "" {
+		id = security.GetProvider().GetCurrentID()
+	}
+	if id == "" {
+		return "", fmt.Errorf("未配置加密密钥,请通过环境变量%s或%s指定", security.EnvConfKeys, security.EnvConfKeyFile)
+	}
+	if _, err := security.GetProvider().GetKey(id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
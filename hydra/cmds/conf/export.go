@@ -56,6 +56,7 @@ type export struct {
 	rgst    registry.IRegistry
 	cover   bool
 	encrypt bool
+	keyID   string
 }
 
 func newExport(plat string, sysName string, types []string, cluster string) *export {
@@ -71,6 +72,13 @@ func newExport(plat string, sysName string, types []string, cluster string) *exp
 }
 func (s *export) Export() error {
 	s.rgst = registry.GetCurrent()
+	if s.encrypt {
+		id, err := getEncryptKeyID()
+		if err != nil {
+			return err
+		}
+		s.keyID = id
+	}
 	if err := s.getMainConf(); err != nil {
 		return err
 	}
@@ -90,12 +98,17 @@ func (s *export) getMainConf() error {
 		if err != nil {
 			return err
 		}
-		s.getNodes(sc.GetServerConf().GetServerPath(), sc.GetServerConf().GetMainConf(), s.confs)
+		if err = s.getNodes(sc.GetServerConf().GetServerPath(), sc.GetServerConf().GetMainConf(), s.confs); err != nil {
+			return err
+		}
 		sc.GetServerConf().Iter(func(path string, v *conf.RawConf) bool {
 			npath := sc.GetServerConf().GetSubConfPath(path)
-			s.getNodes(npath, v, s.confs)
-			return true
+			err = s.getNodes(npath, v, s.confs)
+			return err == nil
 		})
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -110,20 +123,25 @@ func (s *export) getVarConf() error {
 	}
 	sc.GetVarConf().Iter(func(path string, v *conf.RawConf) bool {
 		npath := sc.GetVarConf().GetVarPath(path)
-		s.getNodes(npath, v, s.confs)
-		return true
+		err = s.getNodes(npath, v, s.confs)
+		return err == nil
 	})
-	return nil
+	return err
 }
 
-func (s *export) getNodes(path string, v *conf.RawConf, input map[string]interface{}) {
+func (s *export) getNodes(path string, v *conf.RawConf, input map[string]interface{}) error {
 	if s.encrypt {
-		input[path] = security.Encrypt(v.GetRaw())
-		return
+		value, err := security.EncryptBy(s.keyID, v.GetRaw())
+		if err != nil {
+			return fmt.Errorf("%s加密失败:%w", path, err)
+		}
+		input[path] = value
+		return nil
 	}
 	t := make(map[string]interface{})
 	json.Unmarshal(v.GetRaw(), &t)
 	input[path] = t
+	return nil
 }
 
 func (s *export) writeConf(path string) error {
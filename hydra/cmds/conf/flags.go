@@ -46,6 +46,7 @@ func getShowFlags() []cli.Flag {
 }
 
 var orgData string
+var encryptKeyID string
 
 //getEncryptFlags 获取运行时的参数
 func getEncryptFlags() []cli.Flag {
@@ -53,9 +54,14 @@ func getEncryptFlags() []cli.Flag {
 	flags = append(flags, cli.StringFlag{
 		Name:        "data",
 		Destination: &orgData,
-		Usage:       `-需要加密数据`,
+		Usage:       `-需要加密数据，已加密的数据将使用指定密钥重新加密`,
 		Required:    true,
 	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "key,k",
+		Destination: &encryptKeyID,
+		Usage:       `-加密使用的密钥编号，默认为当前密钥`,
+	})
 
 	flags = append(flags, global.ConfCli.GetFlags()...)
 	return flags
@@ -83,6 +89,11 @@ func getExportFlags() []cli.Flag {
 		Destination: &confEncrypt,
 		Usage:       `-导出配置是否进行加密`,
 	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "key,k",
+		Destination: &encryptKeyID,
+		Usage:       `-加密使用的密钥编号，默认为当前密钥`,
+	})
 	flags = append(flags, cli.StringFlag{
 		Name:        "out,o",
 		Destination: &confExportPath,
@@ -91,3 +102,32 @@ func getExportFlags() []cli.Flag {
 	flags = append(flags, global.ConfCli.GetFlags()...)
 	return flags
 }
+
+//getHistoryFlags 获取配置历史相关命令的参数
+func getHistoryFlags() []cli.Flag {
+	flags := pkgs.GetBaseFlags()
+	flags = append(flags, cli.BoolFlag{
+		Name:        "debug,d",
+		Destination: &global.FlagVal.IsDebug,
+		Usage:       `-调试模式，打印更详细的系统运行日志，避免将详细的错误信息返回给调用方`,
+	})
+	flags = append(flags, global.ConfCli.GetFlags()...)
+	return flags
+}
+
+//getRotateFlags 获取密钥轮换时的参数
+func getRotateFlags() []cli.Flag {
+	flags := pkgs.GetBaseFlags()
+	flags = append(flags, cli.BoolFlag{
+		Name:        "debug,d",
+		Destination: &global.FlagVal.IsDebug,
+		Usage:       `-调试模式，打印更详细的系统运行日志，避免将详细的错误信息返回给调用方`,
+	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "key,k",
+		Destination: &encryptKeyID,
+		Usage:       `-重新加密使用的密钥编号，默认为当前密钥`,
+	})
+	flags = append(flags, global.ConfCli.GetFlags()...)
+	return flags
+}
@@ -0,0 +1,121 @@
+package conf
+
+import (
+	"fmt"
+	"strings"
+
+	logs "github.com/lib4dev/cli/logger"
+	"github.com/micro-plat/hydra/conf/history"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/global/compatible"
+	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/lib4go/types"
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"github.com/urfave/cli"
+)
+
+func historyNow(c *cli.Context) (err error) {
+	h, err := bindHistory(c, 0)
+	if err != nil {
+		return err
+	}
+	list, err := h.List()
+	if err != nil {
+		return err
+	}
+	if len(list) == 0 {
+		fmt.Println("暂无配置历史")
+		return nil
+	}
+	for _, s := range list {
+		fmt.Printf("%-6d %-9s %s  %-24s %s  %d个节点 %s\n",
+			s.Version, s.Action, s.Time, s.Author, s.Hash[:12], len(s.Paths), s.Comment)
+	}
+	return nil
+}
+
+func diffNow(c *cli.Context) (err error) {
+	h, err := bindHistory(c, 2)
+	if err != nil {
+		return err
+	}
+	from, err := h.Get(types.GetInt64(c.Args().Get(0)))
+	if err != nil {
+		return err
+	}
+	to, err := h.Get(types.GetInt64(c.Args().Get(1)))
+	if err != nil {
+		return err
+	}
+	changes := history.Diff(from, to)
+	if len(changes) == 0 {
+		fmt.Printf("版本%d与版本%d配置相同\n", from.Version, to.Version)
+		return nil
+	}
+	for _, ch := range changes {
+		fmt.Printf("\x1b[36m%s %s\x1b[0m\n", ch.Type, ch.Path)
+		fmt.Println(diffLines(history.Indent(ch.Old), history.Indent(ch.New)))
+	}
+	return nil
+}
+
+func rollbackNow(c *cli.Context) (err error) {
+	h, err := bindHistory(c, 1)
+	if err != nil {
+		return err
+	}
+	version := types.GetInt64(c.Args().First())
+	s, err := h.Rollback(version, history.CurrentAuthor())
+	if err != nil {
+		logs.Log.Error("回滚配置:", compatible.FAILED)
+		return err
+	}
+	logs.Log.Infof("回滚系统%s的配置到版本%d(平台var配置不回滚)，生成版本%d:%s", global.Current().GetSysName(), version, s.Version, compatible.SUCCESS)
+	return nil
+}
+
+//bindHistory 绑定应用程序参数，检查版本参数个数并构建配置历史
+func bindHistory(c *cli.Context, nargs int) (*history.History, error) {
+	global.Current().Log().Pause()
+	if err := global.Def.Bind(c); err != nil {
+		cli.ShowCommandHelp(c, c.Command.Name)
+		return nil, err
+	}
+	if c.NArg() != nargs {
+		cli.ShowCommandHelp(c, c.Command.Name)
+		return nil, fmt.Errorf("需要%d个版本号参数", nargs)
+	}
+	if registry.GetProto(global.Current().GetRegistryAddr()) == registry.LocalMemory {
+		return nil, fmt.Errorf("本地内存注册中心不保存配置历史")
+	}
+	r, err := registry.GetRegistry(global.Current().GetRegistryAddr(), global.Def.Log())
+	if err != nil {
+		return nil, err
+	}
+	return history.NewHistory(r, global.Current().GetPlatName(), global.Current().GetSysName()), nil
+}
+
+//diffLines 按行比较配置，删除行以红色显示，新增行以绿色显示
+func diffLines(source string, target string) string {
+	dmp := diffmatchpatch.New()
+	a, b, lines := dmp.DiffLinesToChars(source, target)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(a, b, false), lines)
+	var builder strings.Builder
+	for _, diff := range diffs {
+		prefix, color := "  ", ""
+		switch diff.Type {
+		case diffmatchpatch.DiffDelete:
+			prefix, color = "- ", "\x1b[31m"
+		case diffmatchpatch.DiffInsert:
+			prefix, color = "+ ", "\x1b[32m"
+		}
+		for _, line := range strings.Split(strings.TrimSuffix(diff.Text, "\n"), "\n") {
+			builder.WriteString(color + prefix + line)
+			if color != "" {
+				builder.WriteString("\x1b[0m")
+			}
+			builder.WriteString("\n")
+		}
+	}
+	return strings.TrimSuffix(builder.String(), "\n")
+}
@@ -0,0 +1,125 @@
+package conf
+
+import (
+	"fmt"
+
+	logs "github.com/lib4dev/cli/logger"
+	"github.com/micro-plat/hydra/conf"
+	"github.com/micro-plat/hydra/conf/app"
+	"github.com/micro-plat/hydra/conf/history"
+	"github.com/micro-plat/hydra/conf/pkgs/security"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/global/compatible"
+	"github.com/micro-plat/hydra/registry"
+	"github.com/urfave/cli"
+)
+
+func rotateNow(c *cli.Context) (err error) {
+	//1. 绑定应用程序参数
+	global.Current().Log().Pause()
+	if err := global.Def.Bind(c); err != nil {
+		cli.ShowCommandHelp(c, c.Command.Name)
+		return err
+	}
+	if registry.GetProto(global.Current().GetRegistryAddr()) == registry.LocalMemory {
+		return fmt.Errorf("本地内存注册中心不支持密钥轮换")
+	}
+	keyID, err := getEncryptKeyID()
+	if err != nil {
+		return err
+	}
+	r, err := registry.GetRegistry(global.Current().GetRegistryAddr(), global.Def.Log())
+	if err != nil {
+		return err
+	}
+
+	//2. 获取当前系统的所有配置节点
+	paths, err := getConfPaths(r,
+		global.Current().GetPlatName(),
+		global.Current().GetSysName(),
+		global.Current().GetServerTypes(),
+		global.Current().GetClusterName(),
+	)
+	if err != nil {
+		return err
+	}
+
+	//3. 逐个节点重新加密
+	n := 0
+	for _, path := range paths {
+		b, err := rotateNode(r, path, keyID)
+		if err != nil {
+			logs.Log.Error("密钥轮换:", compatible.FAILED)
+			return err
+		}
+		if b {
+			n++
+		}
+	}
+	if n == 0 {
+		logs.Log.Info("没有需要重新加密的配置")
+		return nil
+	}
+
+	//4. 记录配置快照
+	h := history.NewHistory(r, global.Current().GetPlatName(), global.Current().GetSysName())
+	s, err := h.Record(history.ActionRotate, history.CurrentAuthor(), fmt.Sprintf("密钥轮换:%s", keyID))
+	if err != nil {
+		return fmt.Errorf("已重新加密%d个配置，记录配置快照出错:%w", n, err)
+	}
+	if s != nil {
+		logs.Log.Infof("已重新加密%d个配置，生成版本%d:%s", n, s.Version, compatible.SUCCESS)
+	}
+	return nil
+}
+
+//getConfPaths 获取系统各服务器的主配置、子配置及平台变量配置路径
+func getConfPaths(r registry.IRegistry, plat string, sysName string, types []string, cluster string) ([]string, error) {
+	paths := make([]string, 0, 8)
+	for i, tp := range types {
+		sc, err := app.NewAPPConfBy(plat, sysName, tp, cluster, r)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, sc.GetServerConf().GetServerPath())
+		sc.GetServerConf().Iter(func(path string, v *conf.RawConf) bool {
+			paths = append(paths, sc.GetServerConf().GetSubConfPath(path))
+			return true
+		})
+		if i > 0 {
+			continue
+		}
+		sc.GetVarConf().Iter(func(path string, v *conf.RawConf) bool {
+			paths = append(paths, sc.GetVarConf().GetVarPath(path))
+			return true
+		})
+	}
+	return paths, nil
+}
+
+//rotateNode 节点已加密且未使用指定密钥时重新加密，节点版本变化时返回冲突错误
+func rotateNode(r registry.IRegistry, path string, keyID string) (bool, error) {
+	data, version, err := r.GetValue(path)
+	if err != nil {
+		return false, fmt.Errorf("获取%s配置失败:%w", path, err)
+	}
+	if !security.IsEncrypted(data) || security.GetKeyID(data) == keyID {
+		return false, nil
+	}
+	raw, err := security.Decrypt(data)
+	if err != nil {
+		return false, fmt.Errorf("%s解密失败:%w", path, err)
+	}
+	value, err := security.EncryptBy(keyID, raw)
+	if err != nil {
+		return false, fmt.Errorf("%s加密失败:%w", path, err)
+	}
+	if err := r.UpdateIfVersion(path, value, version); err != nil {
+		if registry.IsConflict(err) {
+			return false, fmt.Errorf("%s配置已被修改，请重新执行密钥轮换:%w", path, err)
+		}
+		return false, fmt.Errorf("更新%s配置失败:%w", path, err)
+	}
+	logs.Log.Info("重新加密:", path, keyID)
+	return true, nil
+}
@@ -0,0 +1,97 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/lib4dev/cli/cmds"
+	"github.com/micro-plat/hydra/conf/app"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/hydra/cmds/pkgs"
+	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/hydra/services"
+	"github.com/urfave/cli"
+)
+
+var output string
+
+func init() {
+	cmds.RegisterFunc(func() cli.Command {
+		return cli.Command{
+			Name:   "openapi",
+			Usage:  "接口文档，根据已注册的服务生成OpenAPI 3文档",
+			Flags:  getFlags(),
+			Action: export,
+		}
+	})
+}
+
+//getFlags 获取运行时的参数
+func getFlags() []cli.Flag {
+	flags := pkgs.GetBaseFlags()
+	flags = append(flags, cli.BoolFlag{
+		Name:        "debug,d",
+		Destination: &global.FlagVal.IsDebug,
+		Usage:       `-调试模式，打印更详细的系统运行日志，避免将详细的错误信息返回给调用方`,
+	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "out,o",
+		Destination: &output,
+		Usage:       `-输出文件路径，未指定时输出到控制台`,
+	})
+	flags = append(flags, global.ConfCli.GetFlags()...)
+	return flags
+}
+
+func export(c *cli.Context) (err error) {
+	//1. 绑定应用程序参数
+	global.Current().Log().Pause()
+	if err := global.Def.Bind(c); err != nil {
+		cli.ShowCommandHelp(c, c.Command.Name)
+		return err
+	}
+
+	//2. 处理本地内存作为注册中心的服务发布问题
+	if registry.GetProto(global.Current().GetRegistryAddr()) == registry.LocalMemory {
+		if err := pkgs.Pub2Registry(true, ""); err != nil {
+			return err
+		}
+	}
+
+	//3. 根据服务器processor配置的servicePrefix生成文档
+	doc, err := services.Def.GetOpenAPIBy(getServicePrefix, global.Current().GetServerTypes()...)
+	if err != nil {
+		return err
+	}
+	buff, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		fmt.Fprintln(os.Stdout, string(buff))
+		return nil
+	}
+	if err := ioutil.WriteFile(output, buff, 0664); err != nil {
+		return fmt.Errorf("保存文档失败:%w", err)
+	}
+	return nil
+}
+
+//getServicePrefix 获取服务器processor配置的服务路径前缀
+func getServicePrefix(tp string) (string, error) {
+	sc, err := app.NewAPPConfBy(global.Current().GetPlatName(),
+		global.Current().GetSysName(),
+		tp,
+		global.Current().GetClusterName(),
+		registry.GetCurrent())
+	if err != nil {
+		return "", err
+	}
+	processor, err := sc.GetProcessorConf()
+	if err != nil {
+		return "", err
+	}
+	return processor.ServicePrefix, nil
+}
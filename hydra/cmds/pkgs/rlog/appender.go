@@ -152,8 +152,14 @@ func Registry(platName string, addr string) error {
 	if layout.Disable {
 		return nil
 	}
-	//注册日志组件
-	logger.AddAppender(rlog.LogName, NewRPCAppender(layout.Service))
+	//注册日志组件，文本格式写入rpc服务时保持原有输出方式
+	var appender logger.IAppender
+	if layout.GetFormat() == rlog.FormatText && layout.GetSink() == rlog.SinkRPC {
+		appender = NewRPCAppender(layout.Service)
+	} else if appender, err = NewAppender(layout); err != nil {
+		return err
+	}
+	logger.AddAppender(rlog.LogName, appender)
 	logger.AddLayout(layout.ToLoggerLayout())
 	return nil
 }
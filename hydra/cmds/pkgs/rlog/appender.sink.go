@@ -0,0 +1,165 @@
+package rlog
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/micro-plat/hydra/conf/vars/rlog"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/lib4go/logger"
+	"github.com/micro-plat/lib4go/net"
+)
+
+var localIP = net.GetLocalIPAddress()
+
+//Appender 日志输出器，日志先写入缓冲区，由后台协程批量写入输出目标。
+//缓冲区满时默认阻塞写入方，配置drop后丢弃日志并计数
+type Appender struct {
+	layout   *rlog.Layout
+	sink     ISink
+	buffer   chan *record
+	batch    int
+	interval time.Duration
+	dropped  int64
+	closing  chan struct{}
+	done     chan struct{}
+	once     sync.Once
+}
+
+//NewAppender 根据配置构建日志输出器
+func NewAppender(layout *rlog.Layout) (*Appender, error) {
+	sink, err := newSink(layout)
+	if err != nil {
+		return nil, err
+	}
+	return newAppender(layout, sink), nil
+}
+
+func newAppender(layout *rlog.Layout, sink ISink) *Appender {
+	a := &Appender{
+		layout:   layout,
+		sink:     sink,
+		buffer:   make(chan *record, getInt(layout.BufferSize, 10000)),
+		batch:    getInt(layout.BatchSize, 100),
+		interval: time.Duration(getInt(layout.FlushInterval, 1000)) * time.Millisecond,
+		closing:  make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go a.loop()
+	return a
+}
+
+//Write 写入日志
+func (a *Appender) Write(layout *logger.Layout, event *logger.LogEvent) error {
+	if event.IsClose() {
+		return nil
+	}
+	if logger.GetLevel(layout.Level) > logger.GetLevel(event.Level) {
+		return nil
+	}
+	r := &record{level: event.Level, data: a.encode(event)}
+	if a.layout.Drop {
+		select {
+		case a.buffer <- r:
+		default:
+			atomic.AddInt64(&a.dropped, 1)
+		}
+		return nil
+	}
+	select {
+	case a.buffer <- r:
+	case <-a.closing:
+		atomic.AddInt64(&a.dropped, 1)
+	}
+	return nil
+}
+
+//Dropped 获取因缓冲区满或已关闭而丢弃的日志数
+func (a *Appender) Dropped() int64 {
+	return atomic.LoadInt64(&a.dropped)
+}
+
+//Close 写入缓冲区中的所有日志并关闭输出目标
+func (a *Appender) Close() error {
+	a.once.Do(func() {
+		close(a.closing)
+		<-a.done
+		a.sink.Close()
+	})
+	return nil
+}
+
+//loop 达到批量条数或提交间隔时写入输出目标
+func (a *Appender) loop() {
+	defer close(a.done)
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+	records := make([]*record, 0, a.batch)
+	flush := func() {
+		if len(records) == 0 {
+			return
+		}
+		if err := a.sink.Write(records); err != nil {
+			logger.SysLog.Errorf("未正确写入日志:%v", err)
+		}
+		records = make([]*record, 0, a.batch)
+	}
+	for {
+		select {
+		case r := <-a.buffer:
+			records = append(records, r)
+			if len(records) >= a.batch {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-a.closing:
+			for {
+				select {
+				case r := <-a.buffer:
+					records = append(records, r)
+					if len(records) >= a.batch {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+//encode 转换日志记录，json格式时输出日志信息与扩展字段，否则使用layout模板输出的内容
+func (a *Appender) encode(event *logger.LogEvent) []byte {
+	if a.layout.GetFormat() != rlog.FormatJSON {
+		return []byte(strings.TrimRight(event.Output, "\n"))
+	}
+	data := map[string]interface{}{}
+	for k, v := range rlog.GetFields(event.Tags) {
+		data[k] = v
+	}
+	data["time"] = event.Now.Format("2006-01-02 15:04:05.000000")
+	data["level"] = strings.ToLower(event.Level)
+	data["name"] = event.Name
+	data["session"] = event.Session
+	data["content"] = event.Content
+	data["server_ip"] = localIP
+	data["plat"] = global.Def.PlatName
+	data["system"] = global.Def.SysName
+	buff, err := json.Marshal(data)
+	if err != nil {
+		buff, _ = json.Marshal(map[string]string{"level": strings.ToLower(event.Level), "content": event.Content})
+	}
+	return buff
+}
+
+func getInt(v int, def int) int {
+	if v <= 0 {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,189 @@
+package rlog
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/micro-plat/hydra/conf/vars/rlog"
+	"github.com/micro-plat/lib4go/assert"
+	"github.com/micro-plat/lib4go/logger"
+)
+
+//memSink 测试用输出目标，记录每批写入的日志
+type memSink struct {
+	lock    sync.Mutex
+	batches [][]*record
+	block   chan struct{}
+}
+
+func (s *memSink) Write(records []*record) error {
+	if s.block != nil {
+		<-s.block
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.batches = append(s.batches, records)
+	return nil
+}
+
+func (s *memSink) Close() error {
+	return nil
+}
+
+func (s *memSink) count() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return len(s.batches)
+}
+
+func (s *memSink) records() []*record {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	list := make([]*record, 0, 1)
+	for _, b := range s.batches {
+		list = append(list, b...)
+	}
+	return list
+}
+
+func newEvent(level string, content string, fields rlog.Fields) *logger.LogEvent {
+	tags := map[string]string{}
+	if fields != nil {
+		tags[rlog.FieldsTag] = fields.Encode()
+	}
+	e := logger.NewLogEvent("api", level, "a1b2c3", content, tags, 1)
+	return e.Event(rlog.DefaultLayout)
+}
+
+var loggerLayout = &logger.Layout{Type: rlog.LogName, Level: "Info"}
+
+func TestAppender_JSON(t *testing.T) {
+	sink := &memSink{}
+	a := newAppender(rlog.New("", rlog.WithJSON(), rlog.WithBatch(2, 10000)), sink)
+
+	a.Write(loggerLayout, newEvent("Info", "请求成功", rlog.Fields{rlog.FieldTraceID: "a1b2c3", rlog.FieldService: "/order/query", rlog.FieldUser: "colin"}))
+	a.Write(loggerLayout, newEvent("Debug", "低于配置级别", nil))
+	a.Write(loggerLayout, newEvent("Error", "请求失败", nil))
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 1, sink.count(), "达到批量条数时写入")
+
+	a.Write(loggerLayout, newEvent("Warn", "未满批量", nil))
+	a.Close()
+	records := sink.records()
+	assert.Equal(t, 3, len(records), "关闭时写入剩余日志")
+
+	data := map[string]string{}
+	assert.Equal(t, nil, json.Unmarshal(records[0].data, &data), "json格式")
+	assert.Equal(t, "请求成功", data["content"], "日志内容")
+	assert.Equal(t, "info", data["level"], "日志级别")
+	assert.Equal(t, "a1b2c3", data[rlog.FieldTraceID], "链路编号")
+	assert.Equal(t, "/order/query", data[rlog.FieldService], "服务")
+	assert.Equal(t, "colin", data[rlog.FieldUser], "用户")
+	assert.Equal(t, "Error", records[1].level, "记录日志级别")
+}
+
+func TestAppender_Text(t *testing.T) {
+	sink := &memSink{}
+	a := newAppender(rlog.New(""), sink)
+	a.Write(loggerLayout, newEvent("Info", "请求成功", nil))
+	a.Close()
+	records := sink.records()
+	assert.Equal(t, 1, len(records), "写入日志")
+	data := map[string]string{}
+	assert.Equal(t, nil, json.Unmarshal(records[0].data, &data), "按layout模板输出")
+	assert.Equal(t, "请求成功", data["content"], "日志内容")
+}
+
+func TestAppender_Drop(t *testing.T) {
+	sink := &memSink{block: make(chan struct{})}
+	a := newAppender(rlog.New("", rlog.WithBuffer(2, true), rlog.WithBatch(1, 10000)), sink)
+
+	//第一条日志被后台协程取出后阻塞在输出目标，缓冲区可再容纳2条
+	a.Write(loggerLayout, newEvent("Info", "1", nil))
+	time.Sleep(50 * time.Millisecond)
+	for i := 0; i < 5; i++ {
+		a.Write(loggerLayout, newEvent("Info", "n", nil))
+	}
+	assert.Equal(t, int64(3), a.Dropped(), "缓冲区满时丢弃")
+	close(sink.block)
+	a.Close()
+	assert.Equal(t, 3, len(sink.records()), "写入未丢弃的日志")
+}
+
+func TestAppender_Block(t *testing.T) {
+	sink := &memSink{block: make(chan struct{})}
+	a := newAppender(rlog.New("", rlog.WithBuffer(1, false), rlog.WithBatch(1, 10000)), sink)
+	a.Write(loggerLayout, newEvent("Info", "1", nil))
+	time.Sleep(50 * time.Millisecond)
+	a.Write(loggerLayout, newEvent("Info", "2", nil))
+
+	written := make(chan struct{})
+	go func() {
+		a.Write(loggerLayout, newEvent("Info", "3", nil))
+		close(written)
+	}()
+	select {
+	case <-written:
+		t.Fatal("缓冲区满时应阻塞写入")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(sink.block)
+	<-written
+	a.Close()
+	assert.Equal(t, int64(0), a.Dropped(), "阻塞时不丢弃日志")
+	assert.Equal(t, 3, len(sink.records()), "写入所有日志")
+}
+
+func TestFileSink_Rotate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rlog")
+	assert.Equal(t, nil, err, "创建临时目录")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "logs", "app.log")
+	s, err := newFileSink(path, 1, 2)
+	assert.Equal(t, nil, err, "创建文件输出")
+	s.maxSize = 20
+
+	for i := 0; i < 4; i++ {
+		err = s.Write([]*record{{level: "Info", data: []byte(`{"content":"12345"}`)}})
+		assert.Equal(t, nil, err, "写入文件")
+	}
+	s.Close()
+	for _, p := range []string{path, path + ".1", path + ".2"} {
+		buff, err := ioutil.ReadFile(p)
+		assert.Equal(t, nil, err, "读取文件"+p)
+		assert.Equal(t, "{\"content\":\"12345\"}\n", string(buff), "每个文件一行日志")
+	}
+	_, err = os.Stat(path + ".3")
+	assert.Equal(t, true, os.IsNotExist(err), "超出保留个数的文件被删除")
+}
+
+func TestHTTPSink_Write(t *testing.T) {
+	lines := make([]string, 0, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+	}))
+	defer srv.Close()
+
+	s := newHTTPSink(srv.URL)
+	err := s.Write([]*record{{data: []byte(`{"a":1}`)}, {data: []byte(`{"a":2}`)}})
+	assert.Equal(t, nil, err, "批量提交")
+	assert.Equal(t, []string{`{"a":1}`, `{"a":2}`}, lines, "按行提交")
+
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	err = s.Write([]*record{{data: []byte(`{"a":3}`)}})
+	assert.NotEqual(t, nil, err, "服务返回错误")
+}
@@ -0,0 +1,87 @@
+package rlog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+//fileSink 按行写入本地文件，文件超过maxSize时滚动为path.1,path.2...
+type fileSink struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+	lock       sync.Mutex
+}
+
+func newFileSink(path string, maxSize int, maxBackups int) (*fileSink, error) {
+	if maxSize <= 0 {
+		maxSize = 100
+	}
+	s := &fileSink{path: path, maxSize: int64(maxSize) * 1024 * 1024, maxBackups: maxBackups}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *fileSink) Write(records []*record) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, r := range records {
+		if s.size > 0 && s.size+int64(len(r.data))+1 > s.maxSize {
+			if err := s.rotate(); err != nil {
+				return err
+			}
+		}
+		n, err := s.file.Write(append(r.data, '\n'))
+		s.size += int64(n)
+		if err != nil {
+			return fmt.Errorf("写入日志文件%s失败:%w", s.path, err)
+		}
+	}
+	return nil
+}
+
+func (s *fileSink) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.file.Close()
+}
+
+func (s *fileSink) open() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("创建日志目录失败:%w", err)
+	}
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("打开日志文件%s失败:%w", s.path, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	s.file, s.size = f, info.Size()
+	return nil
+}
+
+//rotate 关闭当前文件并依次重命名历史文件，超出maxBackups的文件被删除
+func (s *fileSink) rotate() error {
+	s.file.Close()
+	if s.maxBackups <= 0 {
+		os.Remove(s.path)
+		return s.open()
+	}
+	os.Remove(fmt.Sprintf("%s.%d", s.path, s.maxBackups))
+	for i := s.maxBackups - 1; i > 0; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", s.path, i), fmt.Sprintf("%s.%d", s.path, i+1))
+	}
+	if err := os.Rename(s.path, s.path+".1"); err != nil {
+		return fmt.Errorf("滚动日志文件%s失败:%w", s.path, err)
+	}
+	return s.open()
+}
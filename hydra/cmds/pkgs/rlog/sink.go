@@ -0,0 +1,72 @@
+package rlog
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/micro-plat/hydra/components"
+	"github.com/micro-plat/hydra/components/rpcs/rpc"
+	"github.com/micro-plat/hydra/conf/vars/rlog"
+	"github.com/micro-plat/hydra/global"
+)
+
+//record 待输出的日志记录
+type record struct {
+	level string
+	data  []byte
+}
+
+//ISink 日志输出目标，批量写入日志记录
+type ISink interface {
+	Write(records []*record) error
+	Close() error
+}
+
+//newSink 根据配置构建日志输出目标
+func newSink(layout *rlog.Layout) (ISink, error) {
+	if err := layout.Check(); err != nil {
+		return nil, err
+	}
+	switch layout.GetSink() {
+	case rlog.SinkRPC:
+		return &rpcSink{service: layout.Service}, nil
+	case rlog.SinkFile:
+		return newFileSink(layout.Path, layout.MaxSize, layout.MaxBackups)
+	case rlog.SinkSyslog:
+		return newSyslogSink(layout.Address)
+	case rlog.SinkHTTP:
+		return newHTTPSink(layout.Address), nil
+	default:
+		return nil, fmt.Errorf("不支持的日志输出目标:%s", layout.Sink)
+	}
+}
+
+//rpcSink 将日志以json数组提交到rpc服务
+type rpcSink struct {
+	service string
+}
+
+func (s *rpcSink) Write(records []*record) error {
+	var buff bytes.Buffer
+	buff.WriteString("[")
+	for i, r := range records {
+		if i > 0 {
+			buff.WriteString(",")
+		}
+		buff.Write(r.data)
+	}
+	buff.WriteString("]")
+	_, err := components.Def.RPC().GetRegularRPC().Request(
+		s.service,
+		buff.String(),
+		rpc.WithHeader("Plat", global.Def.PlatName),
+		rpc.WithHeader("System", global.Def.SysName))
+	if err != nil {
+		return fmt.Errorf("rlog写入日志失败 %s %w", s.service, err)
+	}
+	return nil
+}
+
+func (s *rpcSink) Close() error {
+	return nil
+}
@@ -0,0 +1,42 @@
+package rlog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+//httpSink 以json lines格式批量提交到http服务
+type httpSink struct {
+	url    string
+	client *http.Client
+}
+
+func newHTTPSink(url string) *httpSink {
+	return &httpSink{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (s *httpSink) Write(records []*record) error {
+	var buff bytes.Buffer
+	for _, r := range records {
+		buff.Write(r.data)
+		buff.WriteByte('\n')
+	}
+	resp, err := s.client.Post(s.url, "application/x-ndjson", &buff)
+	if err != nil {
+		return fmt.Errorf("rlog提交日志失败 %s %w", s.url, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("rlog提交日志失败 %s 状态码:%d", s.url, resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *httpSink) Close() error {
+	return nil
+}
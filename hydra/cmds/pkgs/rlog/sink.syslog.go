@@ -0,0 +1,60 @@
+// +build !windows,!plan9
+
+package rlog
+
+import (
+	"log/syslog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/micro-plat/lib4go/logger"
+)
+
+//syslogSink 写入syslog，按日志级别设置优先级
+type syslogSink struct {
+	writer *syslog.Writer
+}
+
+//newSyslogSink 地址为空时写入本机syslog，否则格式为network://host:port
+func newSyslogSink(address string) (ISink, error) {
+	network, raddr := "", ""
+	if address != "" {
+		parts := strings.SplitN(address, "://", 2)
+		network, raddr = "udp", parts[0]
+		if len(parts) == 2 {
+			network, raddr = parts[0], parts[1]
+		}
+	}
+	w, err := syslog.Dial(network, raddr, syslog.LOG_INFO|syslog.LOG_LOCAL0, filepath.Base(os.Args[0]))
+	if err != nil {
+		return nil, err
+	}
+	return &syslogSink{writer: w}, nil
+}
+
+func (s *syslogSink) Write(records []*record) error {
+	for _, r := range records {
+		var err error
+		switch r.level {
+		case logger.SLevel_Debug:
+			err = s.writer.Debug(string(r.data))
+		case logger.SLevel_Warn:
+			err = s.writer.Warning(string(r.data))
+		case logger.SLevel_Error:
+			err = s.writer.Err(string(r.data))
+		case logger.SLevel_Fatal:
+			err = s.writer.Crit(string(r.data))
+		default:
+			err = s.writer.Info(string(r.data))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *syslogSink) Close() error {
+	return s.writer.Close()
+}
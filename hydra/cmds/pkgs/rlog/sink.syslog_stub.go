@@ -0,0 +1,9 @@
+// +build windows plan9
+
+package rlog
+
+import "fmt"
+
+func newSyslogSink(address string) (ISink, error) {
+	return nil, fmt.Errorf("当前系统不支持syslog")
+}
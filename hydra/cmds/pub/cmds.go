@@ -10,9 +10,13 @@ type cmd string
 var cmdCD cmd = "cd @path"
 var cmdMkdir cmd = "mkdir @path"
 var cmdRm cmd = "rm -rf @path"
+var cmdNow cmd = "date +%s"
 var cmdRunScript cmd = `sh @path @temp_file @bin_name @project_path "@install_params"`
 
-func (c cmd) CMD(path string) string {
+//cmdRestore 使用最近一次发布时备份的程序替换当前程序并重启服务
+var cmdRestore cmd = `BIN=@bin_name; cd ~/@project_path/bin || exit 1; bak=$(ls -t ${BIN}_[0-9]* 2>/dev/null | head -n 1); if [ -z "$bak" ]; then echo "未找到${BIN}的备份文件"; exit 1; fi; ./${BIN} stop; mv -f $bak ${BIN} && chmod 755 ${BIN} && ./${BIN} start`
+
+func (c cmd) CMD(client *sshClient, path string) string {
 	ps := make([]interface{}, 0, 12)
 	ps = append(ps, "name")
 	ps = append(ps, global.AppName)
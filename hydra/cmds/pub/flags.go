@@ -1,25 +1,64 @@
 package pub
 
 import (
+	"github.com/micro-plat/hydra/hydra/cmds/pkgs"
 	"github.com/urfave/cli"
 )
 
 var runInstall = ""
 var pwd string
+var inventory string
+var batchSize = 1
+var healthPath = "/"
+var healthTimeout = 60
 
 //getFlags 获取运行时的参数
 func getFlags() []cli.Flag {
-	flags := make([]cli.Flag, 0, 1)
+	flags := getHostFlags()
 	flags = append(flags, cli.StringFlag{
 		Name:        "install,i",
 		Destination: &runInstall,
 		Usage:       `-按需执行install命令`,
 	})
+	flags = append(flags, cli.IntFlag{
+		Name:        "batch,b",
+		Destination: &batchSize,
+		Value:       batchSize,
+		Usage:       `-每批发布的服务器数量，当前批次通过健康检查后发布下一批`,
+	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "health-path",
+		Destination: &healthPath,
+		Value:       healthPath,
+		Usage:       `-http服务健康检查地址，返回状态码小于500视为健康`,
+	})
+	flags = append(flags, cli.IntFlag{
+		Name:        "health-timeout",
+		Destination: &healthTimeout,
+		Value:       healthTimeout,
+		Usage:       `-等待服务注册并通过健康检查的超时时长(秒)`,
+	})
+	flags = append(flags, pkgs.GetBaseFlags()...)
+	return flags
+}
+
+//getRollbackFlags 获取回滚时的参数
+func getRollbackFlags() []cli.Flag {
+	return getHostFlags()
+}
+
+//getHostFlags 获取远程服务器参数
+func getHostFlags() []cli.Flag {
+	flags := make([]cli.Flag, 0, 1)
 	flags = append(flags, cli.StringFlag{
 		Name:        "pwd",
 		Destination: &pwd,
-		Usage:       `-远程服务器密码`,
-		Required:    true,
+		Usage:       `-远程服务器密码，服务器清单中未指定密码时使用`,
+	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "inventory,f",
+		Destination: &inventory,
+		Usage:       `-服务器清单文件，每行一个服务器,格式:userName@ip[:port]:/path [pwd]`,
 	})
 	return flags
 }
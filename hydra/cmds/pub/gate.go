@@ -0,0 +1,118 @@
+package pub
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/registry"
+)
+
+//gate 健康检查，等待服务器上的新服务注册到注册中心并通过健康检查
+type gate struct {
+	r          registry.IRegistry
+	paths      []string
+	healthPath string
+	timeout    time.Duration
+	interval   time.Duration
+	client     *http.Client
+}
+
+func newGate(r registry.IRegistry, paths []string, healthPath string, timeout time.Duration) *gate {
+	return &gate{
+		r:          r,
+		paths:      paths,
+		healthPath: healthPath,
+		timeout:    timeout,
+		interval:   time.Second,
+		client:     &http.Client{Timeout: 3 * time.Second},
+	}
+}
+
+//Wait 等待服务器ip在since(服务器时间)之后注册的所有服务通过健康检查
+func (g *gate) Wait(ip string, since int64) error {
+	deadline := time.Now().Add(g.timeout)
+	for {
+		err := g.check(ip, since)
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("服务器%s未通过健康检查:%w", ip, err)
+		}
+		time.Sleep(g.interval)
+	}
+}
+
+func (g *gate) check(ip string, since int64) error {
+	for _, path := range g.paths {
+		addr, err := g.getAddr(path, ip, since)
+		if err != nil {
+			return err
+		}
+		if err := g.health(addr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//getAddr 获取服务器发布到集群节点的服务地址
+func (g *gate) getAddr(path string, ip string, since int64) (string, error) {
+	children, _, err := g.r.GetChildren(path)
+	if err != nil {
+		return "", err
+	}
+	for _, c := range children {
+		buff, _, err := g.r.GetValue(registry.Join(path, c))
+		if err != nil {
+			continue
+		}
+		node := struct {
+			Addr string `json:"addr"`
+			Time int64  `json:"time"`
+		}{}
+		if err := json.Unmarshal(buff, &node); err != nil || node.Time < since {
+			continue
+		}
+		_, addr, err := global.ParseProto(node.Addr)
+		if err != nil {
+			continue
+		}
+		if host, _, err := net.SplitHostPort(strings.TrimPrefix(addr, "/")); err == nil && host == ip {
+			return node.Addr, nil
+		}
+	}
+	return "", fmt.Errorf("服务未注册到%s", path)
+}
+
+//health 检查服务是否可用，http服务请求健康检查地址，其它服务检查端口是否可连接
+func (g *gate) health(addr string) error {
+	proto, raddr, err := global.ParseProto(addr)
+	if err != nil {
+		return err
+	}
+	raddr = strings.TrimPrefix(raddr, "/")
+	switch proto {
+	case "http", "https":
+		resp, err := g.client.Get(fmt.Sprintf("%s://%s/%s", proto, raddr, strings.TrimPrefix(g.healthPath, "/")))
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+			return fmt.Errorf("%s健康检查返回状态码%d", addr, resp.StatusCode)
+		}
+		return nil
+	default:
+		conn, err := net.DialTimeout("tcp", raddr, 3*time.Second)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+}
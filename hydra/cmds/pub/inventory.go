@@ -0,0 +1,66 @@
+package pub
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+//getHosts 根据命令行参数与服务器清单文件构建远程服务器列表
+func getHosts(args []string, inventory string, localPath string, pwd string) ([]*sshClient, error) {
+	lines := make([]string, 0, len(args))
+	lines = append(lines, args...)
+	if inventory != "" {
+		list, err := readInventory(inventory)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, list...)
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("未指定远程服务器信息")
+	}
+
+	hosts := make([]*sshClient, 0, len(lines))
+	exists := make(map[string]bool)
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		hpwd := pwd
+		if len(fields) > 1 {
+			hpwd = fields[1]
+		}
+		s := &sshClient{}
+		if err := s.Bind(fields[0], localPath, hpwd); err != nil {
+			return nil, err
+		}
+		if exists[s.String()] {
+			return nil, fmt.Errorf("服务器%s重复", s.String())
+		}
+		exists[s.String()] = true
+		hosts = append(hosts, s)
+	}
+	return hosts, nil
+}
+
+//readInventory 读取服务器清单，忽略空行与#开头的注释行
+func readInventory(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开服务器清单文件失败%w", err)
+	}
+	defer f.Close()
+	lines := make([]string, 0, 1)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取服务器清单文件失败%w", err)
+	}
+	return lines, nil
+}
@@ -1,19 +1,34 @@
 package pub
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/lib4dev/cli/cmds"
+	"github.com/micro-plat/hydra/conf/server"
 	"github.com/micro-plat/hydra/global"
 	"github.com/micro-plat/hydra/global/compatible"
+	"github.com/micro-plat/hydra/registry"
 	"github.com/urfave/cli"
 )
 
 func init() {
 	cmds.RegisterFunc(func() cli.Command {
 		return cli.Command{
-			Name:   "pub",
-			Usage:  "远程发布服务，将当前应用发布到远程服务器，并启动",
-			Flags:  getFlags(),
-			Action: doPub,
+			Name:      "pub",
+			Usage:     "远程发布服务，将当前应用分批发布到远程服务器，并启动",
+			ArgsUsage: "[userName@ip[:port]:/path ...]",
+			Flags:     getFlags(),
+			Action:    doPub,
+			Subcommands: []cli.Command{
+				{
+					Name:      "rollback",
+					Usage:     "-回滚服务，使用最近一次发布前备份的程序恢复服务",
+					ArgsUsage: "[userName@ip[:port]:/path ...]",
+					Flags:     getRollbackFlags(),
+					Action:    doRollback,
+				},
+			},
 		}
 	})
 }
@@ -27,41 +42,40 @@ func doPub(c *cli.Context) (err error) {
 	}
 
 	//2.绑定请求参数
-	if err = client.Bind(c.Args().Get(0), global.AppName, pwd); err != nil {
+	if err := global.Def.Bind(c); err != nil {
+		cli.ShowCommandHelp(c, c.Command.Name)
 		return err
 	}
-
-	//3.登录到远程服务器
-	if err = client.Login(); err != nil {
+	hosts, err := getHosts(c.Args(), inventory, global.AppName, pwd)
+	if err != nil {
 		return err
 	}
 
-	//4.切换工作目录
-	defer client.Close()
-	if err := client.GoWorkDir(); err != nil {
+	//3.构建健康检查
+	g, err := getGate()
+	if err != nil {
 		return err
 	}
 
-	//5. 上传文件
-	if err := client.UploadFile(); err != nil {
-		return err
-	}
+	//4.分批发布
+	return newRolling(hosts, batchSize, g).Run()
+}
 
-	//6.上传脚本
-	path, err := client.UploadScript()
-	if err != nil {
-		return err
+//getGate 根据注册中心构建健康检查，本地内存注册中心无法获取远程服务时不检查
+func getGate() (*gate, error) {
+	addr := global.Current().GetRegistryAddr()
+	if registry.GetProto(addr) == registry.LocalMemory {
+		fmt.Println("未指定注册中心，发布后不进行健康检查")
+		return nil, nil
 	}
-
-	//7. 执行脚本
-	if err := client.ExecScript(path); err != nil {
-		return err
+	r, err := registry.GetRegistry(addr, global.Def.Log())
+	if err != nil {
+		return nil, err
 	}
-
-	//8.删除文件
-	if err := client.RmWorkDir(); err != nil {
-		return err
+	paths := make([]string, 0, len(global.Current().GetServerTypes()))
+	for _, tp := range global.Current().GetServerTypes() {
+		pub := server.NewServerPub(global.Current().GetPlatName(), global.Current().GetSysName(), tp, global.Current().GetClusterName())
+		paths = append(paths, pub.GetServerPubPath())
 	}
-
-	return nil
+	return newGate(r, paths, healthPath, time.Duration(healthTimeout)*time.Second), nil
 }
@@ -138,8 +138,8 @@ if [ ! -d ${BIN_DIR} ];then
 	if [ $? -gt 0 ]
 	then  
 		curstep=1
-		rollabck
-		exit
+		rollback
+		exit 1
 	fi  
 	sleep 1
 fi
@@ -161,7 +161,7 @@ if [ -f ${BIN_NAME} ];then
 	checksucc "${stop_tips}" "OK" "installed"
 	if [ $? -gt 0 ]
 	then 
-		exit
+		exit 1
 	fi  
 	sleep 1
 	
@@ -178,8 +178,8 @@ cp /tmp/${TMP_FILE} ./${BIN_NAME}
 if [ $? -gt 0 ]
 then  
 	curstep=3
-	rollabck
-	exit
+	rollback
+	exit 1
 fi 
 
 
@@ -200,7 +200,7 @@ if [ -n "${INSTALL_PARAMS}" ] ; then
 	checksucc "${backup_tips}" "OK" "installed"
 	if [ $? -gt 0 ]
 	then
-		exit
+		exit 1
 	fi
 
 	echo "5.1 执行remove指令:./${BIN_NAME} remove"
@@ -212,7 +212,7 @@ if [ -n "${INSTALL_PARAMS}" ] ; then
 	checksucc "${remove_tips}" "OK" "installed"
 	if [ $? -gt 0 ]
 	then 
-		exit
+		exit 1
 	fi  
 	sleep 1
 		
@@ -224,7 +224,7 @@ if [ -n "${INSTALL_PARAMS}" ] ; then
 	checksucc "${install_tips}" "OK"
 	if [ $? -gt 0 ]
 	then 
-		exit
+		exit 1
 	fi  
  
 	sleep 1
@@ -237,7 +237,7 @@ curstep=7
 checksucc "${start_tips}" "OK"
 if [ $? -gt 0 ]
 then 
-	exit
+	exit 1
 fi  
 
 if [ -f $newfile ] ; then
@@ -0,0 +1,25 @@
+package pub
+
+import (
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/global/compatible"
+	"github.com/urfave/cli"
+)
+
+func doRollback(c *cli.Context) (err error) {
+
+	//1.检查是否有管理员权限
+	global.Current().Log().Pause()
+	if err = compatible.CheckPrivileges(); err != nil {
+		return err
+	}
+
+	//2.绑定请求参数
+	hosts, err := getHosts(c.Args(), inventory, global.AppName, pwd)
+	if err != nil {
+		return err
+	}
+
+	//3.恢复所有服务器
+	return restoreAll(hosts)
+}
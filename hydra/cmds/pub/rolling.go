@@ -0,0 +1,174 @@
+package pub
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/micro-plat/lib4go/types"
+)
+
+//rolling 分批发布，每批服务器发布完成并通过健康检查后再发布下一批，
+//任一服务器失败时停止发布，并将已发布的服务器恢复为发布前的程序
+type rolling struct {
+	hosts []*sshClient
+	batch int
+	gate  *gate
+}
+
+func newRolling(hosts []*sshClient, batch int, g *gate) *rolling {
+	if batch <= 0 {
+		batch = 1
+	}
+	return &rolling{hosts: hosts, batch: batch, gate: g}
+}
+
+//Run 执行分批发布
+func (r *rolling) Run() error {
+	deployed := make([]*sshClient, 0, len(r.hosts))
+	for i := 0; i < len(r.hosts); i += r.batch {
+		end := i + r.batch
+		if end > len(r.hosts) {
+			end = len(r.hosts)
+		}
+		hosts := r.hosts[i:end]
+		fmt.Printf("发布第%d批服务器:%s\n", i/r.batch+1, joinHosts(hosts))
+
+		//只记录发布成功的服务器，发布失败的服务器未替换程序，无需恢复
+		var lock sync.Mutex
+		err := r.each(hosts, func(s *sshClient) error {
+			if err := s.Deploy(); err != nil {
+				return err
+			}
+			lock.Lock()
+			defer lock.Unlock()
+			deployed = append(deployed, s)
+			return nil
+		})
+		if err == nil && r.gate != nil {
+			err = r.each(hosts, func(s *sshClient) error {
+				return r.gate.Wait(s.ip, s.deployTime)
+			})
+		}
+		if err != nil && len(deployed) == 0 {
+			return err
+		}
+		if err != nil {
+			fmt.Printf("发布失败，恢复已发布的服务器:%s\n", joinHosts(deployed))
+			if rerr := restoreAll(deployed); rerr != nil {
+				return fmt.Errorf("%w,恢复失败:%v", err, rerr)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+//each 并行处理同一批服务器
+func (r *rolling) each(hosts []*sshClient, f func(s *sshClient) error) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(hosts))
+	for i, s := range hosts {
+		wg.Add(1)
+		go func(i int, s *sshClient) {
+			defer wg.Done()
+			if err := f(s); err != nil {
+				errs[i] = fmt.Errorf("%s:%w", s.String(), err)
+			}
+		}(i, s)
+	}
+	wg.Wait()
+	return joinErrs(errs)
+}
+
+//restoreAll 恢复所有服务器
+func restoreAll(hosts []*sshClient) error {
+	errs := make([]error, 0, len(hosts))
+	for _, s := range hosts {
+		if err := s.Restore(); err != nil {
+			errs = append(errs, fmt.Errorf("%s:%w", s.String(), err))
+		}
+	}
+	return joinErrs(errs)
+}
+
+//Deploy 上传程序与发布脚本并执行
+func (s *sshClient) Deploy() error {
+	if err := s.Login(); err != nil {
+		return err
+	}
+	defer s.Close()
+
+	//读取服务器时间，健康检查时与服务器注册的时间比较
+	if err := s.readTime(); err != nil {
+		return err
+	}
+
+	//切换工作目录
+	if err := s.GoWorkDir(); err != nil {
+		return err
+	}
+
+	//上传文件
+	if err := s.UploadFile(); err != nil {
+		return err
+	}
+
+	//上传脚本
+	path, err := s.UploadScript()
+	if err != nil {
+		return err
+	}
+
+	//执行脚本
+	if err := s.ExecScript(path); err != nil {
+		return err
+	}
+
+	//删除文件
+	return s.RmWorkDir()
+}
+
+//Restore 使用发布时备份的程序恢复服务
+func (s *sshClient) Restore() error {
+	if err := s.Login(); err != nil {
+		return err
+	}
+	defer s.Close()
+	return s.run(cmdRestore.CMD(s, ""))
+}
+
+//readTime 读取服务器当前时间
+func (s *sshClient) readTime() error {
+	out, err := s.output(string(cmdNow))
+	if err != nil {
+		return err
+	}
+	t := types.GetInt64(strings.TrimSpace(out), -1)
+	if t < 0 {
+		return fmt.Errorf("读取服务器时间失败:%s", out)
+	}
+	s.deployTime = t
+	return nil
+}
+
+func joinHosts(hosts []*sshClient) string {
+	list := make([]string, 0, len(hosts))
+	for _, s := range hosts {
+		list = append(list, s.String())
+	}
+	return strings.Join(list, ",")
+}
+
+func joinErrs(errs []error) error {
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		if err != nil {
+			msgs = append(msgs, err.Error())
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s", strings.Join(msgs, ";"))
+}
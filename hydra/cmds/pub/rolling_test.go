@@ -0,0 +1,202 @@
+package pub
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/hydra/registry/registry/localmemory"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+const testServerPath = "/hydra/pub/api/prod/servers"
+
+//testNode 测试服务器，发布脚本执行后将服务注册到注册中心，offset为服务器时间与本地时间的差值
+type testNode struct {
+	sshd   *testSSHD
+	http   *httptest.Server
+	offset time.Duration
+	failed bool
+}
+
+func newTestNode(t *testing.T, r registry.IRegistry, ip string, status int) *testNode {
+	ln, err := net.Listen("tcp", net.JoinHostPort(ip, "0"))
+	if err != nil {
+		t.Skipf("无法监听地址%s:%v", ip, err)
+	}
+	h := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	h.Listener.Close()
+	h.Listener = ln
+	h.Start()
+
+	n := &testNode{http: h}
+	n.sshd = newTestSSHD(t, ip, "123456", func(cmd string) (string, int) {
+		now := time.Now().Add(n.offset)
+		switch {
+		case cmd == string(cmdNow):
+			return fmt.Sprintf("%d\n", now.Unix()), 0
+		case strings.HasPrefix(cmd, "sh ") && strings.Contains(cmd, "pub.sh"):
+			if n.failed {
+				return "发布失败", 1
+			}
+			buff, _ := json.Marshal(map[string]interface{}{"addr": h.URL, "time": now.Unix()})
+			r.CreatePersistentNode(registry.Join(testServerPath, fmt.Sprintf("%s_%d", ip, now.UnixNano())), string(buff))
+		}
+		return "", 0
+	})
+	return n
+}
+
+func (n *testNode) Close() {
+	n.sshd.Close()
+	n.http.Close()
+}
+
+//newTestBin 在临时目录中创建待发布的程序，并切换到该目录
+func newTestBin(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "hydra_pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "hydra_app"), []byte("hydra test binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	wd, _ := os.Getwd()
+	os.Chdir(dir)
+	return "hydra_app", func() {
+		os.Chdir(wd)
+		os.RemoveAll(dir)
+	}
+}
+
+func newTestGate(r registry.IRegistry) *gate {
+	g := newGate(r, []string{testServerPath}, "/health", 300*time.Millisecond)
+	g.interval = 50 * time.Millisecond
+	return g
+}
+
+func TestRolling_Run(t *testing.T) {
+	bin, clear := newTestBin(t)
+	defer clear()
+
+	r := localmemory.NewLocalMemory()
+	nodes := []*testNode{
+		newTestNode(t, r, "127.0.0.2", http.StatusOK),
+		newTestNode(t, r, "127.0.0.3", http.StatusOK),
+		newTestNode(t, r, "127.0.0.4", http.StatusOK),
+	}
+	//服务器时间比本地慢，注册时间早于本地发布时间
+	nodes[1].offset = -time.Hour
+	args := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		defer n.Close()
+		args = append(args, n.sshd.Host("root", "/home/hydra"))
+	}
+
+	hosts, err := getHosts(args, "", bin, "123456")
+	assert.Equal(t, nil, err, "构建服务器列表")
+	err = newRolling(hosts, 2, newTestGate(r)).Run()
+	assert.Equal(t, nil, err, "分批发布")
+	for _, n := range nodes {
+		assert.Equal(t, true, n.sshd.Contains("pub.sh"), "执行发布脚本")
+		assert.Equal(t, false, n.sshd.Contains("BIN="), "发布成功不回滚")
+	}
+}
+
+func TestRolling_RunFailed(t *testing.T) {
+	bin, clear := newTestBin(t)
+	defer clear()
+
+	r := localmemory.NewLocalMemory()
+	nodes := []*testNode{
+		newTestNode(t, r, "127.0.0.2", http.StatusOK),
+		newTestNode(t, r, "127.0.0.3", http.StatusNotFound),
+		newTestNode(t, r, "127.0.0.4", http.StatusOK),
+	}
+	args := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		defer n.Close()
+		args = append(args, n.sshd.Host("root", "/home/hydra"))
+	}
+
+	hosts, err := getHosts(args, "", bin, "123456")
+	assert.Equal(t, nil, err, "构建服务器列表")
+	err = newRolling(hosts, 1, newTestGate(r)).Run()
+	assert.NotEqual(t, nil, err, "健康检查失败")
+	assert.Equal(t, true, strings.Contains(err.Error(), "127.0.0.3"), "失败的服务器")
+
+	assert.Equal(t, true, nodes[0].sshd.Contains("BIN=hydra_app"), "已发布的服务器回滚")
+	assert.Equal(t, true, nodes[1].sshd.Contains("BIN="), "未通过健康检查的服务器回滚")
+	assert.Equal(t, 0, len(nodes[2].sshd.Cmds()), "失败后停止发布")
+}
+
+func TestRolling_RunDeployFailed(t *testing.T) {
+	bin, clear := newTestBin(t)
+	defer clear()
+
+	r := localmemory.NewLocalMemory()
+	nodes := []*testNode{
+		newTestNode(t, r, "127.0.0.2", http.StatusOK),
+		newTestNode(t, r, "127.0.0.3", http.StatusOK),
+	}
+	nodes[1].failed = true
+	args := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		defer n.Close()
+		args = append(args, n.sshd.Host("root", "/home/hydra"))
+	}
+
+	hosts, err := getHosts(args, "", bin, "123456")
+	assert.Equal(t, nil, err, "构建服务器列表")
+	err = newRolling(hosts, 2, newTestGate(r)).Run()
+	assert.NotEqual(t, nil, err, "发布失败")
+	assert.Equal(t, true, strings.Contains(err.Error(), "127.0.0.3"), "失败的服务器")
+	assert.Equal(t, true, nodes[0].sshd.Contains("BIN=hydra_app"), "发布成功的服务器回滚")
+	assert.Equal(t, false, nodes[1].sshd.Contains("BIN="), "发布失败的服务器不回滚")
+}
+
+func TestRolling_RunNotRegistered(t *testing.T) {
+	bin, clear := newTestBin(t)
+	defer clear()
+
+	r := localmemory.NewLocalMemory()
+	n := newTestNode(t, r, "127.0.0.2", http.StatusOK)
+	defer n.Close()
+	n.sshd.exec = func(cmd string) (string, int) {
+		if cmd == string(cmdNow) {
+			return fmt.Sprintf("%d", time.Now().Unix()), 0
+		}
+		return "", 0
+	}
+
+	hosts, err := getHosts([]string{n.sshd.Host("root", "/home/hydra")}, "", bin, "123456")
+	assert.Equal(t, nil, err, "构建服务器列表")
+	err = newRolling(hosts, 1, newTestGate(r)).Run()
+	assert.NotEqual(t, nil, err, "服务未注册")
+	assert.Equal(t, true, n.sshd.Contains("BIN="), "服务未注册时回滚")
+}
+
+func TestRestoreAll(t *testing.T) {
+	n := newTestNode(t, localmemory.NewLocalMemory(), "127.0.0.2", http.StatusOK)
+	defer n.Close()
+	n.sshd.exec = func(cmd string) (string, int) { return "未找到备份文件", 1 }
+
+	hosts, err := getHosts([]string{n.sshd.Host("root", "/home/hydra")}, "", "hydra_app", "123456")
+	assert.Equal(t, nil, err, "构建服务器列表")
+	err = restoreAll(hosts)
+	assert.NotEqual(t, nil, err, "无备份文件时回滚失败")
+	cmds := n.sshd.Cmds()
+	assert.Equal(t, 1, len(cmds), "执行回滚命令")
+	assert.Equal(t, true, strings.HasPrefix(cmds[0], "BIN=hydra_app; cd ~/hydra/bin"), "回滚命令")
+}
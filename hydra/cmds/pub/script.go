@@ -0,0 +1,13 @@
+package pub
+
+import (
+	_ "embed"
+)
+
+//go:embed pub.sh
+var pubScript string
+
+//getScript 获取远程服务器执行的发布脚本
+func getScript() (string, string) {
+	return "pub.sh", pubScript
+}
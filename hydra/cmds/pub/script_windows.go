@@ -1,5 +0,0 @@
-package pub
-
-func getScript() (string, string) {
-	return "", ""
-}
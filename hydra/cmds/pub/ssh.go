@@ -3,21 +3,21 @@ package pub
 import (
 	"fmt"
 	"io"
- 	"net"
+	"net"
 	"os"
 	"path"
 	"strings"
 	"time"
 
+	"github.com/micro-plat/lib4go/types"
 	"github.com/micro-plat/lib4go/utility"
 	"github.com/pkg/sftp"
 	"golang.org/x/crypto/ssh"
 )
 
-var client = &sshClient{}
-
 type sshClient struct {
 	ip          string
+	port        string
 	userName    string
 	pwd         string
 	client      *ssh.Client
@@ -26,6 +26,7 @@ type sshClient struct {
 	tmpFile     string
 	localPath   string
 	projectPath string
+	deployTime  int64
 }
 
 func (s *sshClient) Bind(host string, localpath string, pwd string) error {
@@ -36,24 +37,31 @@ func (s *sshClient) Bind(host string, localpath string, pwd string) error {
 		return fmt.Errorf("未指远程服务器登录密码")
 	}
 	if !strings.Contains(host, ":") {
-		return fmt.Errorf("%s 服务器信息应包含远程目录,格式:userName@ip:/path", host)
+		return fmt.Errorf("%s 服务器信息应包含远程目录,格式:userName@ip[:port]:/path", host)
 	}
 	if !strings.Contains(host, "@") {
-		return fmt.Errorf("%s 服务器信息应包含远程服务器ip地址,格式:userName@ip:/path", host)
+		return fmt.Errorf("%s 服务器信息应包含远程服务器ip地址,格式:userName@ip[:port]:/path", host)
 	}
 	paths := strings.Split(host, ":")
-	if len(paths) != 2 || paths[1] == "" || paths[0] == "" {
-		return fmt.Errorf("%s 远程路径有误,格式:userName@ip:/path", host)
+	if len(paths) < 2 || len(paths) > 3 || paths[len(paths)-1] == "" || paths[0] == "" {
+		return fmt.Errorf("%s 远程路径有误,格式:userName@ip[:port]:/path", host)
+	}
+	s.port = "22"
+	if len(paths) == 3 {
+		if types.GetInt(paths[1]) <= 0 {
+			return fmt.Errorf("%s 远程服务器端口有误,格式:userName@ip[:port]:/path", host)
+		}
+		s.port = paths[1]
 	}
 
 	hosts := strings.Split(paths[0], "@")
 	if len(hosts) != 2 || hosts[1] == "" || hosts[0] == "" {
-		return fmt.Errorf("%s 远程服务有误,格式:userName@ip:/path", host)
+		return fmt.Errorf("%s 远程服务有误,格式:userName@ip[:port]:/path", host)
 	}
 	s.userName = hosts[0]
 	s.ip = hosts[1]
 	s.localPath = localpath
-	_, s.projectPath = path.Split(paths[1])
+	_, s.projectPath = path.Split(paths[len(paths)-1])
 	s.tmpDir = utility.GetGUID()
 	s.tmpFile = path.Join(s.tmpDir, s.localPath)
 	s.tmpPath = path.Join(os.TempDir(), s.tmpDir)
@@ -61,13 +69,18 @@ func (s *sshClient) Bind(host string, localpath string, pwd string) error {
 	return nil
 }
 
+//String 服务器地址
+func (s *sshClient) String() string {
+	return fmt.Sprintf("%s@%s:%s", s.userName, s.ip, s.port)
+}
+
 //登录到服务器
 func (s *sshClient) Login() (err error) {
 	if s.ip == "" || s.userName == "" || s.pwd == "" {
 		return fmt.Errorf("服务器ip,用户名，密码不能为空")
 	}
 	//通过ssh连接到远程服务器
-	address := fmt.Sprintf("%s:22", s.ip)
+	address := net.JoinHostPort(s.ip, s.port)
 	s.client, err = ssh.Dial("tcp", address, &ssh.ClientConfig{
 		User: s.userName,
 		Auth: []ssh.AuthMethod{ssh.Password(s.pwd)},
@@ -94,6 +107,20 @@ func (s *sshClient) run(cmd string) error {
 	return session.Run(cmd)
 }
 
+//output 执行命令并返回输出内容
+func (s *sshClient) output(cmd string) (string, error) {
+	if s.client == nil {
+		return "", fmt.Errorf("服务器未登录")
+	}
+	session, err := s.client.NewSession()
+	if err != nil {
+		return "", err
+	}
+	defer session.Close()
+	buff, err := session.Output(cmd)
+	return string(buff), err
+}
+
 //获取当前文件名
 func (s *sshClient) GetFileName() string {
 	return path.Base(s.localPath)
@@ -118,7 +145,9 @@ func (s *sshClient) UploadFile() error {
 	defer ftpclient.Close()
 
 	//3. 创建远程文件
-
+	if err := ftpclient.MkdirAll(s.tmpPath); err != nil {
+		return fmt.Errorf("创建目录失败%w", err)
+	}
 	dstFile, e := ftpclient.Create(path.Join(s.tmpPath, s.localPath))
 	if e != nil {
 		return fmt.Errorf("创建文件失败%w", e)
@@ -126,7 +155,7 @@ func (s *sshClient) UploadFile() error {
 	}
 
 	fileInfo, _ := srcFile.Stat()
-	idx := 0
+	written := int64(0)
 	defer dstFile.Close()
 	buffer := make([]byte, 1024)
 	for {
@@ -138,10 +167,12 @@ func (s *sshClient) UploadFile() error {
 				return fmt.Errorf("读取文件出错%w", err)
 			}
 		}
-		idx++
-		percent := Progress(1024 * 100 * idx / int(fileInfo.Size()))
+		if _, err := dstFile.Write(buffer[:n]); err != nil {
+			return fmt.Errorf("上传文件出错%w", err)
+		}
+		written += int64(n)
+		percent := Progress(written * 100 / fileInfo.Size())
 		percent.Show()
-		dstFile.Write(buffer[:n])
 	}
 
 	fmt.Print("\n")
@@ -178,26 +209,31 @@ func (s *sshClient) UploadScript() (string, error) {
 //GoWorkDir 转到工作目录
 func (s *sshClient) GoWorkDir() (err error) {
 
-	if err := s.run(cmdMkdir.CMD(s.tmpPath)); err != nil {
+	if err := s.run(cmdMkdir.CMD(s, s.tmpPath)); err != nil {
 		return err
 	}
 
-	return s.run(cmdCD.CMD(s.tmpPath))
+	return s.run(cmdCD.CMD(s, s.tmpPath))
 }
 
 //ExecScript
 func (s *sshClient) ExecScript(p string) error {
 	scriptPath := path.Join(s.tmpPath, p)
-	return s.run(cmdRunScript.CMD(scriptPath))
+	return s.run(cmdRunScript.CMD(s, scriptPath))
 }
 
 func (s *sshClient) Close() error {
-	return nil
+	if s.client == nil {
+		return nil
+	}
+	err := s.client.Close()
+	s.client = nil
+	return err
 }
 
 //删除工作目录
 func (s *sshClient) RmWorkDir() (err error) {
-	return s.run(cmdRm.CMD(s.tmpPath))
+	return s.run(cmdRm.CMD(s, s.tmpPath))
 }
 
  
\ No newline at end of file
@@ -28,7 +28,13 @@ func Test_sshClient_Bind(t *testing.T) {
 		fields  fields
 		args    args
 		wantErr bool
-	}{}
+	}{
+		{name: "默认端口", args: args{host: "root@192.168.0.1:/home/hydra", localpath: "hydra", pwd: "123"}},
+		{name: "指定端口", args: args{host: "root@192.168.0.1:2222:/home/hydra", localpath: "hydra", pwd: "123"}},
+		{name: "端口错误", args: args{host: "root@192.168.0.1:abc:/home/hydra", localpath: "hydra", pwd: "123"}, wantErr: true},
+		{name: "缺少用户名", args: args{host: "192.168.0.1:/home/hydra", localpath: "hydra", pwd: "123"}, wantErr: true},
+		{name: "缺少密码", args: args{host: "root@192.168.0.1:/home/hydra", localpath: "hydra"}, wantErr: true},
+	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &sshClient{
@@ -0,0 +1,128 @@
+package pub
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+//testSSHD 测试用ssh服务，记录执行的命令，支持sftp上传文件(保存在内存中)
+type testSSHD struct {
+	ln   net.Listener
+	exec func(cmd string) (string, int)
+	fs   sftp.Handlers
+	lock sync.Mutex
+	cmds []string
+}
+
+func newTestSSHD(t *testing.T, ip string, pwd string, exec func(cmd string) (string, int)) *testSSHD {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, p []byte) (*ssh.Permissions, error) {
+			if string(p) != pwd {
+				return nil, fmt.Errorf("密码错误")
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(signer)
+	ln, err := net.Listen("tcp", net.JoinHostPort(ip, "0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &testSSHD{ln: ln, exec: exec, fs: sftp.InMemHandler()}
+	go s.serve(config)
+	return s
+}
+
+//Host 获取发布地址
+func (s *testSSHD) Host(user string, path string) string {
+	return fmt.Sprintf("%s@%s:%s", user, s.ln.Addr().String(), path)
+}
+
+//Cmds 获取执行过的命令
+func (s *testSSHD) Cmds() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]string{}, s.cmds...)
+}
+
+//Contains 是否执行过包含指定内容的命令
+func (s *testSSHD) Contains(sub string) bool {
+	for _, c := range s.Cmds() {
+		if strings.Contains(c, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *testSSHD) Close() {
+	s.ln.Close()
+}
+
+func (s *testSSHD) serve(config *ssh.ServerConfig) {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			_, chans, reqs, err := ssh.NewServerConn(conn, config)
+			if err != nil {
+				return
+			}
+			go ssh.DiscardRequests(reqs)
+			for nc := range chans {
+				if nc.ChannelType() != "session" {
+					nc.Reject(ssh.UnknownChannelType, "unsupported")
+					continue
+				}
+				ch, creqs, err := nc.Accept()
+				if err != nil {
+					continue
+				}
+				go s.session(ch, creqs)
+			}
+		}()
+	}
+}
+
+func (s *testSSHD) session(ch ssh.Channel, reqs <-chan *ssh.Request) {
+	defer ch.Close()
+	for req := range reqs {
+		switch req.Type {
+		case "exec":
+			var payload struct{ Command string }
+			ssh.Unmarshal(req.Payload, &payload)
+			req.Reply(true, nil)
+			s.lock.Lock()
+			s.cmds = append(s.cmds, payload.Command)
+			s.lock.Unlock()
+			out, status := s.exec(payload.Command)
+			ch.Write([]byte(out))
+			ch.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{uint32(status)}))
+			return
+		case "subsystem":
+			req.Reply(true, nil)
+			sftp.NewRequestServer(ch, s.fs).Serve()
+			return
+		default:
+			req.Reply(false, nil)
+		}
+	}
+}
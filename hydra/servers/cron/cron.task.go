@@ -1,10 +1,13 @@
 package cron
 
 import (
+	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/micro-plat/hydra/conf/server/task"
+	"github.com/micro-plat/lib4go/utility"
 	cron "github.com/robfig/cron/v3"
 )
 
@@ -19,17 +22,26 @@ type CronTask struct {
 	method   string
 	form     map[string]interface{}
 	header   map[string]string
+	*execution
+}
+
+//execution 任务的执行状态，配置重新加载时由同名的新任务继承
+type execution struct {
+	lock    sync.Mutex
+	running map[string]context.CancelFunc
+	missed  int
 }
 
 //NewCronTask 构建定时任务
 func NewCronTask(t *task.Task) (r *CronTask, err error) {
 	r = &CronTask{
-		Task:    t,
-		Counter: &Counter{},
-		Round:   &Round{},
-		method:  DefMethod,
-		form:    make(map[string]interface{}),
-		header:  map[string]string{"Client-IP": "127.0.0.1"},
+		Task:      t,
+		Counter:   &Counter{},
+		Round:     &Round{},
+		method:    DefMethod,
+		form:      make(map[string]interface{}),
+		header:    map[string]string{"Client-IP": "127.0.0.1"},
+		execution: &execution{},
 	}
 	if t.IsImmediately() {
 		return r, nil
@@ -74,3 +86,85 @@ func (m *CronTask) NextTime(t time.Time) time.Time {
 	}
 	return m.schedule.Next(t)
 }
+
+//begin 按并发策略开始一次执行，返回本次执行的请求及执行完成后的清理函数，不允许执行时返回false
+func (m *CronTask) begin() (*cronRequest, func(), bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	switch m.GetConcurrencyPolicy() {
+	case task.ConcurrencyForbid:
+		if len(m.running) > 0 {
+			return nil, nil, false
+		}
+	case task.ConcurrencyReplace:
+		for _, cancel := range m.running {
+			cancel()
+		}
+	}
+	if m.running == nil {
+		m.running = make(map[string]context.CancelFunc)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	if timeout := m.GetTimeout(); timeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	}
+	id := utility.GetGUID()
+	m.running[id] = cancel
+	return &cronRequest{CronTask: m, ctx: ctx}, func() {
+		cancel()
+		m.lock.Lock()
+		delete(m.running, id)
+		m.lock.Unlock()
+	}, true
+}
+
+//Running 正在执行的次数
+func (m *CronTask) Running() int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return len(m.running)
+}
+
+//cancel 撤销所有未完成的执行
+func (m *CronTask) cancel() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for _, cancel := range m.running {
+		cancel()
+	}
+}
+
+//miss 记录错过的执行
+func (m *CronTask) miss() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.missed++
+}
+
+//takeMissed 按错过执行策略获取需补执行的次数，并清除错过次数
+func (m *CronTask) takeMissed() int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	n := m.missed
+	m.missed = 0
+	switch m.GetMisfire() {
+	case task.MisfireFireAll:
+		return n
+	case task.MisfireFireOnce:
+		if n > 0 {
+			return 1
+		}
+	}
+	return 0
+}
+
+//cronRequest 任务的单次执行请求，超时或被替换时撤销上下文
+type cronRequest struct {
+	*CronTask
+	ctx context.Context
+}
+
+//GetContext 获取本次执行的上下文
+func (r *cronRequest) GetContext() context.Context {
+	return r.ctx
+}
@@ -1,8 +1,10 @@
 package cron
 
 import (
+	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/micro-plat/hydra/conf/server/task"
 	"github.com/micro-plat/lib4go/assert"
@@ -25,12 +27,13 @@ func TestNewCronTask(t *testing.T) {
 	}
 	for _, tt := range tests {
 		m := &CronTask{
-			Task:    tt.args,
-			Counter: &Counter{},
-			Round:   &Round{},
-			method:  "GET",
-			form:    make(map[string]interface{}),
-			header:  map[string]string{"Client-IP": "127.0.0.1"},
+			Task:      tt.args,
+			Counter:   &Counter{},
+			Round:     &Round{},
+			method:    "GET",
+			form:      make(map[string]interface{}),
+			header:    map[string]string{"Client-IP": "127.0.0.1"},
+			execution: &execution{},
 		}
 		if tt.ok {
 			s, err := cron.ParseStandard(tt.args.Cron)
@@ -72,3 +75,71 @@ func TestCronTask_GetName(t *testing.T) {
 	got2 := m.GetHeader()
 	assert.Equal(t, map[string]string{"Client-IP": "192.168.0.101", "Host": "www.baidu.com"}, got2, "获取任务的GetForm失败")
 }
+
+func TestCronTask_begin(t *testing.T) {
+	tests := []struct {
+		name         string
+		policy       string
+		secondOK     bool
+		firstCancel  bool
+		runningAfter int
+	}{
+		{name: "1. cron任务并发-默认禁止并发", policy: "", secondOK: false, firstCancel: false, runningAfter: 1},
+		{name: "2. cron任务并发-允许并发", policy: task.ConcurrencyAllow, secondOK: true, firstCancel: false, runningAfter: 2},
+		{name: "3. cron任务并发-禁止并发", policy: task.ConcurrencyForbid, secondOK: false, firstCancel: false, runningAfter: 1},
+		{name: "4. cron任务并发-替换执行", policy: task.ConcurrencyReplace, secondOK: true, firstCancel: true, runningAfter: 2},
+	}
+	for _, tt := range tests {
+		m, err := NewCronTask(task.NewTask("@every 10s", "/cron/serve1", task.WithConcurrencyPolicy(tt.policy)))
+		assert.Equalf(t, nil, err, tt.name)
+
+		first, done1, ok := m.begin()
+		assert.Equalf(t, true, ok, tt.name+",首次执行")
+		_, done2, ok := m.begin()
+		assert.Equalf(t, tt.secondOK, ok, tt.name+",再次执行")
+		assert.Equalf(t, tt.firstCancel, first.GetContext().Err() == context.Canceled, tt.name+",首次执行被撤销")
+		assert.Equalf(t, tt.runningAfter, m.Running(), tt.name+",正在执行数")
+
+		done1()
+		if done2 != nil {
+			done2()
+		}
+		assert.Equalf(t, 0, m.Running(), tt.name+",执行完成")
+	}
+}
+
+func TestCronTask_timeout(t *testing.T) {
+	m, err := NewCronTask(task.NewTask("@every 10s", "/cron/serve1", task.WithTimeout(1)))
+	assert.Equal(t, nil, err, "构建任务")
+	req, done, ok := m.begin()
+	assert.Equal(t, true, ok, "执行任务")
+	defer done()
+	deadline, ok := req.GetContext().Deadline()
+	assert.Equal(t, true, ok, "设置超时时长")
+	assert.Equal(t, true, time.Until(deadline) <= time.Second, "超时时长")
+	<-req.GetContext().Done()
+	assert.Equal(t, context.DeadlineExceeded, req.GetContext().Err(), "超时撤销")
+}
+
+func TestCronTask_takeMissed(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		missed int
+		want   int
+	}{
+		{name: "1. cron任务补执行-默认跳过", policy: "", missed: 3, want: 0},
+		{name: "2. cron任务补执行-补执行一次", policy: task.MisfireFireOnce, missed: 3, want: 1},
+		{name: "3. cron任务补执行-补执行全部", policy: task.MisfireFireAll, missed: 3, want: 3},
+		{name: "4. cron任务补执行-未错过执行", policy: task.MisfireFireOnce, missed: 0, want: 0},
+	}
+	for _, tt := range tests {
+		m, err := NewCronTask(task.NewTask("@every 10s", "/cron/serve1", task.WithMisfire(tt.policy)))
+		assert.Equalf(t, nil, err, tt.name)
+		for i := 0; i < tt.missed; i++ {
+			m.miss()
+		}
+		assert.Equalf(t, tt.want, m.takeMissed(), tt.name)
+		assert.Equalf(t, 0, m.takeMissed(), tt.name+",已清除错过次数")
+	}
+}
@@ -35,6 +35,8 @@ type Processor struct {
 	metric    *middleware.Metric
 	status    int
 	engine    *adapter.DispatcherEngine
+	misfired  cmap.ConcurrentMap
+	tasks     cmap.ConcurrentMap
 }
 
 //NewProcessor 创建processor
@@ -46,6 +48,8 @@ func NewProcessor(routers ...*router.Router) (p *Processor) {
 		length:    60,
 		startTime: time.Now(),
 		metric:    middleware.NewMetric(),
+		misfired:  cmap.New(2),
+		tasks:     cmap.New(4),
 	}
 	p.engine = adapter.NewDispatcherEngine(CRON)
 
@@ -54,6 +58,7 @@ func NewProcessor(routers ...*router.Router) (p *Processor) {
 	p.engine.Use(middleware.Logging())
 	p.engine.Use(middleware.Recovery())
 
+	p.engine.Use(middleware.APM())   //链路跟踪
 	p.engine.Use(middleware.Trace()) //跟踪信息
 	p.engine.Use(middlewares...)
 
@@ -96,7 +101,7 @@ func (s *Processor) Add(ts ...*task.Task) (err error) {
 		if err != nil {
 			return fmt.Errorf("构建cron.task失败:%v", err)
 		}
-
+		s.replace(task)
 		if _, _, err := s.add(task); err != nil {
 			return err
 		}
@@ -122,6 +127,27 @@ func (s *Processor) add(task *CronTask) (offset int, round int, err error) {
 	return
 }
 
+//replace 替换已添加的同名任务，新任务继承原任务正在执行及错过执行的状态
+func (s *Processor) replace(t *CronTask) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	name := t.GetName()
+	if v, ok := s.tasks.Get(name); ok {
+		old := v.(*CronTask)
+		old.Disable = true
+		t.execution = old.execution
+		for _, slot := range s.slots {
+			slot.RemoveIterCb(func(k string, value interface{}) bool {
+				return value.(*CronTask) == old
+			})
+		}
+		if s.misfired.Has(name) {
+			s.misfired.Set(name, t)
+		}
+	}
+	s.tasks.Set(name, t)
+}
+
 //Remove 移除服务
 func (s *Processor) Remove(name string) {
 	s.lock.Lock()
@@ -133,6 +159,8 @@ func (s *Processor) Remove(name string) {
 			return task.GetName() == name
 		})
 	}
+	s.misfired.Remove(name)
+	s.tasks.Remove(name)
 }
 
 //Pause 暂停所有任务
@@ -148,6 +176,7 @@ func (s *Processor) Pause() (bool, error) {
 func (s *Processor) Resume() (bool, error) {
 	if s.status != running {
 		s.status = running
+		s.fireMisfired()
 		return true, nil
 	}
 	return false, nil
@@ -161,6 +190,11 @@ func (s *Processor) Close() {
 	if !s.done {
 		s.done = true
 		close(s.closeChan)
+		for _, slot := range s.slots {
+			for item := range slot.IterBuffered() {
+				item.Val.(*CronTask).cancel()
+			}
+		}
 	}
 }
 
@@ -208,18 +242,57 @@ func (s *Processor) execute() {
 		return false
 	})
 }
-func (s *Processor) handle(task *CronTask) error {
-	if s.done || task.Disable {
+func (s *Processor) handle(t *CronTask) error {
+	if s.done || t.Disable {
 		return nil
 	}
-	if s.status == running {
-		task.Counter.Increase()
-		s.engine.HandleRequest(task) //触发服务引擎进行业务处理
+
+	//先计算下次执行时间，执行时长不影响任务的调度周期
+	if !t.IsImmediately() {
+		if _, _, err := s.add(t); err != nil {
+			return err
+		}
 	}
-	if task.IsImmediately() {
-		return nil
+	switch s.status {
+	case running:
+		s.run(t)
+	case pause:
+		if t.GetMisfire() != task.MisfireSkip {
+			t.miss()
+			s.misfired.Set(t.GetName(), t)
+		}
+	}
+	return nil
+}
+
+//run 按任务并发策略执行任务
+func (s *Processor) run(t *CronTask) bool {
+	req, done, ok := t.begin()
+	if !ok {
+		return false
 	}
-	_, _, err := s.add(task)
-	return err
+	defer done()
+	t.Counter.Increase()
+	s.engine.HandleRequest(req) //触发服务引擎进行业务处理
+	return true
+}
 
+//fireMisfired 按错过执行策略补执行暂停期间错过的任务
+func (s *Processor) fireMisfired() {
+	for item := range s.misfired.IterBuffered() {
+		s.misfired.Remove(item.Key)
+		t := item.Val.(*CronTask)
+		n := t.takeMissed()
+		if n == 0 {
+			continue
+		}
+		go func() {
+			for i := 0; i < n; i++ {
+				if s.done || t.Disable || s.status != running {
+					return
+				}
+				s.run(t)
+			}
+		}()
+	}
 }
@@ -60,6 +60,33 @@ func TestProcessor_Add(t *testing.T) {
 	}
 }
 
+func TestProcessor_AddReload(t *testing.T) {
+	s := NewProcessor()
+	err := s.Add(task.NewTask("@every 10s", "/cron/serve1"))
+	assert.Equal(t, nil, err, "添加任务")
+	v, _ := s.tasks.Get(task.NewTask("@every 10s", "/cron/serve1").GetUNQ())
+	old := v.(*CronTask)
+	_, done, ok := old.begin()
+	assert.Equal(t, true, ok, "执行任务")
+
+	err = s.Add(task.NewTask("@every 10s", "/cron/serve1"))
+	assert.Equal(t, nil, err, "重新加载任务")
+	count := 0
+	for _, slot := range s.slots {
+		count += slot.Count()
+	}
+	assert.Equal(t, 1, count, "替换原任务")
+	v, _ = s.tasks.Get(old.GetName())
+	current := v.(*CronTask)
+	assert.Equal(t, true, current != old, "使用新任务")
+	assert.Equal(t, true, old.Disable, "原任务不再调度")
+	assert.Equal(t, 1, current.Running(), "继承正在执行的状态")
+	_, _, ok = current.begin()
+	assert.Equal(t, false, ok, "上次执行未完成时不再执行")
+	done()
+	assert.Equal(t, 0, current.Running(), "执行完成")
+}
+
 func TestProcessor_Remove(t *testing.T) {
 	type args struct {
 		name string
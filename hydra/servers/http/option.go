@@ -1,7 +1,10 @@
 package http
 
 import (
+	"crypto/tls"
+
 	"github.com/gin-gonic/gin"
+	wsconf "github.com/micro-plat/hydra/conf/server/ws"
 	"github.com/micro-plat/hydra/hydra/servers/pkg/middleware"
 )
 
@@ -15,6 +18,8 @@ type option struct {
 	metric            *middleware.Metric
 	serverType        string
 	ginTrace          bool
+	tls               *tls.Config
+	wsUpgrade         *wsconf.Upgrade
 }
 
 //Option 配置选项
@@ -42,3 +47,17 @@ func WithGinTrace(b bool) Option {
 		o.ginTrace = b
 	}
 }
+
+//WithTLS 启用TLS
+func WithTLS(c *tls.Config) Option {
+	return func(o *option) {
+		o.tls = c
+	}
+}
+
+//WithWSUpgrade 设置ws连接升级与读写限制配置
+func WithWSUpgrade(u *wsconf.Upgrade) Option {
+	return func(o *option) {
+		o.wsUpgrade = u
+	}
+}
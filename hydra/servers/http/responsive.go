@@ -7,6 +7,7 @@ import (
 	"github.com/micro-plat/hydra/conf"
 	"github.com/micro-plat/hydra/conf/app"
 	"github.com/micro-plat/hydra/conf/server/api"
+	wsconf "github.com/micro-plat/hydra/conf/server/ws"
 	"github.com/micro-plat/hydra/global"
 	"github.com/micro-plat/hydra/hydra/servers"
 	"github.com/micro-plat/hydra/registry/pub"
@@ -51,6 +52,11 @@ func (w *Responsive) Start() (err error) {
 		return
 	}
 
+	if err = w.Server.metric.Init(w.conf); err != nil {
+		err = fmt.Errorf("%s启动失败 %w", w.conf.GetServerConf().GetServerType(), err)
+		return
+	}
+
 	if err = w.Server.Start(); err != nil {
 		err = fmt.Errorf("%s启动失败 %w", w.conf.GetServerConf().GetServerType(), err)
 		return
@@ -80,7 +86,11 @@ func (w *Responsive) Notify(c app.IAPPConf) (change bool, err error) {
 	if !w.comparer.IsChanged() {
 		return false, nil
 	}
-	if w.comparer.IsValueChanged() || w.comparer.IsSubConfChanged() {
+	apiConf, err := api.GetConf(c.GetServerConf())
+	if err != nil {
+		return false, err
+	}
+	if w.comparer.IsValueChanged() || w.comparer.IsSubConfChanged() || apiConf.IsTLS() != w.Server.IsTLS() {
 		w.log.Info("关键配置发生变化，准备重启服务器")
 		if err := services.Def.DoSetup(c); err != nil {
 			return false, err
@@ -104,6 +114,22 @@ func (w *Responsive) Notify(c app.IAPPConf) (change bool, err error) {
 		}
 		return true, nil
 	}
+	if apiConf.IsTLS() {
+		tlsConf, err := apiConf.GetTLSConfig()
+		if err != nil {
+			return false, err
+		}
+		w.Server.ReloadTLS(tlsConf)
+		w.log.Info("证书已重新加载")
+	}
+	if c.GetServerConf().GetServerType() == WS {
+		wsConf, err := wsconf.GetConf(c.GetServerConf())
+		if err != nil {
+			return false, err
+		}
+		w.Server.ReloadWSUpgrade(&wsConf.Upgrade)
+		w.log.Info("ws连接配置已重新加载")
+	}
 	app.Cache.Save(c)
 	w.conf = c
 	return true, nil
@@ -161,31 +187,39 @@ func (w *Responsive) getServer(cnf app.IAPPConf) (*Server, error) {
 	if err != nil {
 		return nil, err
 	}
+	opts := []Option{
+		WithServerType(tp),
+		WithTimeout(apiConf.GetRTimeout(), apiConf.GetWTimeout(), apiConf.GetRHTimeout()),
+		WithGinTrace(apiConf.Trace),
+	}
+	if apiConf.IsTLS() {
+		tlsConf, err := apiConf.GetTLSConfig()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithTLS(tlsConf))
+	}
 	switch tp {
 	case WS:
+		wsConf, err := wsconf.GetConf(cnf.GetServerConf())
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithWSUpgrade(&wsConf.Upgrade))
 		return NewWSServer(tp,
 			apiConf.GetWSAddress(),
 			routersObj.GetRouters(),
-			WithServerType(tp),
-			WithTimeout(apiConf.GetRTimeout(), apiConf.GetWTimeout(), apiConf.GetRHTimeout()),
-			WithGinTrace(apiConf.Trace),
-		)
+			opts...)
 	case Web:
 		return NewServer(tp,
 			apiConf.GetWEBAddress(),
 			routersObj.GetRouters(),
-			WithServerType(tp),
-			WithTimeout(apiConf.GetRTimeout(), apiConf.GetWTimeout(), apiConf.GetRHTimeout()),
-			WithGinTrace(apiConf.Trace),
-		)
+			opts...)
 	default:
 		return NewServer(tp,
 			apiConf.GetAPIAddress(),
 			routersObj.GetRouters(),
-			WithServerType(tp),
-			WithTimeout(apiConf.GetRTimeout(), apiConf.GetWTimeout(), apiConf.GetRHTimeout()),
-			WithGinTrace(apiConf.Trace),
-		)
+			opts...)
 	}
 }
 
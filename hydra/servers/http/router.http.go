@@ -18,7 +18,7 @@ func (s *Server) addHttpRouters(routers ...*router.Router) {
 	s.engine.Use(middleware.Logging()) //记录请求日志
 	s.engine.Use(middleware.Recovery())
 
-	// s.engine.Use(middleware.APM())       //链数跟踪
+	s.engine.Use(middleware.APM())       //链路跟踪
 	s.engine.Use(middleware.Trace())     //跟踪信息
 	s.engine.Use(middleware.BlackList()) //黑名单控制
 	s.engine.Use(middleware.WhiteList()) //白名单控制
@@ -26,6 +26,7 @@ func (s *Server) addHttpRouters(routers ...*router.Router) {
 	s.engine.Use(middleware.Delay())     //
 	s.engine.Use(middleware.Limit())     //限流处理
 	s.engine.Use(middleware.Header())    //设置请求头
+	s.engine.Use(middleware.OpenAPI())   //提供OpenAPI文档
 	s.engine.Use(middleware.Static())    //处理静态文件
 	s.engine.Use(middleware.Options())   //处理option响应
 	s.engine.Use(middleware.BasicAuth()) //
@@ -28,7 +28,7 @@ func (s *Server) addWSRouter(routers ...*router.Router) {
 	ws.InitWSEngine(routers...)
 	router := router.GetWSHomeRouter()
 	for _, a := range router.Action {
-		s.engine.Handle(a, router.Path, ws.WSExecuteHandler())
+		s.engine.Handle(a, router.Path, ws.WSExecuteHandler(s.getWSUpgrade))
 	}
 
 }
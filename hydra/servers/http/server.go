@@ -2,12 +2,15 @@ package http
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	xnet "net"
 	x "net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/micro-plat/hydra/conf/server/router"
+	wsconf "github.com/micro-plat/hydra/conf/server/ws"
 	"github.com/micro-plat/hydra/global"
 	"github.com/micro-plat/hydra/hydra/servers/pkg/adapter"
 	"github.com/micro-plat/hydra/hydra/servers/pkg/middleware"
@@ -25,6 +28,8 @@ type Server struct {
 	host    string
 	port    string
 	engine  *adapter.GinEngine
+	tlsConf atomic.Value
+	wsConf  atomic.Value
 }
 
 //NewServer 创建http api服务嚣
@@ -43,7 +48,8 @@ func NewWSServer(name string, addr string, routers []*router.Router, opts ...Opt
 	if err != nil {
 		return
 	}
-	t.proto = "ws"
+	t.proto = types.DecodeString(t.tls != nil, true, "wss", "ws")
+	t.ReloadWSUpgrade(t.wsUpgrade)
 	t.addWSRouters(routers...)
 	return
 }
@@ -74,16 +80,70 @@ func new(name string, addr string, opts ...Option) (t *Server, err error) {
 		WriteTimeout:      time.Second * time.Duration(t.option.writeTimeout),
 		MaxHeaderBytes:    1 << 20,
 	}
+	if t.option.tls != nil {
+		t.proto = "https"
+		t.tlsConf.Store(t.option.tls)
+		//go1.22之前ServeTLS仅在设置了Certificates或GetCertificate时才不从文件加载证书
+		t.server.TLSConfig = &tls.Config{GetConfigForClient: t.getTLSConfig, GetCertificate: t.getCertificate}
+	}
 	return
 }
 
+//ReloadTLS 重新加载证书，新建立的连接使用新证书
+func (s *Server) ReloadTLS(c *tls.Config) {
+	if c != nil {
+		s.tlsConf.Store(c)
+	}
+}
+
+//ReloadWSUpgrade 重新加载ws连接升级配置，新建立的连接使用新配置
+func (s *Server) ReloadWSUpgrade(u *wsconf.Upgrade) {
+	if u != nil {
+		s.wsConf.Store(u)
+	}
+}
+
+func (s *Server) getWSUpgrade() *wsconf.Upgrade {
+	if u, ok := s.wsConf.Load().(*wsconf.Upgrade); ok {
+		return u
+	}
+	return &wsconf.Upgrade{}
+}
+
+//IsTLS 是否启用TLS
+func (s *Server) IsTLS() bool {
+	return s.server.TLSConfig != nil
+}
+
+func (s *Server) getTLSConfig(*tls.ClientHelloInfo) (*tls.Config, error) {
+	return s.tlsConf.Load().(*tls.Config), nil
+}
+
+func (s *Server) getCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c := s.tlsConf.Load().(*tls.Config)
+	if c.GetCertificate != nil {
+		return c.GetCertificate(hello)
+	}
+	if len(c.Certificates) == 0 {
+		return nil, fmt.Errorf("未配置服务器证书")
+	}
+	return &c.Certificates[0], nil
+}
+
+func (s *Server) listenAndServe() error {
+	if s.IsTLS() {
+		return s.server.ListenAndServeTLS("", "")
+	}
+	return s.server.ListenAndServe()
+}
+
 // Start the http server
 func (s *Server) Start() error {
 	s.running = true
 	errChan := make(chan error, 1)
 
 	go func(ch chan error) {
-		if err := s.server.ListenAndServe(); err != nil {
+		if err := s.listenAndServe(); err != nil {
 			ch <- err
 		}
 	}(errChan)
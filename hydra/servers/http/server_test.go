@@ -1,11 +1,19 @@
 package http
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
 	xnet "net"
 	"testing"
 	"time"
 
 	"github.com/micro-plat/hydra/conf/server/router"
+	wsconf "github.com/micro-plat/hydra/conf/server/ws"
 	"github.com/micro-plat/hydra/global"
 	"github.com/micro-plat/lib4go/assert"
 	"github.com/micro-plat/lib4go/net"
@@ -86,3 +94,61 @@ func TestNewWSServer(t *testing.T) {
 		assert.Equal(t, time.Duration(o.writeTimeout)*time.Second, gotT.server.WriteTimeout, tt.name)
 	}
 }
+
+//newTLSConfig 生成自签名证书
+func newTLSConfig(t *testing.T, cn string) *tls.Config {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Equal(t, nil, err, "生成私钥失败")
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []xnet.IP{xnet.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, &key.PublicKey, key)
+	assert.Equal(t, nil, err, "生成证书失败")
+	return &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+}
+
+func TestServer_ReloadWSUpgrade(t *testing.T) {
+	s, err := NewWSServer("ws", "127.0.0.1:8080", nil)
+	assert.Equal(t, nil, err, "构建ws服务")
+	assert.Equal(t, &wsconf.Upgrade{}, s.getWSUpgrade(), "未设置时使用默认配置")
+
+	u1 := &wsconf.Upgrade{AllowedOrigins: []string{"*"}}
+	s, err = NewWSServer("ws", "127.0.0.1:8080", nil, WithWSUpgrade(u1))
+	assert.Equal(t, nil, err, "构建ws服务")
+	assert.Equal(t, u1, s.getWSUpgrade(), "使用构建时的配置")
+
+	u2 := &wsconf.Upgrade{Subprotocols: []string{"chat"}}
+	s.ReloadWSUpgrade(u2)
+	assert.Equal(t, u2, s.getWSUpgrade(), "配置变更后使用新配置")
+	s.ReloadWSUpgrade(nil)
+	assert.Equal(t, u2, s.getWSUpgrade(), "空配置不覆盖")
+}
+
+func TestServer_TLS(t *testing.T) {
+	l, err := xnet.Listen("tcp", "127.0.0.1:0")
+	assert.Equal(t, nil, err, "获取端口失败")
+	addr := l.Addr().String()
+	l.Close()
+
+	s, err := NewServer("api", addr, nil, WithServerType("api"), WithTLS(newTLSConfig(t, "first")))
+	assert.Equal(t, nil, err, "构建https服务")
+	assert.Equal(t, true, s.IsTLS(), "应启用TLS")
+	assert.Equal(t, nil, s.Start(), "启动https服务")
+	defer s.Shutdown()
+
+	peer := func() string {
+		conn, err := tls.Dial("tcp", addr, &tls.Config{InsecureSkipVerify: true})
+		assert.Equal(t, nil, err, "建立TLS连接")
+		defer conn.Close()
+		return conn.ConnectionState().PeerCertificates[0].Subject.CommonName
+	}
+	assert.Equal(t, "first", peer(), "服务器证书不一致")
+
+	//重新加载证书后新连接使用新证书
+	s.ReloadTLS(newTLSConfig(t, "second"))
+	assert.Equal(t, "second", peer(), "重新加载后服务器证书不一致")
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/micro-plat/hydra/context"
 	"github.com/micro-plat/lib4go/concurrent/cmap"
 	"github.com/micro-plat/lib4go/errs"
+	"github.com/micro-plat/lib4go/utility"
 )
 
 //WSExchange web socket message exchange
@@ -26,68 +27,140 @@ func Conf(queueConfName string) {
 
 //IDataExchange 数据交换接口
 type IDataExchange interface {
+
+	//Notify 向指定连接发送消息
 	Notify(uuid string, data interface{}) error
+
+	//Join 将当前节点上的连接加入房间
+	Join(uuid string, room string) error
+
+	//Leave 将连接移出房间
+	Leave(uuid string, room string) error
+
+	//Broadcast 向房间内所有连接发送消息(集群)
+	Broadcast(room string, data interface{}) error
+
+	//BroadcastAll 向所有在线连接发送消息(集群)
+	BroadcastAll(data interface{}) error
+
+	//LocalMembers 获取当前节点房间内的连接，不包含集群其它节点的连接
+	LocalMembers(room string) ([]string, error)
+
+	//LocalOnline 获取当前节点的在线连接，不包含集群其它节点的连接
+	LocalOnline() ([]string, error)
+}
+
+//iBackbone 消息队列，用于向连接或节点投递消息
+type iBackbone interface {
+
+	//Subscribe 订阅队列，消息由service对应的处理函数处理
+	Subscribe(queue string, service string, h func(requestID string, body []byte) error)
+
+	//Unsubscribe 取消订阅
+	Unsubscribe(queue string, service string)
+
+	//Send 发送消息，requestID作为消息的请求编号
+	Send(queue string, msg interface{}, requestID string) error
 }
 
 //Exchange 数据交换中心
 type Exchange struct {
 	uuid            cmap.ConcurrentMap
+	lock            sync.Mutex
+	rooms           map[string]map[string]bool
+	joined          map[string]map[string]bool
+	node            string
+	online          bool
+	backbone        iBackbone
 	queueFormatName string
 	service         string
-	once            sync.Once
+	nodeService     string
 }
 
 //NewExchange 构建数据交换中心
 func NewExchange() *Exchange {
+	return newExchange(&queueBackbone{services: cmap.New(2)})
+}
+
+func newExchange(backbone iBackbone) *Exchange {
 	return &Exchange{
 		uuid:            cmap.New(8),
+		rooms:           make(map[string]map[string]bool),
+		joined:          make(map[string]map[string]bool),
+		node:            utility.GetGUID()[0:16],
+		backbone:        backbone,
 		queueFormatName: "ws:exchange:%s",
 		service:         "/ws/handle",
+		nodeService:     "/ws/broadcast",
 	}
 }
 
-//Subscribe 订阅消息通知
+//Subscribe 订阅消息通知，当前节点登记失败时撤销订阅并返回错误
 func (e *Exchange) Subscribe(uuid string, f func(...interface{}) error) error {
-	e.once.Do(func() {
-		hydra.S.MQC(e.service, e.handle) //注册MQC服务
-	})
-
-	if ok, _ := e.uuid.SetIfAbsent(uuid, f); ok {
-		hydra.MQC.Add(e.getQueueName(uuid), e.service) //为每个用户添加处理队列
+	if ok, _ := e.uuid.SetIfAbsent(uuid, f); !ok {
+		return nil
+	}
+	if err := e.goOnline(); err != nil {
+		e.uuid.Remove(uuid)
+		return err
 	}
+	e.backbone.Subscribe(e.getQueueName(uuid), e.service, e.handle) //为每个用户添加处理队列
 	return nil
 }
 
 //Unsubscribe 取消订阅
 func (e *Exchange) Unsubscribe(uuid string) {
+	e.leaveAll(uuid)
 	e.uuid.Remove(uuid)
-	hydra.MQC.Remove(e.getQueueName(uuid), e.service) //关闭队列
+	e.backbone.Unsubscribe(e.getQueueName(uuid), e.service) //关闭队列
 }
 
 //Notify 发送通知消息
 func (e *Exchange) Notify(uuid string, msg interface{}) error {
-	queue, err := hydra.C.Queue().GetQueue(confName)
-	if err != nil {
-		return err
-	}
-	return queue.Send(e.getQueueName(uuid), msg, uuid)
+	return e.backbone.Send(e.getQueueName(uuid), msg, uuid)
 }
 
 //handle 业务回调处理
-func (e *Exchange) handle(ctx context.IContext) interface{} {
-	uuid := ctx.User().GetTraceID()
+func (e *Exchange) handle(uuid string, body []byte) error {
 	v, ok := e.uuid.Get(uuid)
 	if !ok {
 		return errs.NewError(http.StatusNoContent, nil)
 	}
 	callback := v.(func(...interface{}) error)
-	body, err := ctx.Request().GetBody()
-	if err != nil {
-		return err
-	}
 	return callback(string(body))
 }
 
 func (e *Exchange) getQueueName(id string) string {
 	return fmt.Sprintf(e.queueFormatName, id)
 }
+
+//queueBackbone 使用hydra消息队列投递消息，每个队列由当前节点的MQC服务消费
+type queueBackbone struct {
+	services cmap.ConcurrentMap
+}
+
+func (q *queueBackbone) Subscribe(queue string, service string, h func(requestID string, body []byte) error) {
+	if ok, _ := q.services.SetIfAbsent(service, h); ok {
+		//注册MQC服务
+		hydra.S.MQC(service, func(ctx context.IContext) interface{} {
+			body, err := ctx.Request().GetBody()
+			if err != nil {
+				return err
+			}
+			return h(ctx.User().GetTraceID(), body)
+		})
+	}
+	hydra.MQC.Add(queue, service)
+}
+
+func (q *queueBackbone) Unsubscribe(queue string, service string) {
+	hydra.MQC.Remove(queue, service)
+}
+
+func (q *queueBackbone) Send(queue string, msg interface{}, requestID string) error {
+	mq, err := hydra.C.Queue().GetQueue(confName)
+	if err != nil {
+		return err
+	}
+	return mq.Send(queue, msg, requestID)
+}
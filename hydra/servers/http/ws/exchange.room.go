@@ -0,0 +1,190 @@
+package ws
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/micro-plat/hydra/components/pkgs"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/lib4go/errs"
+	"github.com/micro-plat/lib4go/logger"
+)
+
+//房间成员保存在连接所在节点，注册中心仅登记ws节点(临时节点，节点退出后自动清除)；
+//广播消息通过消息队列向每个节点发送一次，由节点推送给本地的房间成员；
+//房间成员及在线连接不在集群内汇总，LocalMembers、LocalOnline仅返回当前节点的连接
+
+//roomMessage 节点广播消息
+type roomMessage struct {
+	Room string `json:"room,omitempty"`
+	All  bool   `json:"all,omitempty"`
+	Data string `json:"data"`
+}
+
+//Join 将当前节点上的连接加入房间
+func (e *Exchange) Join(uuid string, room string) error {
+	if room == "" {
+		return fmt.Errorf("房间名称不能为空")
+	}
+	if !e.uuid.Has(uuid) {
+		return errs.NewErrorf(404, "连接%s不存在或不在当前节点", uuid)
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	add(e.rooms, room, uuid)
+	add(e.joined, uuid, room)
+	return nil
+}
+
+//Leave 将连接移出房间
+func (e *Exchange) Leave(uuid string, room string) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	remove(e.rooms, room, uuid)
+	remove(e.joined, uuid, room)
+	return nil
+}
+
+//Broadcast 向房间内所有连接发送消息
+func (e *Exchange) Broadcast(room string, msg interface{}) error {
+	if room == "" {
+		return fmt.Errorf("房间名称不能为空")
+	}
+	return e.publish(&roomMessage{Room: room, Data: pkgs.GetString(msg)})
+}
+
+//BroadcastAll 向所有在线连接发送消息
+func (e *Exchange) BroadcastAll(msg interface{}) error {
+	return e.publish(&roomMessage{All: true, Data: pkgs.GetString(msg)})
+}
+
+//LocalMembers 获取当前节点房间内的连接，不包含集群其它节点的连接
+func (e *Exchange) LocalMembers(room string) ([]string, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return keys(e.rooms[room]), nil
+}
+
+//LocalOnline 获取当前节点的在线连接，不包含集群其它节点的连接
+func (e *Exchange) LocalOnline() ([]string, error) {
+	list := e.uuid.Keys()
+	sort.Strings(list)
+	return list, nil
+}
+
+//publish 向每个ws节点发送一次广播消息
+func (e *Exchange) publish(m *roomMessage) error {
+	nodes, err := e.getChildren(e.getNodePath())
+	if err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		if nerr := e.backbone.Send(e.getNodeQueueName(node), m, node); nerr != nil {
+			err = fmt.Errorf("发送广播消息到节点%s失败:%w", node, nerr)
+		}
+	}
+	return err
+}
+
+//handleNode 处理节点广播消息，推送给当前节点的房间成员
+func (e *Exchange) handleNode(node string, body []byte) error {
+	m := &roomMessage{}
+	if err := json.Unmarshal(body, m); err != nil {
+		return fmt.Errorf("广播消息格式有误:%w", err)
+	}
+	var members []string
+	if m.All {
+		members, _ = e.LocalOnline()
+	} else {
+		members, _ = e.LocalMembers(m.Room)
+	}
+	for _, uuid := range members {
+		v, ok := e.uuid.Get(uuid)
+		if !ok {
+			continue
+		}
+		if err := v.(func(...interface{}) error)(m.Data); err != nil {
+			global.Def.Log().Errorf("推送广播消息到%s失败:%v", uuid, err)
+		}
+	}
+	return nil
+}
+
+//goOnline 登记当前ws节点并订阅节点广播队列
+func (e *Exchange) goOnline() error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.online {
+		return nil
+	}
+	r, err := e.getRegistry()
+	if err != nil {
+		return err
+	}
+	if err := r.CreateTempNode(registry.Join(e.getNodePath(), e.node), global.LocalIP()); err != nil {
+		return fmt.Errorf("登记ws节点失败:%w", err)
+	}
+	e.backbone.Subscribe(e.getNodeQueueName(e.node), e.nodeService, e.handleNode)
+	e.online = true
+	return nil
+}
+
+//leaveAll 连接关闭时退出所有房间
+func (e *Exchange) leaveAll(uuid string) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	for room := range e.joined[uuid] {
+		remove(e.rooms, room, uuid)
+	}
+	delete(e.joined, uuid)
+}
+
+func (e *Exchange) getChildren(path string) ([]string, error) {
+	r, err := e.getRegistry()
+	if err != nil {
+		return nil, err
+	}
+	if ok, err := r.Exists(path); err != nil || !ok {
+		return []string{}, err
+	}
+	children, _, err := r.GetChildren(path)
+	return children, err
+}
+
+func (e *Exchange) getRegistry() (registry.IRegistry, error) {
+	return registry.GetRegistry(global.Def.RegistryAddr, logger.New("ws.exchange"))
+}
+
+//getNodePath 获取ws节点登记路径，位于当前集群路径下
+func (e *Exchange) getNodePath() string {
+	return registry.Join(global.Def.PlatName, global.Def.SysName, global.WS, global.Def.GetClusterName(), "nodes")
+}
+
+func (e *Exchange) getNodeQueueName(node string) string {
+	return e.getQueueName("node:" + node)
+}
+
+func add(m map[string]map[string]bool, k string, v string) {
+	if _, ok := m[k]; !ok {
+		m[k] = make(map[string]bool)
+	}
+	m[k][v] = true
+}
+
+func remove(m map[string]map[string]bool, k string, v string) {
+	delete(m[k], v)
+	if len(m[k]) == 0 {
+		delete(m, k)
+	}
+}
+
+func keys(m map[string]bool) []string {
+	list := make([]string, 0, len(m))
+	for k := range m {
+		list = append(list, k)
+	}
+	sort.Strings(list)
+	return list
+}
@@ -0,0 +1,136 @@
+package ws
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/micro-plat/hydra/components/pkgs"
+	"github.com/micro-plat/hydra/global"
+	_ "github.com/micro-plat/hydra/registry/registry/localmemory"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+//hub 测试用消息队列，同步投递消息并记录发送次数
+type hub struct {
+	lock     sync.Mutex
+	handlers map[string]func(string, []byte) error
+	sent     int
+}
+
+func newHub() *hub {
+	return &hub{handlers: make(map[string]func(string, []byte) error)}
+}
+
+func (h *hub) Subscribe(queue string, service string, f func(string, []byte) error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.handlers[queue] = f
+}
+
+func (h *hub) Unsubscribe(queue string, service string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	delete(h.handlers, queue)
+}
+
+func (h *hub) Send(queue string, msg interface{}, requestID string) error {
+	h.lock.Lock()
+	f, ok := h.handlers[queue]
+	h.sent++
+	h.lock.Unlock()
+	if !ok {
+		return fmt.Errorf("队列%s不存在", queue)
+	}
+	return f(requestID, []byte(pkgs.GetString(msg)))
+}
+
+//recorder 记录连接收到的消息
+type recorder struct {
+	lock sync.Mutex
+	msgs []string
+}
+
+func (r *recorder) recv(uuid string) func(...interface{}) error {
+	return func(v ...interface{}) error {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		r.msgs = append(r.msgs, fmt.Sprintf("%s:%v", uuid, v[0]))
+		return nil
+	}
+}
+
+func (r *recorder) get() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	sort.Strings(r.msgs)
+	v := r.msgs
+	r.msgs = nil
+	return v
+}
+
+func setTestRegistry(sys string) {
+	global.Def.PlatName = "hydra_test"
+	global.Def.SysName = sys
+	global.Def.ClusterName = "prod"
+	global.Def.RegistryAddr = "lm://."
+}
+
+func TestExchange_Broadcast(t *testing.T) {
+	setTestRegistry("ws_broadcast")
+	h := newHub()
+	n1, n2 := newExchange(h), newExchange(h)
+	r := &recorder{}
+	assert.Equal(t, nil, n1.Subscribe("u1", r.recv("u1")), "订阅消息")
+	assert.Equal(t, nil, n1.Subscribe("u2", r.recv("u2")), "订阅消息")
+	assert.Equal(t, nil, n2.Subscribe("u3", r.recv("u3")), "订阅消息")
+	rgst, _ := n1.getRegistry()
+	nodes, _, _ := rgst.GetChildren("/hydra_test/ws_broadcast/ws/prod/nodes")
+	sort.Strings(nodes)
+	want := []string{n1.node, n2.node}
+	sort.Strings(want)
+	assert.Equal(t, want, nodes, "ws节点登记在集群路径下")
+
+	assert.Equal(t, nil, n1.Join("u1", "room"), "加入房间")
+	assert.Equal(t, nil, n2.Join("u3", "room"), "加入房间")
+	assert.Equal(t, true, n2.Join("u1", "room") != nil, "连接不在当前节点时不能加入房间")
+	members, _ := n1.LocalMembers("room")
+	assert.Equal(t, []string{"u1"}, members, "当前节点房间成员")
+
+	//每个节点只发送一次广播消息，由节点推送给房间成员
+	assert.Equal(t, nil, n1.Broadcast("room", `{"m":"hello"}`), "广播消息")
+	assert.Equal(t, []string{`u1:{"m":"hello"}`, `u3:{"m":"hello"}`}, r.get(), "房间成员收到的消息")
+	assert.Equal(t, 2, h.sent, "每个节点发送一次")
+
+	assert.Equal(t, nil, n2.BroadcastAll(map[string]interface{}{"id": 1}), "广播消息")
+	assert.Equal(t, []string{`u1:{"id":1}`, `u2:{"id":1}`, `u3:{"id":1}`}, r.get(), "所有连接收到的消息")
+
+	//退出房间及取消订阅后不再收到消息
+	assert.Equal(t, nil, n1.Leave("u1", "room"), "退出房间")
+	n2.Unsubscribe("u3")
+	assert.Equal(t, nil, n1.Broadcast("room", `{"m":"bye"}`), "广播消息")
+	assert.Equal(t, 0, len(r.get()), "房间无成员")
+	members, _ = n2.LocalMembers("room")
+	assert.Equal(t, 0, len(members), "取消订阅后退出所有房间")
+
+	assert.Equal(t, nil, n2.Notify("u2", `{"m":"direct"}`), "发送消息")
+	assert.Equal(t, []string{`u2:{"m":"direct"}`}, r.get(), "指定连接收到的消息")
+}
+
+func TestExchange_SubscribeFailed(t *testing.T) {
+	setTestRegistry("ws_subscribe")
+	global.Def.RegistryAddr = "xx://."
+	defer func() { global.Def.RegistryAddr = "lm://." }()
+
+	h := newHub()
+	e := newExchange(h)
+	err := e.Subscribe("u1", (&recorder{}).recv("u1"))
+	assert.Equal(t, true, err != nil, "节点登记失败时应返回错误")
+	assert.Equal(t, false, e.uuid.Has("u1"), "订阅失败时撤销本地登记")
+	assert.Equal(t, 0, len(h.handlers), "订阅失败时不应订阅队列")
+	online, _ := e.LocalOnline()
+	assert.Equal(t, 0, len(online), "订阅失败时不在线")
+	assert.Equal(t, true, strings.Contains(fmt.Sprint(e.Join("u1", "room")), "不存在"), "订阅失败后不能加入房间")
+}
@@ -6,13 +6,14 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
+	wsconf "github.com/micro-plat/hydra/conf/server/ws"
 	"github.com/micro-plat/hydra/hydra/servers/pkg/middleware"
 )
 
 //upgrader 处理ws请求
 
-//WSExecuteHandler 业务处理Handler
-func WSExecuteHandler() middleware.Handler {
+//WSExecuteHandler 业务处理Handler，getConf返回服务器构建或配置变更时加载的ws连接配置
+func WSExecuteHandler(getConf func() *wsconf.Upgrade) middleware.Handler {
 	return func(ctx middleware.IMiddleContext) {
 		n, ok := ctx.Meta().Get("__context_")
 		if !ok {
@@ -20,15 +21,22 @@ func WSExecuteHandler() middleware.Handler {
 		}
 		c := n.(*gin.Context)
 
-		conn, err := getUpgrader(c.Writer, c.Request, c.Request.Header)
+		wsConf := getConf()
+		conn, err := getUpgrader(c.Writer, c.Request, wsConf)
 		if err != nil {
 			ctx.Response().Write(http.StatusNotAcceptable, fmt.Errorf("无法初始化ws.upgrader %w", err))
 			return
 		}
 
 		//构建处理函数
-		h := newWSHandler(conn, ctx.User().GetTraceID(), ctx.User().GetClientIP())
-		exchange.Subscribe(ctx.User().GetTraceID(), h.recvNotify(c))
+		h := newWSHandler(conn, ctx.User().GetTraceID(), ctx.User().GetClientIP(), wsConf)
+		if err := exchange.Subscribe(ctx.User().GetTraceID(), h.recvNotify(c)); err != nil {
+			ctx.Log().Errorf("ws订阅消息失败:%v", err)
+			conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseInternalServerErr, "订阅消息失败"))
+			conn.Close()
+			ctx.Response().NoNeedWrite(http.StatusInternalServerError)
+			return
+		}
 		defer exchange.Unsubscribe(ctx.User().GetTraceID())
 
 		//异步读取与写入
@@ -37,14 +45,14 @@ func WSExecuteHandler() middleware.Handler {
 		ctx.Response().NoNeedWrite(c.Writer.Status())
 	}
 }
-func getUpgrader(w http.ResponseWriter, r *http.Request, h http.Header) (*websocket.Conn, error) {
+
+//getUpgrader 根据配置检查请求来源，协商子协议并升级为ws连接
+func getUpgrader(w http.ResponseWriter, r *http.Request, conf *wsconf.Upgrade) (*websocket.Conn, error) {
 	var upgrader = websocket.Upgrader{
-		ReadBufferSize:  1024,
-		WriteBufferSize: 1024,
-		Subprotocols:    []string{h.Get("Sec-WebSocket-Extensions")},
-		CheckOrigin: func(r *http.Request) bool {
-			return true
-		},
+		ReadBufferSize:  conf.GetReadBufferSize(),
+		WriteBufferSize: conf.GetWriteBufferSize(),
+		Subprotocols:    conf.Subprotocols,
+		CheckOrigin:     conf.CheckOrigin,
 	}
 	return upgrader.Upgrade(w, r, nil)
 }
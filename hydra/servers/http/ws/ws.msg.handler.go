@@ -2,24 +2,13 @@ package ws
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
+	wsconf "github.com/micro-plat/hydra/conf/server/ws"
 	"github.com/micro-plat/lib4go/logger"
-)
-
-const (
-	// Time allowed to write a message to the peer.
-	writeWait = 10 * time.Second
-
-	// Time allowed to read the next pong message from the peer.
-	pongWait = 60 * time.Second
-
-	// Send pings to peer with this period. Must be less than pongWait.
-	pingPeriod = (pongWait * 9) / 10
-
-	// Maximum message size allowed from peer.
-	maxMessageSize = 512
+	"golang.org/x/time/rate"
 )
 
 var (
@@ -37,10 +26,13 @@ type wsHandler struct {
 	uuid      string
 	log       logger.ILogger
 	clientip  string
+	conf      *wsconf.Upgrade
+	limiter   *rate.Limiter
+	active    int64
 }
 
 //newWSHandler 使用新引擎进行业务处理
-func newWSHandler(conn *websocket.Conn, uuid string, clientip string) *wsHandler {
+func newWSHandler(conn *websocket.Conn, uuid string, clientip string, conf *wsconf.Upgrade) *wsHandler {
 	if wsInternalEngine == nil {
 		panic("ws internal engine未初始化")
 	}
@@ -52,6 +44,25 @@ func newWSHandler(conn *websocket.Conn, uuid string, clientip string) *wsHandler
 		log:       logger.GetSession("ws", uuid),
 		clientip:  clientip,
 		engine:    wsInternalEngine,
+		conf:      conf,
+		active:    time.Now().UnixNano(),
+	}
+	if limit, burst := conf.GetRateLimit(); limit > 0 {
+		s.limiter = rate.NewLimiter(rate.Limit(limit), burst)
 	}
 	return s
 }
+
+//touch 记录客户端最近一次发送消息的时间
+func (c *wsHandler) touch() {
+	atomic.StoreInt64(&c.active, time.Now().UnixNano())
+}
+
+//isIdle 连接是否已超过空闲时长
+func (c *wsHandler) isIdle() bool {
+	idle := c.conf.GetIdleTimeout()
+	if idle == 0 {
+		return false
+	}
+	return time.Since(time.Unix(0, atomic.LoadInt64(&c.active))) > idle
+}
@@ -13,7 +13,8 @@ func (c *wsHandler) readPump() {
 	defer func() {
 		c.close()
 	}()
-	c.conn.SetReadLimit(maxMessageSize)
+	pongWait := c.conf.GetPongTimeout()
+	c.conn.SetReadLimit(c.conf.GetMaxMessageSize())
 	c.conn.SetReadDeadline(time.Now().Add(pongWait))
 	c.conn.SetPongHandler(func(string) error { c.conn.SetReadDeadline(time.Now().Add(pongWait)); return nil })
 	for {
@@ -46,6 +47,13 @@ func (c *wsHandler) wsAction() error {
 	if tp != websocket.TextMessage && tp != websocket.BinaryMessage {
 		return nil
 	}
+	c.touch()
+
+	//检查消息频率
+	if c.limiter != nil && !c.limiter.Allow() {
+		c.sendNow("/ws.limit", http.StatusTooManyRequests, fmt.Errorf("消息发送过于频繁"))
+		return nil
+	}
 
 	//构建请求
 	req, err := NewRequest(http.MethodGet, msg, c.uuid, c.clientip)
@@ -11,7 +11,8 @@ import (
 
 //writePump 向客户端写入响应消息
 func (c *wsHandler) writePump() {
-	ticker := time.NewTicker(pingPeriod)
+	writeWait := c.conf.GetWriteTimeout()
+	ticker := time.NewTicker(c.conf.GetPingInterval())
 	defer func() {
 		ticker.Stop()
 		c.close()
@@ -40,6 +41,11 @@ func (c *wsHandler) writePump() {
 				c.close()
 			}
 		case <-ticker.C:
+			if c.isIdle() {
+				c.log.Infof("连接空闲超时，断开连接")
+				c.close()
+				break
+			}
 			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
 				c.close()
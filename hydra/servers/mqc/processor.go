@@ -2,6 +2,7 @@ package mqc
 
 import (
 	"fmt"
+	"net/http"
 	"sync"
 	"time"
 
@@ -31,6 +32,11 @@ type Processor struct {
 	customer  mq.IMQC
 	status    int
 	engine    *adapter.DispatcherEngine
+	proto     string
+	confRaw   string
+	plock     sync.Mutex
+	producer  mq.IMQP
+	after     func(time.Duration) <-chan time.Time
 }
 
 //NewProcessor 创建processor
@@ -41,6 +47,9 @@ func NewProcessor(proto string, confRaw string, routers ...*router.Router) (p *P
 		startTime: time.Now(),
 		queues:    cmap.New(4),
 		metric:    middleware.NewMetric(),
+		proto:     proto,
+		confRaw:   confRaw,
+		after:     time.After,
 	}
 
 	p.customer, err = mq.NewMQC(proto, confRaw)
@@ -53,6 +62,7 @@ func NewProcessor(proto string, confRaw string, routers ...*router.Router) (p *P
 	p.engine.Use(p.metric.Handle())
 	p.engine.Use(middleware.Logging())
 	p.engine.Use(middleware.Recovery())
+	p.engine.Use(middleware.APM())   //链路跟踪
 	p.engine.Use(middleware.Trace()) //跟踪信息
 	p.engine.Use(middlewares...)
 
@@ -87,10 +97,15 @@ func (s *Processor) Start(wait ...bool) error {
 	return nil
 }
 
-//Add 添加队列信息
+//Add 添加队列信息，队列已存在时替换为新的配置(如重试策略)，已注册的消费使用新配置处理失败的消息
 func (s *Processor) Add(queues ...*queue.Queue) error {
 	for _, queue := range queues {
-		if ok, _ := s.queues.SetIfAbsent(queue.Queue, queue); ok && s.status == running {
+		ok, _ := s.queues.SetIfAbsent(queue.Queue, queue)
+		if !ok {
+			s.queues.Set(queue.Queue, queue)
+			continue
+		}
+		if s.status == running {
 			if err := s.consume(queue); err != nil {
 				return err
 			}
@@ -99,6 +114,14 @@ func (s *Processor) Add(queues ...*queue.Queue) error {
 	return nil
 }
 
+//getQueue 获取队列的当前配置
+func (s *Processor) getQueue(q *queue.Queue) *queue.Queue {
+	if v, ok := s.queues.Get(q.Queue); ok {
+		return v.(*queue.Queue)
+	}
+	return q
+}
+
 //Remove 除移队列信息
 func (s *Processor) Remove(queues ...*queue.Queue) error {
 	for _, queue := range queues {
@@ -158,6 +181,7 @@ func (s *Processor) Close() {
 		close(s.closeChan)
 		s.queues.Clear()
 		s.customer.Close()
+		s.closeProducer()
 	}
 }
 
@@ -167,6 +191,13 @@ func (s *Processor) handle(queue *queue.Queue) func(mq.IMQCMessage) {
 		if err != nil {
 			panic(err)
 		}
-		s.engine.HandleRequest(req)
+		w, err := s.engine.HandleRequest(req)
+		if err != nil || w.Status() >= http.StatusBadRequest {
+			s.onFailed(s.getQueue(queue), req)
+			return
+		}
+
+		//处理完成后确认消息，可靠消费模式下消息才会从处理中列表删除
+		m.Ack()
 	}
 }
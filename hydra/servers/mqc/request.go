@@ -45,9 +45,26 @@ func newOldRequest(queue *queue.Queue, m mq.IMQCMessage) (r *Request, err error)
 	message := m.GetMessage()
 	json.Unmarshal(types.StringToBytes(message), &input)
 
+	//重新投递的消息包含处理次数等头信息
+	if v, ok := input["__header__"].(map[string]interface{}); ok {
+		for n, m := range v {
+			r.header[n] = fmt.Sprint(m)
+		}
+		delete(input, "__header__")
+	}
+
 	r.form = input
 	//检查是否包含头信息
 	r.form["__body__"] = message
+
+	//非json消息重新投递时原消息编码在__data__中
+	if v, ok := input["__data__"].(string); ok && len(input) == 2 {
+		if _, ok := r.header[xMqcAttempts]; ok {
+			buff, _ := base64.DecodeBytes(v)
+			delete(r.form, "__data__")
+			r.form["__body__"] = string(buff)
+		}
+	}
 	r.header["Content-Type"] = "application/json"
 
 	//处理头信息
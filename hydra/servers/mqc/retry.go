@@ -0,0 +1,108 @@
+package mqc
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/micro-plat/hydra/components/queues/mq"
+	"github.com/micro-plat/hydra/conf/server/queue"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/lib4go/encoding/base64"
+	"github.com/micro-plat/lib4go/types"
+)
+
+//xMqcAttempts 消息已处理次数
+const xMqcAttempts = "X-Mqc-Attempts"
+
+//getProducer 获取用于重新投递消息的生产者
+func (s *Processor) getProducer() (mq.IMQP, error) {
+	s.plock.Lock()
+	defer s.plock.Unlock()
+	if s.producer != nil {
+		return s.producer, nil
+	}
+	producer, err := mq.NewMQP(s.proto, s.confRaw)
+	if err != nil {
+		return nil, err
+	}
+	s.producer = producer
+	return producer, nil
+}
+
+//closeProducer 关闭生产者，本地队列的生产者与消费者共用队列，不能关闭
+func (s *Processor) closeProducer() {
+	s.plock.Lock()
+	defer s.plock.Unlock()
+	if s.producer != nil && !global.IsLocal(s.proto) {
+		s.producer.Close()
+	}
+	s.producer = nil
+}
+
+func (s *Processor) push(name string, message string) error {
+	producer, err := s.getProducer()
+	if err != nil {
+		return err
+	}
+	return producer.Push(name, message)
+}
+
+//onFailed 消息处理失败，未超过最大处理次数时延迟重新投递，否则放入死信队列，
+//未配置重试与死信队列时确认并丢弃消息，避免无法处理的消息被反复投递
+func (s *Processor) onFailed(queue *queue.Queue, req *Request) {
+	if queue.MaxAttempts <= 1 && queue.DeadLetter == "" {
+		req.Ack()
+		return
+	}
+	attempts := types.GetInt(req.header[xMqcAttempts], 1)
+	if attempts < queue.MaxAttempts {
+		go s.retry(queue, req, setAttempts(req.GetMessage(), attempts+1), queue.GetDelay(attempts))
+		return
+	}
+	if queue.DeadLetter != "" {
+		if err := s.push(queue.DeadLetter, setAttempts(req.GetMessage(), attempts)); err != nil {
+			global.Def.Log().Errorf("消息放入死信队列%s失败:%v", queue.DeadLetter, err)
+			req.Nack()
+			return
+		}
+	}
+	req.Ack()
+}
+
+//retry 延迟重新投递消息，服务关闭时取消消息
+func (s *Processor) retry(queue *queue.Queue, req *Request, message string, delay time.Duration) {
+	select {
+	case <-s.closeChan:
+		req.Nack()
+		return
+	case <-s.after(delay):
+	}
+	if err := s.push(queue.Queue, message); err != nil {
+		global.Def.Log().Errorf("消息重新投递到%s失败:%v", queue.Queue, err)
+		req.Nack()
+		return
+	}
+	req.Ack()
+}
+
+//setAttempts 设置消息头中的处理次数，消息不是json对象时将原消息编码后放入__data__
+func setAttempts(message string, attempts int) string {
+	input := make(map[string]interface{})
+	decoder := json.NewDecoder(bytes.NewReader(types.StringToBytes(message)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&input); err != nil || decoder.More() {
+		input = map[string]interface{}{
+			"__data__": base64.Encode(message),
+		}
+	}
+	header, ok := input["__header__"].(map[string]interface{})
+	if !ok {
+		header = make(map[string]interface{})
+	}
+	header[xMqcAttempts] = strconv.Itoa(attempts)
+	input["__header__"] = header
+	buff, _ := json.Marshal(input)
+	return string(buff)
+}
@@ -0,0 +1,132 @@
+package mqc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/micro-plat/hydra/components/queues/mq/lmq"
+	"github.com/micro-plat/hydra/conf/server/queue"
+	"github.com/micro-plat/lib4go/assert"
+	"github.com/micro-plat/lib4go/concurrent/cmap"
+)
+
+type testMessage struct {
+	message string
+	result  chan string
+}
+
+func newTestMessage(message string) *testMessage {
+	return &testMessage{message: message, result: make(chan string, 1)}
+}
+
+func (m *testMessage) Ack() error {
+	m.result <- "ack"
+	return nil
+}
+func (m *testMessage) Nack() error {
+	m.result <- "nack"
+	return nil
+}
+func (m *testMessage) GetMessage() string {
+	return m.message
+}
+
+func TestSetAttempts(t *testing.T) {
+	msg := setAttempts(`{"__data__":"e30=","__header__":{"X-Request-Id":"123"}}`, 2)
+	assert.Equal(t, `{"__data__":"e30=","__header__":{"X-Mqc-Attempts":"2","X-Request-Id":"123"}}`, msg, "消息不一致")
+
+	msg = setAttempts(`{"id":12345678901234567890}`, 3)
+	assert.Equal(t, `{"__header__":{"X-Mqc-Attempts":"3"},"id":12345678901234567890}`, msg, "数字精度丢失")
+
+	msg = setAttempts(`abc`, 2)
+	assert.Equal(t, `{"__data__":"YWJj","__header__":{"X-Mqc-Attempts":"2"}}`, msg, "非json消息应编码到__data__中")
+
+	q := queue.NewQueue("order", "/order/pay")
+	req, _ := newRequest(q, newTestMessage(msg))
+	assert.Equal(t, "abc", req.GetForm()["__body__"], "重新投递后原消息不一致")
+	assert.Equal(t, "2", req.header[xMqcAttempts], "处理次数不一致")
+}
+
+func TestQueue_GetDelay(t *testing.T) {
+	q := queue.NewQueue("order", "/order/pay", queue.WithRetry(5, 2, 10))
+	assert.Equal(t, time.Second*2, q.GetDelay(1), "首次重试间隔错误")
+	assert.Equal(t, time.Second*4, q.GetDelay(2), "第二次重试间隔错误")
+	assert.Equal(t, time.Second*8, q.GetDelay(3), "第三次重试间隔错误")
+	assert.Equal(t, time.Second*10, q.GetDelay(4), "重试间隔不能超过最大值")
+}
+
+//newTestProcessor 构建延迟时间由测试控制的processor
+func newTestProcessor() (*Processor, chan time.Duration, chan time.Time) {
+	delays := make(chan time.Duration, 1)
+	fire := make(chan time.Time)
+	p := &Processor{proto: "lmq", closeChan: make(chan struct{})}
+	p.after = func(d time.Duration) <-chan time.Time {
+		delays <- d
+		return fire
+	}
+	return p, delays, fire
+}
+
+func TestProcessor_onFailed(t *testing.T) {
+	p, delays, fire := newTestProcessor()
+	q := queue.NewQueue("mqc_retry_test", "/order/pay", queue.WithRetry(2, 3), queue.WithDeadLetter("mqc_retry_test_dlq"))
+
+	//首次失败，延迟后重新投递
+	m := newTestMessage(`{"__header__":{}}`)
+	req, _ := newRequest(q, m)
+	p.onFailed(q, req)
+	assert.Equal(t, time.Second*3, <-delays, "重试间隔不一致")
+	assert.Equal(t, 0, len(lmq.GetOrAddQueue(q.Queue)), "延迟时间未到不应重新投递")
+	fire <- time.Now()
+	assert.Equal(t, "ack", <-m.result, "重新投递后应确认原消息")
+	retry := <-lmq.GetOrAddQueue(q.Queue)
+	assert.Equal(t, `{"__header__":{"X-Mqc-Attempts":"2"}}`, retry, "重新投递的消息不一致")
+
+	//达到最大处理次数，放入死信队列
+	m = newTestMessage(retry)
+	req, _ = newRequest(q, m)
+	p.onFailed(q, req)
+	assert.Equal(t, "ack", <-m.result, "放入死信队列后应确认原消息")
+	assert.Equal(t, 1, len(lmq.GetOrAddQueue(q.DeadLetter)), "消息应放入死信队列")
+	assert.Equal(t, retry, <-lmq.GetOrAddQueue(q.DeadLetter), "死信消息不一致")
+	assert.Equal(t, 0, len(lmq.GetOrAddQueue(q.Queue)), "不应再重新投递")
+}
+
+func TestProcessor_onFailedClosed(t *testing.T) {
+	p, delays, _ := newTestProcessor()
+	q := queue.NewQueue("mqc_retry_closed_test", "/order/pay", queue.WithRetry(3, 1))
+
+	//等待重新投递时服务关闭，取消消息
+	m := newTestMessage(`{}`)
+	req, _ := newRequest(q, m)
+	p.onFailed(q, req)
+	<-delays
+	close(p.closeChan)
+	assert.Equal(t, "nack", <-m.result, "服务关闭后应取消消息")
+	assert.Equal(t, 0, len(lmq.GetOrAddQueue(q.Queue)), "服务关闭后不应重新投递")
+}
+
+func TestProcessor_onFailedNoRetry(t *testing.T) {
+	p, _, _ := newTestProcessor()
+	q := queue.NewQueue("mqc_noretry_test", "/order/pay")
+
+	//未配置重试与死信队列，确认并丢弃消息，不重新放回队列
+	m := newTestMessage(`{}`)
+	req, _ := newRequest(q, m)
+	p.onFailed(q, req)
+	assert.Equal(t, "ack", <-m.result, "未配置重试时应丢弃消息")
+}
+
+func TestProcessor_AddReplace(t *testing.T) {
+	p, _, _ := newTestProcessor()
+	p.queues = cmap.New(4)
+	p.status = pause
+	old := queue.NewQueue("mqc_replace_test", "/order/pay")
+	assert.Equal(t, nil, p.Add(old), "添加队列")
+
+	//重试策略变更后，失败的消息使用新配置处理
+	nq := queue.NewQueue("mqc_replace_test", "/order/pay", queue.WithRetry(3, 1))
+	assert.Equal(t, nil, p.Add(nq), "更新队列")
+	assert.Equal(t, nq, p.getQueue(old), "使用新的队列配置")
+	assert.Equal(t, 0, old.MaxAttempts, "不修改原队列配置")
+}
@@ -1,6 +1,7 @@
 package dispatcher
 
 import (
+	"context"
 	"math"
 	"strings"
 	"time"
@@ -18,6 +19,12 @@ type IRequest interface {
 	GetHeader() map[string]string
 }
 
+//IContextRequest 携带上下文的请求，上下文撤销时处理程序可通过ctx.Context()感知
+type IContextRequest interface {
+	IRequest
+	GetContext() context.Context
+}
+
 type Context struct {
 	engine    *Engine
 	writermem responseWriter
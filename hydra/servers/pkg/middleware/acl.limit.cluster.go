@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	rds "github.com/go-redis/redis"
+	"github.com/micro-plat/hydra/components"
+	"github.com/micro-plat/hydra/components/pkgs/redis"
+	"github.com/micro-plat/hydra/conf"
+	"github.com/micro-plat/hydra/conf/server/acl/limiter"
+	varredis "github.com/micro-plat/hydra/conf/vars/redis"
+	"github.com/micro-plat/lib4go/concurrent/cmap"
+)
+
+//reserveScript 集群令牌桶，按时间补充令牌并预留一个令牌，返回需等待的毫秒数。
+//等待时长超过最大等待时长时不预留令牌，使用redis服务器时间避免各节点时钟不一致
+var reserveScript = rds.NewScript(`
+redis.replicate_commands()
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local maxWait = tonumber(ARGV[3])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local v = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(v[1])
+local ts = tonumber(v[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+if now > ts then
+	tokens = math.min(burst, tokens + (now - ts) * rate / 1000)
+	ts = now
+end
+tokens = tokens - 1
+local delay = 0
+if tokens < 0 then
+	delay = math.ceil(-tokens * 1000 / rate)
+end
+if delay > maxWait then
+	return delay
+end
+redis.call('HMSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(ts))
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst * 1000 / rate) + maxWait + 1000)
+return delay`)
+
+//clusterUnavailableTime redis不可用后暂停使用集群限流的时长
+const clusterUnavailableTime = time.Second * 10
+
+//clusterUnavailable redis不可用的时间，暂停期间使用本地限流，避免每个请求都等待连接超时
+var clusterUnavailable = cmap.New(4)
+
+//clusterReserve 从集群令牌桶中获取执行令牌，返回需等待的时长及是否获取成功
+func clusterReserve(key string, rule *limiter.Rule) (time.Duration, bool, error) {
+	if rule.MaxAllow <= 0 {
+		return rule.GetDelay() + time.Second, false, nil
+	}
+	name := rule.GetRedisName()
+	if v, ok := clusterUnavailable.Get(name); ok && time.Since(v.(time.Time)) < clusterUnavailableTime {
+		return 0, false, fmt.Errorf("redis(%s)暂不可用", name)
+	}
+	client, err := getLimiterRedis(name)
+	if err != nil {
+		clusterUnavailable.Set(name, time.Now())
+		return 0, false, err
+	}
+	wait := rule.GetDelay()
+	delay, err := reserve(client, key, rule.MaxAllow, wait)
+	if err != nil {
+		clusterUnavailable.Set(name, time.Now())
+		return 0, false, err
+	}
+	clusterUnavailable.Remove(name)
+	return delay, delay <= wait, nil
+}
+
+//reserve 从令牌桶中预留一个令牌，返回需等待的时长
+func reserve(client rds.Cmdable, key string, rate int, wait time.Duration) (time.Duration, error) {
+	ms, err := reserveScript.Run(client, []string{key}, rate, rate, wait.Milliseconds()).Int64()
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+//getLimiterRedis 获取集群限流使用的redis客户端
+func getLimiterRedis(name string) (*redis.Client, error) {
+	obj, err := components.Def.Container().GetOrCreate(varredis.TypeNodeName, name, func(conf *conf.RawConf, keys ...string) (interface{}, error) {
+		if conf.IsEmpty() {
+			return nil, fmt.Errorf("节点/%s/%s未配置，或不可用", varredis.TypeNodeName, name)
+		}
+		opts := varredis.New("")
+		if err := json.Unmarshal(conf.GetRaw(), opts); err != nil {
+			return nil, fmt.Errorf("/%s/%s配置有误:%w", varredis.TypeNodeName, name, err)
+		}
+		client, err := redis.NewByConfig(opts)
+		if err != nil {
+			if client != nil {
+				client.Close()
+			}
+			return nil, err
+		}
+		return client, nil
+	}, "limiter")
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*redis.Client), nil
+}
+
+//getClusterKey 获取集群限流的令牌桶名称
+func getClusterKey(ctx IMiddleContext, rule *limiter.Rule) string {
+	return fmt.Sprintf("hydra:limiter:%s:%s", ctx.APPConf().GetServerConf().GetServerPath(), rule.Path)
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	rds "github.com/go-redis/redis"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func TestReserve(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.Equal(t, nil, err, "启动redis")
+	defer s.Close()
+	client := rds.NewClient(&rds.Options{Addr: s.Addr()})
+	defer client.Close()
+
+	now := time.Now()
+	s.SetTime(now)
+
+	//令牌桶初始为满，每秒补充2个令牌
+	for i := 0; i < 2; i++ {
+		delay, err := reserve(client, "limiter", 2, time.Second)
+		assert.Equal(t, nil, err, "预留令牌")
+		assert.Equal(t, time.Duration(0), delay, "令牌充足时无需等待")
+	}
+
+	//令牌不足，等待补充
+	delay, err := reserve(client, "limiter", 2, time.Second)
+	assert.Equal(t, nil, err, "预留令牌")
+	assert.Equal(t, time.Millisecond*500, delay, "令牌不足时应等待补充")
+
+	//等待时长超过最大等待时长，不预留令牌
+	delay, err = reserve(client, "limiter", 2, time.Millisecond*500)
+	assert.Equal(t, nil, err, "预留令牌")
+	assert.Equal(t, time.Second, delay, "已预留的令牌应计入等待时长")
+	delay, err = reserve(client, "limiter", 2, time.Millisecond*500)
+	assert.Equal(t, time.Second, delay, "超过最大等待时长时不应预留令牌")
+
+	//以redis服务器时间补充令牌
+	s.SetTime(now.Add(time.Second))
+	delay, err = reserve(client, "limiter", 2, time.Second)
+	assert.Equal(t, nil, err, "预留令牌")
+	assert.Equal(t, time.Duration(0), delay, "服务器时间推进后应补充令牌")
+	delay, err = reserve(client, "limiter", 2, 0)
+	assert.Equal(t, time.Millisecond*500, delay, "补充的令牌已用完")
+}
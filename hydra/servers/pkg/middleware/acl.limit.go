@@ -3,6 +3,8 @@ package middleware
 import (
 	"net/http"
 	"time"
+
+	"github.com/micro-plat/hydra/conf/server/acl/limiter"
 )
 
 //Limit 服务器限流配置
@@ -27,29 +29,47 @@ func Limit() Handler {
 			return
 		}
 
+		//集群限流，redis不可用时使用本地限流
+		if rule.IsCluster() {
+			delay, ok, err := clusterReserve(getClusterKey(ctx, rule), rule)
+			if err == nil {
+				limit(ctx, rule, delay, ok)
+				return
+			}
+			ctx.Log().Warnf("集群限流不可用，使用本地限流:%v", err)
+		}
+
 		//获取执行令牌
 		res := rule.GetLimiter().Reserve()
 
 		//判断请求是否需要进行延迟处理
 		delay := res.Delay()
-		if delay <= 0 {
-			ctx.Next()
-			return
-		}
-		//当前请求被限流
-		ctx.Response().AddSpecial("limit")
-		wait := rule.GetDelay()
-		if delay > wait { //当前请求将被限流，根据配置进行降级或结果输出处理
+		ok := delay <= rule.GetDelay()
+		if !ok {
 			res.Cancel()
-			ctx.Request().Path().Limit(true, rule.Fallback)
-			s, c := rule.GetResponse()
-			ctx.Response().Write(s, c)
-			ctx.Next()
-			return
 		}
+		limit(ctx, rule, delay, ok)
+	}
+}
 
-		//等待一定时间后继续处理
-		time.Sleep(delay)
+//limit 根据令牌等待时长进行延迟处理，无法获取令牌时根据配置进行降级或结果输出处理
+func limit(ctx IMiddleContext, rule *limiter.Rule, delay time.Duration, ok bool) {
+	if delay <= 0 {
 		ctx.Next()
+		return
 	}
+
+	//当前请求被限流
+	ctx.Response().AddSpecial("limit")
+	if !ok { //当前请求将被限流，根据配置进行降级或结果输出处理
+		ctx.Request().Path().Limit(true, rule.Fallback)
+		s, c := rule.GetResponse()
+		ctx.Response().Write(s, c)
+		ctx.Next()
+		return
+	}
+
+	//等待一定时间后继续处理
+	time.Sleep(delay)
+	ctx.Next()
 }
@@ -1,12 +1,18 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
+	"net"
+	"net/http"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/micro-plat/hydra/components/pkgs/metrics"
-	"github.com/micro-plat/lib4go/logger"
+	"github.com/micro-plat/hydra/conf/app"
 	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/lib4go/logger"
 )
 
 //Metric 服务器处理能力统计
@@ -17,6 +23,9 @@ type Metric struct {
 	needCollect     bool
 	once            sync.Once
 	ip              string
+	promPath        string
+	promServer      *http.Server
+	err             error
 }
 
 //NewMetric new metric
@@ -24,36 +33,67 @@ func NewMetric() *Metric {
 	return &Metric{}
 
 }
-func (m *Metric) onceDo(ctx IMiddleContext) {
+
+//Init 根据配置初始化统计及上报服务，未调用时在处理首个请求时初始化
+func (m *Metric) Init(cnf app.IAPPConf) error {
 	m.once.Do(func() {
-		metric, err := ctx.APPConf().GetMetricConf()
-		if err != nil {
-			panic(fmt.Errorf("metric配置获取失败:%w", err))
-		}
+		m.err = m.init(cnf)
+	})
+	return m.err
+}
 
-		if metric.Disable {
-			return
-		}
+func (m *Metric) init(cnf app.IAPPConf) (err error) {
+	metric, err := cnf.GetMetricConf()
+	if err != nil {
+		return fmt.Errorf("metric配置获取失败:%w", err)
+	}
+
+	if metric.Disable {
+		return nil
+	}
 
-		m.currentRegistry = metrics.NewRegistry()
-		m.ip = global.LocalIP()
-		m.logger = logger.New("metric")
-
-		//2. 创建上报服务
-		m.reporter, err = metrics.InfluxDB(m.currentRegistry,
-			metric.Cron,
-			metric.Host,
-			metric.DataBase,
-			metric.UserName,
-			metric.Password, m.logger)
+	m.currentRegistry = metrics.NewRegistry()
+	m.ip = global.LocalIP()
+	m.logger = logger.New("metric")
+
+	//1. 提供prometheus拉取接口
+	if metric.IsPrometheus() {
+		if metric.Port == "" {
+			m.promPath = metric.GetPath()
+			m.needCollect = true
+			return nil
+		}
+		mux := http.NewServeMux()
+		mux.Handle(metric.GetPath(), metrics.PrometheusHandler(m.currentRegistry))
+		m.promServer = &http.Server{Addr: net.JoinHostPort("", metric.Port), Handler: mux}
+		ln, err := net.Listen("tcp", m.promServer.Addr)
 		if err != nil {
-			panic(fmt.Errorf("初始化metric失败:%w", err))
+			return fmt.Errorf("启动prometheus拉取服务失败:%w", err)
 		}
+		go m.promServer.Serve(ln)
 		m.needCollect = true
-		//定时上报
-		go m.reporter.Run()
+		return nil
+	}
 
-	})
+	//2. 创建上报服务
+	m.reporter, err = metrics.InfluxDB(m.currentRegistry,
+		metric.Cron,
+		metric.Host,
+		metric.DataBase,
+		metric.UserName,
+		metric.Password, m.logger)
+	if err != nil {
+		return fmt.Errorf("初始化metric失败:%w", err)
+	}
+	m.needCollect = true
+	//定时上报
+	go m.reporter.Run()
+	return nil
+}
+
+//onceDo 执行首次初始化，初始化失败时已在服务器启动时返回错误，此处不再统计
+func (m *Metric) onceDo(ctx IMiddleContext) bool {
+	return m.Init(ctx.APPConf()) == nil
 }
 
 //Handle 处理请求
@@ -61,16 +101,28 @@ func (m *Metric) Handle() Handler {
 	return func(ctx IMiddleContext) {
 
 		//执行首次初始化
-		m.onceDo(ctx)
-		if !m.needCollect {
+		if !m.onceDo(ctx) || !m.needCollect {
 			ctx.Next()
 			return
 		}
 
+		//输出prometheus统计数据
+		if m.promPath != "" && strings.EqualFold(ctx.Request().Path().GetRequestPath(), m.promPath) {
+			ctx.Response().AddSpecial("metric")
+			var buff strings.Builder
+			metrics.WritePrometheus(&buff, m.currentRegistry)
+			ctx.Response().ContentType(metrics.PrometheusContentType)
+			ctx.Response().Abort(http.StatusOK, buff.String())
+			return
+		}
+
 		ctx.Response().AddSpecial("metric")
 
-		//1. 初始化三类统计器---请求的QPS/正在处理的计数器/时间统计器
-		url := ctx.Request().Path().GetRequestPath()
+		//1. 初始化三类统计器---请求的QPS/正在处理的计数器/时间统计器，使用服务注册路径避免路由参数产生过多统计项
+		url := ctx.GetRouterPath()
+		if url == "" {
+			url = ctx.Request().Path().GetRequestPath()
+		}
 		conterName := metrics.MakeName(ctx.APPConf().GetServerConf().GetServerType()+".server.request", metrics.WORKING, "server", ctx.APPConf().GetServerConf().GetServerName(), "host", m.ip, "url", url) //堵塞计数
 		timerName := metrics.MakeName(ctx.APPConf().GetServerConf().GetServerType()+".server.request", metrics.TIMER, "server", ctx.APPConf().GetServerConf().GetServerName(), "host", m.ip, "url", url)    //堵塞计数
 		requestName := metrics.MakeName(ctx.APPConf().GetServerConf().GetServerType()+".server.request", metrics.QPS, "server", ctx.APPConf().GetServerConf().GetServerName(), "host", m.ip, "url", url)    //请求数
@@ -106,4 +158,10 @@ func (m *Metric) Stop() {
 		m.reporter.Close()
 		m.reporter = nil
 	}
+	if m.promServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+		m.promServer.Shutdown(ctx)
+		m.promServer = nil
+	}
 }
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"bytes"
+	r "context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -10,6 +11,7 @@ import (
 	"net/http"
 	"net/url"
 
+	"github.com/micro-plat/hydra/context"
 	"github.com/micro-plat/hydra/global"
 	"github.com/micro-plat/hydra/hydra/servers/pkg/dispatcher"
 	"github.com/micro-plat/lib4go/types"
@@ -59,6 +61,18 @@ func (g *dispCtx) GetBody() io.ReadCloser {
 		return &buffer{Buffer: b}
 	}
 }
+func (g *dispCtx) GetParentContext() r.Context {
+	if req, ok := g.Context.Request.(dispatcher.IContextRequest); ok {
+		return req.GetContext()
+	}
+	return nil
+}
+func (g *dispCtx) GetStream() context.IStream {
+	if req, ok := g.Context.Request.(context.IStreamContext); ok {
+		return req.GetStream()
+	}
+	return nil
+}
 func (g *dispCtx) GetService() string {
 	return g.Context.Request.GetService()
 }
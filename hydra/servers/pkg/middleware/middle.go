@@ -41,6 +41,14 @@ func (m *MiddleContext) Trace(s ...interface{}) {
 	}
 }
 
+//GetStream 获取流式请求的消息流，非流式请求返回nil
+func (m *MiddleContext) GetStream() context.IStream {
+	if s, ok := m.IContext.(context.IStreamContext); ok {
+		return s.GetStream()
+	}
+	return nil
+}
+
 //NewMiddleContext 构建中间件处理handler
 func NewMiddleContext(c context.IContext, n imiddle) IMiddleContext {
 	return &MiddleContext{IContext: c, imiddle: n}
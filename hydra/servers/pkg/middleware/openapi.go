@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/services"
+)
+
+//OpenAPI debug模式下根据已注册的服务生成OpenAPI文档
+func OpenAPI() Handler {
+	return func(ctx IMiddleContext) {
+		if !global.Def.IsDebug() ||
+			ctx.Request().Path().GetMethod() != http.MethodGet ||
+			!strings.EqualFold(ctx.Request().Path().GetRequestPath(), global.Def.GetOpenAPIPath()) {
+			ctx.Next()
+			return
+		}
+		ctx.Response().AddSpecial("openapi")
+		processor, err := ctx.APPConf().GetProcessorConf()
+		if err != nil {
+			ctx.Response().Abort(http.StatusNotExtended, err)
+			return
+		}
+		doc, err := services.Def.GetOpenAPI(processor.ServicePrefix, ctx.APPConf().GetServerConf().GetServerType())
+		if err != nil {
+			ctx.Response().Abort(http.StatusInternalServerError, err)
+			return
+		}
+		buff, err := json.Marshal(doc)
+		if err != nil {
+			ctx.Response().Abort(http.StatusInternalServerError, err)
+			return
+		}
+		ctx.Response().ContentType("application/json; charset=utf-8")
+		ctx.Response().Abort(http.StatusOK, string(buff))
+	}
+}
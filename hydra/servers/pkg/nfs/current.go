@@ -2,6 +2,10 @@ package nfs
 
 import (
 	"fmt"
+	"io"
+	"time"
+
+	"github.com/micro-plat/hydra/hydra/servers/pkg/nfs/infs"
 )
 
 // Save 保存文件到本地NFS服务路径
@@ -16,6 +20,14 @@ func Save(name string, buff []byte) (string, error) {
 	return fp, nil
 }
 
+//SaveStream 以流的方式保存文件到本地NFS服务路径
+func SaveStream(name string, r io.Reader) (string, error) {
+	if currentModule == nil {
+		return "", fmt.Errorf("本地nfs未初始化或已关闭")
+	}
+	return currentModule.SaveStream(name, r)
+}
+
 //Exists 检查本地和远程文件是否存在
 func Exists(name string) bool {
 	if currentModule == nil {
@@ -24,6 +36,14 @@ func Exists(name string) bool {
 	return currentModule.Exists(name)
 }
 
+//Delete 删除文件
+func Delete(name string) error {
+	if currentModule == nil {
+		return fmt.Errorf("本地nfs未初始化或已关闭")
+	}
+	return currentModule.Delete(name)
+}
+
 //Download 下载文件
 func Download(name string) ([]byte, error) {
 	if currentModule == nil {
@@ -32,3 +52,40 @@ func Download(name string) ([]byte, error) {
 	buff, _, err := currentModule.Get(name)
 	return buff, err
 }
+
+//Open 打开文件读取流，使用完成后需关闭
+func Open(name string) (infs.File, *infs.FileStat, error) {
+	if currentModule == nil {
+		return nil, nil, fmt.Errorf("本地nfs未初始化或已关闭")
+	}
+	return currentModule.Open(name)
+}
+
+//PresignGet 生成预签名下载地址，仅S3兼容存储支持
+func PresignGet(name string, expire time.Duration) (string, error) {
+	p, err := getPresigner()
+	if err != nil {
+		return "", err
+	}
+	return p.PresignGet(name, expire)
+}
+
+//PresignPut 生成预签名上传地址，仅S3兼容存储支持
+func PresignPut(name string, expire time.Duration) (string, error) {
+	p, err := getPresigner()
+	if err != nil {
+		return "", err
+	}
+	return p.PresignPut(name, expire)
+}
+
+func getPresigner() (infs.IPresigner, error) {
+	if currentModule == nil {
+		return nil, fmt.Errorf("本地nfs未初始化或已关闭")
+	}
+	p, ok := currentModule.(infs.IPresigner)
+	if !ok {
+		return nil, fmt.Errorf("当前nfs不支持预签名地址")
+	}
+	return p, nil
+}
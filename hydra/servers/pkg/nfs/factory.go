@@ -9,12 +9,15 @@ import (
 	"github.com/micro-plat/hydra/hydra/servers/pkg/nfs/infs"
 	"github.com/micro-plat/hydra/hydra/servers/pkg/nfs/lnfs"
 	"github.com/micro-plat/hydra/hydra/servers/pkg/nfs/obs"
+	"github.com/micro-plat/hydra/hydra/servers/pkg/nfs/s3"
 )
 
 func getNFS(app app.IAPPConf, c *nfs.NFS) infs.Infs {
 	switch strings.ToUpper(c.CloudNFS) {
 	case "OBS":
 		return obs.NewOBS(c.AccessKey, c.SecretKey, c.Local, c.Endpoint, c.Excludes, c.Includes...)
+	case "S3":
+		return s3.NewS3(c.AccessKey, c.SecretKey, c.Local, c.Endpoint, c.Region, c.PathStyle, c.Excludes, c.Includes...)
 	case "":
 		return lnfs.NewNFS(app, c)
 	default:
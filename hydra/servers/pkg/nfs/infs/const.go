@@ -14,6 +14,12 @@ const (
 	DIRNAME = "dir"
 
 	NDIRNAME = "ndir"
+
+	NFILENAME = "nname"
+
+	UPLOADID = "upload_id"
+
+	PARTNUMBER = "part_number"
 )
 const (
 	DIR = "dir"
@@ -24,6 +30,24 @@ const (
 
 	SVSPreview = "/nfs/preview"
 
+	//SVSChunkUpload 分片上传服务前缀
+	SVSChunkUpload = "/nfs/upload/chunk"
+
+	//SVSChunkInit 创建分片上传任务
+	SVSChunkInit = "/init"
+
+	//SVSChunkPart 上传分片
+	SVSChunkPart = "/part"
+
+	//SVSChunkStatus 查询已上传的分片
+	SVSChunkStatus = "/status"
+
+	//SVSChunkComplete 合并分片
+	SVSChunkComplete = "/complete"
+
+	//SVSChunkAbort 取消上传
+	SVSChunkAbort = "/abort"
+
 	//SVSDonwload 用户端下载文件
 	SVSDonwload = "/nfs/file/:dir/:name"
 
@@ -42,6 +66,12 @@ const (
 	//SVSRenameDir 重命名文件目录
 	SVSRenameDir = "/nfs/create/:dir/:ndir"
 
+	//SVSDelete 删除文件
+	SVSDelete = "/nfs/delete"
+
+	//SVSMove 移动文件
+	SVSMove = "/nfs/move"
+
 	//获取远程文件的指纹信息
 	RMT_FP_GET = "/_/nfs/fp/get"
 
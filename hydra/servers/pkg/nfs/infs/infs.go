@@ -1,9 +1,12 @@
 package infs
 
 import (
+	"fmt"
+	"io"
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 type Infs interface {
@@ -15,14 +18,44 @@ type Infs interface {
 	GetFileList(path string, q string, all bool, index int, count int) FileList
 
 	Save(string, []byte) (string, error)
+	SaveStream(string, io.Reader) (string, error)
 	Get(string) ([]byte, string, error)
+	Open(string) (File, *FileStat, error)
 	CreateDir(string) error
 	Rename(string, string) error
+	Delete(string) error
+	Move(string, string) error
 	GetScaleImage(path string, width int, height int, quality int) (buff []byte, ctp string, err error)
 	GetPDF4Preview(path string) (buff []byte, ctp string, err error)
 	Registry(tp string)
 }
 
+//IPresigner 支持生成预签名地址的存储，客户端可使用预签名地址直接上传或下载文件
+type IPresigner interface {
+	PresignGet(path string, expire time.Duration) (string, error)
+	PresignPut(path string, expire time.Duration) (string, error)
+}
+
+//File 文件读取流，支持顺序读取、随机读取与定位
+type File interface {
+	io.ReadSeeker
+	io.ReaderAt
+	io.Closer
+}
+
+//FileStat 文件基本信息
+type FileStat struct {
+	Size        int64
+	ModTime     time.Time
+	ContentType string
+	ETag        string
+}
+
+//GetETag 根据文件大小与修改时间生成ETag
+func GetETag(size int64, modTime time.Time) string {
+	return fmt.Sprintf(`"%x-%x"`, modTime.UnixNano(), size)
+}
+
 //处理多级目录
 func MultiPath(path string) string {
 	return strings.Trim(strings.ReplaceAll(path, "|", "/"), "/")
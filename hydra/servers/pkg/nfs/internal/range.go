@@ -0,0 +1,120 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+//RangeFile 远程对象读取流，顺序读取时按当前位置发起一次范围请求，随机读取时按需发起范围请求
+type RangeFile struct {
+	size   int64
+	offset int64
+	body   io.ReadCloser
+	get    func(start int64, end int64) (io.ReadCloser, error)
+}
+
+//NewRangeFile 构建远程对象读取流，get用于获取[start,end]范围的内容
+func NewRangeFile(size int64, get func(start int64, end int64) (io.ReadCloser, error)) *RangeFile {
+	return &RangeFile{size: size, get: get}
+}
+
+//Read 从当前位置顺序读取
+func (f *RangeFile) Read(p []byte) (n int, err error) {
+	if f.offset >= f.size {
+		return 0, io.EOF
+	}
+	if f.body == nil {
+		if f.body, err = f.get(f.offset, f.size-1); err != nil {
+			return 0, err
+		}
+	}
+	n, err = f.body.Read(p)
+	f.offset += int64(n)
+	if err == io.EOF && f.offset < f.size {
+		return n, io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+//Seek 设置读取位置，位置变化时关闭当前请求
+func (f *RangeFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.size
+	default:
+		return 0, errors.New("无效的whence参数")
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("无效的读取位置:%d", offset)
+	}
+	if offset != f.offset {
+		f.closeBody()
+		f.offset = offset
+	}
+	return offset, nil
+}
+
+//ReadAt 从指定位置读取
+func (f *RangeFile) ReadAt(p []byte, off int64) (n int, err error) {
+	if off < 0 {
+		return 0, fmt.Errorf("无效的读取位置:%d", off)
+	}
+	if off >= f.size {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	end := off + int64(len(p)) - 1
+	if end >= f.size {
+		end = f.size - 1
+	}
+	body, err := f.get(off, end)
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+	n, err = io.ReadFull(body, p[:end-off+1])
+	if err != nil {
+		return n, err
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+//Close 关闭读取流
+func (f *RangeFile) Close() error {
+	return f.closeBody()
+}
+
+func (f *RangeFile) closeBody() error {
+	if f.body == nil {
+		return nil
+	}
+	err := f.body.Close()
+	f.body = nil
+	return err
+}
+
+//rangeBody 范围请求的返回内容，丢弃多请求的字节并限制读取长度
+type rangeBody struct {
+	io.Reader
+	io.Closer
+}
+
+//NewRangeBody 构建范围请求的返回内容，跳过skip个字节后最多读取length个字节
+func NewRangeBody(body io.ReadCloser, skip int64, length int64) (io.ReadCloser, error) {
+	if skip > 0 {
+		if _, err := io.CopyN(io.Discard, body, skip); err != nil {
+			body.Close()
+			return nil, err
+		}
+	}
+	return &rangeBody{Reader: io.LimitReader(body, length), Closer: body}, nil
+}
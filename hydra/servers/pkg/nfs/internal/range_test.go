@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func newTestRangeFile(content []byte, calls *int) *RangeFile {
+	return NewRangeFile(int64(len(content)), func(start int64, end int64) (io.ReadCloser, error) {
+		*calls++
+		return ioutil.NopCloser(bytes.NewReader(content[start : end+1])), nil
+	})
+}
+
+func TestRangeFile_Read(t *testing.T) {
+	calls := 0
+	content := []byte("0123456789")
+	f := newTestRangeFile(content, &calls)
+	defer f.Close()
+
+	buff, err := ioutil.ReadAll(f)
+	assert.Equal(t, nil, err, "顺序读取")
+	assert.Equal(t, content, buff, "读取全部内容")
+	assert.Equal(t, 1, calls, "顺序读取只发起一次请求")
+
+	n, err := f.Seek(-3, io.SeekEnd)
+	assert.Equal(t, nil, err, "从末尾定位")
+	assert.Equal(t, int64(7), n, "定位位置")
+	buff, err = ioutil.ReadAll(f)
+	assert.Equal(t, nil, err, "定位后读取")
+	assert.Equal(t, "789", string(buff), "读取剩余内容")
+	assert.Equal(t, 2, calls, "定位后重新发起请求")
+
+	_, err = f.Seek(-1, io.SeekStart)
+	assert.NotEqual(t, nil, err, "无效的定位位置")
+}
+
+func TestRangeFile_ReadAt(t *testing.T) {
+	calls := 0
+	f := newTestRangeFile([]byte("0123456789"), &calls)
+	defer f.Close()
+
+	p := make([]byte, 4)
+	n, err := f.ReadAt(p, 2)
+	assert.Equal(t, nil, err, "随机读取")
+	assert.Equal(t, "2345", string(p[:n]), "读取指定范围")
+
+	n, err = f.ReadAt(p, 8)
+	assert.Equal(t, io.EOF, err, "读取到末尾")
+	assert.Equal(t, "89", string(p[:n]), "读取剩余内容")
+
+	_, err = f.ReadAt(p, 10)
+	assert.Equal(t, io.EOF, err, "超出文件大小")
+}
+
+func TestRangeBody(t *testing.T) {
+	body, err := NewRangeBody(ioutil.NopCloser(bytes.NewReader([]byte("0123456789"))), 1, 1)
+	assert.Equal(t, nil, err, "跳过多读取的字节")
+	buff, _ := ioutil.ReadAll(body)
+	assert.Equal(t, "1", string(buff), "只返回请求的字节")
+}
@@ -1,6 +1,7 @@
 package lnfs
 
 import (
+	"path/filepath"
 	"time"
 
 	"github.com/micro-plat/lib4go/types"
@@ -10,10 +11,13 @@ type EFileFPLists map[string]*eFileFP
 
 func (e EFileFPLists) Merge(list EFileFPLists) {
 	for k, v := range list {
-		if _, ok := e[k]; !ok {
+		if _, ok := e[k]; !ok || v.supersede(e[k]) {
 			e[k] = v
 			continue
 		}
+		if e[k].supersede(v) {
+			continue
+		}
 		e[k].MergeHosts(v.Hosts...)
 	}
 }
@@ -43,6 +47,19 @@ type eFileFP struct {
 
 	//文件大小
 	Size int64 `json:"size,omitempty"`
+
+	//文件内容的sha256摘要(十六进制)，用于内容去重与拉取、巡检时校验
+	SHA256 string `json:"sha256,omitempty"`
+
+	//删除标记，文件删除后保留指纹，ModTime为删除时间，Hosts为已删除文件的服务器
+	Deleted bool `json:"deleted,omitempty"`
+}
+
+//newTombstone 构建删除标记
+func newTombstone(name string, t time.Time, hosts ...string) *eFileFP {
+	fp := &eFileFP{Path: name, Name: filepath.Base(name), ModTime: t, Deleted: true}
+	fp.MergeHosts(hosts...)
+	return fp
 }
 
 //eFileEntity 文件实体
@@ -103,6 +120,28 @@ func (e *eFileFP) GetAliveHost(aliveHosts ...string) []string {
 	return nhost
 }
 
+//supersede 当前指纹是否取代指定指纹，删除时间不早于文件修改时间时删除标记取代文件，
+//文件修改时间晚于删除时间(删除后重新创建)时文件取代删除标记，
+//同一文件在不同服务器上内容不一致时修改时间晚的取代修改时间早的，时间相同时按摘要排序
+func (e *eFileFP) supersede(o *eFileFP) bool {
+	if !e.Deleted && !o.Deleted {
+		if e.SHA256 == "" || o.SHA256 == "" || e.SHA256 == o.SHA256 {
+			return false
+		}
+		if e.ModTime.Equal(o.ModTime) {
+			return e.SHA256 > o.SHA256
+		}
+		return e.ModTime.After(o.ModTime)
+	}
+	if e.Deleted == o.Deleted {
+		return false
+	}
+	if e.Deleted {
+		return !e.ModTime.Before(o.ModTime)
+	}
+	return e.ModTime.After(o.ModTime)
+}
+
 func (e *eFileFP) Has(host string) bool {
 	for _, h := range e.Hosts {
 		if h == host {
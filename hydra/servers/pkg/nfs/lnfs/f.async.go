@@ -1,6 +1,7 @@
 package lnfs
 
 import (
+	"io"
 	"time"
 
 	"github.com/micro-plat/lib4go/concurrent/cmap"
@@ -45,8 +46,11 @@ func (m *async) DoReport(f EFileFPLists) {
 	}
 	for k, v := range f {
 		mv, ok := m.reportList.Get(k)
-		if ok {
+		if ok && !v.supersede(mv.(*eFileFP)) {
 			nv := mv.(*eFileFP)
+			if nv.supersede(v) {
+				continue
+			}
 			nv.MergeHosts(v.Hosts...)
 			m.reportList.Set(k, nv)
 			continue
@@ -126,19 +130,15 @@ func (m *async) loopDownload() {
 			if !ok {
 				return
 			}
-			if m.local.Has(f.Path) {
-				continue
-			}
-			//从远程拉取文件
-			buff, err := m.remoting.Pull(f)
-			if err != nil {
-				go func() {
-					time.Sleep(time.Second * 60)
-					m.DoDownload(f) //出错自动下载
-				}()
+			if m.local.Match(f) || m.local.IsDeleted(f) {
 				continue
 			}
-			fx, err := m.local.SaveFile(f.Path, buff, f.Hosts...)
+			//从远程拉取文件并保存到本地
+			var fx *eFileFP
+			err := m.remoting.Pull(f, func(r io.Reader) (err error) {
+				fx, err = m.local.SaveRemote(f, r)
+				return err
+			})
 			if err != nil {
 				go func() {
 					time.Sleep(time.Second * 60)
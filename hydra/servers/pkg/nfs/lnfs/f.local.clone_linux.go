@@ -0,0 +1,31 @@
+// +build linux
+
+package lnfs
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+//cloneFile 以写时复制(reflink)的方式复制文件，两个文件共用磁盘数据但相互独立，
+//任一文件被修改不影响另一文件，文件系统不支持时返回错误
+func cloneFile(src string, dst string) error {
+	sf, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer sf.Close()
+	df, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+	if err != nil {
+		return err
+	}
+	err = unix.IoctlFileClone(int(df.Fd()), int(sf.Fd()))
+	if cerr := df.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(dst)
+	}
+	return err
+}
@@ -0,0 +1,10 @@
+// +build !linux
+
+package lnfs
+
+import "errors"
+
+//cloneFile 当前系统不支持写时复制，不进行内容去重
+func cloneFile(src string, dst string) error {
+	return errors.New("不支持写时复制")
+}
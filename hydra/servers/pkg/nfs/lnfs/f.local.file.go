@@ -1,31 +1,136 @@
 package lnfs
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/micro-plat/hydra/hydra/servers/pkg/nfs/infs"
+	"github.com/micro-plat/lib4go/errs"
 )
 
 //SaveFile 保存文件
 func (l *local) SaveFile(name string, buff []byte, hosts ...string) (f *eFileFP, err error) {
 
 	//将文件写入本地
-	if err := l.FWrite(name, buff); err != nil {
+	digest, err := l.FWrite(name, buff)
+	if err != nil {
 		return nil, fmt.Errorf("保存文件失败:%w", err)
 	}
 
-	//生成crc64并
+	return l.addFP(name, digest, hosts...)
+}
+
+//SaveStream 以流的方式保存文件
+func (l *local) SaveStream(name string, r io.Reader, hosts ...string) (f *eFileFP, err error) {
+
+	//将文件写入本地
+	digest, err := l.FWriteStream(name, r)
+	if err != nil {
+		return nil, fmt.Errorf("保存文件失败:%w", err)
+	}
+	return l.addFP(name, digest, hosts...)
+}
+
+//SaveRemote 保存从远程拉取的文件流，内容与指纹摘要不一致时不保存，文件修改时间与远程指纹保持一致
+func (l *local) SaveRemote(f *eFileFP, r io.Reader) (*eFileFP, error) {
+	digest, err := l.fwrite(f.Path, r, f.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("保存文件失败:%w", err)
+	}
+	if !f.ModTime.IsZero() {
+		os.Chtimes(filepath.Join(l.path, f.Path), f.ModTime, f.ModTime)
+	}
+	return l.addFP(f.Path, digest, f.Hosts...)
+}
+
+//DeleteFile 删除本地文件，并记录删除标记
+func (l *local) DeleteFile(name string) (*eFileFP, error) {
+	if err := l.FRemove(name); err != nil {
+		return nil, err
+	}
+	fp := newTombstone(name, time.Now(), l.currentAddr)
+	l.FPS.Set(name, fp)
+	return fp, l.FPWrite(l.FPS.Items())
+}
+
+//MoveFile 移动本地文件或目录，原文件记录删除标记，新文件记录指纹
+func (l *local) MoveFile(oname string, nname string) (EFileFPLists, error) {
+	names := l.Names(oname)
+	if len(names) == 0 {
+		return nil, errs.NewErrorf(http.StatusNotFound, "文件%s不存在", oname)
+	}
+	npath := filepath.Join(l.path, nname)
+	if _, err := os.Stat(npath); err == nil || l.Has(nname) {
+		return nil, fmt.Errorf("文件名称重复:%s", nname)
+	}
+	if err := os.MkdirAll(filepath.Dir(npath), 0777); err != nil {
+		return nil, fmt.Errorf("创建目录失败:%w", err)
+	}
+	if err := os.Rename(filepath.Join(l.path, oname), npath); err != nil {
+		return nil, fmt.Errorf("移动文件失败:%w", err)
+	}
+
+	//新文件使用当前时间作为修改时间，避免被目标路径上的删除标记取代
+	now := time.Now()
+	changes := make(EFileFPLists, len(names)*2)
+	for _, name := range names {
+		target := filepath.Join(nname, strings.TrimPrefix(name, oname))
+		os.Chtimes(filepath.Join(l.path, target), now, now)
+		digest := ""
+		if v, ok := l.GetFP(name); ok {
+			digest = v.SHA256
+		}
+		fp, err := l.addFP(target, digest)
+		if err != nil {
+			return nil, err
+		}
+		changes[target] = fp
+		changes[name] = newTombstone(name, now, l.currentAddr)
+		l.FPS.Set(name, changes[name])
+	}
+	return changes, l.FPWrite(l.FPS.Items())
+}
+
+//Names 获取文件或目录下的所有未删除的文件
+func (l *local) Names(name string) []string {
+	if l.Has(name) {
+		return []string{name}
+	}
+	prefix := strings.TrimSuffix(name, "/") + "/"
+	names := make([]string, 0, 1)
+	for k, v := range l.FPS.Items() {
+		if strings.HasPrefix(k, prefix) && !v.(*eFileFP).Deleted {
+			names = append(names, k)
+		}
+	}
+	return names
+}
+
+//addFP 添加文件指纹，文件大小与修改时间以本地文件为准
+func (l *local) addFP(name string, digest string, hosts ...string) (f *eFileFP, err error) {
+	info, err := os.Stat(filepath.Join(l.path, name))
+	if err != nil {
+		return nil, fmt.Errorf("读取文件失败:%w", err)
+	}
 	fp := &eFileFP{
-		Path: name,
-		// CRC64: getCRC64(buff),
+		Path:    name,
+		Name:    filepath.Base(name),
+		ModTime: info.ModTime(),
+		Size:    info.Size(),
+		SHA256:  digest,
 	}
 	fp.MergeHosts(hosts...)
 	fp.MergeHosts(l.currentAddr)
 	l.FPS.Set(name, fp)
-	return fp, l.FPWrite(l.FPS)
+	return fp, l.FPWrite(l.FPS.Items())
 }
 
 //FRead 读取文件，本地不存在
@@ -37,23 +142,62 @@ func (l *local) FRead(name string) ([]byte, error) {
 	return buff, nil
 }
 
-//FWrite 写入文件到本地
-func (l *local) FWrite(name string, buff []byte) error {
+//FWrite 写入文件到本地，返回文件内容摘要
+func (l *local) FWrite(name string, buff []byte) (string, error) {
+	return l.FWriteStream(name, bytes.NewReader(buff))
+}
+
+//FRemove 删除本地文件，文件不存在时忽略
+func (l *local) FRemove(name string) error {
+	err := os.Remove(filepath.Join(l.path, name))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("删除文件失败:%w", err)
+	}
+	return nil
+}
+
+//FWriteStream 以流的方式写入文件到本地，先写入临时文件，完成后重命名，返回文件内容摘要
+//内容相同的文件共用同一份磁盘数据(硬链接到内容对象)
+func (l *local) FWriteStream(name string, r io.Reader) (string, error) {
+	return l.fwrite(name, r, "")
+}
+
+//fwrite 写入文件到本地，指定了摘要(expect)时内容不一致则丢弃临时文件
+func (l *local) fwrite(name string, r io.Reader, expect string) (string, error) {
 	rpath := filepath.Join(l.path, name)
 
 	//处理目录
 	dir := filepath.Dir(rpath)
-	_, err := os.Stat(dir)
-	if os.IsNotExist(err) {
-		os.MkdirAll(dir, 0777)
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return "", fmt.Errorf("创建目录失败:%w", err)
 	}
 
-	//生成文件
-	err = os.WriteFile(rpath, buff, 0666)
+	//写入临时文件，以"."开头的文件不会被监控与同步
+	f, err := os.CreateTemp(dir, ".upload-*")
 	if err != nil {
-		return fmt.Errorf("写文件失败:%w", err)
+		return "", fmt.Errorf("写文件失败:%w", err)
 	}
-	return nil
+	defer os.Remove(f.Name())
+	h := sha256.New()
+	if _, err = io.Copy(io.MultiWriter(f, h), r); err != nil {
+		f.Close()
+		return "", fmt.Errorf("写文件失败:%w", err)
+	}
+	if err = f.Close(); err != nil {
+		return "", fmt.Errorf("写文件失败:%w", err)
+	}
+	if err = os.Chmod(f.Name(), 0666); err != nil {
+		return "", fmt.Errorf("写文件失败:%w", err)
+	}
+	digest := hex.EncodeToString(h.Sum(nil))
+	if expect != "" && digest != expect {
+		return "", fmt.Errorf("文件内容校验失败:%s", name)
+	}
+	l.dedup(f.Name(), digest)
+	if err = os.Rename(f.Name(), rpath); err != nil {
+		return "", fmt.Errorf("写文件失败:%w", err)
+	}
+	return digest, nil
 }
 
 //FList 获取本地所有文件清单
@@ -134,16 +278,19 @@ func (l *local) FindChange() bool {
 
 	//处理不一致数据
 	for _, entity := range lst {
-		if ok := l.FPS.Has(entity.Path); !ok {
-			fp := &eFileFP{
-				Path:    entity.Path,
-				Size:    entity.Size,
-				ModTime: entity.ModTime,
-				Hosts:   []string{l.currentAddr},
-			}
-			l.FPS.Set(entity.Path, fp)
-			change = true
+		fp := &eFileFP{
+			Path:    entity.Path,
+			Name:    entity.Name,
+			Size:    entity.Size,
+			ModTime: entity.ModTime,
+			Hosts:   []string{l.currentAddr},
+		}
+		if v, ok := l.GetFP(entity.Path); ok && !fp.supersede(v) {
+			continue
 		}
+		fp.SHA256 = l.digest(entity, nil)
+		l.FPS.Set(entity.Path, fp)
+		change = true
 	}
 	return change
 }
@@ -6,9 +6,22 @@ import (
 	"os"
 )
 
-//FPHas 本地是否存在文件
+//FPHas 本地是否存在文件，已删除的文件视为不存在
 func (l *local) Has(name string) bool {
-	return l.FPS.Has(name)
+	fp, ok := l.GetFP(name)
+	return ok && !fp.Deleted
+}
+
+//Match 本地是否存在与指定指纹内容一致(或更新)的文件，内容不一致且远程文件更新时需重新拉取
+func (l *local) Match(f *eFileFP) bool {
+	fp, ok := l.GetFP(f.Path)
+	return ok && !fp.Deleted && !f.supersede(fp)
+}
+
+//IsDeleted 指定的远程文件是否已被本地删除标记取代
+func (l *local) IsDeleted(f *eFileFP) bool {
+	fp, ok := l.GetFP(f.Path)
+	return ok && fp.supersede(f)
 }
 
 //GetFP 获以FP配置
@@ -30,7 +43,7 @@ func (l *local) GetFPs() EFileFPLists {
 	return list
 }
 
-//FPWrite 写入本地文件
+//FPWrite 写入本地文件，先写入临时文件再重命名，避免并发写入时读取到不完整的内容
 func (l *local) FPWrite(content interface{}) error {
 	buff, err := json.Marshal(content)
 	if err != nil {
@@ -40,7 +53,20 @@ func (l *local) FPWrite(content interface{}) error {
 	if os.IsNotExist(s) {
 		os.MkdirAll(l.path, 0777)
 	}
-	return os.WriteFile(l.fpPath, buff, 0666)
+	f, err := os.CreateTemp(l.path, ".fp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err = f.Write(buff); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	os.Chmod(f.Name(), 0666)
+	return os.Rename(f.Name(), l.fpPath)
 }
 
 //FPRead 读取指纹信息
@@ -7,12 +7,16 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/micro-plat/hydra"
 	"github.com/micro-plat/lib4go/concurrent/cmap"
 )
 
+//tombstoneExpire 删除标记保留时长，超过时长未同步到删除标记的服务器可能重新同步已删除的文件
+const tombstoneExpire = time.Hour * 24 * 7
+
 //local 本地文件管理
 type local struct {
 	path        string
@@ -28,6 +32,7 @@ type local struct {
 	includes    []string
 	lockValue   int32
 	done        bool
+	dedupable   bool
 }
 
 //newLocal 构建本地处理服务
@@ -42,6 +47,9 @@ func newLocal(path string, excludes []string, includes []string) *local {
 		init:      true,
 		fpPath:    filepath.Join(path, ".fp"),
 	}
+	if l.dedupable = reflinkSupported(filepath.Join(path, objectDir)); !l.dedupable {
+		hydra.G.Log().Warnf("nfs目录%s所在文件系统不支持写时复制(reflink)，不进行内容去重", path)
+	}
 	l.nfsChecker.Add(1)
 	l.fsWatcher, _ = fsnotify.NewWatcher()
 	l.fsWatcher.Add(path)
@@ -89,16 +97,42 @@ func (l *local) Update(currentAddr string) {
 }
 
 //Merge 合并到本地列表
+//1. 远程删除标记取代本地文件时删除本地文件，本地没有时记录删除标记，避免从其它服务器重新拉取
+//2. 远程文件取代本地删除标记(删除后重新创建)或本地不存在时下载文件
+//3. 本地删除标记取代远程文件时上报删除标记，通知其它服务器删除
 func (l *local) Merge(list EFileFPLists) (reports EFileFPLists, download EFileFPLists, err error) {
 	reports = make(EFileFPLists, 10)
 	download = make(EFileFPLists, 10)
 	for _, fp := range list {
 		nlk, ok := l.FPS.Get(fp.Path)
 		if !ok {
-			download[fp.Path] = fp
+			if !fp.Deleted {
+				download[fp.Path] = fp
+				continue
+			}
+			fp.MergeHosts(l.currentAddr)
+			l.FPS.Set(fp.Path, fp)
+			reports[fp.Path] = fp
 			continue
 		}
 		lk := nlk.(*eFileFP)
+		if fp.supersede(lk) {
+			if !fp.Deleted {
+				download[fp.Path] = fp
+				continue
+			}
+			if err := l.FRemove(fp.Path); err != nil {
+				return nil, nil, err
+			}
+			fp.MergeHosts(l.currentAddr)
+			l.FPS.Set(fp.Path, fp)
+			reports[fp.Path] = fp
+			continue
+		}
+		if lk.supersede(fp) {
+			reports[fp.Path] = lk
+			continue
+		}
 		v0 := fp.MergeHosts(l.currentAddr)
 		v1 := lk.MergeHosts(fp.Hosts...)
 		v2 := fp.MergeHosts(lk.Hosts...)
@@ -166,16 +200,42 @@ func (l *local) check() error {
 			ModTime: entity.ModTime,
 			Hosts:   []string{l.currentAddr},
 		}
-		if v, ok := fps[entity.Path]; ok {
-			fp.MergeHosts(v.Hosts...)
+		v, ok := l.GetFP(entity.Path)
+		if !ok {
+			v, ok = fps[entity.Path]
 		}
+		if ok {
+			//文件已被删除，删除本地残留的文件
+			if v.supersede(fp) {
+				l.FRemove(entity.Path)
+				continue
+			}
+			if !v.Deleted {
+				fp.MergeHosts(v.Hosts...)
+			}
+		}
+		fp.SHA256 = l.digest(entity, v)
 		l.FPS.Set(entity.Path, fp)
 	}
 
+	//保留有效期内的删除标记，移除已不存在的文件
 	lstMap := lst.GetMap()
 	for _, fp := range fps {
+		if fp.Deleted {
+			if v, ok := l.FPS.Get(fp.Path); ok && v.(*eFileFP).supersede(fp) {
+				continue
+			}
+			if time.Since(fp.ModTime) > tombstoneExpire {
+				l.FPS.Remove(fp.Path)
+				continue
+			}
+			l.FPS.Set(fp.Path, fp)
+			continue
+		}
 		if _, ok := lstMap[fp.Path]; !ok {
-			delete(fps, fp.Path)
+			if v, ok := l.GetFP(fp.Path); ok && v.Deleted {
+				continue
+			}
 			l.FPS.Remove(fp.Path)
 		}
 	}
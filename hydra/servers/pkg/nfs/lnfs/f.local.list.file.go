@@ -26,6 +26,9 @@ func (l *local) getFileList(path string, q string, all bool) infs.FileList {
 	list := make(infs.FileList, 0, 1)
 	fps := l.GetFPs()
 	for k, v := range fps {
+		if v.Deleted {
+			continue
+		}
 
 		if all || !all && k == filepath.Join(path, v.Name) {
 
@@ -0,0 +1,117 @@
+package lnfs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+//objectDir 内容对象目录，以"."开头不会被监控与同步
+//目录中的对象是私有副本，不与用户文件共用inode
+const objectDir = ".objects"
+
+//objectPath 获取内容对象路径，按摘要前两位分目录存放
+func (l *local) objectPath(digest string) string {
+	return filepath.Join(l.path, objectDir, digest[:2], digest)
+}
+
+//dedup 内容去重，已存在相同内容的对象时将文件替换为对象的写时复制副本，否则由文件复制出新的内容对象
+//文件与对象之间只共用磁盘数据块，不使用硬链接，修改任一文件不影响其它文件
+//替换前校验对象摘要，对象已损坏时删除对象并由文件重新生成
+//去重依赖写时复制(reflink)，仅支持linux下的btrfs、xfs等文件系统，不支持时保留原文件，启动时记录一次警告
+func (l *local) dedup(name string, digest string) {
+	if !l.dedupable {
+		return
+	}
+	obj := l.objectPath(digest)
+	oinfo, err := os.Stat(obj)
+	if err == nil {
+		if odigest, derr := fileDigest(obj); derr != nil || odigest != digest {
+			os.Remove(obj)
+			err = os.ErrNotExist
+		}
+	}
+	if os.IsNotExist(err) {
+		if os.MkdirAll(filepath.Dir(obj), 0777) == nil {
+			cloneFile(name, obj)
+		}
+		return
+	}
+	info, nerr := os.Stat(name)
+	if err != nil || nerr != nil || oinfo.Size() != info.Size() {
+		return
+	}
+	clone := name + ".clone"
+	if err := cloneFile(obj, clone); err != nil {
+		return
+	}
+	if err := os.Rename(clone, name); err != nil {
+		os.Remove(clone)
+	}
+}
+
+//reflinkSupported 检查目录所在文件系统是否支持写时复制
+func reflinkSupported(dir string) bool {
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return false
+	}
+	f, err := os.CreateTemp(dir, ".reflink-*")
+	if err != nil {
+		return false
+	}
+	defer os.Remove(f.Name())
+	_, err = f.WriteString("reflink")
+	if cerr := f.Close(); err != nil || cerr != nil {
+		return false
+	}
+	clone := f.Name() + ".clone"
+	defer os.Remove(clone)
+	return cloneFile(f.Name(), clone) == nil
+}
+
+//digest 获取本地文件摘要，文件大小与修改时间与指纹一致时沿用指纹中的摘要
+func (l *local) digest(entity *eFileEntity, v *eFileFP) string {
+	if v != nil && !v.Deleted && v.Size == entity.Size && v.ModTime.Equal(entity.ModTime) {
+		return v.SHA256
+	}
+	digest, _ := l.FDigest(entity.Path)
+	return digest
+}
+
+//FDigest 计算本地文件内容的sha256摘要
+func (l *local) FDigest(name string) (string, error) {
+	return fileDigest(filepath.Join(l.path, name))
+}
+
+func fileDigest(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("读取文件失败:%w", err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("读取文件失败:%w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+//clearObjects 清理未被任何文件引用或内容已损坏的内容对象，对象是独立的副本，删除不影响已保存的文件
+func (l *local) clearObjects(refs map[string]bool) {
+	filepath.WalkDir(filepath.Join(l.path, objectDir), func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if !refs[d.Name()] {
+			os.Remove(path)
+			return nil
+		}
+		if digest, err := fileDigest(path); err != nil || digest != d.Name() {
+			os.Remove(path)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,53 @@
+package lnfs
+
+import "sync/atomic"
+
+//Scrub 巡检本地文件，重新计算文件内容摘要并与指纹比对
+//1. 指纹未记录摘要的(历史文件)补充摘要
+//2. 摘要不一致的文件删除本地副本与指纹，返回需重新拉取的文件
+//3. 清理未被引用或内容已损坏的内容对象
+//与本地文件检查共用同一把锁，检查进行中时跳过本次巡检
+func (l *local) Scrub() (EFileFPLists, error) {
+	if !atomic.CompareAndSwapInt32(&l.lockValue, 0, 1) {
+		return nil, nil
+	}
+	defer atomic.CompareAndSwapInt32(&l.lockValue, 1, 0)
+
+	corrupts := make(EFileFPLists)
+	refs := make(map[string]bool)
+	change := false
+	for k, v := range l.FPS.Items() {
+		fp := v.(*eFileFP)
+		if fp.Deleted {
+			continue
+		}
+		digest, err := l.FDigest(k)
+		if err != nil {
+			continue
+		}
+		if fp.SHA256 == "" {
+			nfp := *fp
+			nfp.SHA256 = digest
+			fp = &nfp
+			l.FPS.Set(k, fp)
+			change = true
+		}
+		if fp.SHA256 == digest {
+			refs[digest] = true
+			continue
+		}
+
+		//内容已损坏，删除本地副本后重新拉取
+		if err := l.FRemove(k); err != nil {
+			return nil, err
+		}
+		l.FPS.Remove(k)
+		corrupts[k] = fp
+		change = true
+	}
+	l.clearObjects(refs)
+	if !change {
+		return corrupts, nil
+	}
+	return corrupts, l.FPWrite(l.FPS.Items())
+}
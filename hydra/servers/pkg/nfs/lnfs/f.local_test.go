@@ -0,0 +1,248 @@
+package lnfs
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func newTestLocal(t *testing.T) (*local, func()) {
+	dir, err := ioutil.TempDir("", "lnfs")
+	assert.Equal(t, nil, err, "创建临时目录")
+	l := newLocal(dir, nil, nil)
+
+	//不监控文件变动，避免后台检查与用例并发执行，由用例主动检查
+	l.fsWatcher.Close()
+	l.Update("127.0.0.1:8080")
+	return l, func() {
+		l.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestEFileFP_Supersede(t *testing.T) {
+	now := time.Now()
+	file := &eFileFP{Path: "a.txt", ModTime: now}
+	assert.Equal(t, true, newTombstone("a.txt", now).supersede(file), "删除时间不早于修改时间时删除标记取代文件")
+	assert.Equal(t, false, newTombstone("a.txt", now.Add(-time.Second)).supersede(file), "删除后重新创建的文件不被取代")
+	assert.Equal(t, true, file.supersede(newTombstone("a.txt", now.Add(-time.Second))), "重新创建的文件取代删除标记")
+	assert.Equal(t, false, file.supersede(&eFileFP{Path: "a.txt", ModTime: now.Add(-time.Second)}), "文件之间不取代")
+
+	older := &eFileFP{Path: "a.txt", ModTime: now.Add(-time.Second), SHA256: "b"}
+	newer := &eFileFP{Path: "a.txt", ModTime: now, SHA256: "a"}
+	assert.Equal(t, true, newer.supersede(older), "内容不一致时修改时间晚的取代修改时间早的")
+	assert.Equal(t, false, older.supersede(newer), "内容不一致时修改时间早的不取代")
+	assert.Equal(t, false, newer.supersede(&eFileFP{Path: "a.txt", ModTime: now.Add(-time.Second), SHA256: "a"}), "内容一致时不取代")
+
+	list := EFileFPLists{"a.txt": file}
+	list.Merge(EFileFPLists{"a.txt": newTombstone("a.txt", now.Add(time.Second), "127.0.0.2:8080")})
+	assert.Equal(t, true, list["a.txt"].Deleted, "合并时删除标记取代文件")
+}
+
+func TestLocal_Delete(t *testing.T) {
+	l, clear := newTestLocal(t)
+	defer clear()
+
+	_, err := l.SaveFile("a/b.txt", []byte("hello"))
+	assert.Equal(t, nil, err, "保存文件")
+	assert.Equal(t, true, l.Has("a/b.txt"), "文件存在")
+
+	fp, err := l.DeleteFile("a/b.txt")
+	assert.Equal(t, nil, err, "删除文件")
+	assert.Equal(t, true, fp.Deleted, "记录删除标记")
+	assert.Equal(t, false, l.Has("a/b.txt"), "删除后文件不存在")
+	_, err = os.Stat(filepath.Join(l.path, "a/b.txt"))
+	assert.Equal(t, true, os.IsNotExist(err), "删除本地文件")
+	assert.Equal(t, 0, len(l.GetFileList("", "", true, 0, 10)), "文件列表不包含已删除的文件")
+
+	//其它服务器上报修改时间早于删除时间的文件时不下载，并通知对方删除
+	remote := &eFileFP{Path: "a/b.txt", ModTime: fp.ModTime.Add(-time.Second), Hosts: []string{"127.0.0.2:8080"}}
+	assert.Equal(t, true, l.IsDeleted(remote), "已删除的文件")
+	reports, downloads, err := l.Merge(EFileFPLists{remote.Path: remote})
+	assert.Equal(t, nil, err, "合并远程指纹")
+	assert.Equal(t, 0, len(downloads), "不重新下载已删除的文件")
+	assert.Equal(t, true, reports["a/b.txt"].Deleted, "上报删除标记")
+
+	//删除后重新创建的文件需下载
+	remote = &eFileFP{Path: "a/b.txt", ModTime: fp.ModTime.Add(time.Second), Hosts: []string{"127.0.0.2:8080"}}
+	_, downloads, _ = l.Merge(EFileFPLists{remote.Path: remote})
+	assert.Equal(t, 1, len(downloads), "下载重新创建的文件")
+
+	//重新检查本地文件时保留删除标记
+	assert.Equal(t, nil, l.check(), "检查本地文件")
+	v, ok := l.GetFP("a/b.txt")
+	assert.Equal(t, true, ok && v.Deleted, "保留删除标记")
+}
+
+func TestLocal_MergeTombstone(t *testing.T) {
+	l, clear := newTestLocal(t)
+	defer clear()
+
+	fp, err := l.SaveFile("c.txt", []byte("hello"))
+	assert.Equal(t, nil, err, "保存文件")
+
+	tomb := newTombstone("c.txt", fp.ModTime.Add(time.Second), "127.0.0.2:8080")
+	reports, _, err := l.Merge(EFileFPLists{"c.txt": tomb, "d.txt": newTombstone("d.txt", time.Now(), "127.0.0.2:8080")})
+	assert.Equal(t, nil, err, "合并删除标记")
+	assert.Equal(t, 2, len(reports), "上报已处理的删除标记")
+	assert.Equal(t, false, l.Has("c.txt"), "文件已删除")
+	_, err = os.Stat(filepath.Join(l.path, "c.txt"))
+	assert.Equal(t, true, os.IsNotExist(err), "删除本地文件")
+	assert.Equal(t, true, l.IsDeleted(&eFileFP{Path: "d.txt"}), "记录本地不存在的文件的删除标记")
+}
+
+func TestLocal_Move(t *testing.T) {
+	l, clear := newTestLocal(t)
+	defer clear()
+
+	l.SaveFile("x/a.txt", []byte("a"))
+	l.SaveFile("x/y/b.txt", []byte("b"))
+	l.SaveFile("z.txt", []byte("z"))
+
+	_, err := l.MoveFile("x", "z.txt")
+	assert.NotEqual(t, nil, err, "目标已存在")
+
+	changes, err := l.MoveFile("x", "m/n")
+	assert.Equal(t, nil, err, "移动目录")
+	assert.Equal(t, 4, len(changes), "原文件删除标记与新文件指纹")
+	assert.Equal(t, true, changes["x/a.txt"].Deleted, "原文件删除标记")
+	assert.Equal(t, true, l.Has("m/n/y/b.txt"), "新文件")
+	buff, err := l.FRead("m/n/a.txt")
+	assert.Equal(t, nil, err, "读取新文件")
+	assert.Equal(t, "a", string(buff), "文件内容")
+
+	_, err = l.MoveFile("x", "k")
+	assert.NotEqual(t, nil, err, "原文件不存在")
+}
+
+func TestLocal_Dedup(t *testing.T) {
+	l, clear := newTestLocal(t)
+	defer clear()
+
+	a, err := l.SaveFile("a.txt", []byte("hello"))
+	assert.Equal(t, nil, err, "保存文件")
+	assert.Equal(t, getSHA256([]byte("hello")), a.SHA256, "记录文件摘要")
+	b, err := l.SaveStream("x/b.txt", bytes.NewReader([]byte("hello")))
+	assert.Equal(t, nil, err, "流式保存文件")
+	assert.Equal(t, a.SHA256, b.SHA256, "相同内容的摘要")
+	c, _ := l.SaveFile("c.txt", []byte("world"))
+
+	ainfo, _ := os.Stat(filepath.Join(l.path, "a.txt"))
+	binfo, _ := os.Stat(filepath.Join(l.path, "x/b.txt"))
+	oinfo, _ := os.Stat(l.objectPath(a.SHA256))
+	assert.Equal(t, false, os.SameFile(ainfo, binfo), "相同内容的文件不使用硬链接")
+	assert.Equal(t, false, oinfo != nil && os.SameFile(ainfo, oinfo), "文件与内容对象不使用硬链接")
+	_, err = os.Stat(l.objectPath(c.SHA256))
+	assert.Equal(t, l.dedupable, err == nil, "支持写时复制时登记内容对象")
+	lst, _, err := l.FList(l.path)
+	assert.Equal(t, nil, err, "获取本地文件")
+	assert.Equal(t, 3, len(lst), "内容对象不在文件清单中")
+	assert.Equal(t, nil, l.check(), "检查本地文件")
+	v, _ := l.GetFP("x/b.txt")
+	assert.Equal(t, a.SHA256, v.SHA256, "检查后保留文件摘要")
+
+	//直接修改文件内容不影响相同内容的其它文件
+	assert.Equal(t, nil, os.WriteFile(filepath.Join(l.path, "a.txt"), []byte("hellx"), 0666), "修改文件内容")
+	buff, _ := l.FRead("x/b.txt")
+	assert.Equal(t, "hello", string(buff), "修改文件不影响其它文件")
+
+	_, err = l.DeleteFile("a.txt")
+	assert.Equal(t, nil, err, "删除文件")
+	buff, err = l.FRead("x/b.txt")
+	assert.Equal(t, nil, err, "删除文件不影响其它文件")
+	assert.Equal(t, "hello", string(buff), "文件内容")
+
+	//远程文件内容与本地不一致且更新时需重新拉取
+	remote := &eFileFP{Path: "c.txt", ModTime: c.ModTime.Add(time.Second), SHA256: getSHA256([]byte("new")), Hosts: []string{"127.0.0.2:8080"}}
+	assert.Equal(t, false, l.Match(remote), "本地内容已过期")
+	_, downloads, _ := l.Merge(EFileFPLists{remote.Path: remote})
+	assert.Equal(t, 1, len(downloads), "下载更新的文件")
+	assert.Equal(t, true, l.Match(c), "本地内容一致")
+}
+
+func TestLocal_Scrub(t *testing.T) {
+	l, clear := newTestLocal(t)
+	defer clear()
+
+	a, _ := l.SaveFile("a.txt", []byte("hello"))
+	l.SaveFile("b.txt", []byte("hello"))
+	c, _ := l.SaveFile("c.txt", []byte("world"))
+	d, _ := l.SaveFile("d.txt", []byte("orphan"))
+	l.DeleteFile("d.txt")
+
+	//历史文件未记录摘要
+	c.SHA256 = ""
+
+	//直接修改磁盘内容，相同内容的其它文件不受影响
+	assert.Equal(t, nil, os.WriteFile(filepath.Join(l.path, "a.txt"), []byte("hellx"), 0666), "修改文件内容")
+
+	//本地文件检查进行中时跳过巡检
+	l.lockValue = 1
+	corrupts, err := l.Scrub()
+	assert.Equal(t, nil, err, "跳过巡检")
+	assert.Equal(t, 0, len(corrupts), "检查进行中时不巡检")
+	l.lockValue = 0
+
+	corrupts, err = l.Scrub()
+	assert.Equal(t, nil, err, "巡检文件")
+	assert.Equal(t, 1, len(corrupts), "内容损坏的文件")
+	assert.Equal(t, a.SHA256, corrupts["a.txt"].SHA256, "返回原始指纹")
+	assert.Equal(t, false, l.Has("a.txt"), "删除损坏文件的指纹")
+	assert.Equal(t, true, l.Has("b.txt"), "保留正常文件的指纹")
+	_, err = os.Stat(filepath.Join(l.path, "a.txt"))
+	assert.Equal(t, true, os.IsNotExist(err), "删除损坏的本地文件")
+	_, err = os.Stat(l.objectPath(d.SHA256))
+	assert.Equal(t, true, os.IsNotExist(err), "清理未被引用的内容对象")
+
+	v, _ := l.GetFP("c.txt")
+	assert.Equal(t, getSHA256([]byte("world")), v.SHA256, "补充历史文件摘要")
+	assert.Equal(t, "", c.SHA256, "不修改原指纹对象")
+	_, err = os.Stat(l.objectPath(v.SHA256))
+	assert.Equal(t, l.dedupable, err == nil, "保留正常的内容对象")
+}
+
+func TestLocal_DedupCorrupted(t *testing.T) {
+	l, clear := newTestLocal(t)
+	defer clear()
+	assert.Equal(t, reflinkSupported(filepath.Join(l.path, objectDir)), l.dedupable, "启动时检查是否支持写时复制")
+
+	//内容对象已损坏时不替换文件，并删除损坏的对象
+	l.dedupable = true
+	name := filepath.Join(l.path, "a.txt")
+	os.WriteFile(name, []byte("hello"), 0666)
+	digest := getSHA256([]byte("hello"))
+	obj := l.objectPath(digest)
+	os.MkdirAll(filepath.Dir(obj), 0777)
+	os.WriteFile(obj, []byte("hellx"), 0666)
+	l.dedup(name, digest)
+	buff, _ := os.ReadFile(name)
+	assert.Equal(t, "hello", string(buff), "不使用损坏的对象替换文件")
+	odigest, err := fileDigest(obj)
+	assert.Equal(t, true, err != nil || odigest == digest, "删除或重新生成损坏的对象")
+}
+
+func TestLocal_SaveRemote(t *testing.T) {
+	l, clear := newTestLocal(t)
+	defer clear()
+
+	mtime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	remote := &eFileFP{Path: "r/a.txt", ModTime: mtime, SHA256: getSHA256([]byte("hello")), Hosts: []string{"127.0.0.2:8080"}}
+	_, err := l.SaveRemote(remote, bytes.NewReader([]byte("hellx")))
+	assert.NotEqual(t, nil, err, "内容与摘要不一致")
+	_, err = os.Stat(filepath.Join(l.path, "r/a.txt"))
+	assert.Equal(t, true, os.IsNotExist(err), "校验失败时不保存文件")
+	names, _ := filepath.Glob(filepath.Join(l.path, "r", ".upload-*"))
+	assert.Equal(t, 0, len(names), "删除临时文件")
+
+	fp, err := l.SaveRemote(remote, bytes.NewReader([]byte("hello")))
+	assert.Equal(t, nil, err, "保存远程文件")
+	assert.Equal(t, remote.SHA256, fp.SHA256, "文件摘要")
+	assert.Equal(t, true, fp.ModTime.Equal(mtime), "修改时间与远程一致")
+	buff, _ := l.FRead("r/a.txt")
+	assert.Equal(t, "hello", string(buff), "文件内容")
+}
@@ -3,6 +3,7 @@ package lnfs
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 
 	"github.com/micro-plat/hydra"
@@ -108,42 +109,54 @@ func (r *remoting) GetFP(name string) (v *eFileFP, err error) {
 	return v, nil
 }
 
-//Pull 主动从远程服务器拉取文件信息
-func (r *remoting) Pull(v *eFileFP) (rpns []byte, err error) {
+//Pull 主动从远程服务器拉取文件，以流的方式交由save保存，保存失败(如内容与指纹摘要不一致)时从下一台服务器拉取
+func (r *remoting) Pull(v *eFileFP, save func(io.Reader) error) (err error) {
 
 	//构建请求参数
 	input := types.XMap{"name": v.Path}
 	host := v.GetAliveHost(r.hosts...)
 	if len(host) == 0 {
-		return nil, errs.NewError(http.StatusNoContent, "无可用的服务器")
+		return errs.NewError(http.StatusNoContent, "无可用的服务器")
 	}
 
 	//向集群发起请求
-	var status int
 	for _, host := range host {
 		log := trace(r.rmt_file_download, v.Path, "from", host)
-		rpns, status, err = hydra.C.HTTP().GetRegularClient().Request("POST", fmt.Sprintf("http://%s%s", host, r.rmt_file_download), input.ToKV(), "utf-8", http.Header{
+		body, status, err1 := hydra.C.HTTP().GetRegularClient().Stream("POST", fmt.Sprintf("http://%s%s", host, r.rmt_file_download), input.ToKV(), "utf-8", http.Header{
 			context.XRequestID: []string{log.log.GetSessionID()},
 			"Accept-Encoding":  []string{"gzip"},
 		})
 
 		//检查是否发生错误
-		if err != nil {
+		if err = err1; err != nil {
 			log.error(r.rmt_file_download, v.Path, "from", host, status, err)
 			continue
 		}
 
 		//检查状态码
-		if status == http.StatusNoContent {
+		if status != http.StatusOK {
+			body.Close()
+			err = errs.NewErrorf(status, "拉取文件失败:%s", v.Path)
 			log.error(r.rmt_file_download, v.Path, "from", host, status)
 			continue
 		}
 
+		//保存文件，由save负责校验文件内容
+		err = save(body)
+		body.Close()
+		if err != nil {
+			log.error(r.rmt_file_download, v.Path, "from", host, status, err)
+			continue
+		}
+
 		//数据正确
 		log.end(r.rmt_file_download, v.Path, "from", host, status)
-		return rpns, nil
+		return nil
 	}
-	return
+	if err == nil {
+		err = errs.NewError(http.StatusNoContent, "无可用的服务器")
+	}
+	return err
 }
 
 //Report 当前差异时主动向集群推送指纹信息
@@ -192,13 +205,7 @@ func (r *remoting) Query() (EFileFPLists, error) {
 		}
 
 		log.end(r.rmt_fp_query, "from", host, status)
-		for k, v := range nresult {
-			if _, ok := result[k]; !ok {
-				result[k] = v
-				continue
-			}
-			result[k].MergeHosts(v.Hosts...)
-		}
+		result.Merge(nresult)
 	}
 	return result, nil
 }
@@ -1,9 +1,13 @@
 package lnfs
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"hash/crc64"
+	"io"
 	"net/http"
+	"os"
 	"path/filepath"
 	"sync"
 	"time"
@@ -25,6 +29,7 @@ type Module struct {
 	once      sync.Once
 	fsWatcher *fsnotify.Watcher
 	checkChan chan struct{}
+	closeChan chan struct{}
 	prefix    string
 	Done      bool
 }
@@ -36,9 +41,11 @@ func newModule(c *nfs.NFS, prefix string) (m *Module) {
 		Local:     newLocal(c.Local, c.Excludes, c.Includes),
 		remoting:  newRemoting(),
 		checkChan: make(chan struct{}, 1),
+		closeChan: make(chan struct{}),
 	}
 	m.async = newAsync(m.Local, m.remoting)
 	go m.watch()
+	go m.scrub()
 	return m
 }
 
@@ -70,20 +77,18 @@ func (m *Module) CheckAndDownload(name string) error {
 		return err
 	}
 
-	//从远程拉取文件
-	buff, err := m.remoting.Pull(fp)
-	if err != nil {
+	//从远程拉取文件并保存到本地
+	var nfp *eFileFP
+	err = m.remoting.Pull(fp, func(r io.Reader) (err error) {
+		nfp, err = m.Local.SaveRemote(fp, r)
 		return err
-	}
-
-	//保存到本地
-	fp, err = m.Local.SaveFile(name, buff, fp.Hosts...)
+	})
 	if err != nil {
 		return err
 	}
 
 	//上报给其它服务器
-	m.async.DoReport(fp.GetMAP())
+	m.async.DoReport(nfp.GetMAP())
 	return nil
 }
 func (m *Module) Get(name string) ([]byte, string, error) {
@@ -95,6 +100,32 @@ func (m *Module) Get(name string) ([]byte, string, error) {
 	return buff, ctp, err
 }
 
+//Open 打开文件流，本地不存在时从远程拉取
+func (m *Module) Open(name string) (infs.File, *infs.FileStat, error) {
+	if err := m.CheckAndDownload(name); err != nil {
+		return nil, nil, err
+	}
+	f, err := os.Open(filepath.Join(m.c.Local, name))
+	if err != nil {
+		return nil, nil, fmt.Errorf("读取文件失败:%w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("读取文件失败:%w", err)
+	}
+	if info.IsDir() {
+		f.Close()
+		return nil, nil, errs.NewErrorf(http.StatusNotFound, "文件%s不存在", name)
+	}
+	return f, &infs.FileStat{
+		Size:        info.Size(),
+		ModTime:     info.ModTime(),
+		ContentType: infs.GetContentType(name),
+		ETag:        infs.GetETag(info.Size(), info.ModTime()),
+	}, nil
+}
+
 //HasFile 本地是否存在文件
 func (m *Module) HasFile(name string) error {
 	if m.Local.Has(name) {
@@ -133,13 +164,83 @@ func (m *Module) Save(name string, buff []byte) (string, error) {
 	return fp.Path, nil
 }
 
+//SaveStream 以流的方式保存新文件到本地
+func (m *Module) SaveStream(name string, r io.Reader) (string, error) {
+	//检查文件是否存在
+	name = getFileName(name, m.c.Rename)
+	if m.Local.Has(name) {
+		return "", fmt.Errorf("文件名称重复:%s", name)
+	}
+
+	//保存到本地
+	fp, err := m.Local.SaveStream(name, r)
+	if err != nil {
+		return "", err
+	}
+
+	//远程通知
+	m.async.DoReport(fp.GetMAP())
+	return fp.Path, nil
+}
+
+//Delete 删除文件或目录下的所有文件
+//1. 删除本地文件，记录删除标记
+//2. 通知master删除标记,如果是master则通知所有人删除文件
+func (m *Module) Delete(name string) error {
+	names := m.Local.Names(name)
+	if len(names) == 0 {
+		if m.remoting.HasFile(name) != nil {
+			return errs.NewErrorf(http.StatusNotFound, "文件%s不存在", name)
+		}
+		names = []string{name}
+	}
+	changes := make(EFileFPLists, len(names))
+	for _, n := range names {
+		fp, err := m.Local.DeleteFile(n)
+		if err != nil {
+			return err
+		}
+		changes[n] = fp
+	}
+
+	//远程通知
+	m.async.DoReport(changes)
+	return nil
+}
+
+//Move 移动文件或目录，原文件记录删除标记，其它服务器从当前服务器拉取新文件
+func (m *Module) Move(oname string, nname string) error {
+	if oname == nname {
+		return nil
+	}
+	//本地不存在时先从远程拉取
+	if len(m.Local.Names(oname)) == 0 {
+		if err := m.CheckAndDownload(oname); err != nil {
+			return err
+		}
+	}
+	changes, err := m.Local.MoveFile(oname, nname)
+	if err != nil {
+		return err
+	}
+
+	//远程通知
+	m.async.DoReport(changes)
+	return nil
+}
+
+//IsMaster 当前服务器是否是master
+func (m *Module) IsMaster() bool {
+	return m.remoting.isMaster
+}
+
 //GetFP 获取本地的指纹信息，用于master对外提供服务
 //1. 查询本地是否有文件的指纹信息
 //2. 如果是master返回不存在
 //3. 向master发起查询
 func (m *Module) GetFP(name string) (*eFileFP, error) {
 	//从本地文件获取
-	if f, ok := m.Local.GetFP(name); ok {
+	if f, ok := m.Local.GetFP(name); ok && !f.Deleted {
 		return f, nil
 	}
 	return nil, errs.NewError(http.StatusNotFound, "文件不存在")
@@ -205,6 +306,7 @@ func (m *Module) Close() error {
 	m.Done = true
 	m.once.Do(func() {
 		close(m.checkChan)
+		close(m.closeChan)
 		m.Local.Close()
 		m.async.Close()
 		if m.fsWatcher != nil {
@@ -218,6 +320,11 @@ func getCRC64(buff []byte) uint64 {
 	return crc64.Checksum(buff, crc64.MakeTable(crc64.ISO))
 }
 
+func getSHA256(buff []byte) string {
+	h := sha256.Sum256(buff)
+	return hex.EncodeToString(h[:])
+}
+
 func getFileName(name string, rename bool) string {
 	if !rename {
 		return filepath.Join(name)
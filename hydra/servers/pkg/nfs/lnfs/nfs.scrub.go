@@ -0,0 +1,35 @@
+package lnfs
+
+import "time"
+
+//defScrubInterval 默认本地文件巡检间隔
+const defScrubInterval = time.Hour * 6
+
+//getScrubInterval 获取本地文件巡检间隔，未配置时使用默认间隔
+func (m *Module) getScrubInterval() time.Duration {
+	if m.c.ScrubInterval <= 0 {
+		return defScrubInterval
+	}
+	return time.Duration(m.c.ScrubInterval) * time.Second
+}
+
+//scrub 定时巡检本地文件，内容校验失败的文件从其它服务器重新拉取
+func (m *Module) scrub() {
+	tk := time.NewTicker(m.getScrubInterval())
+	defer tk.Stop()
+	for {
+		select {
+		case <-m.closeChan:
+			return
+		case <-tk.C:
+			corrupts, err := m.Local.Scrub()
+			if err != nil {
+				trace().error("文件巡检失败", err)
+			}
+			for _, f := range corrupts {
+				trace().error("文件内容校验失败，重新拉取", f.Path, f.SHA256)
+				m.async.DoDownload(f)
+			}
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package nfs
 
 import (
+	"os"
+	"path/filepath"
+	"time"
+
 	"github.com/micro-plat/hydra/conf/app"
 	"github.com/micro-plat/hydra/conf/server/nfs"
 	"github.com/micro-plat/hydra/global"
@@ -14,24 +18,35 @@ import (
 var currentModule infs.Infs
 
 type cnfs struct {
-	c        *nfs.NFS
-	app      app.IAPPConf
-	services []string
-	infs     infs.Infs
+	c         *nfs.NFS
+	app       app.IAPPConf
+	services  []string
+	infs      infs.Infs
+	uploads   *uploads
+	retention *retention
 }
 
 func newNFS(app app.IAPPConf, c *nfs.NFS) *cnfs {
 	currentModule = getNFS(app, c)
+	temp := types.GetString(c.ChunkTemp, filepath.Join(os.TempDir(), "hydra-nfs-chunk"))
+	expire := time.Duration(types.GetInt(c.ChunkExpire, 86400)) * time.Second
 	return &cnfs{c: c,
-		app:      app,
-		infs:     currentModule,
-		services: make([]string, 0, 3)}
+		app:       app,
+		infs:      currentModule,
+		uploads:   newUploads(currentModule, temp, expire),
+		retention: newRetention(currentModule, c.Retentions),
+		services:  make([]string, 0, 3)}
 }
 func (c *cnfs) Start() error {
-	return c.infs.Start()
+	if err := c.infs.Start(); err != nil {
+		return err
+	}
+	c.retention.Start()
+	return nil
 }
 
 func (r *cnfs) Close() error {
+	r.retention.Close()
 	return r.infs.Close()
 }
 
@@ -105,6 +120,14 @@ func registry(tp string, cnfs *cnfs, cnf *nfs.NFS) {
 			cnfs.services = append(cnfs.services, s)
 		}
 
+		if cnf.AllowChunkUpload {
+			prefix := types.GetString(cnf.ChunkUploadService, infs.SVSChunkUpload)
+			for s, h := range cnfs.chunkServices(prefix) {
+				services.Def.API(s, h)
+				cnfs.services = append(cnfs.services, s)
+			}
+		}
+
 		if cnf.AllowDownload {
 			s := types.GetString(cnf.DownloadService, infs.SVSDonwload)
 			services.Def.API(s, cnfs.Download)
@@ -144,6 +167,18 @@ func registry(tp string, cnfs *cnfs, cnf *nfs.NFS) {
 			services.Def.API(s, cnfs.RenameDir)
 			cnfs.services = append(cnfs.services, s)
 		}
+
+		if cnf.AllowDelete {
+			s := types.GetString(cnf.DeleteService, infs.SVSDelete)
+			services.Def.API(s, cnfs.Delete)
+			cnfs.services = append(cnfs.services, s)
+		}
+
+		if cnf.AllowMove {
+			s := types.GetString(cnf.MoveService, infs.SVSMove)
+			services.Def.API(s, cnfs.Move)
+			cnfs.services = append(cnfs.services, s)
+		}
 	}
 
 	if tp == global.Web {
@@ -154,6 +189,14 @@ func registry(tp string, cnfs *cnfs, cnf *nfs.NFS) {
 			cnfs.services = append(cnfs.services, s)
 		}
 
+		if cnf.AllowChunkUpload {
+			prefix := types.GetString(cnf.ChunkUploadService, infs.SVSChunkUpload)
+			for s, h := range cnfs.chunkServices(prefix) {
+				services.Def.Web(s, h)
+				cnfs.services = append(cnfs.services, s)
+			}
+		}
+
 		if cnf.AllowDownload {
 			s := types.GetString(cnf.DownloadService, infs.SVSDonwload)
 			services.Def.Web(s, cnfs.Download)
@@ -195,5 +238,17 @@ func registry(tp string, cnfs *cnfs, cnf *nfs.NFS) {
 			services.Def.Web(s, cnfs.RenameDir)
 			cnfs.services = append(cnfs.services, s)
 		}
+
+		if cnf.AllowDelete {
+			s := types.GetString(cnf.DeleteService, infs.SVSDelete)
+			services.Def.Web(s, cnfs.Delete)
+			cnfs.services = append(cnfs.services, s)
+		}
+
+		if cnf.AllowMove {
+			s := types.GetString(cnf.MoveService, infs.SVSMove)
+			services.Def.Web(s, cnfs.Move)
+			cnfs.services = append(cnfs.services, s)
+		}
 	}
 }
@@ -2,7 +2,6 @@ package nfs
 
 import (
 	"fmt"
-	"io/ioutil"
 	"net/http"
 	"path/filepath"
 	"strings"
@@ -28,31 +27,21 @@ func (c *cnfs) Upload(ctx context.IContext) interface{} {
 	if err != nil {
 		return err
 	}
-
-	//读取内容
 	defer reader.Close()
-	buff, err := ioutil.ReadAll(reader)
-	if err != nil {
-		return err
-	}
 
 	// 保存文件
 	path := infs.MultiPath(ctx.Request().Path().Params().GetString("path", ctx.Request().GetString("path")))
 
-	npath, err := c.infs.Save(filepath.Join(path, name), buff)
+	npath, err := c.infs.SaveStream(filepath.Join(path, name), reader)
 	if err != nil {
 		return err
 	}
 
 	// 处理返回结果
-	xpath := fmt.Sprintf("%s/%s", strings.Trim(c.c.Domain, "/"), strings.Trim(npath, "/"))
-	ctx.Response().AddSpecial(fmt.Sprintf("nfs|%s|%d", name, size))
-	return map[string]interface{}{
-		"path": xpath,
-	}
+	return c.result(ctx, name, npath, size)
 }
 
-//Download 用户下载文件
+//Download 用户下载文件，支持Range、If-Range断点下载
 func (c *cnfs) Download(ctx context.IContext) interface{} {
 
 	//检查参数
@@ -63,20 +52,32 @@ func (c *cnfs) Download(ctx context.IContext) interface{} {
 	}
 
 	//获取文件
-
 	path := filepath.Join(dir, name)
-	buff, tp, err := c.infs.Get(path)
+	f, stat, err := c.infs.Open(path)
 	if err != nil {
 		return err
 	}
+	defer f.Close()
 
 	//写入文件
-	//未设置文件头
-	ctx.Response().ContentType(tp)
-	ctx.Response().GetHTTPReponse().Write(buff)
+	w := ctx.Response().GetHTTPReponse()
+	w.Header().Set("Content-Type", stat.ContentType)
+	if stat.ETag != "" {
+		w.Header().Set("ETag", stat.ETag)
+	}
+	http.ServeContent(w, ctx.Request().GetHTTPRequest(), name, stat.ModTime, f)
 	return nil
 }
 
+//result 构建上传结果
+func (c *cnfs) result(ctx context.IContext, name string, npath string, size int64) interface{} {
+	xpath := fmt.Sprintf("%s/%s", strings.Trim(c.c.Domain, "/"), strings.Trim(npath, "/"))
+	ctx.Response().AddSpecial(fmt.Sprintf("nfs|%s|%d", name, size))
+	return map[string]interface{}{
+		"path": xpath,
+	}
+}
+
 //GetFileList 获取本机的指定文件的指纹信息，仅master提供对外查询功能
 func (c *cnfs) GetFileList(ctx context.IContext) interface{} {
 	return c.infs.GetFileList(infs.MultiPath(ctx.Request().Path().Params().GetString(infs.DIRNAME,
@@ -111,6 +112,30 @@ func (c *cnfs) RenameDir(ctx context.IContext) interface{} {
 	return c.infs.Rename(dir, ndir)
 }
 
+//Delete 删除文件，集群中其它服务器同步删除
+func (c *cnfs) Delete(ctx context.IContext) interface{} {
+	//检查参数
+	dir := infs.MultiPath(ctx.Request().Path().Params().GetString(infs.DIRNAME, ctx.Request().GetString(infs.DIRNAME)))
+	name := infs.MultiPath(ctx.Request().Path().Params().GetString(infs.FILENAME, ctx.Request().GetString(infs.FILENAME)))
+	if name == "" {
+		return errs.NewErrorf(http.StatusNotAcceptable, "参数不能为空,请求路径中应包含参数 \":%s\"", infs.FILENAME)
+	}
+	return c.infs.Delete(filepath.Join(dir, name))
+}
+
+//Move 移动文件，集群中其它服务器同步移动
+func (c *cnfs) Move(ctx context.IContext) interface{} {
+	//检查参数
+	dir := infs.MultiPath(ctx.Request().Path().Params().GetString(infs.DIRNAME, ctx.Request().GetString(infs.DIRNAME)))
+	name := infs.MultiPath(ctx.Request().Path().Params().GetString(infs.FILENAME, ctx.Request().GetString(infs.FILENAME)))
+	ndir := infs.MultiPath(ctx.Request().Path().Params().GetString(infs.NDIRNAME, ctx.Request().GetString(infs.NDIRNAME, dir)))
+	nname := infs.MultiPath(ctx.Request().Path().Params().GetString(infs.NFILENAME, ctx.Request().GetString(infs.NFILENAME, name)))
+	if name == "" {
+		return errs.NewErrorf(http.StatusNotAcceptable, "参数不能为空,请求路径中应包含参数 \":%s\"", infs.FILENAME)
+	}
+	return c.infs.Move(filepath.Join(dir, name), filepath.Join(ndir, nname))
+}
+
 //ImgScale 缩略图生成
 func (c *cnfs) ImgScale(ctx context.IContext) interface{} {
 	//检查参数
@@ -0,0 +1,91 @@
+package nfs
+
+import (
+	"net/http"
+	"path/filepath"
+
+	"github.com/micro-plat/hydra/context"
+	"github.com/micro-plat/hydra/hydra/servers/pkg/nfs/infs"
+	"github.com/micro-plat/lib4go/errs"
+)
+
+//UploadInit 创建分片上传任务，返回上传任务编号
+func (c *cnfs) UploadInit(ctx context.IContext) interface{} {
+	name := ctx.Request().GetString(infs.FILENAME)
+	if name == "" {
+		return errs.NewErrorf(http.StatusNotAcceptable, "参数不能为空,请求中应包含参数 \"%s\"", infs.FILENAME)
+	}
+	path := infs.MultiPath(ctx.Request().GetString("path"))
+	up, err := c.uploads.Init(filepath.Join(path, filepath.Base(name)), ctx.Request().GetInt64("size"))
+	if err != nil {
+		return err
+	}
+	return up
+}
+
+//UploadPart 上传分片，分片编号从1开始，重复上传同一编号时覆盖
+func (c *cnfs) UploadPart(ctx context.IContext) interface{} {
+	if err := ctx.Request().Check(infs.UPLOADID, infs.PARTNUMBER); err != nil {
+		return err
+	}
+	_, reader, _, err := ctx.Request().GetFile(ctx.Request().GetString(infs.FILENAME, "file"))
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	p, err := c.uploads.WritePart(ctx.Request().GetString(infs.UPLOADID), ctx.Request().GetInt(infs.PARTNUMBER), reader)
+	if err != nil {
+		return err
+	}
+	return p
+}
+
+//UploadStatus 查询上传任务与已上传的分片，用于断点续传
+func (c *cnfs) UploadStatus(ctx context.IContext) interface{} {
+	if err := ctx.Request().Check(infs.UPLOADID); err != nil {
+		return err
+	}
+	status, err := c.uploads.Status(ctx.Request().GetString(infs.UPLOADID))
+	if err != nil {
+		return err
+	}
+	return status
+}
+
+//UploadComplete 合并已上传的分片并保存文件
+func (c *cnfs) UploadComplete(ctx context.IContext) interface{} {
+	if err := ctx.Request().Check(infs.UPLOADID); err != nil {
+		return err
+	}
+	up, err := c.uploads.get(ctx.Request().GetString(infs.UPLOADID))
+	if err != nil {
+		return err
+	}
+	npath, size, err := c.uploads.Complete(up.ID)
+	if err != nil {
+		return err
+	}
+	return c.result(ctx, filepath.Base(up.Name), npath, size)
+}
+
+//UploadAbort 取消上传任务
+func (c *cnfs) UploadAbort(ctx context.IContext) interface{} {
+	if err := ctx.Request().Check(infs.UPLOADID); err != nil {
+		return err
+	}
+	if err := c.uploads.Abort(ctx.Request().GetString(infs.UPLOADID)); err != nil {
+		return err
+	}
+	return "success"
+}
+
+//chunkServices 获取分片上传服务
+func (c *cnfs) chunkServices(prefix string) map[string]func(context.IContext) interface{} {
+	return map[string]func(context.IContext) interface{}{
+		prefix + infs.SVSChunkInit:     c.UploadInit,
+		prefix + infs.SVSChunkPart:     c.UploadPart,
+		prefix + infs.SVSChunkStatus:   c.UploadStatus,
+		prefix + infs.SVSChunkComplete: c.UploadComplete,
+		prefix + infs.SVSChunkAbort:    c.UploadAbort,
+	}
+}
@@ -3,7 +3,9 @@ package obs
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"path/filepath"
 	"sort"
@@ -75,6 +77,60 @@ func (o *OBS) Save(path string, buff []byte) (string, error) {
 	_, err := o.obsClient.PutObject(input)
 	return path, err
 }
+
+//SaveStream 以流的方式保存文件
+func (o *OBS) SaveStream(path string, r io.Reader) (string, error) {
+	input := &obs.PutObjectInput{}
+	input.Bucket = o.bucket
+	input.Key = path
+	input.ContentType = infs.GetContentType(path)
+	input.Body = r
+	_, err := o.obsClient.PutObject(input)
+	return path, err
+}
+
+//Open 打开对象读取流，读取时按需发起范围请求
+func (o *OBS) Open(path string) (infs.File, *infs.FileStat, error) {
+	input := &obs.GetObjectMetadataInput{}
+	input.Bucket = o.bucket
+	input.Key = path
+	output, err := o.obsClient.GetObjectMetadata(input)
+	if err != nil {
+		if e, ok := err.(obs.ObsError); ok && e.StatusCode == http.StatusNotFound {
+			return nil, nil, errs.NewErrorf(http.StatusNotFound, "文件%s不存在", path)
+		}
+		return nil, nil, err
+	}
+	stat := &infs.FileStat{
+		Size:        output.ContentLength,
+		ModTime:     output.LastModified,
+		ContentType: infs.GetContentType(path),
+		ETag:        output.ETag,
+	}
+	return internal.NewRangeFile(stat.Size, func(start int64, end int64) (io.ReadCloser, error) {
+		return o.getRange(path, start, end)
+	}), stat, nil
+}
+
+//getRange 获取对象指定范围的内容
+func (o *OBS) getRange(path string, start int64, end int64) (io.ReadCloser, error) {
+	input := &obs.GetObjectInput{}
+	input.Bucket = o.bucket
+	input.Key = path
+
+	//sdk只在结束位置大于开始位置时设置Range头，读取单个字节时向前多读取一个字节
+	skip := int64(0)
+	input.RangeStart, input.RangeEnd = start, end
+	if start == end && start > 0 {
+		input.RangeStart, skip = start-1, 1
+	}
+	output, err := o.obsClient.GetObject(input)
+	if err != nil {
+		return nil, err
+	}
+	return internal.NewRangeBody(output.Body, skip, end-start+1)
+}
+
 func (o *OBS) Get(path string) ([]byte, string, error) {
 	input := &obs.GetObjectInput{}
 	input.Bucket = o.bucket
@@ -185,6 +241,25 @@ func (o *OBS) Rename(path string, new string) error {
 	}
 	return o.Delete(path)
 }
+
+//Move 移动文件，复制到新路径后删除原文件
+func (o *OBS) Move(path string, new string) error {
+	if path == new {
+		return nil
+	}
+	if o.Exists(new) {
+		return fmt.Errorf("文件名称重复:%s", new)
+	}
+	input := &obs.CopyObjectInput{}
+	input.Bucket = o.bucket
+	input.Key = new
+	input.CopySourceBucket = o.bucket
+	input.CopySourceKey = path
+	if _, err := o.obsClient.CopyObject(input); err != nil {
+		return err
+	}
+	return o.Delete(path)
+}
 func (o *OBS) GetDirList(path string, deep int) infs.DirList {
 	input := &obs.ListObjectsInput{}
 	input.Bucket = o.bucket
@@ -0,0 +1,120 @@
+package nfs
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/micro-plat/hydra/conf/server/nfs"
+	"github.com/micro-plat/hydra/hydra/servers/pkg/nfs/infs"
+	"github.com/micro-plat/lib4go/logger"
+)
+
+//retentionInterval 保留规则检查间隔
+var retentionInterval = time.Minute * 10
+
+//retention 按目录保留规则清理文件，集群模式下只由master执行，删除操作通过删除标记同步到其它服务器
+type retention struct {
+	fs      infs.Infs
+	rules   []*nfs.Retention
+	closeCh chan struct{}
+	once    sync.Once
+	log     logger.ILogger
+}
+
+func newRetention(fs infs.Infs, rules []*nfs.Retention) *retention {
+	return &retention{
+		fs:      fs,
+		rules:   rules,
+		closeCh: make(chan struct{}),
+		log:     logger.New("nfs"),
+	}
+}
+
+//Start 启动定时检查
+func (r *retention) Start() {
+	if len(r.rules) == 0 {
+		return
+	}
+	go func() {
+		tk := time.NewTicker(retentionInterval)
+		defer tk.Stop()
+		for {
+			select {
+			case <-r.closeCh:
+				return
+			case <-tk.C:
+				r.Check()
+			}
+		}
+	}()
+}
+
+//Check 检查所有规则，返回已删除的文件
+func (r *retention) Check() []string {
+	if m, ok := r.fs.(interface{ IsMaster() bool }); ok && !m.IsMaster() {
+		return nil
+	}
+	deleted := make([]string, 0, 1)
+	now := time.Now()
+	for _, rule := range r.rules {
+		for _, f := range r.expired(rule, now) {
+			if err := r.fs.Delete(f.Path); err != nil {
+				r.log.Errorf("删除文件失败:%s %v", f.Path, err)
+				continue
+			}
+			deleted = append(deleted, f.Path)
+		}
+	}
+	if len(deleted) > 0 {
+		r.log.Infof("按保留规则删除文件:%d个", len(deleted))
+	}
+	return deleted
+}
+
+//expired 获取超过保留时长，或目录总大小超过上限时最早的文件
+func (r *retention) expired(rule *nfs.Retention, now time.Time) []*infs.FileInfo {
+	files := r.files(rule.Dir)
+	sort.Slice(files, func(i, j int) bool { return files[i].ModTime < files[j].ModTime })
+
+	var total int64
+	for _, f := range files {
+		total += f.Size
+	}
+	list := make([]*infs.FileInfo, 0, 1)
+	for _, f := range files {
+		t, err := time.ParseInLocation("2006/01/02 15:04:05", f.ModTime, time.Local)
+		old := err == nil && rule.MaxAge > 0 && now.Sub(t) > time.Duration(rule.MaxAge)*time.Second
+		full := rule.MaxSize > 0 && total > rule.MaxSize
+		if !old && !full {
+			break
+		}
+		list = append(list, f)
+		total -= f.Size
+	}
+	return list
+}
+
+//files 获取目录及子目录下的所有文件
+func (r *retention) files(dir string) []*infs.FileInfo {
+	dir = infs.MultiPath(dir)
+	prefix := dir + "/"
+	files := make([]*infs.FileInfo, 0, 1)
+	for _, f := range r.fs.GetFileList(dir, "", true, 0, math.MaxInt32) {
+		if f.Type == infs.DIR || dir != "" && !strings.HasPrefix(strings.Trim(f.Path, "/"), prefix) {
+			continue
+		}
+		files = append(files, f)
+	}
+	return files
+}
+
+//Close 停止定时检查
+func (r *retention) Close() error {
+	r.once.Do(func() {
+		close(r.closeCh)
+	})
+	return nil
+}
@@ -0,0 +1,65 @@
+package nfs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/micro-plat/hydra/conf/server/nfs"
+	"github.com/micro-plat/hydra/hydra/servers/pkg/nfs/infs"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+//listFS 测试用文件系统，提供文件列表与删除
+type listFS struct {
+	infs.Infs
+	files   infs.FileList
+	deleted []string
+	master  bool
+}
+
+func (l *listFS) GetFileList(path string, q string, all bool, index int, count int) infs.FileList {
+	return l.files
+}
+
+func (l *listFS) Delete(name string) error {
+	l.deleted = append(l.deleted, name)
+	return nil
+}
+
+func (l *listFS) IsMaster() bool {
+	return l.master
+}
+
+func newFileInfo(path string, age time.Duration, size int64) *infs.FileInfo {
+	return &infs.FileInfo{
+		Path:    path,
+		Type:    infs.GetFileType(path),
+		ModTime: time.Now().Add(-age).Format("2006/01/02 15:04:05"),
+		Size:    size,
+	}
+}
+
+func TestRetention_Check(t *testing.T) {
+	fs := &listFS{master: true, files: infs.FileList{
+		newFileInfo("logs/a.log", 3*time.Hour, 10),
+		newFileInfo("logs/b.log", 2*time.Hour, 10),
+		newFileInfo("logs/c.log", time.Minute, 10),
+		newFileInfo("logs/d.log", 0, 10),
+		newFileInfo("logsx/e.log", 5*time.Hour, 10),
+		newFileInfo("img/f.png", 5*time.Hour, 10),
+	}}
+
+	r := newRetention(fs, []*nfs.Retention{{Dir: "logs", MaxAge: 9000}})
+	assert.Equal(t, []string{"logs/a.log"}, r.Check(), "删除超过保留时长的文件")
+
+	fs.deleted = nil
+	r = newRetention(fs, []*nfs.Retention{{Dir: "logs|", MaxSize: 25}})
+	assert.Equal(t, []string{"logs/a.log", "logs/b.log"}, r.Check(), "从最早的文件开始删除直到不超过上限")
+
+	fs.deleted = nil
+	r = newRetention(fs, []*nfs.Retention{{Dir: "logs", MaxAge: 9000, MaxSize: 15}})
+	assert.Equal(t, []string{"logs/a.log", "logs/b.log", "logs/c.log"}, r.Check(), "同时满足时长与大小限制")
+
+	fs.master = false
+	assert.Equal(t, 0, len(r.Check()), "非master不执行")
+}
@@ -0,0 +1,233 @@
+package s3
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//client S3兼容对象存储客户端，使用SigV4签名，支持path-style与virtual-hosted-style地址
+type client struct {
+	scheme    string
+	host      string
+	bucket    string
+	pathStyle bool
+	signer    *signer
+	http      *http.Client
+}
+
+func newClient(ak string, sk string, bucket string, endpoint string, region string, pathStyle bool) (*client, error) {
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("s3服务地址有误:%s %v", endpoint, err)
+	}
+	if bucket == "" {
+		return nil, fmt.Errorf("s3存储桶不能为空")
+	}
+	if region == "" {
+		region = DefaultRegion
+	}
+	return &client{
+		scheme:    u.Scheme,
+		host:      u.Host,
+		bucket:    bucket,
+		pathStyle: pathStyle,
+		signer:    newSigner(ak, sk, region),
+		http:      &http.Client{},
+	}, nil
+}
+
+//objectURL 获取对象地址
+func (c *client) objectURL(key string, query url.Values) *url.URL {
+	u := &url.URL{Scheme: c.scheme, Host: c.host, Path: "/" + key}
+	switch {
+	case c.pathStyle && key == "":
+		u.Path = "/" + c.bucket
+	case c.pathStyle:
+		u.Path = "/" + c.bucket + "/" + key
+	default:
+		u.Host = c.bucket + "." + c.host
+	}
+	u.RawPath = uriEncode(u.Path, false)
+	if query != nil {
+		u.RawQuery = canonicalQuery(query)
+	}
+	return u
+}
+
+//do 发送签名请求，返回状态码不为2xx时返回错误
+func (c *client) do(method string, key string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(method, c.objectURL(key, query).String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.ContentLength = int64(len(body))
+	if len(body) == 0 {
+		req.Body = http.NoBody
+	}
+	c.signer.Sign(req, hashSHA256(body), time.Now())
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	return nil, newError(resp)
+}
+
+//doXML 发送请求并解析xml结果
+func (c *client) doXML(method string, key string, query url.Values, header http.Header, body []byte, result interface{}) error {
+	resp, err := c.do(method, key, query, header, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if result == nil {
+		io.Copy(ioutil.Discard, resp.Body)
+		return nil
+	}
+	buff, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	//部分服务(如CompleteMultipartUpload)在状态码200时通过内容返回错误
+	e := &Error{}
+	if xml.Unmarshal(buff, e) == nil && e.Code != "" {
+		e.StatusCode = resp.StatusCode
+		return e
+	}
+	return xml.Unmarshal(buff, result)
+}
+
+//PutObject 上传对象
+func (c *client) PutObject(key string, contentType string, body []byte) error {
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	return c.doXML(http.MethodPut, key, nil, header, body, nil)
+}
+
+//GetObject 获取对象指定范围的内容，end小于0时获取start之后的所有内容
+func (c *client) GetObject(key string, start int64, end int64) (io.ReadCloser, error) {
+	header := http.Header{}
+	if start > 0 || end >= 0 {
+		rg := fmt.Sprintf("bytes=%d-", start)
+		if end >= 0 {
+			rg += strconv.FormatInt(end, 10)
+		}
+		header.Set("Range", rg)
+	}
+	resp, err := c.do(http.MethodGet, key, nil, header, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+//HeadObject 获取对象信息
+func (c *client) HeadObject(key string) (*objectInfo, error) {
+	resp, err := c.do(http.MethodHead, key, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	info := &objectInfo{Key: key, ETag: resp.Header.Get("ETag"), ContentType: resp.Header.Get("Content-Type")}
+	info.Size, _ = strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+	info.LastModified, _ = http.ParseTime(resp.Header.Get("Last-Modified"))
+	return info, nil
+}
+
+//DeleteObject 删除对象
+func (c *client) DeleteObject(key string) error {
+	return c.doXML(http.MethodDelete, key, nil, nil, nil, nil)
+}
+
+//CopyObject 复制对象
+func (c *client) CopyObject(source string, key string) error {
+	header := http.Header{}
+	header.Set("X-Amz-Copy-Source", uriEncode("/"+c.bucket+"/"+source, false))
+	return c.doXML(http.MethodPut, key, nil, header, nil, &copyObjectResult{})
+}
+
+//ListObjects 获取指定前缀的所有对象
+func (c *client) ListObjects(prefix string) ([]*objectInfo, error) {
+	list := make([]*objectInfo, 0, 1)
+	token := ""
+	for {
+		query := url.Values{"list-type": []string{"2"}, "prefix": []string{prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		result := &listBucketResult{}
+		if err := c.doXML(http.MethodGet, "", query, nil, nil, result); err != nil {
+			return nil, err
+		}
+		for _, v := range result.Contents {
+			list = append(list, &objectInfo{Key: v.Key, Size: v.Size, ETag: v.ETag, LastModified: v.LastModified})
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return list, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+//CreateMultipartUpload 创建分段上传任务
+func (c *client) CreateMultipartUpload(key string, contentType string) (string, error) {
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	result := &initiateMultipartUploadResult{}
+	if err := c.doXML(http.MethodPost, key, url.Values{"uploads": []string{""}}, header, nil, result); err != nil {
+		return "", err
+	}
+	return result.UploadID, nil
+}
+
+//UploadPart 上传分段，返回分段ETag
+func (c *client) UploadPart(key string, uploadID string, number int, body []byte) (string, error) {
+	query := url.Values{"partNumber": []string{strconv.Itoa(number)}, "uploadId": []string{uploadID}}
+	resp, err := c.do(http.MethodPut, key, query, nil, body)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	return resp.Header.Get("ETag"), nil
+}
+
+//CompleteMultipartUpload 合并分段
+func (c *client) CompleteMultipartUpload(key string, uploadID string, parts []*completedPart) error {
+	body, err := xml.Marshal(&completeMultipartUpload{Parts: parts})
+	if err != nil {
+		return err
+	}
+	return c.doXML(http.MethodPost, key, url.Values{"uploadId": []string{uploadID}}, nil, body, &completeMultipartUploadResult{})
+}
+
+//AbortMultipartUpload 取消分段上传
+func (c *client) AbortMultipartUpload(key string, uploadID string) error {
+	return c.doXML(http.MethodDelete, key, url.Values{"uploadId": []string{uploadID}}, nil, nil, nil)
+}
+
+//Presign 生成预签名地址
+func (c *client) Presign(method string, key string, expire time.Duration) string {
+	return c.signer.Presign(method, c.objectURL(key, nil), expire, time.Now())
+}
@@ -0,0 +1,365 @@
+package s3
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/micro-plat/hydra/hydra/servers/pkg/nfs/infs"
+	"github.com/micro-plat/hydra/hydra/servers/pkg/nfs/internal"
+	"github.com/micro-plat/lib4go/errs"
+	"github.com/micro-plat/lib4go/security/md5"
+)
+
+//DefaultRegion 默认区域
+const DefaultRegion = "us-east-1"
+
+//PartSize 分段上传的分段大小，超过此大小的文件使用分段上传
+var PartSize = 8 * 1024 * 1024
+
+//S3 S3兼容对象存储，支持AWS S3、MinIO、Ceph、阿里云OSS(S3兼容模式)等
+type S3 struct {
+	ak        string
+	sk        string
+	bucket    string
+	endpoint  string
+	region    string
+	pathStyle bool
+	client    *client
+	excludes  []string
+	includes  []string
+}
+
+//NewS3 构建S3兼容对象存储，pathStyle为true时使用 endpoint/bucket/key 格式地址(MinIO、Ceph等)，否则使用 bucket.endpoint/key 格式地址
+func NewS3(ak, sk, bucket, endpoint, region string, pathStyle bool, excludes []string, includes ...string) *S3 {
+	return &S3{
+		ak:        ak,
+		sk:        sk,
+		bucket:    bucket,
+		endpoint:  endpoint,
+		region:    region,
+		pathStyle: pathStyle,
+		excludes:  excludes,
+		includes:  includes,
+	}
+}
+
+func (o *S3) Start() error {
+	var err error
+	o.client, err = newClient(o.ak, o.sk, o.bucket, o.endpoint, o.region, o.pathStyle)
+	return err
+}
+
+func (o *S3) Close() error {
+	return nil
+}
+
+func (o *S3) Save(path string, buff []byte) (string, error) {
+	return path, o.client.PutObject(path, infs.GetContentType(path), buff)
+}
+
+//SaveStream 以流的方式保存文件，超过分段大小时使用分段上传
+func (o *S3) SaveStream(path string, r io.Reader) (string, error) {
+	buff := make([]byte, PartSize)
+	n, err := io.ReadFull(r, buff)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return o.Save(path, buff[:n])
+	}
+	if err != nil {
+		return "", err
+	}
+
+	//分段上传
+	uploadID, err := o.client.CreateMultipartUpload(path, infs.GetContentType(path))
+	if err != nil {
+		return "", err
+	}
+	parts := make([]*completedPart, 0, 2)
+	for number := 1; n > 0; number++ {
+		etag, err := o.client.UploadPart(path, uploadID, number, buff[:n])
+		if err != nil {
+			o.client.AbortMultipartUpload(path, uploadID)
+			return "", err
+		}
+		parts = append(parts, &completedPart{PartNumber: number, ETag: etag})
+		n, err = io.ReadFull(r, buff)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			o.client.AbortMultipartUpload(path, uploadID)
+			return "", err
+		}
+	}
+	if err := o.client.CompleteMultipartUpload(path, uploadID, parts); err != nil {
+		o.client.AbortMultipartUpload(path, uploadID)
+		return "", err
+	}
+	return path, nil
+}
+
+func (o *S3) Get(path string) ([]byte, string, error) {
+	body, err := o.client.GetObject(path, 0, -1)
+	if err != nil {
+		return nil, "", o.error(path, err)
+	}
+	defer body.Close()
+	buff, err := ioutil.ReadAll(body)
+	return buff, infs.GetContentType(path), err
+}
+
+//Open 打开对象读取流，读取时按需发起范围请求
+func (o *S3) Open(path string) (infs.File, *infs.FileStat, error) {
+	info, err := o.client.HeadObject(path)
+	if err != nil {
+		return nil, nil, o.error(path, err)
+	}
+	stat := &infs.FileStat{
+		Size:        info.Size,
+		ModTime:     info.LastModified,
+		ContentType: infs.GetContentType(path),
+		ETag:        info.ETag,
+	}
+	return internal.NewRangeFile(info.Size, func(start int64, end int64) (io.ReadCloser, error) {
+		return o.client.GetObject(path, start, end)
+	}), stat, nil
+}
+
+//PresignGet 生成预签名下载地址
+func (o *S3) PresignGet(path string, expire time.Duration) (string, error) {
+	return o.client.Presign(http.MethodGet, path, expire), nil
+}
+
+//PresignPut 生成预签名上传地址
+func (o *S3) PresignPut(path string, expire time.Duration) (string, error) {
+	return o.client.Presign(http.MethodPut, path, expire), nil
+}
+
+func (o *S3) CreateDir(path string) error {
+	if !strings.HasSuffix(path, "/") {
+		path = path + "/"
+	}
+	return o.client.PutObject(path, "", nil)
+}
+
+func (o *S3) GetFileList(path string, q string, all bool, index int, count int) infs.FileList {
+	prefix := getCurrentDir(path)
+	objects, err := o.client.ListObjects(prefix)
+	if err != nil {
+		return make(infs.FileList, 0)
+	}
+	list := make(infs.FileList, 0, len(objects))
+	for _, val := range objects {
+		if strings.HasSuffix(val.Key, "/") || o.fileExclude(val.Key) || !strings.HasPrefix(val.Key, prefix) {
+			continue
+		}
+		if !strings.Contains(infs.GetFullFileName(val.Key), q) {
+			continue
+		}
+		list = append(list, &infs.FileInfo{
+			Path:    val.Key,
+			DPath:   infs.UnMultiPath(val.Key),
+			Name:    infs.GetFileName(val.Key),
+			Type:    infs.GetFileType(val.Key),
+			ModTime: val.LastModified.Local().Format("2006/01/02 15:04:05"),
+			Size:    val.Size,
+		})
+	}
+	sort.Sort(list)
+
+	max := index + count
+	if index >= list.Len() {
+		return make(infs.FileList, 0)
+	}
+	if max > list.Len() {
+		return list[index:]
+	}
+	return list[index:max]
+}
+
+func (o *S3) Exists(p string) bool {
+	_, err := o.client.HeadObject(p)
+	return err == nil
+}
+
+func (o *S3) Delete(path string) error {
+	if strings.HasSuffix(path, "/") {
+		list := o.GetFileList(path, "", true, 0, 1)
+		if len(list) > 0 {
+			return fmt.Errorf("目录不为空，不允许删除")
+		}
+	}
+	return o.client.DeleteObject(path)
+}
+
+func (o *S3) Rename(path string, new string) error {
+	list := o.GetFileList(path, "", true, 0, 1)
+	if len(list) > 0 {
+		return fmt.Errorf("目录不为空，不允许重命名")
+	}
+	if err := o.client.CopyObject(path, new); err != nil {
+		return o.error(path, err)
+	}
+	return o.Delete(path)
+}
+
+//Move 移动文件，复制到新路径后删除原文件
+func (o *S3) Move(path string, new string) error {
+	if path == new {
+		return nil
+	}
+	if o.Exists(new) {
+		return fmt.Errorf("文件名称重复:%s", new)
+	}
+	if err := o.client.CopyObject(path, new); err != nil {
+		return o.error(path, err)
+	}
+	return o.Delete(path)
+}
+
+func (o *S3) GetDirList(path string, deep int) infs.DirList {
+	objects, err := o.client.ListObjects(getCurrentDir(path))
+	if err != nil {
+		return make(infs.DirList, 0)
+	}
+	list := make(infs.DirList, 0, len(objects))
+	for _, val := range objects {
+		if val.Key == path || o.fileExclude(path) || !strings.HasSuffix(val.Key, "/") || !strings.HasPrefix(val.Key, path) {
+			continue
+		}
+		list = append(list, &infs.DirInfo{
+			ID:      md5.Encrypt(val.Key)[8:16],
+			Path:    infs.MultiPath(val.Key),
+			DPath:   infs.UnMultiPath(val.Key),
+			Name:    infs.GetFileName(val.Key),
+			ModTime: val.LastModified.Local().Format("2006/01/02 15:04:05"),
+			Size:    val.Size,
+			PID:     getParent(val.Key),
+		})
+	}
+	sort.Sort(list)
+	return list.GetMultiLevel(path)
+}
+
+func (o *S3) GetScaleImage(path string, width int, height int, quality int) (buff []byte, ctp string, err error) {
+	dir := getCurrentDir(path)
+	name := infs.GetFullFileName(path)
+	thumbnail := filepath.Join(dir, "__thumbnail_"+name)
+	if strings.HasPrefix(name, "__thumbnail_") {
+		thumbnail = path
+	}
+
+	if o.Exists(thumbnail) {
+		return o.Get(thumbnail)
+	}
+	if path == thumbnail || !o.Exists(path) {
+		return nil, "", errs.NewError(http.StatusNotFound, fmt.Errorf("文件不存在:%s", path))
+	}
+	buff, ctp, err = o.Get(path)
+	if err != nil {
+		return nil, "", err
+	}
+	buff, err = internal.ScaleImage(buff, width, height, quality)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err = o.Save(thumbnail, buff); err != nil {
+		return nil, "", err
+	}
+	return buff, ctp, nil
+}
+
+func (o *S3) GetPDF4Preview(path string) (buff []byte, ctp string, err error) {
+	dir := getCurrentDir(path)
+	oname := infs.GetFullFileName(path)
+	name := infs.GetFileName(path) + ".pdf"
+
+	//构建PDF文件目录
+	pdf := filepath.Join(dir, "__pdf_"+name)
+	if strings.HasPrefix(name, "__pdf_") {
+		pdf = path
+	}
+	if o.Exists(pdf) {
+		return o.Get(pdf)
+	}
+	if path == pdf || !o.Exists(path) {
+		return nil, "", errs.NewError(http.StatusNotFound, fmt.Errorf("文件不存在:%s", path))
+	}
+
+	//获取原始文件
+	buff, _, err = o.Get(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	//构建临时文件
+	tempDir, err := ioutil.TempDir("", "hydad-pdf-")
+	if err != nil {
+		return nil, "", fmt.Errorf("构建临时目录失败:%w", err)
+	}
+	defer os.RemoveAll(tempDir)
+	file, err := ioutil.TempFile(tempDir, "*"+oname)
+	if err != nil {
+		return nil, "", err
+	}
+	defer file.Close()
+	file.Write(buff)
+
+	//转换为pdf文件
+	buff, ctp, rpath, err := internal.Conver2PDF(tempDir, file.Name())
+	if err != nil {
+		return nil, "", err
+	}
+	defer os.Remove(rpath)
+
+	if _, err = o.Save(pdf, buff); err != nil {
+		return nil, "", err
+	}
+	return buff, ctp, nil
+}
+
+func (o *S3) Registry(tp string) {
+}
+
+//error 转换对象不存在错误
+func (o *S3) error(path string, err error) error {
+	if isNotFound(err) {
+		return errs.NewErrorf(http.StatusNotFound, "文件%s不存在", path)
+	}
+	return err
+}
+
+func (o *S3) fileExclude(p string) bool {
+	nexcludes := append(o.excludes, "__pdf_", "__thumbnail_")
+	return infs.Exclude(p, nexcludes, o.includes...)
+}
+
+func getCurrentDir(p string) string {
+	if len(p) > 1 {
+		if strings.HasSuffix(p, "/") {
+			return p
+		}
+		if !strings.Contains(p, ".") {
+			return p + "/"
+		}
+	}
+	f := filepath.Dir(p)
+	if f == "." || f == "/" || f == "" {
+		return ""
+	}
+	return f + "/"
+}
+
+func getParent(p string) string {
+	path := strings.Trim(p, "/")
+	if path == "" {
+		return path
+	}
+	items := strings.Split(path, "/")
+	return strings.Join(items[0:len(items)-1], "/")
+}
+
+var _ infs.Infs = &S3{}
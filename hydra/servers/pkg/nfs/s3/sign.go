@@ -0,0 +1,179 @@
+package s3
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	algorithm       = "AWS4-HMAC-SHA256"
+	service         = "s3"
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+	timeFormat      = "20060102T150405Z"
+	dateFormat      = "20060102"
+
+	headerDate          = "X-Amz-Date"
+	headerContentSHA256 = "X-Amz-Content-Sha256"
+)
+
+//signer AWS Signature Version 4 签名
+type signer struct {
+	ak     string
+	sk     string
+	region string
+}
+
+func newSigner(ak string, sk string, region string) *signer {
+	return &signer{ak: ak, sk: sk, region: region}
+}
+
+//Sign 对请求头签名，payloadHash为请求内容的sha256(十六进制)
+func (s *signer) Sign(req *http.Request, payloadHash string, t time.Time) {
+	amzDate := t.UTC().Format(timeFormat)
+	req.Header.Set(headerDate, amzDate)
+	req.Header.Set(headerContentSHA256, payloadHash)
+
+	headers, signed := canonicalHeaders(req)
+	scope := s.scope(t)
+	creq := strings.Join([]string{
+		req.Method,
+		canonicalURI(req.URL),
+		canonicalQuery(req.URL.Query()),
+		headers,
+		signed,
+		payloadHash,
+	}, "\n")
+	signature := s.signature(t, stringToSign(amzDate, scope, creq))
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		algorithm, s.ak, scope, signed, signature))
+}
+
+//Presign 生成预签名地址，只对host签名，请求内容不参与签名
+func (s *signer) Presign(method string, u *url.URL, expire time.Duration, t time.Time) string {
+	amzDate := t.UTC().Format(timeFormat)
+	scope := s.scope(t)
+	query := u.Query()
+	query.Set("X-Amz-Algorithm", algorithm)
+	query.Set("X-Amz-Credential", s.ak+"/"+scope)
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expire/time.Second)))
+	query.Set("X-Amz-SignedHeaders", "host")
+	creq := strings.Join([]string{
+		method,
+		canonicalURI(u),
+		canonicalQuery(query),
+		"host:" + u.Host + "\n",
+		"host",
+		unsignedPayload,
+	}, "\n")
+	query.Set("X-Amz-Signature", s.signature(t, stringToSign(amzDate, scope, creq)))
+	nu := *u
+	nu.RawQuery = canonicalQuery(query)
+	return nu.String()
+}
+
+func (s *signer) scope(t time.Time) string {
+	return strings.Join([]string{t.UTC().Format(dateFormat), s.region, service, "aws4_request"}, "/")
+}
+
+func (s *signer) signature(t time.Time, stringToSign string) string {
+	key := hmacSHA256([]byte("AWS4"+s.sk), t.UTC().Format(dateFormat))
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func stringToSign(amzDate string, scope string, creq string) string {
+	return strings.Join([]string{algorithm, amzDate, scope, hashSHA256([]byte(creq))}, "\n")
+}
+
+//canonicalHeaders 参与签名的请求头，包含host、content-type、content-md5及所有x-amz-*头
+func canonicalHeaders(req *http.Request) (string, string) {
+	values := map[string]string{"host": req.URL.Host}
+	if req.Host != "" {
+		values["host"] = req.Host
+	}
+	for k, v := range req.Header {
+		lk := strings.ToLower(k)
+		if lk == "content-type" || lk == "content-md5" || strings.HasPrefix(lk, "x-amz-") {
+			values[lk] = strings.Join(v, ",")
+		}
+	}
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var buff strings.Builder
+	for _, k := range keys {
+		buff.WriteString(k)
+		buff.WriteString(":")
+		buff.WriteString(strings.Join(strings.Fields(values[k]), " "))
+		buff.WriteString("\n")
+	}
+	return buff.String(), strings.Join(keys, ";")
+}
+
+//canonicalURI 按RFC3986编码路径，保留"/"
+func canonicalURI(u *url.URL) string {
+	path := u.EscapedPath()
+	if p, err := url.PathUnescape(path); err == nil {
+		path = p
+	}
+	if path == "" {
+		return "/"
+	}
+	return uriEncode(path, false)
+}
+
+//canonicalQuery 按参数名排序并按RFC3986编码查询参数
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	items := make([]string, 0, len(keys))
+	for _, k := range keys {
+		vs := append([]string{}, query[k]...)
+		sort.Strings(vs)
+		for _, v := range vs {
+			items = append(items, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	return strings.Join(items, "&")
+}
+
+//uriEncode 按RFC3986编码，encodeSlash为false时保留"/"
+func uriEncode(s string, encodeSlash bool) string {
+	var buff strings.Builder
+	for _, b := range []byte(s) {
+		if (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z') || (b >= '0' && b <= '9') ||
+			b == '-' || b == '_' || b == '.' || b == '~' || (b == '/' && !encodeSlash) {
+			buff.WriteByte(b)
+			continue
+		}
+		fmt.Fprintf(&buff, "%%%02X", b)
+	}
+	return buff.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func hashSHA256(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
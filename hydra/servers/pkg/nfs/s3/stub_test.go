@@ -0,0 +1,229 @@
+package s3
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+//stubObject 测试用S3服务中的对象
+type stubObject struct {
+	data    []byte
+	ctype   string
+	modTime time.Time
+}
+
+//stubS3 测试用S3服务(path-style)，校验SigV4签名，对象与分段上传数据保存在内存中
+type stubS3 struct {
+	*httptest.Server
+	ak       string
+	sk       string
+	bucket   string
+	pageSize int
+	lock     sync.Mutex
+	objects  map[string]*stubObject
+	uploads  map[string]map[int][]byte
+	requests []string
+}
+
+func newStubS3(ak string, sk string, bucket string) *stubS3 {
+	s := &stubS3{
+		ak:       ak,
+		sk:       sk,
+		bucket:   bucket,
+		pageSize: 2,
+		objects:  map[string]*stubObject{},
+		uploads:  map[string]map[int][]byte{},
+	}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+//Requested 是否收到过指定类型的请求
+func (s *stubS3) Requested(name string) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, r := range s.requests {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *stubS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	if err := s.verify(r, body); err != nil {
+		s.error(w, http.StatusForbidden, "SignatureDoesNotMatch", err.Error())
+		return
+	}
+	if !strings.HasPrefix(r.URL.Path, "/"+s.bucket) {
+		s.error(w, http.StatusNotFound, "NoSuchBucket", r.URL.Path)
+		return
+	}
+	key := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/"+s.bucket), "/")
+	query := r.URL.Query()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	switch {
+	case r.Method == http.MethodGet && key == "" && query.Get("list-type") == "2":
+		s.requests = append(s.requests, "list")
+		s.list(w, query.Get("prefix"), query.Get("continuation-token"))
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		obj, ok := s.objects[key]
+		if !ok {
+			s.error(w, http.StatusNotFound, "NoSuchKey", key)
+			return
+		}
+		w.Header().Set("Content-Type", obj.ctype)
+		w.Header().Set("ETag", fmt.Sprintf(`"%x"`, len(obj.data)))
+		http.ServeContent(w, r, key, obj.modTime, bytes.NewReader(obj.data))
+	case r.Method == http.MethodPut && query.Get("uploadId") != "":
+		parts, ok := s.uploads[query.Get("uploadId")]
+		if !ok {
+			s.error(w, http.StatusNotFound, "NoSuchUpload", key)
+			return
+		}
+		var number int
+		fmt.Sscan(query.Get("partNumber"), &number)
+		parts[number] = body
+		s.requests = append(s.requests, "part")
+		w.Header().Set("ETag", fmt.Sprintf(`"part-%d"`, number))
+	case r.Method == http.MethodPut && r.Header.Get("X-Amz-Copy-Source") != "":
+		source := strings.TrimPrefix(r.Header.Get("X-Amz-Copy-Source"), "/"+s.bucket+"/")
+		obj, ok := s.objects[source]
+		if !ok {
+			s.error(w, http.StatusNotFound, "NoSuchKey", source)
+			return
+		}
+		s.objects[key] = &stubObject{data: obj.data, ctype: obj.ctype, modTime: time.Now()}
+		s.write(w, &copyObjectResult{ETag: `"copy"`, LastModified: time.Now()})
+	case r.Method == http.MethodPut:
+		s.objects[key] = &stubObject{data: body, ctype: r.Header.Get("Content-Type"), modTime: time.Now()}
+	case r.Method == http.MethodPost && hasQuery(query, "uploads"):
+		id := fmt.Sprintf("upload-%d", len(s.uploads)+1)
+		s.uploads[id] = map[int][]byte{}
+		s.requests = append(s.requests, "initiate")
+		s.write(w, &initiateMultipartUploadResult{Bucket: s.bucket, Key: key, UploadID: id})
+	case r.Method == http.MethodPost && query.Get("uploadId") != "":
+		parts, ok := s.uploads[query.Get("uploadId")]
+		if !ok {
+			s.error(w, http.StatusNotFound, "NoSuchUpload", key)
+			return
+		}
+		input := &completeMultipartUpload{}
+		xml.Unmarshal(body, input)
+		data := make([]byte, 0, len(body))
+		for i, p := range input.Parts {
+			if p.PartNumber != i+1 || p.ETag != fmt.Sprintf(`"part-%d"`, p.PartNumber) {
+				s.error(w, http.StatusBadRequest, "InvalidPart", p.ETag)
+				return
+			}
+			data = append(data, parts[p.PartNumber]...)
+		}
+		delete(s.uploads, query.Get("uploadId"))
+		s.objects[key] = &stubObject{data: data, modTime: time.Now()}
+		s.requests = append(s.requests, "complete")
+		s.write(w, &completeMultipartUploadResult{Bucket: s.bucket, Key: key, ETag: `"complete"`})
+	case r.Method == http.MethodDelete && query.Get("uploadId") != "":
+		delete(s.uploads, query.Get("uploadId"))
+		s.requests = append(s.requests, "abort")
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		s.error(w, http.StatusMethodNotAllowed, "MethodNotAllowed", r.Method)
+	}
+}
+
+//list 按页返回指定前缀的对象
+func (s *stubS3) list(w http.ResponseWriter, prefix string, token string) {
+	keys := make([]string, 0, len(s.objects))
+	for k := range s.objects {
+		if strings.HasPrefix(k, prefix) && k > token {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	result := &listBucketResult{Name: s.bucket, Prefix: prefix}
+	if len(keys) > s.pageSize {
+		keys = keys[:s.pageSize]
+		result.IsTruncated = true
+		result.NextContinuationToken = keys[len(keys)-1]
+	}
+	for _, k := range keys {
+		obj := s.objects[k]
+		result.Contents = append(result.Contents, &listObject{Key: k, Size: int64(len(obj.data)), LastModified: obj.modTime})
+	}
+	result.KeyCount = len(keys)
+	s.write(w, result)
+}
+
+//verify 校验请求头签名或预签名地址
+func (s *stubS3) verify(r *http.Request, body []byte) error {
+	sg := newSigner(s.ak, s.sk, DefaultRegion)
+	query := r.URL.Query()
+	if sig := query.Get("X-Amz-Signature"); sig != "" {
+		t, err := time.Parse(timeFormat, query.Get("X-Amz-Date"))
+		if err != nil {
+			return err
+		}
+		var expire int
+		fmt.Sscan(query.Get("X-Amz-Expires"), &expire)
+		if time.Since(t) > time.Duration(expire)*time.Second {
+			return fmt.Errorf("签名已过期")
+		}
+		query.Del("X-Amz-Signature")
+		u := &url.URL{Scheme: "http", Host: r.Host, Path: r.URL.Path, RawPath: r.URL.RawPath, RawQuery: canonicalQuery(query)}
+		expect, err := url.Parse(sg.Presign(r.Method, u, time.Duration(expire)*time.Second, t))
+		if err != nil || expect.Query().Get("X-Amz-Signature") != sig {
+			return fmt.Errorf("预签名不匹配")
+		}
+		return nil
+	}
+
+	if hashSHA256(body) != r.Header.Get(headerContentSHA256) {
+		return fmt.Errorf("请求内容sha256不匹配")
+	}
+	t, err := time.Parse(timeFormat, r.Header.Get(headerDate))
+	if err != nil {
+		return err
+	}
+	expect, _ := http.NewRequest(r.Method, "http://"+r.Host+r.URL.RequestURI(), nil)
+	for k, v := range r.Header {
+		expect.Header[k] = v
+	}
+	sg.Sign(expect, r.Header.Get(headerContentSHA256), t)
+	if expect.Header.Get("Authorization") != r.Header.Get("Authorization") {
+		return fmt.Errorf("签名不匹配")
+	}
+	return nil
+}
+
+func (s *stubS3) write(w http.ResponseWriter, v interface{}) {
+	buff, _ := xml.Marshal(v)
+	w.Header().Set("Content-Type", "application/xml")
+	w.Write(buff)
+}
+
+func (s *stubS3) error(w http.ResponseWriter, status int, code string, msg string) {
+	buff, _ := xml.Marshal(&Error{Code: code, Message: msg})
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	w.Write(buff)
+}
+
+func hasQuery(query url.Values, name string) bool {
+	_, ok := query[name]
+	return ok
+}
@@ -0,0 +1,316 @@
+package nfs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/micro-plat/hydra/hydra/servers/pkg/nfs/infs"
+	"github.com/micro-plat/lib4go/errs"
+	"github.com/micro-plat/lib4go/utility"
+)
+
+const (
+	stateFile  = "upload.json"
+	partSuffix = ".part"
+
+	//maxPartNumber 最大分片编号
+	maxPartNumber = 10000
+
+	//maxPartSize 单个分片的最大字节数
+	maxPartSize int64 = 5 << 30
+)
+
+var uploadIDRegexp = regexp.MustCompile(`^[0-9a-zA-Z]+$`)
+
+//upload 分片上传任务
+type upload struct {
+	ID     string    `json:"upload_id"`
+	Name   string    `json:"name"`
+	Size   int64     `json:"size,omitempty"`
+	Create time.Time `json:"create"`
+}
+
+//part 已上传的分片
+type part struct {
+	Number int   `json:"part_number"`
+	Size   int64 `json:"size"`
+}
+
+//uploadStatus 分片上传状态，用于断点续传
+type uploadStatus struct {
+	*upload
+	Uploaded int64   `json:"uploaded"`
+	Parts    []*part `json:"parts"`
+}
+
+//uploads 分片上传管理，上传状态与分片保存在临时目录中，服务重启后可继续上传
+//每个上传任务一个目录，目录中包含任务信息(upload.json)与已上传的分片({number}.part)
+type uploads struct {
+	fs     infs.Infs
+	root   string
+	expire time.Duration
+}
+
+func newUploads(fs infs.Infs, root string, expire time.Duration) *uploads {
+	return &uploads{fs: fs, root: root, expire: expire}
+}
+
+//Init 创建分片上传任务
+func (u *uploads) Init(name string, size int64) (*upload, error) {
+	if name == "" {
+		return nil, errs.NewErrorf(http.StatusNotAcceptable, "参数不能为空,请求中应包含参数 \"%s\"", infs.FILENAME)
+	}
+	if size < 0 {
+		return nil, errs.NewErrorf(http.StatusNotAcceptable, "文件大小无效:%d", size)
+	}
+	u.clear()
+	up := &upload{ID: utility.GetGUID(), Name: name, Size: size, Create: time.Now()}
+	dir := filepath.Join(u.root, up.ID)
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return nil, fmt.Errorf("创建上传目录失败:%w", err)
+	}
+	buff, err := json.Marshal(up)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, stateFile), buff, 0666); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("保存上传任务失败:%w", err)
+	}
+	return up, nil
+}
+
+//WritePart 保存分片，分片完整写入后才记录为已上传，重复上传时覆盖原分片，
+//分片超过最大字节数或已上传大小超过任务文件大小时拒绝保存
+func (u *uploads) WritePart(id string, number int, r io.Reader) (*part, error) {
+	if number < 1 || number > maxPartNumber {
+		return nil, errs.NewErrorf(http.StatusNotAcceptable, "分片编号应在1-%d之间:%d", maxPartNumber, number)
+	}
+	up, err := u.get(id)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := u.partLimit(up, number)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(u.root, up.ID)
+	f, err := os.CreateTemp(dir, "*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("保存分片失败:%w", err)
+	}
+	defer os.Remove(f.Name())
+	size, err := io.Copy(f, io.LimitReader(r, limit+1))
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("保存分片失败:%w", err)
+	}
+	if size > limit {
+		f.Close()
+		return nil, errs.NewErrorf(http.StatusRequestEntityTooLarge, "分片%d超出大小限制,最大%d字节", number, limit)
+	}
+	if err = f.Close(); err != nil {
+		return nil, fmt.Errorf("保存分片失败:%w", err)
+	}
+	if err = os.Rename(f.Name(), filepath.Join(dir, fmt.Sprintf("%d%s", number, partSuffix))); err != nil {
+		return nil, fmt.Errorf("保存分片失败:%w", err)
+	}
+	return &part{Number: number, Size: size}, nil
+}
+
+//partLimit 获取分片允许的最大字节数，任务指定文件大小时不超过文件大小减去其它分片的大小
+func (u *uploads) partLimit(up *upload, number int) (int64, error) {
+	if up.Size <= 0 {
+		return maxPartSize, nil
+	}
+	parts, err := u.parts(up.ID)
+	if err != nil {
+		return 0, err
+	}
+	rest := up.Size
+	for _, p := range parts {
+		if p.Number != number {
+			rest -= p.Size
+		}
+	}
+	if rest < 0 {
+		rest = 0
+	}
+	if rest < maxPartSize {
+		return rest, nil
+	}
+	return maxPartSize, nil
+}
+
+//Status 获取上传任务状态与已上传的分片
+func (u *uploads) Status(id string) (*uploadStatus, error) {
+	up, err := u.get(id)
+	if err != nil {
+		return nil, err
+	}
+	parts, err := u.parts(up.ID)
+	if err != nil {
+		return nil, err
+	}
+	status := &uploadStatus{upload: up, Parts: parts}
+	for _, p := range parts {
+		status.Uploaded += p.Size
+	}
+	return status, nil
+}
+
+//Complete 按分片编号顺序合并分片并保存文件，分片编号须从1开始连续
+func (u *uploads) Complete(id string) (string, int64, error) {
+	status, err := u.Status(id)
+	if err != nil {
+		return "", 0, err
+	}
+	if len(status.Parts) == 0 {
+		return "", 0, errs.NewErrorf(http.StatusNotAcceptable, "未上传任何分片:%s", id)
+	}
+	for i, p := range status.Parts {
+		if p.Number != i+1 {
+			return "", 0, errs.NewErrorf(http.StatusNotAcceptable, "分片%d未上传", i+1)
+		}
+	}
+	if status.Size > 0 && status.Size != status.Uploaded {
+		return "", 0, errs.NewErrorf(http.StatusNotAcceptable, "文件大小不一致,应为%d,已上传%d", status.Size, status.Uploaded)
+	}
+
+	//合并分片
+	dir := filepath.Join(u.root, status.ID)
+	reader := &partsReader{paths: make([]string, 0, len(status.Parts))}
+	for _, p := range status.Parts {
+		reader.paths = append(reader.paths, filepath.Join(dir, fmt.Sprintf("%d%s", p.Number, partSuffix)))
+	}
+	defer reader.Close()
+	path, err := u.fs.SaveStream(status.Name, reader)
+	if err != nil {
+		return "", 0, err
+	}
+	os.RemoveAll(dir)
+	return path, status.Uploaded, nil
+}
+
+//Abort 取消上传任务，删除已上传的分片
+func (u *uploads) Abort(id string) error {
+	up, err := u.get(id)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(filepath.Join(u.root, up.ID))
+}
+
+//get 获取上传任务
+func (u *uploads) get(id string) (*upload, error) {
+	if !uploadIDRegexp.MatchString(id) {
+		return nil, errs.NewErrorf(http.StatusNotAcceptable, "上传任务编号无效:%s", id)
+	}
+	buff, err := os.ReadFile(filepath.Join(u.root, id, stateFile))
+	if os.IsNotExist(err) {
+		return nil, errs.NewErrorf(http.StatusNotFound, "上传任务不存在或已过期:%s", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取上传任务失败:%w", err)
+	}
+	up := &upload{}
+	if err := json.Unmarshal(buff, up); err != nil {
+		return nil, fmt.Errorf("上传任务数据有误:%w", err)
+	}
+	return up, nil
+}
+
+//parts 获取已上传的分片
+func (u *uploads) parts(id string) ([]*part, error) {
+	entities, err := os.ReadDir(filepath.Join(u.root, id))
+	if err != nil {
+		return nil, fmt.Errorf("读取分片失败:%w", err)
+	}
+	parts := make([]*part, 0, len(entities))
+	for _, e := range entities {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), partSuffix) {
+			continue
+		}
+		number, err := strconv.Atoi(strings.TrimSuffix(e.Name(), partSuffix))
+		if err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, fmt.Errorf("读取分片失败:%w", err)
+		}
+		parts = append(parts, &part{Number: number, Size: info.Size()})
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].Number < parts[j].Number })
+	return parts, nil
+}
+
+//clear 清理超过有效期未更新的上传任务
+func (u *uploads) clear() {
+	if u.expire <= 0 {
+		return
+	}
+	entities, err := os.ReadDir(u.root)
+	if err != nil {
+		return
+	}
+	for _, e := range entities {
+		if !e.IsDir() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || time.Since(info.ModTime()) < u.expire {
+			continue
+		}
+		os.RemoveAll(filepath.Join(u.root, e.Name()))
+	}
+}
+
+//partsReader 按顺序读取分片文件，同一时间只打开一个分片
+type partsReader struct {
+	paths []string
+	f     *os.File
+}
+
+func (r *partsReader) Read(p []byte) (int, error) {
+	for {
+		if r.f == nil {
+			if len(r.paths) == 0 {
+				return 0, io.EOF
+			}
+			f, err := os.Open(r.paths[0])
+			if err != nil {
+				return 0, fmt.Errorf("读取分片失败:%w", err)
+			}
+			r.f, r.paths = f, r.paths[1:]
+		}
+		n, err := r.f.Read(p)
+		if err != io.EOF {
+			return n, err
+		}
+		r.f.Close()
+		r.f = nil
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+//Close 关闭正在读取的分片
+func (r *partsReader) Close() error {
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
@@ -0,0 +1,149 @@
+package nfs
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/micro-plat/hydra/hydra/servers/pkg/nfs/infs"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+//memFS 测试用文件系统，记录以流的方式保存的文件
+type memFS struct {
+	infs.Infs
+	files map[string]string
+}
+
+func (m *memFS) SaveStream(name string, r io.Reader) (string, error) {
+	buff, err := ioutil.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	m.files[name] = string(buff)
+	return name, nil
+}
+
+func newTestUploads(t *testing.T) (*uploads, *memFS, func()) {
+	dir, err := ioutil.TempDir("", "nfs-chunk")
+	assert.Equal(t, nil, err, "创建临时目录")
+	fs := &memFS{files: map[string]string{}}
+	return newUploads(fs, dir, time.Hour), fs, func() { os.RemoveAll(dir) }
+}
+
+func TestUploads_Complete(t *testing.T) {
+	u, fs, clear := newTestUploads(t)
+	defer clear()
+
+	up, err := u.Init("a/b.txt", 11)
+	assert.Equal(t, nil, err, "创建上传任务")
+
+	_, err = u.WritePart(up.ID, 2, strings.NewReader(" world"))
+	assert.Equal(t, nil, err, "上传第二个分片")
+	_, _, err = u.Complete(up.ID)
+	assert.NotEqual(t, nil, err, "分片不连续时不能合并")
+
+	_, err = u.WritePart(up.ID, 1, strings.NewReader("hel"))
+	assert.Equal(t, nil, err, "上传第一个分片")
+	_, _, err = u.Complete(up.ID)
+	assert.NotEqual(t, nil, err, "文件大小不一致时不能合并")
+
+	p, err := u.WritePart(up.ID, 1, strings.NewReader("hello"))
+	assert.Equal(t, nil, err, "重新上传第一个分片")
+	assert.Equal(t, int64(5), p.Size, "分片大小")
+
+	status, err := u.Status(up.ID)
+	assert.Equal(t, nil, err, "查询上传状态")
+	assert.Equal(t, 2, len(status.Parts), "已上传分片个数")
+	assert.Equal(t, int64(11), status.Uploaded, "已上传大小")
+
+	path, size, err := u.Complete(up.ID)
+	assert.Equal(t, nil, err, "合并分片")
+	assert.Equal(t, "a/b.txt", path, "文件路径")
+	assert.Equal(t, int64(11), size, "文件大小")
+	assert.Equal(t, "hello world", fs.files["a/b.txt"], "文件内容")
+
+	_, err = u.Status(up.ID)
+	assert.NotEqual(t, nil, err, "合并后删除上传任务")
+}
+
+func TestUploads_WritePartLimit(t *testing.T) {
+	u, _, clear := newTestUploads(t)
+	defer clear()
+
+	up, err := u.Init("a/c.txt", 8)
+	assert.Equal(t, nil, err, "创建上传任务")
+	_, err = u.WritePart(up.ID, 1, strings.NewReader("123456789"))
+	assert.NotEqual(t, nil, err, "分片超过文件大小")
+	_, err = u.WritePart(up.ID, 1, strings.NewReader("12345"))
+	assert.Equal(t, nil, err, "上传第一个分片")
+	_, err = u.WritePart(up.ID, 2, strings.NewReader("6789"))
+	assert.NotEqual(t, nil, err, "已上传大小超过文件大小")
+	_, err = u.WritePart(up.ID, 1, strings.NewReader("1234"))
+	assert.Equal(t, nil, err, "重新上传第一个分片时不计算原分片大小")
+	_, err = u.WritePart(up.ID, 2, strings.NewReader("5678"))
+	assert.Equal(t, nil, err, "上传第二个分片")
+	status, _ := u.Status(up.ID)
+	assert.Equal(t, 2, len(status.Parts), "超出大小的分片未保存")
+
+	_, err = u.Init("a/d.txt", -1)
+	assert.NotEqual(t, nil, err, "文件大小无效")
+}
+
+func TestPartsReader(t *testing.T) {
+	dir := t.TempDir()
+	paths := make([]string, 0, 3)
+	for i, v := range []string{"ab", "", "cde"} {
+		path := filepath.Join(dir, fmt.Sprintf("%d%s", i+1, partSuffix))
+		os.WriteFile(path, []byte(v), 0666)
+		paths = append(paths, path)
+	}
+	r := &partsReader{paths: paths}
+	buff, err := ioutil.ReadAll(r)
+	assert.Equal(t, nil, err, "读取分片")
+	assert.Equal(t, "abcde", string(buff), "按顺序合并分片")
+	assert.Equal(t, true, r.f == nil, "读取完成后关闭分片")
+
+	r = &partsReader{paths: []string{filepath.Join(dir, "none")}}
+	_, err = ioutil.ReadAll(r)
+	assert.NotEqual(t, nil, err, "分片不存在")
+}
+
+func TestUploads_Abort(t *testing.T) {
+	u, _, clear := newTestUploads(t)
+	defer clear()
+
+	up, err := u.Init("b.txt", 0)
+	assert.Equal(t, nil, err, "创建上传任务")
+	_, err = u.WritePart(up.ID, 1, strings.NewReader("hello"))
+	assert.Equal(t, nil, err, "上传分片")
+	_, err = u.WritePart(up.ID, 0, strings.NewReader("hello"))
+	assert.NotEqual(t, nil, err, "无效的分片编号")
+
+	assert.Equal(t, nil, u.Abort(up.ID), "取消上传")
+	_, err = os.Stat(filepath.Join(u.root, up.ID))
+	assert.Equal(t, true, os.IsNotExist(err), "删除已上传的分片")
+
+	_, err = u.Status("../" + up.ID)
+	assert.NotEqual(t, nil, err, "无效的上传任务编号")
+}
+
+func TestUploads_Clear(t *testing.T) {
+	u, _, clear := newTestUploads(t)
+	defer clear()
+
+	up, err := u.Init("c.txt", 0)
+	assert.Equal(t, nil, err, "创建上传任务")
+	old := time.Now().Add(-2 * time.Hour)
+	os.Chtimes(filepath.Join(u.root, up.ID), old, old)
+
+	_, err = u.Init("d.txt", 0)
+	assert.Equal(t, nil, err, "创建新的上传任务")
+	_, err = u.Status(up.ID)
+	assert.NotEqual(t, nil, err, "清理过期的上传任务")
+}
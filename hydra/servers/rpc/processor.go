@@ -3,11 +3,13 @@ package rpc
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 
 	"github.com/micro-plat/hydra/components/rpcs/rpc/pb"
 	"github.com/micro-plat/hydra/conf/server/router"
 	"github.com/micro-plat/hydra/hydra/servers/pkg/adapter"
+	"github.com/micro-plat/hydra/hydra/servers/pkg/dispatcher"
 	"github.com/micro-plat/hydra/hydra/servers/pkg/middleware"
 	"github.com/micro-plat/lib4go/jsons"
 	"github.com/micro-plat/lib4go/types"
@@ -34,6 +36,7 @@ func NewProcessor(routers ...*router.Router) (p *Processor) {
 	p.engine.Use(middleware.Logging())
 	p.engine.Use(middleware.Recovery())
 
+	p.engine.Use(middleware.APM())   //链路跟踪
 	p.engine.Use(middleware.Trace()) //跟踪信息
 	p.engine.Use(middleware.Delay())
 	p.engine.Use(middlewares...)
@@ -52,34 +55,65 @@ func (s *Processor) Request(context context.Context, request *pb.RequestContext)
 	//转换输入参数
 	req, err := NewRequest(request)
 	if err != nil {
-		p = &pb.ResponseContext{}
-		p.Status = int32(http.StatusNotAcceptable)
-		p.Result = fmt.Sprintf("输入参数有误:%v", err)
-		return p, nil
+		return newResponse(http.StatusNotAcceptable, fmt.Sprintf("输入参数有误:%v", err)), nil
 	}
+	return s.handle(req), nil
+}
+
+//ServerStream 处理服务端流请求，处理函数通过context.GetStream推送消息，返回值作为最后一条消息
+func (s *Processor) ServerStream(request *pb.RequestContext, ss pb.RPC_ServerStreamServer) error {
+	return s.handleStream(ss.Context(), request, newStream(ss.Send, nil))
+}
+
+//BidiStream 处理双向流请求，首条消息用于路由及中间件处理，后续消息通过IStream.Recv读取
+func (s *Processor) BidiStream(bs pb.RPC_BidiStreamServer) error {
+	request, err := bs.Recv()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.handleStream(bs.Context(), request, newStream(bs.Send, bs.Recv))
+}
+
+func (s *Processor) handleStream(ctx context.Context, request *pb.RequestContext, stream *stream) error {
+	req, err := NewRequest(request)
+	if err != nil {
+		return stream.sendResponse(newResponse(http.StatusNotAcceptable, fmt.Sprintf("输入参数有误:%v", err)))
+	}
+	p := s.handle(&streamRequest{Request: req, ctx: ctx, stream: stream})
+
+	//处理函数无返回内容时不再推送结束消息
+	if p.Status == int32(http.StatusOK) && p.Result == "" {
+		return nil
+	}
+	return stream.sendResponse(p)
+}
+
+//handle 执行中间件及业务处理，并转换为响应内容
+func (s *Processor) handle(req dispatcher.IRequest) *pb.ResponseContext {
 
 	//发起本地处理
 	w, err := s.engine.HandleRequest(req)
 	if err != nil {
-		p = &pb.ResponseContext{}
-		p.Status = int32(http.StatusInternalServerError)
-		p.Result = fmt.Sprintf("处理请求有误%s", err.Error())
-		return p, nil
+		return newResponse(http.StatusInternalServerError, fmt.Sprintf("处理请求有误%s", err.Error()))
 	}
 
 	//处理响应内容
-	p = &pb.ResponseContext{}
+	p := &pb.ResponseContext{}
 	p.Status = int32(w.Status())
 	p.Result = string(w.Data())
 	h, err := jsons.Marshal(w.Header())
 	if err != nil {
-		p = &pb.ResponseContext{}
-		p.Status = int32(http.StatusInternalServerError)
-		p.Result = fmt.Sprintf("输换响应头失败 %s", err.Error())
-		return p, nil
+		return newResponse(http.StatusInternalServerError, fmt.Sprintf("输换响应头失败 %s", err.Error()))
 	}
 	p.Header = string(h)
-	return p, nil
+	return p
+}
+
+func newResponse(status int, result string) *pb.ResponseContext {
+	return &pb.ResponseContext{Status: int32(status), Result: result}
 }
 
 //GetServices 获取所有服务列表
@@ -50,6 +50,11 @@ func (w *Responsive) Start() (err error) {
 		w.log.Warnf("%s被禁用，未启动", w.conf.GetServerConf().GetServerType())
 		return
 	}
+	if err = w.Server.metric.Init(w.conf); err != nil {
+		err = fmt.Errorf("%s启动失败 %w", w.conf.GetServerConf().GetServerType(), err)
+		return
+	}
+
 	if err = w.Server.Start(); err != nil {
 		err = fmt.Errorf("%s启动失败 %w", w.conf.GetServerConf().GetServerType(), err)
 		return
@@ -0,0 +1,85 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/micro-plat/hydra/components/rpcs/rpc/pb"
+	hctx "github.com/micro-plat/hydra/context"
+	"github.com/micro-plat/lib4go/errs"
+)
+
+//stream 流式请求的消息通道
+type stream struct {
+	lock sync.Mutex
+	send func(*pb.ResponseContext) error
+	recv func() (*pb.RequestContext, error)
+}
+
+func newStream(send func(*pb.ResponseContext) error, recv func() (*pb.RequestContext, error)) *stream {
+	return &stream{send: send, recv: recv}
+}
+
+//Send 向调用方推送一条响应消息
+func (s *stream) Send(result interface{}) error {
+	p := &pb.ResponseContext{Status: int32(http.StatusOK)}
+	switch v := result.(type) {
+	case nil:
+	case string:
+		p.Result = v
+	case []byte:
+		p.Result = string(v)
+	case errs.IError:
+		p.Status = int32(v.GetCode())
+		p.Result = v.Error()
+	case error:
+		p.Status = int32(http.StatusInternalServerError)
+		p.Result = v.Error()
+	default:
+		buff, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("流消息转换失败:%w", err)
+		}
+		p.Result = string(buff)
+	}
+	return s.sendResponse(p)
+}
+
+//Recv 接收调用方发送的下一条消息，服务端流无后续消息
+func (s *stream) Recv() (string, error) {
+	if s.recv == nil {
+		return "", io.EOF
+	}
+	m, err := s.recv()
+	if err != nil {
+		return "", err
+	}
+	return m.Input, nil
+}
+
+func (s *stream) sendResponse(p *pb.ResponseContext) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.send(p)
+}
+
+//streamRequest 流式请求，上下文随调用方断开或撤销
+type streamRequest struct {
+	*Request
+	ctx    context.Context
+	stream *stream
+}
+
+//GetContext 获取请求上下文
+func (r *streamRequest) GetContext() context.Context {
+	return r.ctx
+}
+
+//GetStream 获取请求的消息流
+func (r *streamRequest) GetStream() hctx.IStream {
+	return r.stream
+}
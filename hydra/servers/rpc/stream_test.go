@@ -0,0 +1,107 @@
+package rpc_test
+
+import (
+	"io"
+	"net"
+	"testing"
+
+	"github.com/micro-plat/hydra/components/rpcs/rpc/pb"
+	"github.com/micro-plat/hydra/conf/app"
+	"github.com/micro-plat/hydra/context"
+	"github.com/micro-plat/hydra/creator"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/hydra/servers/rpc"
+	_ "github.com/micro-plat/hydra/registry/registry/localmemory"
+	"github.com/micro-plat/hydra/services"
+	"github.com/micro-plat/lib4go/assert"
+	xcontext "golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+//newStreamClient 发布rpc服务器配置，使用bufconn启动rpc服务并返回客户端
+func newStreamClient(t *testing.T) (pb.RPCClient, func()) {
+	global.Def.PlatName = "hydra_test"
+	global.Def.SysName = "stream"
+	global.Def.ClusterName = "test"
+	global.Def.RegistryAddr = "lm://."
+	global.Def.ServerTypes = []string{rpc.RPC}
+	creator.Conf.RPC(":8090")
+	err := creator.Conf.Pub(global.Def.PlatName, global.Def.SysName, global.Def.ClusterName, global.Def.RegistryAddr, nil)
+	assert.Equal(t, nil, err, "发布配置失败")
+	assert.Equal(t, nil, app.PullAndSave(), "拉取配置失败")
+
+	services.Def.RPC("/order/watch", func(ctx context.IContext, s context.IStream) interface{} {
+		for i := 0; i < 2; i++ {
+			if err := s.Send(map[string]interface{}{"id": i}); err != nil {
+				return err
+			}
+		}
+		return "done"
+	})
+	services.Def.RPC("/order/chat", func(ctx context.IContext, s context.IStream) interface{} {
+		for {
+			m, err := s.Recv()
+			if err == io.EOF {
+				return "bye"
+			}
+			if err != nil {
+				return err
+			}
+			s.Send(m)
+		}
+	})
+	routers, err := services.GetRouter(rpc.RPC).BuildRouters("")
+	assert.Equal(t, nil, err, "获取路由失败")
+
+	l := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	pb.RegisterRPCServer(srv, rpc.NewProcessor(routers.GetRouters()...))
+	go srv.Serve(l)
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(xcontext.Context, string) (net.Conn, error) {
+		return l.Dial()
+	}))
+	assert.Equal(t, nil, err, "连接服务器失败")
+	return pb.NewRPCClient(conn), func() {
+		conn.Close()
+		srv.Stop()
+	}
+}
+
+func TestProcessor_Stream(t *testing.T) {
+	client, stop := newStreamClient(t)
+	defer stop()
+
+	//服务端流，处理函数推送的消息及返回值依次返回
+	ss, err := client.ServerStream(xcontext.Background(), &pb.RequestContext{Service: "/order/watch", Method: "POST", Input: "{}", Header: "{}"})
+	assert.Equal(t, nil, err, "发送服务端流请求失败")
+	results := []string{}
+	for {
+		r, err := ss.Recv()
+		if err == io.EOF {
+			break
+		}
+		assert.Equal(t, nil, err, "读取响应失败")
+		assert.Equal(t, int32(200), r.Status, "响应状态码不一致")
+		results = append(results, r.Result)
+	}
+	assert.Equal(t, []string{`{"id":0}`, `{"id":1}`, "done"}, results, "服务端流响应不一致")
+
+	//双向流，首条消息用于路由，后续消息原样返回
+	bs, err := client.BidiStream(xcontext.Background())
+	assert.Equal(t, nil, err, "发送双向流请求失败")
+	assert.Equal(t, nil, bs.Send(&pb.RequestContext{Service: "/order/chat", Method: "POST", Input: "{}", Header: "{}"}), "发送首条消息失败")
+	assert.Equal(t, nil, bs.Send(&pb.RequestContext{Input: "hello"}), "发送消息失败")
+	r, err := bs.Recv()
+	assert.Equal(t, nil, err, "读取消息失败")
+	assert.Equal(t, "hello", r.Result, "双向流消息不一致")
+	assert.Equal(t, nil, bs.CloseSend(), "结束发送失败")
+	r, err = bs.Recv()
+	assert.Equal(t, nil, err, "读取结束消息失败")
+	assert.Equal(t, "bye", r.Result, "双向流结束消息不一致")
+
+	//非流式请求调用流式服务
+	resp, err := client.Request(xcontext.Background(), &pb.RequestContext{Service: "/order/watch", Method: "POST", Input: "{}", Header: "{}"})
+	assert.Equal(t, nil, err, "发送请求失败")
+	assert.Equal(t, int32(406), resp.Status, "非流式请求应返回406")
+}
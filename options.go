@@ -122,3 +122,10 @@ func WithTracePort(port string) Option {
 		global.Def.TracePort = port
 	}
 }
+
+//WithOpenAPI 设置debug模式下OpenAPI文档的访问路径
+func WithOpenAPI(path string) Option {
+	return func() {
+		global.Def.OpenAPIPath = path
+	}
+}
@@ -95,6 +95,7 @@ func (p *Publisher) Publish(serverName string, serviceAddr string, clusterID str
 	input["addr"] = serviceAddr
 	input["cluster_id"] = clusterID
 	input["time"] = time.Now().Unix()
+	p.appendWeight(input)
 	buff, err := jsons.Marshal(input)
 	if err != nil {
 		return fmt.Errorf("服务器发布数据转换为json失败:%w", err)
@@ -126,6 +127,7 @@ func (p *Publisher) Update(serverName string, serviceAddr string, clusterID stri
 	input["addr"] = serviceAddr
 	input["cluster_id"] = clusterID
 	input["time"] = time.Now().Unix()
+	p.appendWeight(input)
 
 	if len(kv)%2 > 0 {
 		return fmt.Errorf("更新服务器发布数据,展参数必须成对出现：%d", len(kv))
@@ -156,6 +158,17 @@ func (p *Publisher) Update(serverName string, serviceAddr string, clusterID stri
 	return nil
 }
 
+//appendWeight 添加服务权重，供客户端加权负载使用
+func (p *Publisher) appendWeight(input map[string]interface{}) {
+	main := p.c.GetMainConf()
+	if main == nil {
+		return
+	}
+	if weight := main.GetInt("weight"); weight > 0 {
+		input["weight"] = weight
+	}
+}
+
 //PubRPCServiceNode 发布RPC服务节点
 func (p *Publisher) PubRPCServiceNode(serverName string, service string, data string) (map[string]string, error) {
 	path := registry.Join(p.c.GetRPCServicePubPath(service), serverName+"_")
@@ -23,6 +23,9 @@ const FileSystem = "fs"
 //Consul Consul
 const Consul = "consul"
 
+//Etcd etcd
+const Etcd = "etcd"
+
 //Redis redis
 const Redis = "redis"
 
@@ -39,6 +42,12 @@ type IRegistry interface {
 	CreateTempNode(path string, data string) (err error)
 	CreateSeqNode(path string, data string) (rpath string, err error)
 	Update(path string, data string) (err error)
+
+	//UpdateIfVersion 节点版本与GetValue返回的version一致时更新，否则返回ErrVersionConflict
+	UpdateIfVersion(path string, data string, version int32) (err error)
+
+	//Txn 原子执行多个操作，任一操作的版本检查失败时所有操作均不生效
+	Txn(ops ...Op) (err error)
 	Delete(path string) error
 	Exists(path string) (bool, error)
 	Close() error
@@ -0,0 +1,187 @@
+package consul
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/micro-plat/hydra/global"
+	r "github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/hydra/registry/registry/consul/internal"
+	"github.com/micro-plat/lib4go/concurrent/cmap"
+	"github.com/micro-plat/lib4go/logger"
+	"github.com/micro-plat/lib4go/types"
+)
+
+//Consul 基于consul kv的注册中心
+type Consul struct {
+	closeCh     chan struct{}
+	once        sync.Once
+	slock       sync.Mutex
+	seqPath     string
+	maxSeq      int64
+	sessionTTL  time.Duration
+	checkTicker time.Duration
+	waitTime    time.Duration
+	session     string
+	tmpNodes    cmap.ConcurrentMap
+	client      *internal.Client
+	log         logger.ILogging
+}
+
+//NewConsul 构建consul注册中心
+func NewConsul(c *internal.ClientConf, log logger.ILogging) (*Consul, error) {
+	client, err := internal.NewClient(c)
+	if err != nil {
+		return nil, err
+	}
+	if log == nil {
+		log = global.Def.Log()
+	}
+	consul := &Consul{
+		client:      client,
+		log:         log,
+		sessionTTL:  time.Second * 15,
+		checkTicker: time.Second * 5,
+		waitTime:    time.Minute,
+		maxSeq:      9999999999,
+		tmpNodes:    cmap.New(4),
+		closeCh:     make(chan struct{}),
+		seqPath:     swapKey("hydra", "seq"),
+	}
+	go consul.keepalive()
+	return consul, nil
+}
+
+//Close 关闭当前服务
+func (c *Consul) Close() error {
+	c.once.Do(func() {
+		close(c.closeCh)
+		c.slock.Lock()
+		defer c.slock.Unlock()
+		if c.session != "" {
+			c.client.DestroySession(c.session)
+			c.session = ""
+		}
+		c.tmpNodes.Clear()
+	})
+	return nil
+}
+
+//getSession 获取临时节点使用的会话，不存在时创建
+func (c *Consul) getSession() (string, error) {
+	c.slock.Lock()
+	defer c.slock.Unlock()
+	if c.session != "" {
+		return c.session, nil
+	}
+	id, err := c.client.CreateSession(fmt.Sprintf("hydra-%s", global.Def.PlatName), c.sessionTTL)
+	if err != nil {
+		return "", fmt.Errorf("创建consul会话失败:%w", err)
+	}
+	c.session = id
+	return id, nil
+}
+
+//keepalive 定时续约会话，会话过期后重建会话并恢复临时节点
+func (c *Consul) keepalive() {
+	tk := time.NewTicker(c.checkTicker)
+	defer tk.Stop()
+	for {
+		select {
+		case <-c.closeCh:
+			return
+		case <-tk.C:
+			c.slock.Lock()
+			session := c.session
+			c.slock.Unlock()
+			if session == "" {
+				continue
+			}
+			err := c.client.RenewSession(session)
+			if err == nil {
+				continue
+			}
+			if err != internal.ErrSessionNotFound {
+				c.log.Warnf("consul会话续约失败:%v", err)
+				continue
+			}
+			c.slock.Lock()
+			if c.session == session {
+				c.session = ""
+			}
+			c.slock.Unlock()
+			c.recoverTmpNodes()
+		}
+	}
+}
+
+//recoverTmpNodes 重新创建会话过期后被删除的临时节点
+func (c *Consul) recoverTmpNodes() {
+	for k, v := range c.tmpNodes.Items() {
+		if err := c.acquire(k, v.(string)); err != nil {
+			c.log.Errorf("恢复临时节点%s失败:%v", swapPath(k), err)
+		}
+	}
+}
+
+func (c *Consul) acquire(key string, data string) error {
+	session, err := c.getSession()
+	if err != nil {
+		return err
+	}
+	ok, err := c.client.Acquire(key, []byte(data), session)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("节点%s已被其它会话占用", swapPath(key))
+	}
+	return nil
+}
+
+//swapKey 将注册中心路径转换为consul键
+func swapKey(elem ...string) string {
+	return r.Trim(r.Join(elem...))
+}
+
+//swapPath 将consul键转换为注册中心路径
+func swapPath(key string) string {
+	return r.Join(key)
+}
+
+//toVersion 将consul索引转换为节点版本号
+func toVersion(index uint64) int32 {
+	return int32(index & 0x7fffffff)
+}
+
+//consulFactory 基于consul的注册中心
+type consulFactory struct {
+	opts *r.Options
+}
+
+//Create 根据配置生成consul注册中心
+func (z *consulFactory) Create(opts ...r.Option) (r.IRegistry, error) {
+	for i := range opts {
+		opts[i](z.opts)
+	}
+	conf := &internal.ClientConf{
+		Address:    z.opts.Addrs,
+		Datacenter: z.opts.Metadata["db"],
+		Timeout:    time.Duration(types.GetInt(z.opts.Metadata["timeout"], 10)) * time.Second,
+	}
+	if z.opts.Auth != nil {
+		conf.Token = types.GetString(z.opts.Auth.Password, z.opts.Auth.Username)
+	}
+	for i, addr := range conf.Address {
+		conf.Address[i] = strings.TrimSpace(addr)
+	}
+	return NewConsul(conf, z.opts.Logger)
+}
+
+func init() {
+	r.Register(r.Consul, &consulFactory{
+		opts: &r.Options{},
+	})
+}
@@ -0,0 +1,378 @@
+package consul
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	r "github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/hydra/registry/registry/consul/internal"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+//fakeConsul 模拟consul kv与session接口
+type fakeConsul struct {
+	mu       sync.Mutex
+	index    uint64
+	kvs      map[string]*internal.KVPair
+	sessions map[string]bool
+	changed  chan struct{}
+}
+
+func newFakeConsul() *fakeConsul {
+	return &fakeConsul{
+		index:    1,
+		kvs:      make(map[string]*internal.KVPair),
+		sessions: make(map[string]bool),
+		changed:  make(chan struct{}),
+	}
+}
+
+func (f *fakeConsul) notify() {
+	f.index++
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+//expireSession 模拟会话过期，删除会话关联的所有键
+func (f *fakeConsul) expireSession(id string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.sessions, id)
+	for k, v := range f.kvs {
+		if v.Session == id {
+			delete(f.kvs, k)
+		}
+	}
+	f.notify()
+}
+
+func (f *fakeConsul) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+	switch {
+	case strings.HasPrefix(req.URL.Path, "/v1/session/create"):
+		f.mu.Lock()
+		id := "session-" + strconv.FormatUint(f.index, 10)
+		f.sessions[id] = true
+		f.notify()
+		f.mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]string{"ID": id})
+	case strings.HasPrefix(req.URL.Path, "/v1/session/renew/"):
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if !f.sessions[strings.TrimPrefix(req.URL.Path, "/v1/session/renew/")] {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case strings.HasPrefix(req.URL.Path, "/v1/session/destroy/"):
+		f.expireSession(strings.TrimPrefix(req.URL.Path, "/v1/session/destroy/"))
+	case req.URL.Path == "/v1/txn":
+		f.serveTxn(w, req)
+	case strings.HasPrefix(req.URL.Path, "/v1/kv/"):
+		f.serveKV(w, req, strings.TrimPrefix(req.URL.Path, "/v1/kv/"), q)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func (f *fakeConsul) serveKV(w http.ResponseWriter, req *http.Request, key string, q map[string][]string) {
+	switch req.Method {
+	case http.MethodGet:
+		index, _ := strconv.ParseUint(first(q, "index"), 10, 64)
+		f.mu.Lock()
+		if index > 0 && index >= f.index {
+			ch := f.changed
+			f.mu.Unlock()
+			select {
+			case <-ch:
+			case <-time.After(time.Second):
+			}
+			f.mu.Lock()
+		}
+		defer f.mu.Unlock()
+		w.Header().Set("X-Consul-Index", strconv.FormatUint(f.index, 10))
+		if _, ok := q["keys"]; ok {
+			f.serveKeys(w, key)
+			return
+		}
+		kv, ok := f.kvs[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode([]*internal.KVPair{kv})
+	case http.MethodPut:
+		buff, _ := ioutil.ReadAll(req.Body)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		old, exists := f.kvs[key]
+		if cas, ok := q["cas"]; ok {
+			idx, _ := strconv.ParseUint(cas[0], 10, 64)
+			if (idx == 0 && exists) || (idx != 0 && (!exists || old.ModifyIndex != idx)) {
+				w.Write([]byte("false"))
+				return
+			}
+		}
+		kv := &internal.KVPair{Key: key, Value: buff, CreateIndex: f.index + 1, ModifyIndex: f.index + 1}
+		if exists {
+			kv.CreateIndex = old.CreateIndex
+			kv.Session = old.Session
+		}
+		if session := first(q, "acquire"); session != "" {
+			if !f.sessions[session] || (kv.Session != "" && kv.Session != session) {
+				w.Write([]byte("false"))
+				return
+			}
+			kv.Session = session
+		}
+		f.kvs[key] = kv
+		f.notify()
+		w.Write([]byte("true"))
+	case http.MethodDelete:
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		delete(f.kvs, key)
+		f.notify()
+		w.Write([]byte("true"))
+	}
+}
+
+//serveTxn 在键值副本上依次执行操作，全部成功后替换
+func (f *fakeConsul) serveTxn(w http.ResponseWriter, req *http.Request) {
+	ops := make([]map[string]*internal.TxnOp, 0, 1)
+	json.NewDecoder(req.Body).Decode(&ops)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	kvs := make(map[string]*internal.KVPair, len(f.kvs))
+	for k, v := range f.kvs {
+		kvs[k] = v
+	}
+	for _, m := range ops {
+		op := m["KV"]
+		old, exists := kvs[op.Key]
+		matched := (op.Index == 0 && !exists) || (exists && old.ModifyIndex == op.Index)
+		switch op.Verb {
+		case "cas", "check-index", "delete-cas":
+			if !matched {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+		}
+		switch op.Verb {
+		case "cas", "set", "lock":
+			kv := &internal.KVPair{Key: op.Key, Value: op.Value, CreateIndex: f.index + 1, ModifyIndex: f.index + 1, Session: op.Session}
+			if exists {
+				kv.CreateIndex = old.CreateIndex
+			}
+			kvs[op.Key] = kv
+		case "delete", "delete-cas":
+			delete(kvs, op.Key)
+		}
+	}
+	f.kvs = kvs
+	f.notify()
+	w.Write([]byte("{}"))
+}
+
+func (f *fakeConsul) serveKeys(w http.ResponseWriter, prefix string) {
+	cache := map[string]bool{}
+	keys := make([]string, 0, 1)
+	for k := range f.kvs {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		name := prefix + strings.SplitAfterN(strings.TrimPrefix(k, prefix), "/", 2)[0]
+		if !cache[name] {
+			cache[name] = true
+			keys = append(keys, name)
+		}
+	}
+	if len(keys) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	sort.Strings(keys)
+	json.NewEncoder(w).Encode(keys)
+}
+
+func first(q map[string][]string, key string) string {
+	if v, ok := q[key]; ok && len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func newConsulForTest(t *testing.T) (*Consul, *fakeConsul, func()) {
+	fake := newFakeConsul()
+	srv := httptest.NewServer(fake)
+	c, err := NewConsul(&internal.ClientConf{Address: []string{"127.0.0.1:1", srv.URL}, Timeout: time.Second}, nil)
+	assert.Equal(t, nil, err, "构建consul注册中心失败")
+	c.waitTime = time.Second
+	return c, fake, func() {
+		c.Close()
+		srv.Close()
+	}
+}
+
+func TestConsul_PersistentNode(t *testing.T) {
+	c, _, closer := newConsulForTest(t)
+	defer closer()
+
+	err := c.CreatePersistentNode("/hydra/apiserver/conf", `{"address":":8080"}`)
+	assert.Equal(t, nil, err, "创建永久节点失败")
+
+	data, version, err := c.GetValue("/hydra/apiserver/conf")
+	assert.Equal(t, nil, err, "获取节点值失败")
+	assert.Equal(t, `{"address":":8080"}`, string(data), "节点值不一致")
+	assert.Equal(t, true, version > 0, "版本号错误")
+
+	//目录节点
+	data, _, err = c.GetValue("/hydra/apiserver")
+	assert.Equal(t, nil, err, "获取目录节点失败")
+	assert.Equal(t, 0, len(data), "目录节点值应为空")
+
+	ok, err := c.Exists("/hydra/apiserver")
+	assert.Equal(t, true, ok && err == nil, "目录节点应存在")
+
+	err = c.Update("/hydra/apiserver/conf", `{"address":":9090"}`)
+	assert.Equal(t, nil, err, "更新节点失败")
+	data, nversion, _ := c.GetValue("/hydra/apiserver/conf")
+	assert.Equal(t, `{"address":":9090"}`, string(data), "更新后的节点值不一致")
+	assert.Equal(t, true, nversion > version, "更新后版本号应增加")
+
+	err = c.Update("/hydra/apiserver/none", "{}")
+	assert.Equal(t, true, err != nil, "不存在的节点不能更新")
+
+	children, _, err := c.GetChildren("/hydra")
+	assert.Equal(t, nil, err, "获取子节点失败")
+	assert.Equal(t, []string{"apiserver"}, children, "子节点不一致")
+
+	err = c.Delete("/hydra/apiserver/conf")
+	assert.Equal(t, nil, err, "删除节点失败")
+	ok, _ = c.Exists("/hydra/apiserver/conf")
+	assert.Equal(t, false, ok, "删除后节点不应存在")
+	_, _, err = c.GetValue("/hydra/apiserver")
+	assert.Equal(t, true, err != nil, "无子节点的目录节点不应存在")
+}
+
+func TestConsul_TempNode(t *testing.T) {
+	c, fake, closer := newConsulForTest(t)
+	defer closer()
+	c.checkTicker = time.Millisecond * 50
+	go c.keepalive()
+
+	err := c.CreateTempNode("/hydra/servers/192.168.0.1", "{}")
+	assert.Equal(t, nil, err, "创建临时节点失败")
+	assert.Equal(t, c.session, fake.kvs["hydra/servers/192.168.0.1"].Session, "临时节点应与会话绑定")
+
+	//会话过期后自动恢复临时节点
+	fake.expireSession(c.session)
+	ok, _ := c.Exists("/hydra/servers/192.168.0.1")
+	assert.Equal(t, false, ok, "会话过期后临时节点应被删除")
+	time.Sleep(time.Millisecond * 300)
+	ok, _ = c.Exists("/hydra/servers/192.168.0.1")
+	assert.Equal(t, true, ok, "临时节点应被恢复")
+
+	//关闭后临时节点被删除
+	c.Close()
+	ok, _ = c.Exists("/hydra/servers/192.168.0.1")
+	assert.Equal(t, false, ok, "关闭后临时节点应被删除")
+}
+
+func TestConsul_SeqNode(t *testing.T) {
+	c, _, closer := newConsulForTest(t)
+	defer closer()
+
+	p1, err := c.CreateSeqNode("/dlock/hydra/test/dlock_", "{}")
+	assert.Equal(t, nil, err, "创建序列节点失败")
+	p2, err := c.CreateSeqNode("/dlock/hydra/test/dlock_", "{}")
+	assert.Equal(t, nil, err, "创建序列节点失败")
+	assert.Equal(t, "/dlock/hydra/test/dlock_0000000001", p1, "序列节点路径错误")
+	assert.Equal(t, "/dlock/hydra/test/dlock_0000000002", p2, "序列节点路径错误")
+
+	children, _, err := c.GetChildren("/dlock/hydra/test")
+	assert.Equal(t, nil, err, "获取子节点失败")
+	assert.Equal(t, []string{"dlock_0000000001", "dlock_0000000002"}, children, "子节点不一致")
+}
+
+func TestConsul_WatchValue(t *testing.T) {
+	c, _, closer := newConsulForTest(t)
+	defer closer()
+
+	c.CreatePersistentNode("/hydra/watch/value", "1")
+	ch, err := c.WatchValue("/hydra/watch/value")
+	assert.Equal(t, nil, err, "监控节点失败")
+
+	//其它节点变化不应触发通知
+	c.CreatePersistentNode("/hydra/watch/other", "1")
+	c.Update("/hydra/watch/value", "2")
+	select {
+	case w := <-ch:
+		assert.Equal(t, nil, w.GetError(), "监控返回错误")
+		v, _ := w.GetValue()
+		assert.Equal(t, "2", string(v), "监控值不一致")
+	case <-time.After(time.Second * 3):
+		t.Fatal("未收到值变化通知")
+	}
+
+	_, err = c.WatchValue("/hydra/watch/none")
+	assert.Equal(t, true, err != nil, "不存在的节点不能监控")
+}
+
+func TestConsul_WatchChildren(t *testing.T) {
+	c, _, closer := newConsulForTest(t)
+	defer closer()
+
+	c.CreatePersistentNode("/hydra/watch/children/a", "1")
+	ch, err := c.WatchChildren("/hydra/watch/children")
+	assert.Equal(t, nil, err, "监控子节点失败")
+
+	//子节点值变化不应触发通知
+	c.Update("/hydra/watch/children/a", "2")
+	c.CreateTempNode("/hydra/watch/children/b", "1")
+	select {
+	case w := <-ch:
+		assert.Equal(t, nil, w.GetError(), "监控返回错误")
+		v, _ := w.GetValue()
+		assert.Equal(t, []string{"a", "b"}, v, "子节点不一致")
+	case <-time.After(time.Second * 3):
+		t.Fatal("未收到子节点变化通知")
+	}
+}
+
+func TestConsul_Txn(t *testing.T) {
+	c, _, closer := newConsulForTest(t)
+	defer closer()
+
+	err := c.Txn(r.CreateOp("/hydra/txn/a", "1"), r.CreateOp("/hydra/txn/b", "2"))
+	assert.Equal(t, nil, err, "事务创建节点失败")
+	err = c.Txn(r.CreateOp("/hydra/txn/a", "1"))
+	assert.Equal(t, true, r.IsConflict(err), "节点已存在时创建失败")
+
+	_, version, _ := c.GetValue("/hydra/txn/a")
+	err = c.UpdateIfVersion("/hydra/txn/a", "3", version)
+	assert.Equal(t, nil, err, "版本一致时更新成功")
+	err = c.UpdateIfVersion("/hydra/txn/a", "4", version)
+	assert.Equal(t, true, r.IsConflict(err), "版本不一致时更新失败")
+	data, _, _ := c.GetValue("/hydra/txn/a")
+	assert.Equal(t, "3", string(data), "版本冲突时节点值不变")
+
+	_, va, _ := c.GetValue("/hydra/txn/a")
+	_, vb, _ := c.GetValue("/hydra/txn/b")
+	c.Update("/hydra/txn/b", "5")
+	err = c.Txn(r.DeleteOp("/hydra/txn/a", va), r.UpdateOp("/hydra/txn/b", "6", vb))
+	assert.Equal(t, true, r.IsConflict(err), "任一节点版本不一致时事务失败")
+	ok, _ := c.Exists("/hydra/txn/a")
+	assert.Equal(t, true, ok, "事务失败时所有操作均不生效")
+
+	err = c.Txn(r.CheckOp("/hydra/txn/b", r.AnyVersion), r.DeleteOp("/hydra/txn/a", va))
+	assert.Equal(t, nil, err, "事务删除节点失败")
+	ok, _ = c.Exists("/hydra/txn/a")
+	assert.Equal(t, false, ok, "节点已删除")
+}
@@ -0,0 +1,67 @@
+package consul
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/micro-plat/hydra/registry/registry/consul/internal"
+)
+
+//CreatePersistentNode 创建永久节点
+func (c *Consul) CreatePersistentNode(path string, data string) (err error) {
+	key := swapKey(path)
+	if _, err = c.client.Put(key, []byte(data)); err != nil {
+		return fmt.Errorf("创建节点%s失败:%w", path, err)
+	}
+	return nil
+}
+
+//CreateTempNode 创建临时节点，节点与会话绑定，会话失效时自动删除
+func (c *Consul) CreateTempNode(path string, data string) (err error) {
+	key := swapKey(path)
+	if err = c.acquire(key, data); err != nil {
+		return fmt.Errorf("创建临时节点%s失败:%w", path, err)
+	}
+	c.tmpNodes.Set(key, data)
+	return nil
+}
+
+//CreateSeqNode 创建序列节点
+func (c *Consul) CreateSeqNode(path string, data string) (rpath string, err error) {
+	nid, err := c.getSeq()
+	if err != nil {
+		return "", err
+	}
+	rpath = fmt.Sprintf("%s%010d", path, nid)
+	if err = c.CreateTempNode(rpath, data); err != nil {
+		return "", err
+	}
+	return swapPath(swapKey(rpath)), nil
+}
+
+//getSeq 通过cas方式递增序列号
+func (c *Consul) getSeq() (int64, error) {
+	for {
+		var index uint64
+		var current int64
+		kv, _, err := c.client.Get(c.seqPath)
+		switch {
+		case err == nil:
+			index = kv.ModifyIndex
+			current, _ = strconv.ParseInt(string(kv.Value), 10, 64)
+		case err != internal.ErrNotFound:
+			return 0, fmt.Errorf("获取序列号失败:%w", err)
+		}
+		next := current + 1
+		if next >= c.maxSeq {
+			next = 1
+		}
+		ok, err := c.client.CAS(c.seqPath, []byte(strconv.FormatInt(next, 10)), index)
+		if err != nil {
+			return 0, fmt.Errorf("更新序列号失败:%w", err)
+		}
+		if ok {
+			return next, nil
+		}
+	}
+}
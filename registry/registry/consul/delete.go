@@ -0,0 +1,15 @@
+package consul
+
+import (
+	"fmt"
+)
+
+//Delete 删除节点
+func (c *Consul) Delete(path string) error {
+	key := swapKey(path)
+	if err := c.client.Delete(key, false); err != nil {
+		return fmt.Errorf("%v(%s)", err, path)
+	}
+	c.tmpNodes.Remove(key)
+	return nil
+}
@@ -0,0 +1,19 @@
+package consul
+
+import "github.com/micro-plat/hydra/registry/registry/consul/internal"
+
+//Exists 检查节点是否存在
+func (c *Consul) Exists(path string) (bool, error) {
+	_, _, err := c.client.Get(swapKey(path))
+	if err == nil {
+		return true, nil
+	}
+	if err != internal.ErrNotFound {
+		return false, err
+	}
+	children, _, err := c.GetChildren(path)
+	if err != nil {
+		return false, err
+	}
+	return len(children) > 0, nil
+}
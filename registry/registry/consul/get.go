@@ -0,0 +1,60 @@
+package consul
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/micro-plat/hydra/registry/registry/consul/internal"
+)
+
+//GetValue 获取节点值
+func (c *Consul) GetValue(path string) (data []byte, version int32, err error) {
+	kv, _, err := c.client.Get(swapKey(path))
+	if err == nil {
+		return kv.Value, toVersion(kv.ModifyIndex), nil
+	}
+	if err != internal.ErrNotFound {
+		return nil, 0, err
+	}
+
+	//consul中不存在目录节点，有子节点时视为节点存在
+	children, _, err := c.GetChildren(path)
+	if err != nil || len(children) == 0 {
+		return nil, 0, fmt.Errorf("数据不存在")
+	}
+	return []byte{}, 0, nil
+}
+
+//GetChildren 获取所有子节点
+func (c *Consul) GetChildren(path string) (paths []string, version int32, err error) {
+	paths, index, err := c.getChildren(path, 0)
+	return paths, toVersion(index), err
+}
+
+func (c *Consul) getChildren(path string, index uint64) (paths []string, nindex uint64, err error) {
+	prefix := swapKey(path) + "/"
+	if prefix == "/" {
+		prefix = ""
+	}
+	var keys []string
+	if index == 0 {
+		keys, nindex, err = c.client.Keys(prefix)
+	} else {
+		keys, nindex, err = c.client.BlockingKeys(prefix, index, c.waitTime)
+	}
+	if err == internal.ErrNotFound {
+		return []string{}, nindex, nil
+	}
+	if err != nil {
+		return nil, nindex, err
+	}
+	paths = make([]string, 0, len(keys))
+	for _, k := range keys {
+		name := strings.Trim(strings.TrimPrefix(k, prefix), "/")
+		if name == "" {
+			continue
+		}
+		paths = append(paths, name)
+	}
+	return paths, nindex, nil
+}
@@ -0,0 +1,297 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+//ErrNotFound 键不存在
+var ErrNotFound = errors.New("consul: key not found")
+
+//ErrSessionNotFound 会话不存在或已过期
+var ErrSessionNotFound = errors.New("consul: session not found")
+
+//KVPair consul键值对
+type KVPair struct {
+	Key         string `json:"Key"`
+	Value       []byte `json:"Value"`
+	Session     string `json:"Session,omitempty"`
+	CreateIndex uint64 `json:"CreateIndex"`
+	ModifyIndex uint64 `json:"ModifyIndex"`
+	LockIndex   uint64 `json:"LockIndex"`
+	Flags       uint64 `json:"Flags"`
+}
+
+//TxnOp consul事务中的键值操作
+type TxnOp struct {
+	Verb    string `json:"Verb"`
+	Key     string `json:"Key"`
+	Value   []byte `json:"Value,omitempty"`
+	Index   uint64 `json:"Index,omitempty"`
+	Session string `json:"Session,omitempty"`
+}
+
+//ClientConf consul客户端配置
+type ClientConf struct {
+	Address    []string
+	Token      string
+	Datacenter string
+	Timeout    time.Duration
+}
+
+//Client 基于consul http api的客户端
+type Client struct {
+	conf    *ClientConf
+	client  *http.Client
+	current int32
+}
+
+//NewClient 构建consul客户端
+func NewClient(c *ClientConf) (*Client, error) {
+	if len(c.Address) == 0 {
+		return nil, fmt.Errorf("未指定consul服务器地址")
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = time.Second * 10
+	}
+	return &Client{conf: c, client: &http.Client{}}, nil
+}
+
+//Get 获取键值
+func (c *Client) Get(key string) (*KVPair, uint64, error) {
+	return c.get(key, 0, 0)
+}
+
+//BlockingGet 阻塞查询键值，直到键的索引大于index或超时
+func (c *Client) BlockingGet(key string, index uint64, wait time.Duration) (*KVPair, uint64, error) {
+	return c.get(key, index, wait)
+}
+
+func (c *Client) get(key string, index uint64, wait time.Duration) (*KVPair, uint64, error) {
+	q := url.Values{}
+	setBlocking(q, index, wait)
+	buff, idx, err := c.do(http.MethodGet, "/v1/kv/"+key, q, nil, wait)
+	if err != nil {
+		return nil, idx, err
+	}
+	pairs := make([]*KVPair, 0, 1)
+	if err := json.Unmarshal(buff, &pairs); err != nil {
+		return nil, idx, err
+	}
+	if len(pairs) == 0 {
+		return nil, idx, ErrNotFound
+	}
+	return pairs[0], idx, nil
+}
+
+//Keys 获取指定前缀下的直接子键
+func (c *Client) Keys(prefix string) ([]string, uint64, error) {
+	return c.keys(prefix, 0, 0)
+}
+
+//BlockingKeys 阻塞查询指定前缀下的直接子键
+func (c *Client) BlockingKeys(prefix string, index uint64, wait time.Duration) ([]string, uint64, error) {
+	return c.keys(prefix, index, wait)
+}
+
+func (c *Client) keys(prefix string, index uint64, wait time.Duration) ([]string, uint64, error) {
+	q := url.Values{}
+	q.Set("keys", "")
+	q.Set("separator", "/")
+	setBlocking(q, index, wait)
+	buff, idx, err := c.do(http.MethodGet, "/v1/kv/"+prefix, q, nil, wait)
+	if err != nil {
+		return nil, idx, err
+	}
+	keys := make([]string, 0, 1)
+	if err := json.Unmarshal(buff, &keys); err != nil {
+		return nil, idx, err
+	}
+	return keys, idx, nil
+}
+
+//Put 设置键值
+func (c *Client) Put(key string, value []byte) (bool, error) {
+	return c.put(key, value, url.Values{})
+}
+
+//CAS 根据索引修改键值，index为0时仅在键不存在时创建
+func (c *Client) CAS(key string, value []byte, index uint64) (bool, error) {
+	q := url.Values{}
+	q.Set("cas", strconv.FormatUint(index, 10))
+	return c.put(key, value, q)
+}
+
+//Acquire 使用会话锁定并设置键值
+func (c *Client) Acquire(key string, value []byte, session string) (bool, error) {
+	q := url.Values{}
+	q.Set("acquire", session)
+	return c.put(key, value, q)
+}
+
+func (c *Client) put(key string, value []byte, q url.Values) (bool, error) {
+	buff, _, err := c.do(http.MethodPut, "/v1/kv/"+key, q, value, 0)
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(string(buff)) == "true", nil
+}
+
+//Delete 删除键值
+func (c *Client) Delete(key string, recurse bool) error {
+	q := url.Values{}
+	if recurse {
+		q.Set("recurse", "")
+	}
+	_, _, err := c.do(http.MethodDelete, "/v1/kv/"+key, q, nil, 0)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+//Txn 原子执行多个键值操作，索引检查失败时事务回滚并返回false
+func (c *Client) Txn(ops []*TxnOp) (bool, error) {
+	req := make([]map[string]*TxnOp, 0, len(ops))
+	for _, op := range ops {
+		req = append(req, map[string]*TxnOp{"KV": op})
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return false, err
+	}
+	_, _, err = c.do(http.MethodPut, "/v1/txn", url.Values{}, body, 0)
+	if s, ok := err.(*statusError); ok && s.code == http.StatusConflict {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+//CreateSession 创建会话，会话失效时删除所有关联的键
+func (c *Client) CreateSession(name string, ttl time.Duration) (string, error) {
+	body, _ := json.Marshal(map[string]string{
+		"Name":      name,
+		"TTL":       ttl.String(),
+		"Behavior":  "delete",
+		"LockDelay": "0s",
+	})
+	buff, _, err := c.do(http.MethodPut, "/v1/session/create", url.Values{}, body, 0)
+	if err != nil {
+		return "", err
+	}
+	session := struct {
+		ID string `json:"ID"`
+	}{}
+	if err := json.Unmarshal(buff, &session); err != nil {
+		return "", err
+	}
+	return session.ID, nil
+}
+
+//RenewSession 续约会话
+func (c *Client) RenewSession(id string) error {
+	_, _, err := c.do(http.MethodPut, "/v1/session/renew/"+id, url.Values{}, nil, 0)
+	if errors.Is(err, ErrNotFound) {
+		return ErrSessionNotFound
+	}
+	return err
+}
+
+//DestroySession 销毁会话
+func (c *Client) DestroySession(id string) error {
+	_, _, err := c.do(http.MethodPut, "/v1/session/destroy/"+id, url.Values{}, nil, 0)
+	return err
+}
+
+func setBlocking(q url.Values, index uint64, wait time.Duration) {
+	if index == 0 {
+		return
+	}
+	q.Set("index", strconv.FormatUint(index, 10))
+	if wait > 0 {
+		q.Set("wait", fmt.Sprintf("%dms", wait.Milliseconds()))
+	}
+}
+
+//do 发送请求，当前服务器不可用时依次切换到下一个服务器
+func (c *Client) do(method string, path string, q url.Values, body []byte, wait time.Duration) ([]byte, uint64, error) {
+	if c.conf.Datacenter != "" {
+		q.Set("dc", c.conf.Datacenter)
+	}
+	var lastErr error
+	for i := 0; i < len(c.conf.Address); i++ {
+		n := int(atomic.LoadInt32(&c.current))
+		addr := c.conf.Address[n%len(c.conf.Address)]
+		buff, idx, err := c.request(addr, method, path, q, body, wait)
+		if err == nil || errors.Is(err, ErrNotFound) {
+			return buff, idx, err
+		}
+		if _, ok := err.(*statusError); ok {
+			return nil, idx, err
+		}
+		lastErr = err
+		atomic.CompareAndSwapInt32(&c.current, int32(n), int32((n+1)%len(c.conf.Address)))
+	}
+	return nil, 0, lastErr
+}
+
+func (c *Client) request(addr string, method string, path string, q url.Values, body []byte, wait time.Duration) ([]byte, uint64, error) {
+	if !strings.HasPrefix(addr, "http://") && !strings.HasPrefix(addr, "https://") {
+		addr = "http://" + addr
+	}
+	u := strings.TrimSuffix(addr, "/") + path
+	if len(q) > 0 {
+		u = u + "?" + q.Encode()
+	}
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, u, reader)
+	if err != nil {
+		return nil, 0, err
+	}
+	if c.conf.Token != "" {
+		req.Header.Set("X-Consul-Token", c.conf.Token)
+	}
+
+	//阻塞查询时，consul会在wait基础上增加最多wait/16的随机时长
+	client := *c.client
+	client.Timeout = c.conf.Timeout + wait + wait/16
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	buff, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+	idx, _ := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, idx, ErrNotFound
+	case resp.StatusCode != http.StatusOK:
+		return nil, idx, &statusError{code: resp.StatusCode, msg: strings.TrimSpace(string(buff))}
+	}
+	return buff, idx, nil
+}
+
+type statusError struct {
+	code int
+	msg  string
+}
+
+func (s *statusError) Error() string {
+	return fmt.Sprintf("consul: 请求失败(%d):%s", s.code, s.msg)
+}
@@ -0,0 +1,123 @@
+package consul
+
+import (
+	"fmt"
+
+	r "github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/hydra/registry/registry/consul/internal"
+)
+
+//UpdateIfVersion 节点版本一致时更新节点值
+func (c *Consul) UpdateIfVersion(path string, data string, version int32) (err error) {
+	return c.Txn(r.UpdateOp(path, data, version))
+}
+
+//Txn 通过consul事务接口原子执行多个操作，使用读取时的ModifyIndex检查节点是否被修改
+func (c *Consul) Txn(ops ...r.Op) (err error) {
+	states := make(map[string]*nodeState)
+	tmps := make(map[string]string)
+	txn := make([]*internal.TxnOp, 0, len(ops))
+	for _, op := range ops {
+		key := swapKey(op.Path)
+		s, ok := states[key]
+		if !ok {
+			kv, _, err := c.client.Get(key)
+			if err != nil && err != internal.ErrNotFound {
+				return fmt.Errorf("检查节点出错:%w", err)
+			}
+			if kv == nil {
+				kv, err = c.getDir(op.Path, key)
+				if err != nil {
+					return err
+				}
+			}
+			s = &nodeState{kv: kv, exists: kv != nil}
+			states[key] = s
+		}
+		var version int32
+		if s.kv != nil {
+			version = toVersion(s.kv.ModifyIndex)
+		}
+		if err := op.Validate(s.exists, version); err != nil {
+			return err
+		}
+
+		//已在事务中修改过的节点不再检查索引，目录节点仅在修改时检查不存在对应的键
+		kv := s.kv
+		checked := kv != nil && kv.ModifyIndex != 0
+		switch op.Type {
+		case r.OpCreate:
+			txn = append(txn, &internal.TxnOp{Verb: "cas", Key: key, Value: []byte(op.Data)})
+		case r.OpUpdate:
+			_, tmp := c.tmpNodes.Get(key)
+			switch {
+			case tmp && kv != nil && kv.Session != "":
+				session, err := c.getSession()
+				if err != nil {
+					return err
+				}
+				if checked {
+					txn = append(txn, &internal.TxnOp{Verb: "check-index", Key: key, Index: kv.ModifyIndex})
+				}
+				txn = append(txn, &internal.TxnOp{Verb: "lock", Key: key, Value: []byte(op.Data), Session: session})
+				tmps[key] = op.Data
+			case kv != nil:
+				txn = append(txn, &internal.TxnOp{Verb: "cas", Key: key, Value: []byte(op.Data), Index: kv.ModifyIndex})
+			default:
+				txn = append(txn, &internal.TxnOp{Verb: "set", Key: key, Value: []byte(op.Data)})
+			}
+		case r.OpDelete:
+			if checked {
+				txn = append(txn, &internal.TxnOp{Verb: "delete-cas", Key: key, Index: kv.ModifyIndex})
+			} else {
+				txn = append(txn, &internal.TxnOp{Verb: "delete", Key: key})
+			}
+			delete(tmps, key)
+		case r.OpCheck:
+			if checked {
+				txn = append(txn, &internal.TxnOp{Verb: "check-index", Key: key, Index: kv.ModifyIndex})
+			}
+			continue
+		default:
+			return fmt.Errorf("不支持的操作类型%d(%s)", op.Type, op.Path)
+		}
+		s.kv, s.exists = nil, op.Type != r.OpDelete
+	}
+	if len(txn) == 0 {
+		return nil
+	}
+	ok, err := c.client.Txn(txn)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return r.NewConflictError(ops[0].Path, "节点在事务执行期间被修改")
+	}
+	for key, data := range tmps {
+		c.tmpNodes.Set(key, data)
+	}
+	for _, op := range ops {
+		if op.Type == r.OpDelete {
+			c.tmpNodes.Remove(swapKey(op.Path))
+		}
+	}
+	return nil
+}
+
+//nodeState 事务中节点的状态
+type nodeState struct {
+	kv     *internal.KVPair
+	exists bool
+}
+
+//getDir 获取目录节点，consul中不存在目录对应的键，有子节点时与GetValue一致视为版本为0的节点
+func (c *Consul) getDir(path string, key string) (*internal.KVPair, error) {
+	children, _, err := c.GetChildren(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(children) == 0 {
+		return nil, nil
+	}
+	return &internal.KVPair{Key: key}, nil
+}
@@ -0,0 +1,38 @@
+package consul
+
+import (
+	"fmt"
+
+	"github.com/micro-plat/hydra/registry/registry/consul/internal"
+)
+
+//Update 更新节点值
+func (c *Consul) Update(path string, data string) (err error) {
+	key := swapKey(path)
+	kv, _, err := c.client.Get(key)
+	if err == internal.ErrNotFound {
+		return fmt.Errorf("节点不存在%s", path)
+	}
+	if err != nil {
+		return fmt.Errorf("检查节点出错:%w", err)
+	}
+
+	//临时节点更新时保持会话锁定
+	if kv.Session != "" {
+		if _, ok := c.tmpNodes.Get(key); ok {
+			if err = c.acquire(key, data); err != nil {
+				return err
+			}
+			c.tmpNodes.Set(key, data)
+			return nil
+		}
+	}
+	ok, err := c.client.CAS(key, []byte(data), kv.ModifyIndex)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("节点%s已被修改", path)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package consul
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/micro-plat/hydra/registry/registry/consul/internal"
+	"github.com/micro-plat/lib4go/registry"
+)
+
+//errClosed 注册中心已关闭
+var errClosed = errors.New("consul: registry is closed")
+
+//WatchValue 监控值变化，值发生变化或节点被删除时通知一次
+func (c *Consul) WatchValue(path string) (data chan registry.ValueWatcher, err error) {
+	key := swapKey(path)
+	kv, index, err := c.client.Get(key)
+	if err != nil && err != internal.ErrNotFound {
+		return nil, err
+	}
+	if kv == nil {
+		return nil, fmt.Errorf("节点%s不存在", path)
+	}
+	modifyIndex := kv.ModifyIndex
+	watcher := make(chan registry.ValueWatcher, 1)
+
+	//通过阻塞查询等待值变化
+	go func() {
+		for {
+			select {
+			case <-c.closeCh:
+				watcher <- &valueEntity{path: path, Err: errClosed}
+				return
+			default:
+			}
+			nkv, nindex, err := c.client.BlockingGet(key, index, c.waitTime)
+			if err == internal.ErrNotFound {
+				watcher <- &valueEntity{path: path, Err: fmt.Errorf("节点%s已删除", path)}
+				return
+			}
+			if err != nil {
+				watcher <- &valueEntity{path: path, Err: err}
+				return
+			}
+			if nindex < index { //索引重置，需重新开始查询
+				nindex = 0
+			}
+			index = nindex
+			if nkv.ModifyIndex == modifyIndex {
+				continue
+			}
+			watcher <- &valueEntity{path: path, version: toVersion(nkv.ModifyIndex), Value: nkv.Value}
+			return
+		}
+	}()
+	return watcher, nil
+}
+
+//WatchChildren 监控子节点变化，子节点增加或减少时通知一次
+func (c *Consul) WatchChildren(path string) (data chan registry.ChildrenWatcher, err error) {
+	children, index, err := c.getChildren(path, 0)
+	if err != nil {
+		return nil, err
+	}
+	current := joinChildren(children)
+	watcher := make(chan registry.ChildrenWatcher, 1)
+
+	//通过阻塞查询等待子节点变化
+	go func() {
+		for {
+			select {
+			case <-c.closeCh:
+				watcher <- &childrenEntity{path: path, Err: errClosed}
+				return
+			default:
+			}
+			nchildren, nindex, err := c.getChildren(path, index)
+			if err != nil {
+				watcher <- &childrenEntity{path: path, Err: err}
+				return
+			}
+			if nindex < index {
+				nindex = 0
+			}
+			index = nindex
+			if joinChildren(nchildren) == current {
+				continue
+			}
+			watcher <- &childrenEntity{path: path, version: toVersion(index), children: nchildren}
+			return
+		}
+	}()
+	return watcher, nil
+}
+
+func joinChildren(children []string) string {
+	list := make([]string, len(children))
+	copy(list, children)
+	sort.Strings(list)
+	return strings.Join(list, ",")
+}
@@ -6,6 +6,7 @@ type sqltexture struct {
 	exists            string
 	getValue          string
 	delete            string
+	deleteByVersion   string
 	getChildren       string
 	clear             string
 	update            string
@@ -83,6 +84,14 @@ func init() {
 	where t.path = @path
 	and t.is_delete = 1
 	`
+	mysqltexture.deleteByVersion = `
+	update hydra_registry_info t
+	set t.update_time = now(),
+	t.is_delete = 0
+	where t.path = @path
+	and t.data_version = @data_version
+	and t.is_delete = 1
+	`
 	mysqltexture.clear = `
 	delete from hydra_registry_info
 	where path = @path
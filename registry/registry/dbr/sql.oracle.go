@@ -113,6 +113,14 @@ func init() {
 	where t.path = @path
 	and t.is_delete = 1
 	`
+	oracletexture.deleteByVersion = `
+	update hydra_registry_info t
+	set t.update_time = sysdate,
+	t.is_delete = 0
+	where t.path = @path
+	and t.data_version = @data_version
+	and t.is_delete = 1
+	`
 	oracletexture.clear = `
 	delete from hydra_registry_info
 	where path = @path
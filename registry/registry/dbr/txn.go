@@ -0,0 +1,90 @@
+package dbr
+
+import (
+	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/lib4go/db"
+	"github.com/micro-plat/lib4go/errs"
+)
+
+//UpdateIfVersion 节点版本(data_version)一致时更新节点值
+func (r *DBR) UpdateIfVersion(path string, data string, version int32) (err error) {
+	return r.Txn(registry.UpdateOp(path, data, version))
+}
+
+//Txn 在数据库事务中执行所有操作，通过data_version字段检查节点版本
+func (r *DBR) Txn(ops ...registry.Op) (err error) {
+	trans, err := r.db.Begin()
+	if err != nil {
+		return errs.New("开启事务失败:%w", err)
+	}
+	type change struct {
+		op      registry.Op
+		version int32
+	}
+	changes := make([]change, 0, len(ops))
+	for _, op := range ops {
+		version, err := r.txnExecute(trans, op)
+		if err != nil {
+			trans.Rollback()
+			return err
+		}
+		changes = append(changes, change{op: op, version: version})
+	}
+	if err = trans.Commit(); err != nil {
+		return errs.New("提交事务失败:%w", err)
+	}
+
+	//通知变更
+	for _, c := range changes {
+		switch c.op.Type {
+		case registry.OpCreate:
+			r.notifyParentChange(c.op.Path, 1)
+		case registry.OpUpdate:
+			r.notifyValueChange(c.op.Path, c.op.Data, c.version)
+		case registry.OpDelete:
+			r.notifyParentChange(c.op.Path, 0)
+		}
+	}
+	return nil
+}
+
+//txnExecute 执行单个操作，返回操作后的节点版本
+func (r *DBR) txnExecute(trans db.IDBTrans, op registry.Op) (int32, error) {
+	datas, err := trans.Query(r.sqltexture.getValue, newInput(op.Path))
+	if err != nil {
+		return 0, err
+	}
+	var current int32
+	if !datas.IsEmpty() {
+		current = datas.Get(0).GetInt32(FieldDataVersion)
+	}
+	if err := op.Validate(!datas.IsEmpty(), current); err != nil {
+		return 0, err
+	}
+
+	var count int64
+	switch op.Type {
+	case registry.OpCreate:
+		if _, err = trans.Execute(r.sqltexture.clear, newInput(op.Path)); err != nil {
+			return 0, err
+		}
+		count, err = trans.Execute(r.sqltexture.createNode, newInputByInsert(op.Path, op.Data, false))
+		current = 1
+	case registry.OpUpdate:
+		count, err = trans.Execute(r.sqltexture.update, newInputByUpdate(op.Path, op.Data, current))
+		current++
+	case registry.OpDelete:
+		count, err = trans.Execute(r.sqltexture.deleteByVersion, newInputByUpdate(op.Path, "", current))
+	case registry.OpCheck:
+		return current, nil
+	default:
+		return 0, errs.New("不支持的操作类型%d(%s)", op.Type, op.Path)
+	}
+	if err != nil {
+		return 0, errs.New("执行事务操作失败(%s):%w", op.Path, err)
+	}
+	if count == 0 {
+		return 0, registry.NewConflictError(op.Path, "节点在事务执行期间被修改")
+	}
+	return current, nil
+}
@@ -0,0 +1,172 @@
+package dbr
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	r "github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/lib4go/assert"
+	dbs "github.com/micro-plat/lib4go/db"
+	"github.com/micro-plat/lib4go/types"
+)
+
+type fakeRow struct {
+	value   string
+	version int32
+}
+
+//fakeTable 按sql语句模拟注册中心表的查询与修改
+type fakeTable map[string]fakeRow
+
+func (t fakeTable) query(sql string, input map[string]interface{}) (dbs.QueryRows, error) {
+	rows := dbs.NewQueryRows()
+	path := types.GetString(input[FieldPath])
+	if row, ok := t[path]; ok && sql == mysqltexture.getValue {
+		rows = append(rows, dbs.QueryRow{FieldPath: path, FieldValue: row.value, FieldDataVersion: row.version})
+	}
+	return rows, nil
+}
+
+func (t fakeTable) execute(sql string, input map[string]interface{}) (int64, error) {
+	path := types.GetString(input[FieldPath])
+	row, ok := t[path]
+	switch sql {
+	case mysqltexture.createNode:
+		if ok {
+			return 0, nil
+		}
+		t[path] = fakeRow{value: types.GetString(input[FieldValue]), version: 1}
+	case mysqltexture.update:
+		if !ok || row.version != types.GetInt32(input[FieldDataVersion]) {
+			return 0, nil
+		}
+		t[path] = fakeRow{value: types.GetString(input[FieldValue]), version: row.version + 1}
+	case mysqltexture.deleteByVersion:
+		if !ok || row.version != types.GetInt32(input[FieldDataVersion]) {
+			return 0, nil
+		}
+		delete(t, path)
+	default:
+		return 0, nil
+	}
+	return 1, nil
+}
+
+type fakeDB struct {
+	lock          sync.Mutex
+	rows          fakeTable
+	beforeExecute func(trans fakeTable)
+}
+
+func (f *fakeDB) Query(sql string, input map[string]interface{}) (dbs.QueryRows, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.rows.query(sql, input)
+}
+func (f *fakeDB) Execute(sql string, input map[string]interface{}) (int64, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.rows.execute(sql, input)
+}
+func (f *fakeDB) Scalar(sql string, input map[string]interface{}) (interface{}, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	count := 0
+	for path := range f.rows {
+		if sql == mysqltexture.exists && strings.HasPrefix(path, types.GetString(input[FieldPath])) {
+			count++
+		}
+	}
+	return count, nil
+}
+func (f *fakeDB) Executes(string, map[string]interface{}) (int64, int64, error) {
+	return 0, 0, nil
+}
+func (f *fakeDB) ExecuteBatch([]string, map[string]interface{}) (dbs.QueryRows, error) {
+	return nil, nil
+}
+func (f *fakeDB) ExecuteSP(string, map[string]interface{}, ...interface{}) (int64, error) {
+	return 0, nil
+}
+func (f *fakeDB) Close() {}
+
+//Begin 事务在数据副本上执行，提交时替换
+func (f *fakeDB) Begin() (dbs.IDBTrans, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	rows := make(fakeTable, len(f.rows))
+	for k, v := range f.rows {
+		rows[k] = v
+	}
+	return &fakeTrans{fakeDB: f, trans: rows}, nil
+}
+
+type fakeTrans struct {
+	*fakeDB
+	trans fakeTable
+}
+
+func (t *fakeTrans) Query(sql string, input map[string]interface{}) (dbs.QueryRows, error) {
+	return t.trans.query(sql, input)
+}
+func (t *fakeTrans) Execute(sql string, input map[string]interface{}) (int64, error) {
+	if h := t.beforeExecute; h != nil && sql != mysqltexture.clear {
+		t.beforeExecute = nil
+		h(t.trans)
+	}
+	return t.trans.execute(sql, input)
+}
+func (t *fakeTrans) Rollback() error { return nil }
+func (t *fakeTrans) Commit() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.rows = t.trans
+	return nil
+}
+
+func newDBRForTest() (*DBR, *fakeDB) {
+	f := &fakeDB{rows: make(fakeTable)}
+	return &DBR{
+		db:               f,
+		sqltexture:       &mysqltexture,
+		valueWatchers:    newValueWatchers(f, &mysqltexture),
+		childrenWatchers: newChildrenWatchers(f, &mysqltexture),
+	}, f
+}
+
+func TestDBR_Txn(t *testing.T) {
+	d, f := newDBRForTest()
+
+	err := d.Txn(r.CreateOp("/hydra/txn/a", "1"), r.CreateOp("/hydra/txn/b", "2"))
+	assert.Equal(t, nil, err, "事务创建节点")
+	err = d.Txn(r.CreateOp("/hydra/txn/a", "1"))
+	assert.Equal(t, true, r.IsConflict(err), "节点已存在时创建失败")
+
+	_, version, _ := d.GetValue("/hydra/txn/a")
+	assert.Equal(t, nil, d.UpdateIfVersion("/hydra/txn/a", "3", version), "版本一致时更新")
+	err = d.UpdateIfVersion("/hydra/txn/a", "4", version)
+	assert.Equal(t, true, r.IsConflict(err), "版本不一致时更新失败")
+	data, _, _ := d.GetValue("/hydra/txn/a")
+	assert.Equal(t, "3", string(data), "版本冲突时节点值不变")
+
+	_, va, _ := d.GetValue("/hydra/txn/a")
+	_, vb, _ := d.GetValue("/hydra/txn/b")
+	err = d.Txn(r.DeleteOp("/hydra/txn/a", va), r.UpdateOp("/hydra/txn/b", "6", vb+1))
+	assert.Equal(t, true, r.IsConflict(err), "任一节点版本不一致时事务失败")
+	ok, _ := d.Exists("/hydra/txn/a")
+	assert.Equal(t, true, ok, "事务失败时所有操作均不生效")
+
+	//读取版本后、修改前节点被其它事务修改
+	f.beforeExecute = func(trans fakeTable) {
+		row := fakeRow{value: "5", version: vb + 1}
+		trans["/hydra/txn/b"] = row
+		f.rows["/hydra/txn/b"] = row
+	}
+	err = d.Txn(r.UpdateOp("/hydra/txn/b", "6", vb), r.DeleteOp("/hydra/txn/a", va))
+	assert.Equal(t, true, r.IsConflict(err), "执行期间节点被修改时事务失败")
+	data, _, _ = d.GetValue("/hydra/txn/b")
+	assert.Equal(t, "5", string(data), "保留其它事务修改的值")
+	ok, _ = d.Exists("/hydra/txn/a")
+	assert.Equal(t, true, ok, "事务失败时节点未删除")
+}
@@ -0,0 +1,38 @@
+package etcd
+
+import (
+	"fmt"
+
+	r "github.com/micro-plat/hydra/registry"
+)
+
+//CreatePersistentNode 创建永久节点
+func (e *Etcd) CreatePersistentNode(path string, data string) (err error) {
+	if _, err = e.client.Put(r.Join(path), []byte(data), 0); err != nil {
+		return fmt.Errorf("创建节点%s失败:%w", path, err)
+	}
+	return nil
+}
+
+//CreateTempNode 创建临时节点，节点与租约绑定，租约过期时自动删除
+func (e *Etcd) CreateTempNode(path string, data string) (err error) {
+	key := r.Join(path)
+	if _, err = e.putWithLease(key, data); err != nil {
+		return fmt.Errorf("创建临时节点%s失败:%w", path, err)
+	}
+	e.tmpNodes.Set(key, data)
+	return nil
+}
+
+//CreateSeqNode 创建序列节点，使用序列键的修订号作为节点序号
+func (e *Etcd) CreateSeqNode(path string, data string) (rpath string, err error) {
+	rev, err := e.client.Put(e.seqPath, []byte(path), 0)
+	if err != nil {
+		return "", fmt.Errorf("获取序列号失败:%w", err)
+	}
+	rpath = r.Join(fmt.Sprintf("%s%010d", path, rev))
+	if err = e.CreateTempNode(rpath, data); err != nil {
+		return "", err
+	}
+	return rpath, nil
+}
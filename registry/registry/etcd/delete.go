@@ -0,0 +1,17 @@
+package etcd
+
+import (
+	"fmt"
+
+	r "github.com/micro-plat/hydra/registry"
+)
+
+//Delete 删除节点
+func (e *Etcd) Delete(path string) error {
+	key := r.Join(path)
+	if _, err := e.client.Delete(key); err != nil {
+		return fmt.Errorf("%v(%s)", err, path)
+	}
+	e.tmpNodes.Remove(key)
+	return nil
+}
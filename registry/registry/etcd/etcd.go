@@ -0,0 +1,166 @@
+package etcd
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/micro-plat/hydra/global"
+	r "github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/hydra/registry/registry/etcd/internal"
+	"github.com/micro-plat/lib4go/concurrent/cmap"
+	"github.com/micro-plat/lib4go/logger"
+	"github.com/micro-plat/lib4go/types"
+)
+
+//Etcd 基于etcd v3的注册中心
+type Etcd struct {
+	ctx         context.Context
+	cancel      context.CancelFunc
+	once        sync.Once
+	llock       sync.Mutex
+	seqPath     string
+	leaseTTL    time.Duration
+	checkTicker time.Duration
+	lease       internal.Int64
+	tmpNodes    cmap.ConcurrentMap
+	client      *internal.Client
+	log         logger.ILogging
+}
+
+//NewEtcd 构建etcd注册中心
+func NewEtcd(c *internal.ClientConf, log logger.ILogging) (*Etcd, error) {
+	client, err := internal.NewClient(c)
+	if err != nil {
+		return nil, err
+	}
+	if log == nil {
+		log = global.Def.Log()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	etcd := &Etcd{
+		ctx:         ctx,
+		cancel:      cancel,
+		client:      client,
+		log:         log,
+		leaseTTL:    time.Second * 15,
+		checkTicker: time.Second * 5,
+		tmpNodes:    cmap.New(4),
+		seqPath:     r.Join("hydra", "seq"),
+	}
+	go etcd.keepalive()
+	return etcd, nil
+}
+
+//Close 关闭当前服务
+func (e *Etcd) Close() error {
+	e.once.Do(func() {
+		e.cancel()
+		e.llock.Lock()
+		defer e.llock.Unlock()
+		if e.lease != 0 {
+			e.client.Revoke(e.lease)
+			e.lease = 0
+		}
+		e.tmpNodes.Clear()
+	})
+	return nil
+}
+
+//getLease 获取临时节点使用的租约，不存在时申请
+func (e *Etcd) getLease() (internal.Int64, error) {
+	e.llock.Lock()
+	defer e.llock.Unlock()
+	if e.lease != 0 {
+		return e.lease, nil
+	}
+	id, err := e.client.Grant(e.leaseTTL)
+	if err != nil {
+		return 0, fmt.Errorf("申请etcd租约失败:%w", err)
+	}
+	e.lease = id
+	return id, nil
+}
+
+//keepalive 定时续约，租约过期后重新申请租约并恢复临时节点
+func (e *Etcd) keepalive() {
+	tk := time.NewTicker(e.checkTicker)
+	defer tk.Stop()
+	for {
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-tk.C:
+			e.llock.Lock()
+			lease := e.lease
+			e.llock.Unlock()
+			if lease == 0 {
+				continue
+			}
+			err := e.client.KeepAliveOnce(lease)
+			if err == nil {
+				continue
+			}
+			if err != internal.ErrLeaseNotFound {
+				e.log.Warnf("etcd租约续约失败:%v", err)
+				continue
+			}
+			e.llock.Lock()
+			if e.lease == lease {
+				e.lease = 0
+			}
+			e.llock.Unlock()
+			e.recoverTmpNodes()
+		}
+	}
+}
+
+//recoverTmpNodes 重新创建租约过期后被删除的临时节点
+func (e *Etcd) recoverTmpNodes() {
+	for k, v := range e.tmpNodes.Items() {
+		if _, err := e.putWithLease(k, v.(string)); err != nil {
+			e.log.Errorf("恢复临时节点%s失败:%v", k, err)
+		}
+	}
+}
+
+func (e *Etcd) putWithLease(key string, data string) (internal.Int64, error) {
+	lease, err := e.getLease()
+	if err != nil {
+		return 0, err
+	}
+	return e.client.Put(key, []byte(data), lease)
+}
+
+//toVersion 将etcd修订号转换为节点版本号
+func toVersion(rev internal.Int64) int32 {
+	return int32(rev & 0x7fffffff)
+}
+
+//etcdFactory 基于etcd的注册中心
+type etcdFactory struct {
+	opts *r.Options
+}
+
+//Create 根据配置生成etcd注册中心
+func (z *etcdFactory) Create(opts ...r.Option) (r.IRegistry, error) {
+	for i := range opts {
+		opts[i](z.opts)
+	}
+	conf := &internal.ClientConf{
+		Address: z.opts.Addrs,
+		Timeout: time.Duration(types.GetInt(z.opts.Metadata["timeout"], 10)) * time.Second,
+	}
+	if z.opts.Auth != nil {
+		conf.Username = z.opts.Auth.Username
+		conf.Password = z.opts.Auth.Password
+	}
+	return NewEtcd(conf, z.opts.Logger)
+}
+
+func init() {
+	r.Register(r.Etcd, &etcdFactory{
+		opts: &r.Options{},
+	})
+}
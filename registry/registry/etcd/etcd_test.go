@@ -0,0 +1,365 @@
+package etcd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	r "github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/hydra/registry/registry/etcd/internal"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+//fakeEtcd 模拟etcd v3 grpc-gateway接口
+type fakeEtcd struct {
+	mu      sync.Mutex
+	rev     internal.Int64
+	lease   internal.Int64
+	kvs     map[string]*internal.KeyValue
+	leases  map[internal.Int64]bool
+	changed chan struct{}
+	events  []*internal.Event
+}
+
+func newFakeEtcd() *fakeEtcd {
+	return &fakeEtcd{
+		rev:     1,
+		kvs:     make(map[string]*internal.KeyValue),
+		leases:  make(map[internal.Int64]bool),
+		changed: make(chan struct{}),
+	}
+}
+
+type fakeRequest struct {
+	Key      []byte              `json:"key"`
+	RangeEnd []byte              `json:"range_end"`
+	Value    []byte              `json:"value"`
+	Lease    internal.Int64      `json:"lease"`
+	ID       internal.Int64      `json:"ID"`
+	Create   *fakeRequest        `json:"create_request"`
+	Start    internal.Int64      `json:"start_revision"`
+	Compare  []*internal.Compare `json:"compare"`
+	Success  []*fakeTxnOp        `json:"success"`
+}
+
+type fakeTxnOp struct {
+	Put    *fakeRequest `json:"request_put"`
+	Delete *fakeRequest `json:"request_delete_range"`
+}
+
+func (f *fakeEtcd) emit(ev *internal.Event) {
+	f.events = append(f.events, ev)
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+func (f *fakeEtcd) put(key string, value []byte, lease internal.Int64) {
+	f.rev++
+	kv := &internal.KeyValue{Key: []byte(key), Value: value, CreateRevision: f.rev, ModRevision: f.rev, Version: 1, Lease: lease}
+	if old, ok := f.kvs[key]; ok {
+		kv.CreateRevision = old.CreateRevision
+		kv.Version = old.Version + 1
+	}
+	f.kvs[key] = kv
+	f.emit(&internal.Event{KV: kv})
+}
+
+func (f *fakeEtcd) delete(key string) int {
+	if _, ok := f.kvs[key]; !ok {
+		return 0
+	}
+	f.rev++
+	delete(f.kvs, key)
+	f.emit(&internal.Event{Type: "DELETE", KV: &internal.KeyValue{Key: []byte(key), ModRevision: f.rev}})
+	return 1
+}
+
+//expireLease 模拟租约过期
+func (f *fakeEtcd) expireLease(id internal.Int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.leases, id)
+	for k, v := range f.kvs {
+		if v.Lease == id {
+			f.delete(k)
+		}
+	}
+}
+
+func inRange(key string, req *fakeRequest) bool {
+	if len(req.RangeEnd) == 0 {
+		return key == string(req.Key)
+	}
+	return key >= string(req.Key) && key < string(req.RangeEnd)
+}
+
+func (f *fakeEtcd) ServeHTTP(w http.ResponseWriter, hreq *http.Request) {
+	req := &fakeRequest{}
+	json.NewDecoder(hreq.Body).Decode(req)
+	if hreq.URL.Path == "/v3/watch" {
+		f.watch(w, req.Create, hreq.Context().Done())
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var resp interface{}
+	switch hreq.URL.Path {
+	case "/v3/kv/range":
+		kvs := make([]*internal.KeyValue, 0, 1)
+		for k, v := range f.kvs {
+			if inRange(k, req) {
+				kvs = append(kvs, v)
+			}
+		}
+		sort.Slice(kvs, func(i, j int) bool { return string(kvs[i].Key) < string(kvs[j].Key) })
+		resp = map[string]interface{}{"header": internal.Header{Revision: f.rev}, "kvs": kvs}
+	case "/v3/kv/put":
+		if req.Lease != 0 && !f.leases[req.Lease] {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		f.put(string(req.Key), req.Value, req.Lease)
+		resp = map[string]interface{}{"header": internal.Header{Revision: f.rev}}
+	case "/v3/kv/deleterange":
+		resp = map[string]interface{}{"deleted": f.delete(string(req.Key))}
+	case "/v3/kv/txn":
+		succeeded := true
+		for _, c := range req.Compare {
+			kv := f.kvs[string(c.Key)]
+			var v internal.Int64
+			switch {
+			case kv != nil && c.Target == "CREATE":
+				v = kv.CreateRevision
+			case kv != nil && c.Target == "MOD":
+				v = kv.ModRevision
+			}
+			want := c.CreateRevision
+			if c.Target == "MOD" {
+				want = c.ModRevision
+			}
+			if (c.Result == "EQUAL") != (v == want) {
+				succeeded = false
+			}
+		}
+		if succeeded {
+			for _, op := range req.Success {
+				if op.Put != nil {
+					f.put(string(op.Put.Key), op.Put.Value, op.Put.Lease)
+				} else {
+					f.delete(string(op.Delete.Key))
+				}
+			}
+		}
+		resp = map[string]interface{}{"succeeded": succeeded}
+	case "/v3/lease/grant":
+		f.lease++
+		f.leases[f.lease] = true
+		resp = map[string]interface{}{"ID": f.lease}
+	case "/v3/lease/keepalive":
+		ttl := 0
+		if f.leases[req.ID] {
+			ttl = 15
+		}
+		resp = map[string]interface{}{"result": map[string]interface{}{"ID": req.ID, "TTL": ttl}}
+	case "/v3/lease/revoke":
+		f.mu.Unlock()
+		f.expireLease(req.ID)
+		f.mu.Lock()
+		resp = map[string]interface{}{}
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (f *fakeEtcd) watch(w http.ResponseWriter, req *fakeRequest, done <-chan struct{}) {
+	enc := json.NewEncoder(w)
+	enc.Encode(map[string]interface{}{"result": map[string]interface{}{"created": true}})
+	w.(http.Flusher).Flush()
+	for {
+		f.mu.Lock()
+		events := make([]*internal.Event, 0, 1)
+		for _, ev := range f.events {
+			if ev.KV.ModRevision >= req.Start && inRange(string(ev.KV.Key), req) {
+				events = append(events, ev)
+			}
+		}
+		ch := f.changed
+		f.mu.Unlock()
+		if len(events) > 0 {
+			req.Start = events[len(events)-1].KV.ModRevision + 1
+			enc.Encode(map[string]interface{}{"result": &internal.WatchResponse{Events: events}})
+			w.(http.Flusher).Flush()
+		}
+		select {
+		case <-ch:
+		case <-done:
+			return
+		}
+	}
+}
+
+func newEtcdForTest(t *testing.T) (*Etcd, *fakeEtcd, func()) {
+	fake := newFakeEtcd()
+	srv := httptest.NewServer(fake)
+	e, err := NewEtcd(&internal.ClientConf{Address: []string{srv.URL}, Timeout: time.Second}, nil)
+	assert.Equal(t, nil, err, "构建etcd注册中心失败")
+	return e, fake, func() {
+		e.Close()
+		srv.CloseClientConnections()
+		srv.Close()
+	}
+}
+
+func TestEtcd_PersistentNode(t *testing.T) {
+	e, _, closer := newEtcdForTest(t)
+	defer closer()
+
+	err := e.CreatePersistentNode("/hydra/apiserver/conf", `{"address":":8080"}`)
+	assert.Equal(t, nil, err, "创建永久节点失败")
+
+	data, version, err := e.GetValue("/hydra/apiserver/conf")
+	assert.Equal(t, nil, err, "获取节点值失败")
+	assert.Equal(t, `{"address":":8080"}`, string(data), "节点值不一致")
+	assert.Equal(t, true, version > 0, "版本号错误")
+
+	data, _, err = e.GetValue("/hydra/apiserver")
+	assert.Equal(t, nil, err, "获取目录节点失败")
+	assert.Equal(t, 0, len(data), "目录节点值应为空")
+
+	err = e.Update("/hydra/apiserver/conf", `{"address":":9090"}`)
+	assert.Equal(t, nil, err, "更新节点失败")
+	data, nversion, _ := e.GetValue("/hydra/apiserver/conf")
+	assert.Equal(t, `{"address":":9090"}`, string(data), "更新后的节点值不一致")
+	assert.Equal(t, true, nversion > version, "更新后版本号应增加")
+
+	err = e.Update("/hydra/apiserver/none", "{}")
+	assert.Equal(t, true, err != nil, "不存在的节点不能更新")
+
+	children, _, err := e.GetChildren("/hydra")
+	assert.Equal(t, nil, err, "获取子节点失败")
+	assert.Equal(t, []string{"apiserver"}, children, "子节点不一致")
+
+	err = e.Delete("/hydra/apiserver/conf")
+	assert.Equal(t, nil, err, "删除节点失败")
+	ok, _ := e.Exists("/hydra/apiserver")
+	assert.Equal(t, false, ok, "删除后节点不应存在")
+}
+
+func TestEtcd_TempNode(t *testing.T) {
+	e, fake, closer := newEtcdForTest(t)
+	defer closer()
+	e.checkTicker = time.Millisecond * 50
+	go e.keepalive()
+
+	err := e.CreateTempNode("/hydra/servers/192.168.0.1", "{}")
+	assert.Equal(t, nil, err, "创建临时节点失败")
+	assert.Equal(t, e.lease, fake.kvs["/hydra/servers/192.168.0.1"].Lease, "临时节点应与租约绑定")
+
+	//更新临时节点保持租约
+	e.Update("/hydra/servers/192.168.0.1", `{"v":1}`)
+	assert.Equal(t, e.lease, fake.kvs["/hydra/servers/192.168.0.1"].Lease, "更新后临时节点应保持租约")
+
+	//租约过期后自动恢复临时节点
+	fake.expireLease(e.lease)
+	ok, _ := e.Exists("/hydra/servers/192.168.0.1")
+	assert.Equal(t, false, ok, "租约过期后临时节点应被删除")
+	time.Sleep(time.Millisecond * 300)
+	data, _, err := e.GetValue("/hydra/servers/192.168.0.1")
+	assert.Equal(t, nil, err, "临时节点应被恢复")
+	assert.Equal(t, `{"v":1}`, string(data), "恢复的临时节点值不一致")
+
+	e.Close()
+	ok, _ = e.Exists("/hydra/servers/192.168.0.1")
+	assert.Equal(t, false, ok, "关闭后临时节点应被删除")
+}
+
+func TestEtcd_SeqNode(t *testing.T) {
+	e, _, closer := newEtcdForTest(t)
+	defer closer()
+
+	p1, err := e.CreateSeqNode("/dlock/hydra/test/dlock_", "{}")
+	assert.Equal(t, nil, err, "创建序列节点失败")
+	p2, err := e.CreateSeqNode("/dlock/hydra/test/dlock_", "{}")
+	assert.Equal(t, nil, err, "创建序列节点失败")
+	assert.Equal(t, true, p1 < p2, "序列节点应递增")
+
+	children, _, err := e.GetChildren("/dlock/hydra/test")
+	assert.Equal(t, nil, err, "获取子节点失败")
+	assert.Equal(t, 2, len(children), "子节点数量不一致")
+	assert.Equal(t, true, bytes.HasSuffix([]byte(p2), []byte(children[1])), "子节点名称不一致")
+}
+
+func TestEtcd_WatchValue(t *testing.T) {
+	e, _, closer := newEtcdForTest(t)
+	defer closer()
+
+	e.CreatePersistentNode("/hydra/watch/value", "1")
+	ch, err := e.WatchValue("/hydra/watch/value")
+	assert.Equal(t, nil, err, "监控节点失败")
+
+	e.CreatePersistentNode("/hydra/watch/other", "1")
+	e.Update("/hydra/watch/value", "2")
+	select {
+	case w := <-ch:
+		assert.Equal(t, nil, w.GetError(), "监控返回错误")
+		v, _ := w.GetValue()
+		assert.Equal(t, "2", string(v), "监控值不一致")
+	case <-time.After(time.Second * 3):
+		t.Fatal("未收到值变化通知")
+	}
+}
+
+func TestEtcd_WatchChildren(t *testing.T) {
+	e, _, closer := newEtcdForTest(t)
+	defer closer()
+
+	e.CreatePersistentNode("/hydra/watch/children/a", "1")
+	ch, err := e.WatchChildren("/hydra/watch/children")
+	assert.Equal(t, nil, err, "监控子节点失败")
+
+	e.Update("/hydra/watch/children/a", "2")
+	e.CreateTempNode("/hydra/watch/children/b", "1")
+	select {
+	case w := <-ch:
+		assert.Equal(t, nil, w.GetError(), "监控返回错误")
+		v, _ := w.GetValue()
+		assert.Equal(t, []string{"a", "b"}, v, "子节点不一致")
+	case <-time.After(time.Second * 3):
+		t.Fatal("未收到子节点变化通知")
+	}
+}
+
+func TestEtcd_Txn(t *testing.T) {
+	e, _, closer := newEtcdForTest(t)
+	defer closer()
+
+	err := e.Txn(r.CreateOp("/hydra/txn/a", "1"), r.CreateOp("/hydra/txn/b", "2"))
+	assert.Equal(t, nil, err, "事务创建节点失败")
+	err = e.Txn(r.CreateOp("/hydra/txn/a", "1"))
+	assert.Equal(t, true, r.IsConflict(err), "节点已存在时创建失败")
+
+	_, version, _ := e.GetValue("/hydra/txn/a")
+	err = e.UpdateIfVersion("/hydra/txn/a", "3", version)
+	assert.Equal(t, nil, err, "版本一致时更新成功")
+	err = e.UpdateIfVersion("/hydra/txn/a", "4", version)
+	assert.Equal(t, true, r.IsConflict(err), "版本不一致时更新失败")
+	data, _, _ := e.GetValue("/hydra/txn/a")
+	assert.Equal(t, "3", string(data), "版本冲突时节点值不变")
+
+	_, va, _ := e.GetValue("/hydra/txn/a")
+	_, vb, _ := e.GetValue("/hydra/txn/b")
+	e.Update("/hydra/txn/b", "5")
+	err = e.Txn(r.DeleteOp("/hydra/txn/a", va), r.UpdateOp("/hydra/txn/b", "6", vb))
+	assert.Equal(t, true, r.IsConflict(err), "任一节点版本不一致时事务失败")
+	ok, _ := e.Exists("/hydra/txn/a")
+	assert.Equal(t, true, ok, "事务失败时所有操作均不生效")
+
+	err = e.Txn(r.CheckOp("/hydra/txn/b", r.AnyVersion), r.DeleteOp("/hydra/txn/a", va))
+	assert.Equal(t, nil, err, "事务删除节点失败")
+	ok, _ = e.Exists("/hydra/txn/a")
+	assert.Equal(t, false, ok, "节点已删除")
+}
@@ -0,0 +1,21 @@
+package etcd
+
+import (
+	r "github.com/micro-plat/hydra/registry"
+)
+
+//Exists 检查节点是否存在
+func (e *Etcd) Exists(path string) (bool, error) {
+	kv, _, err := e.client.Get(r.Join(path))
+	if err != nil {
+		return false, err
+	}
+	if kv != nil {
+		return true, nil
+	}
+	children, _, err := e.GetChildren(path)
+	if err != nil {
+		return false, err
+	}
+	return len(children) > 0, nil
+}
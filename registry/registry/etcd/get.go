@@ -0,0 +1,52 @@
+package etcd
+
+import (
+	"fmt"
+	"strings"
+
+	r "github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/hydra/registry/registry/etcd/internal"
+)
+
+//GetValue 获取节点值
+func (e *Etcd) GetValue(path string) (data []byte, version int32, err error) {
+	kv, _, err := e.client.Get(r.Join(path))
+	if err != nil {
+		return nil, 0, err
+	}
+	if kv != nil {
+		return kv.Value, toVersion(kv.ModRevision), nil
+	}
+
+	//etcd中不存在目录节点，有子节点时视为节点存在
+	children, _, err := e.GetChildren(path)
+	if err != nil || len(children) == 0 {
+		return nil, 0, fmt.Errorf("数据不存在")
+	}
+	return []byte{}, 0, nil
+}
+
+//GetChildren 获取所有子节点
+func (e *Etcd) GetChildren(path string) (paths []string, version int32, err error) {
+	paths, rev, err := e.getChildren(path)
+	return paths, toVersion(rev), err
+}
+
+func (e *Etcd) getChildren(path string) (paths []string, rev internal.Int64, err error) {
+	prefix := strings.TrimSuffix(r.Join(path), "/") + "/"
+	kvs, rev, err := e.client.Range(prefix, true, true)
+	if err != nil {
+		return nil, 0, err
+	}
+	cache := map[string]bool{}
+	paths = make([]string, 0, len(kvs))
+	for _, kv := range kvs {
+		name := strings.SplitN(strings.TrimPrefix(string(kv.Key), prefix), "/", 2)[0]
+		if name == "" || cache[name] {
+			continue
+		}
+		cache[name] = true
+		paths = append(paths, name)
+	}
+	return paths, rev, nil
+}
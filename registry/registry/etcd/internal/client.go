@@ -0,0 +1,396 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+//ErrLeaseNotFound 租约不存在或已过期
+var ErrLeaseNotFound = errors.New("etcd: lease not found")
+
+//ErrWatchCanceled 监控被服务器取消
+var ErrWatchCanceled = errors.New("etcd: watch canceled")
+
+//Int64 兼容grpc-gateway以字符串输出的int64
+type Int64 int64
+
+//UnmarshalJSON 解析数字或字符串格式的int64
+func (i *Int64) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	if s == "" || s == "null" {
+		*i = 0
+		return nil
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return err
+	}
+	*i = Int64(v)
+	return nil
+}
+
+//MarshalJSON 以字符串格式输出int64
+func (i Int64) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(strconv.FormatInt(int64(i), 10))), nil
+}
+
+//KeyValue etcd键值对
+type KeyValue struct {
+	Key            []byte `json:"key"`
+	Value          []byte `json:"value"`
+	CreateRevision Int64  `json:"create_revision"`
+	ModRevision    Int64  `json:"mod_revision"`
+	Version        Int64  `json:"version"`
+	Lease          Int64  `json:"lease"`
+}
+
+//Header 响应头
+type Header struct {
+	Revision Int64 `json:"revision"`
+}
+
+//Event 监控事件
+type Event struct {
+	Type string    `json:"type"`
+	KV   *KeyValue `json:"kv"`
+}
+
+//WatchResponse 监控响应
+type WatchResponse struct {
+	Header   Header   `json:"header"`
+	Created  bool     `json:"created"`
+	Canceled bool     `json:"canceled"`
+	Events   []*Event `json:"events"`
+}
+
+//Compare 事务比较条件
+type Compare struct {
+	Key            []byte `json:"key"`
+	Target         string `json:"target"`
+	Result         string `json:"result"`
+	CreateRevision Int64  `json:"create_revision,omitempty"`
+	ModRevision    Int64  `json:"mod_revision,omitempty"`
+}
+
+//TxnOp 事务操作，Delete为true时删除键值，否则设置键值
+type TxnOp struct {
+	Key    []byte
+	Value  []byte
+	Lease  Int64
+	Delete bool
+}
+
+//ClientConf etcd客户端配置
+type ClientConf struct {
+	Address  []string
+	Username string
+	Password string
+	Timeout  time.Duration
+}
+
+//Client 基于etcd v3 grpc-gateway接口的客户端
+type Client struct {
+	conf    *ClientConf
+	client  *http.Client
+	current int32
+	token   string
+	tlock   sync.RWMutex
+}
+
+//NewClient 构建etcd客户端
+func NewClient(c *ClientConf) (*Client, error) {
+	if len(c.Address) == 0 {
+		return nil, fmt.Errorf("未指定etcd服务器地址")
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = time.Second * 10
+	}
+	client := &Client{conf: c, client: &http.Client{}}
+	if c.Username != "" {
+		if err := client.authenticate(); err != nil {
+			return nil, err
+		}
+	}
+	return client, nil
+}
+
+//Get 获取单个键值
+func (c *Client) Get(key string) (*KeyValue, Int64, error) {
+	kvs, rev, err := c.Range(key, false, false)
+	if err != nil || len(kvs) == 0 {
+		return nil, rev, err
+	}
+	return kvs[0], rev, nil
+}
+
+//Range 获取键值，prefix为true时获取所有以key为前缀的键
+func (c *Client) Range(key string, prefix bool, keysOnly bool) ([]*KeyValue, Int64, error) {
+	req := map[string]interface{}{"key": []byte(key)}
+	if prefix {
+		req["range_end"] = prefixEnd(key)
+	}
+	if keysOnly {
+		req["keys_only"] = true
+	}
+	resp := struct {
+		Header Header      `json:"header"`
+		KVs    []*KeyValue `json:"kvs"`
+	}{}
+	if err := c.call("/v3/kv/range", req, &resp); err != nil {
+		return nil, 0, err
+	}
+	return resp.KVs, resp.Header.Revision, nil
+}
+
+//Put 设置键值，lease为0时不绑定租约
+func (c *Client) Put(key string, value []byte, lease Int64) (Int64, error) {
+	req := map[string]interface{}{"key": []byte(key), "value": value}
+	if lease != 0 {
+		req["lease"] = lease
+	}
+	resp := struct {
+		Header Header `json:"header"`
+	}{}
+	if err := c.call("/v3/kv/put", req, &resp); err != nil {
+		return 0, err
+	}
+	return resp.Header.Revision, nil
+}
+
+//Delete 删除键值
+func (c *Client) Delete(key string) (int64, error) {
+	resp := struct {
+		Deleted Int64 `json:"deleted"`
+	}{}
+	if err := c.call("/v3/kv/deleterange", map[string]interface{}{"key": []byte(key)}, &resp); err != nil {
+		return 0, err
+	}
+	return int64(resp.Deleted), nil
+}
+
+//Txn 比较条件全部成立时执行所有操作，否则不执行并返回false
+func (c *Client) Txn(cmps []*Compare, ops []*TxnOp) (bool, error) {
+	success := make([]map[string]interface{}, 0, len(ops))
+	for _, op := range ops {
+		if op.Delete {
+			success = append(success, map[string]interface{}{"request_delete_range": map[string]interface{}{"key": op.Key}})
+			continue
+		}
+		put := map[string]interface{}{"key": op.Key, "value": op.Value}
+		if op.Lease != 0 {
+			put["lease"] = op.Lease
+		}
+		success = append(success, map[string]interface{}{"request_put": put})
+	}
+	resp := struct {
+		Succeeded bool `json:"succeeded"`
+	}{}
+	if err := c.call("/v3/kv/txn", map[string]interface{}{"compare": cmps, "success": success}, &resp); err != nil {
+		return false, err
+	}
+	return resp.Succeeded, nil
+}
+
+//Grant 申请租约
+func (c *Client) Grant(ttl time.Duration) (Int64, error) {
+	resp := struct {
+		ID    Int64  `json:"ID"`
+		Error string `json:"error"`
+	}{}
+	if err := c.call("/v3/lease/grant", map[string]interface{}{"TTL": Int64(ttl / time.Second)}, &resp); err != nil {
+		return 0, err
+	}
+	if resp.Error != "" {
+		return 0, errors.New(resp.Error)
+	}
+	return resp.ID, nil
+}
+
+//KeepAliveOnce 续约一次
+func (c *Client) KeepAliveOnce(id Int64) error {
+	resp := struct {
+		Result struct {
+			ID  Int64 `json:"ID"`
+			TTL Int64 `json:"TTL"`
+		} `json:"result"`
+	}{}
+	if err := c.call("/v3/lease/keepalive", map[string]interface{}{"ID": id}, &resp); err != nil {
+		return err
+	}
+	if resp.Result.TTL <= 0 {
+		return ErrLeaseNotFound
+	}
+	return nil
+}
+
+//Revoke 撤销租约，所有绑定的键将被删除
+func (c *Client) Revoke(id Int64) error {
+	return c.call("/v3/lease/revoke", map[string]interface{}{"ID": id}, nil)
+}
+
+//Watch 建立监控流，从startRevision开始推送键(或前缀)的变更事件，ctx取消时关闭
+func (c *Client) Watch(ctx context.Context, key string, prefix bool, startRevision Int64) (chan *WatchResponse, error) {
+	create := map[string]interface{}{"key": []byte(key)}
+	if prefix {
+		create["range_end"] = prefixEnd(key)
+	}
+	if startRevision > 0 {
+		create["start_revision"] = startRevision
+	}
+	body, _ := json.Marshal(map[string]interface{}{"create_request": create})
+	req, err := http.NewRequest(http.MethodPost, c.url("/v3/watch"), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	c.setHeader(req)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		c.next()
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		buff, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, fmt.Errorf("etcd: 监控失败(%d):%s", resp.StatusCode, strings.TrimSpace(string(buff)))
+	}
+	ch := make(chan *WatchResponse, 1)
+	go func() {
+		defer resp.Body.Close()
+		defer close(ch)
+		decoder := json.NewDecoder(bufio.NewReader(resp.Body))
+		for {
+			msg := struct {
+				Result *WatchResponse `json:"result"`
+			}{}
+			if err := decoder.Decode(&msg); err != nil || msg.Result == nil {
+				return
+			}
+			select {
+			case ch <- msg.Result:
+			case <-ctx.Done():
+				return
+			}
+			if msg.Result.Canceled {
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+func (c *Client) authenticate() error {
+	resp := struct {
+		Token string `json:"token"`
+	}{}
+	if err := c.call("/v3/auth/authenticate", map[string]interface{}{
+		"name":     c.conf.Username,
+		"password": c.conf.Password,
+	}, &resp); err != nil {
+		return fmt.Errorf("etcd认证失败:%w", err)
+	}
+	c.tlock.Lock()
+	c.token = resp.Token
+	c.tlock.Unlock()
+	return nil
+}
+
+//call 发送请求，当前服务器不可用时依次切换到下一个服务器
+func (c *Client) call(path string, req interface{}, resp interface{}) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	var lastErr error
+	for i := 0; i < len(c.conf.Address); i++ {
+		buff, status, err := c.post(path, body)
+		if err != nil {
+			lastErr = err
+			c.next()
+			continue
+		}
+		if status == http.StatusUnauthorized && c.conf.Username != "" && path != "/v3/auth/authenticate" {
+			if err := c.authenticate(); err != nil {
+				return err
+			}
+			buff, status, err = c.post(path, body)
+			if err != nil {
+				return err
+			}
+		}
+		if status != http.StatusOK {
+			if path == "/v3/lease/keepalive" && status == http.StatusNotFound {
+				return ErrLeaseNotFound
+			}
+			return fmt.Errorf("etcd: 请求失败(%d):%s", status, strings.TrimSpace(string(buff)))
+		}
+		if resp == nil {
+			return nil
+		}
+		return json.Unmarshal(buff, resp)
+	}
+	return lastErr
+}
+
+func (c *Client) post(path string, body []byte) ([]byte, int, error) {
+	req, err := http.NewRequest(http.MethodPost, c.url(path), bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, err
+	}
+	c.setHeader(req)
+	client := *c.client
+	client.Timeout = c.conf.Timeout
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	buff, err := ioutil.ReadAll(resp.Body)
+	return buff, resp.StatusCode, err
+}
+
+func (c *Client) setHeader(req *http.Request) {
+	req.Header.Set("Content-Type", "application/json")
+	c.tlock.RLock()
+	defer c.tlock.RUnlock()
+	if c.token != "" {
+		req.Header.Set("Authorization", c.token)
+	}
+}
+
+func (c *Client) url(path string) string {
+	n := atomic.LoadInt32(&c.current)
+	addr := c.conf.Address[int(n)%len(c.conf.Address)]
+	if !strings.HasPrefix(addr, "http://") && !strings.HasPrefix(addr, "https://") {
+		addr = "http://" + addr
+	}
+	return strings.TrimSuffix(addr, "/") + path
+}
+
+func (c *Client) next() {
+	n := atomic.LoadInt32(&c.current)
+	atomic.CompareAndSwapInt32(&c.current, n, (n+1)%int32(len(c.conf.Address)))
+}
+
+//prefixEnd 获取前缀查询的结束键
+func prefixEnd(key string) []byte {
+	end := []byte(key)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i] = end[i] + 1
+			return end[:i+1]
+		}
+	}
+	return []byte{0}
+}
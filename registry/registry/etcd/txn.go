@@ -0,0 +1,116 @@
+package etcd
+
+import (
+	"fmt"
+
+	r "github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/hydra/registry/registry/etcd/internal"
+)
+
+//UpdateIfVersion 节点版本一致时更新节点值
+func (e *Etcd) UpdateIfVersion(path string, data string, version int32) (err error) {
+	return e.Txn(r.UpdateOp(path, data, version))
+}
+
+//Txn 通过etcd事务原子执行多个操作，使用读取时的mod_revision检查节点是否被修改，
+//同一事务中不能多次修改同一节点
+func (e *Etcd) Txn(ops ...r.Op) (err error) {
+	states := make(map[string]*nodeState)
+	cmps := make([]*internal.Compare, 0, len(ops))
+	txn := make([]*internal.TxnOp, 0, len(ops))
+	tmps := make(map[string]string)
+	for _, op := range ops {
+		key := r.Join(op.Path)
+		s, ok := states[key]
+		if !ok {
+			kv, _, err := e.client.Get(key)
+			if err != nil {
+				return fmt.Errorf("检查节点出错:%w", err)
+			}
+			if kv == nil {
+				kv, err = e.getDir(op.Path, key)
+				if err != nil {
+					return err
+				}
+			}
+			s = &nodeState{kv: kv, exists: kv != nil}
+			states[key] = s
+		}
+		var version int32
+		if s.kv != nil {
+			version = toVersion(s.kv.ModRevision)
+		}
+		if err := op.Validate(s.exists, version); err != nil {
+			return err
+		}
+
+		//已在事务中修改过的节点不再添加比较条件，目录节点仅在修改时检查不存在对应的键
+		if s.kv != nil || !ok {
+			dir := s.kv != nil && s.kv.ModRevision == 0
+			switch {
+			case op.Type == r.OpCreate, dir && op.Type == r.OpUpdate:
+				cmps = append(cmps, &internal.Compare{Key: []byte(key), Target: "CREATE", Result: "EQUAL"})
+			case dir:
+			case op.Version == r.AnyVersion:
+				cmps = append(cmps, &internal.Compare{Key: []byte(key), Target: "CREATE", Result: "NOT_EQUAL"})
+			default:
+				cmps = append(cmps, &internal.Compare{Key: []byte(key), Target: "MOD", Result: "EQUAL", ModRevision: s.kv.ModRevision})
+			}
+		}
+		switch op.Type {
+		case r.OpCreate:
+			txn = append(txn, &internal.TxnOp{Key: []byte(key), Value: []byte(op.Data)})
+		case r.OpUpdate:
+			var lease internal.Int64
+			if s.kv != nil {
+				lease = s.kv.Lease
+			}
+			txn = append(txn, &internal.TxnOp{Key: []byte(key), Value: []byte(op.Data), Lease: lease})
+			if _, ok := e.tmpNodes.Get(key); ok {
+				tmps[key] = op.Data
+			}
+		case r.OpDelete:
+			txn = append(txn, &internal.TxnOp{Key: []byte(key), Delete: true})
+			delete(tmps, key)
+		case r.OpCheck:
+			continue
+		default:
+			return fmt.Errorf("不支持的操作类型%d(%s)", op.Type, op.Path)
+		}
+		s.kv, s.exists = nil, op.Type != r.OpDelete
+	}
+	ok, err := e.client.Txn(cmps, txn)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return r.NewConflictError(ops[0].Path, "节点在事务执行期间被修改")
+	}
+	for key, data := range tmps {
+		e.tmpNodes.Set(key, data)
+	}
+	for _, op := range ops {
+		if op.Type == r.OpDelete {
+			e.tmpNodes.Remove(r.Join(op.Path))
+		}
+	}
+	return nil
+}
+
+//nodeState 事务中节点的状态
+type nodeState struct {
+	kv     *internal.KeyValue
+	exists bool
+}
+
+//getDir 获取目录节点，etcd中不存在目录对应的键，有子节点时与GetValue一致视为版本为0的节点
+func (e *Etcd) getDir(path string, key string) (*internal.KeyValue, error) {
+	children, _, err := e.GetChildren(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(children) == 0 {
+		return nil, nil
+	}
+	return &internal.KeyValue{Key: []byte(key)}, nil
+}
@@ -0,0 +1,26 @@
+package etcd
+
+import (
+	"fmt"
+
+	r "github.com/micro-plat/hydra/registry"
+)
+
+//Update 更新节点值，临时节点保持原租约
+func (e *Etcd) Update(path string, data string) (err error) {
+	key := r.Join(path)
+	kv, _, err := e.client.Get(key)
+	if err != nil {
+		return fmt.Errorf("检查节点出错:%w", err)
+	}
+	if kv == nil {
+		return fmt.Errorf("节点不存在%s", path)
+	}
+	if _, err = e.client.Put(key, []byte(data), kv.Lease); err != nil {
+		return err
+	}
+	if _, ok := e.tmpNodes.Get(key); ok {
+		e.tmpNodes.Set(key, data)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package etcd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	r "github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/hydra/registry/registry/etcd/internal"
+	"github.com/micro-plat/lib4go/registry"
+)
+
+//WatchValue 监控值变化，值发生变化或节点被删除时通知一次
+func (e *Etcd) WatchValue(path string) (data chan registry.ValueWatcher, err error) {
+	key := r.Join(path)
+	kv, rev, err := e.client.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if kv == nil {
+		return nil, fmt.Errorf("节点%s不存在", path)
+	}
+	ctx, cancel := context.WithCancel(e.ctx)
+	events, err := e.client.Watch(ctx, key, false, rev+1)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	watcher := make(chan registry.ValueWatcher, 1)
+	go func() {
+		defer cancel()
+		for {
+			select {
+			case <-ctx.Done():
+				watcher <- &valueEntity{path: path, Err: ctx.Err()}
+				return
+			case resp, ok := <-events:
+				if !ok || resp.Canceled {
+					watcher <- &valueEntity{path: path, Err: internal.ErrWatchCanceled}
+					return
+				}
+				for _, ev := range resp.Events {
+					if ev.Type == "DELETE" {
+						watcher <- &valueEntity{path: path, Err: fmt.Errorf("节点%s已删除", path)}
+						return
+					}
+					watcher <- &valueEntity{path: path, version: toVersion(ev.KV.ModRevision), Value: ev.KV.Value}
+					return
+				}
+			}
+		}
+	}()
+	return watcher, nil
+}
+
+//WatchChildren 监控子节点变化，子节点增加或减少时通知一次
+func (e *Etcd) WatchChildren(path string) (data chan registry.ChildrenWatcher, err error) {
+	children, rev, err := e.getChildren(path)
+	if err != nil {
+		return nil, err
+	}
+	current := joinChildren(children)
+	ctx, cancel := context.WithCancel(e.ctx)
+	prefix := strings.TrimSuffix(r.Join(path), "/") + "/"
+	events, err := e.client.Watch(ctx, prefix, true, rev+1)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	watcher := make(chan registry.ChildrenWatcher, 1)
+	go func() {
+		defer cancel()
+		for {
+			select {
+			case <-ctx.Done():
+				watcher <- &childrenEntity{path: path, Err: ctx.Err()}
+				return
+			case resp, ok := <-events:
+				if !ok || resp.Canceled {
+					watcher <- &childrenEntity{path: path, Err: internal.ErrWatchCanceled}
+					return
+				}
+				if !hasChildrenEvent(resp.Events) {
+					continue
+				}
+				nchildren, nrev, err := e.getChildren(path)
+				if err != nil {
+					watcher <- &childrenEntity{path: path, Err: err}
+					return
+				}
+				if joinChildren(nchildren) == current {
+					continue
+				}
+				watcher <- &childrenEntity{path: path, version: toVersion(nrev), children: nchildren}
+				return
+			}
+		}
+	}()
+	return watcher, nil
+}
+
+//hasChildrenEvent 是否包含节点创建或删除事件，值修改不会引起子节点变化
+func hasChildrenEvent(events []*internal.Event) bool {
+	for _, ev := range events {
+		if ev.Type == "DELETE" || (ev.KV != nil && ev.KV.Version == 1) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinChildren(children []string) string {
+	list := make([]string, len(children))
+	copy(list, children)
+	sort.Strings(list)
+	return strings.Join(list, ",")
+}
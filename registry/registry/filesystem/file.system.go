@@ -40,6 +40,7 @@ type fs struct {
 	watchLock           sync.Mutex
 	tempNodes           map[string]bool
 	tempNodeLock        sync.Mutex
+	txnLock             sync.Mutex
 	closeCh             chan struct{}
 	rootDir             string
 	done                bool
@@ -189,7 +190,7 @@ func (l *fs) GetChildren(path string) (paths []string, version int32, err error)
 	}
 	paths = make([]string, 0, len(children))
 	for _, f := range children {
-		if strings.HasSuffix(f.Name(), ".swp") || strings.HasPrefix(f.Name(), "~") || strings.HasPrefix(f.Name(), ".init") {
+		if strings.HasSuffix(f.Name(), ".swp") || strings.HasPrefix(f.Name(), "~") || strings.HasPrefix(f.Name(), ".init") || f.Name() == txnLockFile {
 			continue
 		}
 		paths = append(paths, l.restoreColon(f.Name()))
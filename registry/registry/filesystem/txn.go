@@ -0,0 +1,133 @@
+package filesystem
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	r "github.com/micro-plat/hydra/registry"
+)
+
+//txnLockFile 事务锁文件，多个进程操作同一目录时互斥
+const txnLockFile = ".txn.lock"
+
+//txnLockTimeout 获取事务锁的超时时长，锁文件超过该时长未释放视为进程异常退出遗留的锁
+const txnLockTimeout = 10 * time.Second
+
+//UpdateIfVersion 节点版本一致时更新节点值
+func (l *fs) UpdateIfVersion(path string, data string, version int32) (err error) {
+	return l.Txn(r.UpdateOp(path, data, version))
+}
+
+//Txn 锁定目录后检查所有操作，检查通过后依次执行
+func (l *fs) Txn(ops ...r.Op) (err error) {
+	unlock, err := l.lockTxn()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	//检查所有操作，操作按顺序作用于节点的预期状态
+	state := make(map[string]bool)
+	for _, op := range ops {
+		path := l.replaceColon(l.formatPath(op.Path))
+		exists, ok := state[path]
+		var version int32
+		if !ok {
+			exists, _ = l.Exists(op.Path)
+		}
+		if exists && !ok {
+			_, version, err = l.GetValue(op.Path)
+			if err != nil {
+				return err
+			}
+		}
+		if err := op.Validate(exists, version); err != nil {
+			return err
+		}
+		switch op.Type {
+		case r.OpCreate, r.OpUpdate:
+			state[path] = true
+		case r.OpDelete:
+			state[path] = false
+		case r.OpCheck:
+		default:
+			return fmt.Errorf("不支持的操作类型%d(%s)", op.Type, op.Path)
+		}
+	}
+
+	//执行所有操作
+	for _, op := range ops {
+		switch op.Type {
+		case r.OpCreate:
+			err = l.CreatePersistentNode(op.Path, op.Data)
+		case r.OpUpdate:
+			err = l.updateNodeData(op.Path, op.Data)
+		case r.OpDelete:
+			err = l.Delete(op.Path)
+		}
+		if err != nil {
+			return fmt.Errorf("执行事务操作失败(%s):%w", op.Path, err)
+		}
+	}
+	return nil
+}
+
+//updateNodeData 修改节点值，并确保节点版本发生变化
+func (l *fs) updateNodeData(path string, data string) error {
+	dataPath := l.replaceColon(l.getDataPath(l.formatPath(path)))
+	var old time.Time
+	if info, err := os.Stat(dataPath); err == nil {
+		old = info.ModTime()
+	}
+	if err := ioutil.WriteFile(dataPath, []byte(data), fileMode); err != nil {
+		return err
+	}
+
+	//节点版本为修改时间的秒数，同一秒内多次修改时将修改时间后移，避免版本相同
+	info, err := os.Stat(dataPath)
+	if err != nil {
+		return err
+	}
+	if info.ModTime().Unix() <= old.Unix() {
+		next := time.Unix(old.Unix()+1, 0)
+		return os.Chtimes(dataPath, next, next)
+	}
+	return nil
+}
+
+//lockTxn 通过独占创建锁文件实现跨进程互斥
+func (l *fs) lockTxn() (func(), error) {
+	l.txnLock.Lock()
+	if err := os.MkdirAll(l.rootDir, dirMode); err != nil {
+		l.txnLock.Unlock()
+		return nil, err
+	}
+	path := filepath.Join(l.rootDir, txnLockFile)
+	deadline := time.Now().Add(txnLockTimeout)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, fileMode)
+		if err == nil {
+			f.Close()
+			return func() {
+				os.Remove(path)
+				l.txnLock.Unlock()
+			}, nil
+		}
+		if !os.IsExist(err) {
+			l.txnLock.Unlock()
+			return nil, fmt.Errorf("创建事务锁文件失败:%w", err)
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > txnLockTimeout {
+			os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			l.txnLock.Unlock()
+			return nil, fmt.Errorf("获取事务锁超时:%s", path)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
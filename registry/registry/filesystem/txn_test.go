@@ -0,0 +1,37 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	r "github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func TestFs_Txn(t *testing.T) {
+	l, err := NewFileSystem(t.TempDir())
+	assert.Equal(t, nil, err, "构建文件系统注册中心")
+	defer l.Close()
+
+	err = l.Txn(r.CreateOp("/hydra/txn/a", "1"), r.CreateOp("/hydra/txn/b", "2"))
+	assert.Equal(t, nil, err, "事务创建节点")
+	err = l.Txn(r.CreateOp("/hydra/txn/a", "1"))
+	assert.Equal(t, true, r.IsConflict(err), "节点已存在时创建失败")
+
+	//同一秒内多次修改，版本仍然变化
+	_, version, _ := l.GetValue("/hydra/txn/a")
+	assert.Equal(t, nil, l.UpdateIfVersion("/hydra/txn/a", "3", version), "版本一致时更新")
+	_, nversion, _ := l.GetValue("/hydra/txn/a")
+	assert.Equal(t, true, nversion != version, "更新后版本变化")
+	err = l.UpdateIfVersion("/hydra/txn/a", "4", version)
+	assert.Equal(t, true, r.IsConflict(err), "版本不一致时更新失败")
+
+	err = l.Txn(r.DeleteOp("/hydra/txn/b", r.AnyVersion), r.CheckOp("/hydra/txn/a", version))
+	assert.Equal(t, true, r.IsConflict(err), "任一操作检查失败时事务失败")
+	ok, _ := l.Exists("/hydra/txn/b")
+	assert.Equal(t, true, ok, "事务失败时节点未删除")
+
+	_, err = os.Stat(filepath.Join(l.rootDir, txnLockFile))
+	assert.Equal(t, true, os.IsNotExist(err), "事务结束后释放锁文件")
+}
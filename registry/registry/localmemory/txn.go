@@ -0,0 +1,94 @@
+package localmemory
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/micro-plat/hydra/registry"
+)
+
+//UpdateIfVersion 节点版本一致时更新节点值
+func (l *localMemory) UpdateIfVersion(path string, data string, version int32) (err error) {
+	return l.Txn(registry.UpdateOp(path, data, version))
+}
+
+//Txn 原子执行多个操作，所有操作检查通过后才修改节点
+func (l *localMemory) Txn(ops ...registry.Op) (err error) {
+	l.lock.Lock()
+
+	//检查所有操作，操作按顺序作用于节点的预期状态
+	state := make(map[string]*value)
+	current := func(path string) (*value, bool) {
+		if v, ok := state[path]; ok {
+			return v, v != nil
+		}
+		v, ok := l.nodes[path]
+		return v, ok
+	}
+	for _, op := range ops {
+		path := registry.Format(op.Path)
+		v, ok := current(path)
+		var version int32
+		if ok {
+			version = v.version
+		}
+		if err := op.Validate(ok, version); err != nil {
+			l.lock.Unlock()
+			return err
+		}
+		switch op.Type {
+		case registry.OpCreate, registry.OpUpdate:
+			state[path] = &value{data: op.Data}
+		case registry.OpDelete:
+			state[path] = nil
+		case registry.OpCheck:
+		default:
+			l.lock.Unlock()
+			return fmt.Errorf("不支持的操作类型%d(%s)", op.Type, op.Path)
+		}
+	}
+
+	//执行所有操作
+	changed := make(map[string]*value)
+	created := make(map[string]int32)
+	deleted := make(map[string]*value)
+	for _, op := range ops {
+		path := registry.Format(op.Path)
+		switch op.Type {
+		case registry.OpCreate:
+			for _, xpath := range l.getPaths(path) {
+				if _, ok := l.nodes[xpath]; !ok && xpath != path {
+					l.nodes[xpath] = newValue("{}")
+				}
+			}
+			nvalue := newValue(op.Data)
+			l.nodes[path] = nvalue
+			created[path] = nvalue.version
+		case registry.OpUpdate:
+			nvalue := newValue(op.Data)
+			l.nodes[path] = nvalue
+			changed[path] = nvalue
+		case registry.OpDelete:
+			for k, nv := range l.nodes {
+				if k == path || strings.HasPrefix(k, path+"/") {
+					delete(l.nodes, k)
+					deleted[k] = nv
+				}
+			}
+		}
+	}
+	l.lock.Unlock()
+
+	//通知节点变化
+	for path, nvalue := range changed {
+		l.notifyValueChange(path, nvalue)
+	}
+	for path, nvalue := range deleted {
+		l.notifyValueChange(path, nvalue)
+		l.notifyParentChange(path, nvalue.version)
+	}
+	for path, version := range created {
+		l.notifyParentChange(path, version)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package localmemory
+
+import (
+	"testing"
+
+	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func TestLocalMemory_Txn(t *testing.T) {
+	l := NewLocalMemory()
+	err := l.Txn(registry.CreateOp("/hydra/txn/a", "1"), registry.CreateOp("/hydra/txn/a/c", "2"))
+	assert.Equal(t, nil, err, "事务创建节点")
+	err = l.Txn(registry.CreateOp("/hydra/txn/a", "1"))
+	assert.Equal(t, true, registry.IsConflict(err), "节点已存在时创建失败")
+
+	_, version, _ := l.GetValue("/hydra/txn/a")
+	assert.Equal(t, nil, l.UpdateIfVersion("/hydra/txn/a", "3", version), "版本一致时更新")
+	err = l.UpdateIfVersion("/hydra/txn/a", "4", version)
+	assert.Equal(t, true, registry.IsConflict(err), "版本不一致时更新失败")
+	data, version, _ := l.GetValue("/hydra/txn/a")
+	assert.Equal(t, "3", string(data), "版本冲突时节点值不变")
+
+	//任一操作检查失败时所有操作均不生效
+	err = l.Txn(registry.DeleteOp("/hydra/txn/a/c", registry.AnyVersion), registry.UpdateOp("/hydra/txn/b", "5", registry.AnyVersion))
+	assert.Equal(t, true, registry.IsConflict(err), "更新不存在的节点")
+	ok, _ := l.Exists("/hydra/txn/a/c")
+	assert.Equal(t, true, ok, "事务失败时节点未删除")
+
+	err = l.Txn(registry.DeleteOp("/hydra/txn/a/c", registry.AnyVersion), registry.UpdateOp("/hydra/txn/a", "6", version))
+	assert.Equal(t, nil, err, "删除子节点并更新")
+	ok, _ = l.Exists("/hydra/txn/a/c")
+	assert.Equal(t, false, ok, "子节点已删除")
+	data, _, _ = l.GetValue("/hydra/txn/a")
+	assert.Equal(t, "6", string(data), "节点已更新")
+}
@@ -0,0 +1,120 @@
+package redis
+
+import (
+	"fmt"
+
+	"github.com/go-redis/redis"
+	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/hydra/registry/registry/redis/internal"
+)
+
+//UpdateIfVersion 节点版本一致时更新节点值
+func (r *Redis) UpdateIfVersion(path string, data string, version int32) (err error) {
+	return r.Txn(registry.UpdateOp(path, data, version))
+}
+
+//Txn 通过WATCH/MULTI原子执行多个操作，集群模式下所有节点须位于同一slot
+func (r *Redis) Txn(ops ...registry.Op) (err error) {
+	if len(ops) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(ops))
+	for _, op := range ops {
+		keys = append(keys, internal.SwapKey(op.Path))
+	}
+
+	changed := make(map[string]*value)
+	created := make(map[string]int32)
+	deleted := make(map[string]bool)
+	err = r.client.Watch(func(tx *redis.Tx) error {
+
+		//读取节点当前值，并检查所有操作
+		current := make(map[string]*value)
+		for i, op := range ops {
+			key := keys[i]
+			ovalue, ok := current[key]
+			if !ok {
+				buff, err := tx.Get(key).Result()
+				switch {
+				case err == redis.Nil:
+					//不存在但有子节点时，与GetValue一致视为版本为0的节点
+					if exists, err := r.client.ExistsChildren(key + ":*"); err != nil {
+						return err
+					} else if exists {
+						ovalue = &value{}
+					}
+				case err != nil:
+					return err
+				default:
+					if ovalue, err = newValueByJSON(buff); err != nil {
+						return err
+					}
+				}
+			}
+			var version int32
+			if ovalue != nil {
+				version = ovalue.Version
+			}
+			if err := op.Validate(ovalue != nil, version); err != nil {
+				return err
+			}
+			switch op.Type {
+			case registry.OpCreate:
+				current[key] = newValue(op.Data, false)
+			case registry.OpUpdate:
+				current[key] = newValue(op.Data, ovalue.IsTemp)
+			case registry.OpDelete:
+				current[key] = nil
+			case registry.OpCheck:
+				current[key] = ovalue
+			default:
+				return fmt.Errorf("不支持的操作类型%d(%s)", op.Type, op.Path)
+			}
+		}
+
+		//在事务中执行所有操作，监控的节点被修改时事务不执行
+		_, err := tx.Pipelined(func(pipe redis.Pipeliner) error {
+			for i, op := range ops {
+				key := keys[i]
+				switch op.Type {
+				case registry.OpCreate:
+					value := current[key]
+					pipe.Set(key, value.String(), r.maxExpiration)
+					created[key] = value.Version
+				case registry.OpUpdate:
+					value := current[key]
+					exp := r.maxExpiration
+					if value.IsTemp {
+						exp = r.tmpExpiration
+					}
+					pipe.Set(key, value.String(), exp)
+					changed[op.Path] = value
+				case registry.OpDelete:
+					pipe.Del(key)
+					deleted[key] = true
+				}
+			}
+			return nil
+		})
+		return err
+	}, keys...)
+	if err == redis.TxFailedErr {
+		return registry.NewConflictError(ops[0].Path, "节点在事务执行期间被修改")
+	}
+	if err != nil {
+		return err
+	}
+
+	//通知变更
+	for path, value := range changed {
+		r.notifyValueChange(path, value)
+	}
+	for key, version := range created {
+		r.notifyParentChange(key, version)
+	}
+	for key := range deleted {
+		r.tmpNodes.Remove(key)
+		r.notifyParentChange(key, 0)
+	}
+	return nil
+}
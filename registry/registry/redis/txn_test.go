@@ -0,0 +1,77 @@
+package redis
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis"
+	r "github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func newRedisForTest(t *testing.T) (*Redis, *miniredis.Miniredis) {
+	s, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rds, err := NewRedisBy("", "", []string{s.Addr()}, 0, 3)
+	if err != nil {
+		s.Close()
+		t.Fatal(err)
+	}
+	return rds, s
+}
+
+func TestRedis_Txn(t *testing.T) {
+	rds, s := newRedisForTest(t)
+	defer s.Close()
+	defer rds.Close()
+
+	err := rds.Txn(r.CreateOp("/hydra/txn/a", "1"), r.CreateOp("/hydra/txn/b", "2"))
+	assert.Equal(t, nil, err, "事务创建节点")
+	err = rds.Txn(r.CreateOp("/hydra/txn/a", "1"))
+	assert.Equal(t, true, r.IsConflict(err), "节点已存在时创建失败")
+
+	_, version, _ := rds.GetValue("/hydra/txn/a")
+	assert.Equal(t, nil, rds.UpdateIfVersion("/hydra/txn/a", "3", version), "版本一致时更新")
+	err = rds.UpdateIfVersion("/hydra/txn/a", "4", version)
+	assert.Equal(t, true, r.IsConflict(err), "版本不一致时更新失败")
+	data, _, _ := rds.GetValue("/hydra/txn/a")
+	assert.Equal(t, "3", string(data), "版本冲突时节点值不变")
+
+	_, va, _ := rds.GetValue("/hydra/txn/a")
+	_, vb, _ := rds.GetValue("/hydra/txn/b")
+	rds.Update("/hydra/txn/b", "5")
+	err = rds.Txn(r.DeleteOp("/hydra/txn/a", va), r.UpdateOp("/hydra/txn/b", "6", vb))
+	assert.Equal(t, true, r.IsConflict(err), "任一节点版本不一致时事务失败")
+	ok, _ := rds.Exists("/hydra/txn/a")
+	assert.Equal(t, true, ok, "事务失败时所有操作均不生效")
+}
+
+func TestRedis_TxnWatch(t *testing.T) {
+	rds, s := newRedisForTest(t)
+	defer s.Close()
+	defer rds.Close()
+
+	assert.Equal(t, nil, rds.Txn(r.CreateOp("/hydra/txn/a", "1")), "事务创建节点")
+	_, version, _ := rds.GetValue("/hydra/txn/a")
+
+	//读取不存在的节点时检查子节点，此时其它客户端修改已监控的节点
+	modified := false
+	rds.client.UniversalClient.(*redis.Client).WrapProcess(func(old func(redis.Cmder) error) func(redis.Cmder) error {
+		return func(cmd redis.Cmder) error {
+			if cmd.Name() == "scan" && !modified {
+				modified = true
+				s.Set("hydra:txn:a", `{"data":"Mg==","version":1}`)
+			}
+			return old(cmd)
+		}
+	})
+	err := rds.Txn(r.UpdateOp("/hydra/txn/a", "3", version), r.CreateOp("/hydra/txn/c", "1"))
+	assert.Equal(t, true, modified, "事务执行期间节点被修改")
+	assert.Equal(t, true, r.IsConflict(err), "监控的节点被修改时事务失败")
+	ok, _ := rds.Exists("/hydra/txn/c")
+	assert.Equal(t, false, ok, "事务失败时节点未创建")
+	data, _, _ := rds.GetValue("/hydra/txn/a")
+	assert.Equal(t, "2", string(data), "保留其它客户端修改的值")
+}
@@ -27,8 +27,10 @@ func (z *zookeeperFactory) Create(opts ...registry.Option) (registry.IRegistry,
 	if err != nil {
 		return nil, err
 	}
-	err = zclient.Connect()
-	return zclient, err
+	if err = zclient.Connect(); err != nil {
+		return nil, err
+	}
+	return newZookeeper(zclient, z.opts), nil
 }
 
 func init() {
@@ -0,0 +1,226 @@
+package zookeeper
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	gozk "github.com/samuel/go-zookeeper/zk"
+
+	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/lib4go/encoding"
+	"github.com/micro-plat/lib4go/zk"
+)
+
+//zookeeper 在客户端基础上提供版本检查与事务，使用独立的连接执行multi请求
+type zookeeper struct {
+	*zk.ZookeeperClient
+	opts     *registry.Options
+	conn     *gozk.Conn
+	getValue func(path string) ([]byte, int32, error)
+	lock     sync.Mutex
+}
+
+//txnConn 执行事务的连接
+type txnConn interface {
+	Exists(path string) (bool, *gozk.Stat, error)
+	Multi(ops ...interface{}) ([]gozk.MultiResponse, error)
+}
+
+func newZookeeper(client *zk.ZookeeperClient, opts *registry.Options) *zookeeper {
+	return &zookeeper{ZookeeperClient: client, opts: opts, getValue: client.GetValue}
+}
+
+//UpdateIfVersion 节点版本一致时更新节点值
+func (z *zookeeper) UpdateIfVersion(path string, data string, version int32) (err error) {
+	return z.Txn(registry.UpdateOp(path, data, version))
+}
+
+//Txn 通过multi请求原子执行多个操作，
+//GetValue返回的版本由修改时间计算，执行前先将其对应到zookeeper的数据版本
+func (z *zookeeper) Txn(ops ...registry.Op) (err error) {
+	conn, err := z.getConn()
+	if err != nil {
+		return err
+	}
+	return z.txn(conn, ops...)
+}
+
+//txn 构建并执行multi请求，创建节点时不存在的父节点在同一请求中创建
+func (z *zookeeper) txn(conn txnConn, ops ...registry.Op) (err error) {
+	requests := make([]interface{}, 0, len(ops))
+	paths := make([]string, 0, len(ops))
+	nodes := make(map[string]bool)
+	for _, op := range ops {
+		version := int32(-1)
+		if op.Type != registry.OpCreate && op.Version != registry.AnyVersion {
+			if version, err = z.getDataVersion(conn, op); err != nil {
+				return err
+			}
+		}
+		switch op.Type {
+		case registry.OpCreate:
+			dirs, err := z.getParents(conn, op.Path, nodes)
+			if err != nil {
+				return err
+			}
+			for _, dir := range dirs {
+				requests = append(requests, &gozk.CreateRequest{Path: dir, Data: []byte{}, Acl: z.ACL})
+				paths = append(paths, dir)
+			}
+			buff, err := encoding.Encode(op.Data, "gbk")
+			if err != nil {
+				return err
+			}
+			requests = append(requests, &gozk.CreateRequest{Path: op.Path, Data: buff, Acl: z.ACL})
+		case registry.OpUpdate:
+			buff, err := encoding.Encode(op.Data, "gbk")
+			if err != nil {
+				return err
+			}
+			requests = append(requests, &gozk.SetDataRequest{Path: op.Path, Data: buff, Version: version})
+		case registry.OpDelete:
+			requests = append(requests, &gozk.DeleteRequest{Path: op.Path, Version: version})
+		case registry.OpCheck:
+			requests = append(requests, &gozk.CheckVersionRequest{Path: op.Path, Version: version})
+		default:
+			return fmt.Errorf("不支持的操作类型%d(%s)", op.Type, op.Path)
+		}
+		if op.Type != registry.OpCreate {
+			nodes[op.Path] = op.Type != registry.OpDelete
+		}
+		paths = append(paths, op.Path)
+	}
+
+	responses, err := conn.Multi(requests...)
+	if err == nil {
+		return nil
+	}
+	path := ops[0].Path
+	for i, resp := range responses {
+		if resp.Error == err && i < len(paths) {
+			path = paths[i]
+			break
+		}
+	}
+	return convertError(path, err)
+}
+
+//getDataVersion 检查节点版本，返回节点对应的zookeeper数据版本
+func (z *zookeeper) getDataVersion(conn txnConn, op registry.Op) (int32, error) {
+	for i := 0; i < 3; i++ {
+		exists, before, err := conn.Exists(op.Path)
+		if err != nil {
+			return 0, err
+		}
+		if !exists {
+			return 0, op.Validate(false, 0)
+		}
+		_, version, err := z.getValue(op.Path)
+		if err != nil {
+			return 0, err
+		}
+		_, after, err := conn.Exists(op.Path)
+		if err != nil {
+			return 0, err
+		}
+
+		//两次读取之间节点被修改时重新读取
+		if after == nil || after.Version != before.Version {
+			continue
+		}
+		if err := op.Validate(true, version); err != nil {
+			return 0, err
+		}
+		return before.Version, nil
+	}
+	return 0, registry.NewConflictError(op.Path, "节点正在被频繁修改")
+}
+
+//getParents 由上至下获取不存在的父节点，nodes记录本次请求执行后节点是否存在，已加入请求的父节点不再重复创建，
+//父节点被其它客户端同时创建时multi请求返回节点已存在，由调用方重试
+func (z *zookeeper) getParents(conn txnConn, path string, nodes map[string]bool) ([]string, error) {
+	dirs := make([]string, 0, 1)
+	for dir := parentDir(path); dir != "/"; dir = parentDir(dir) {
+		if exists, ok := nodes[dir]; ok {
+			if exists {
+				break
+			}
+			dirs = append(dirs, dir)
+			continue
+		}
+		exists, _, err := conn.Exists(dir)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			break
+		}
+		dirs = append(dirs, dir)
+	}
+	for i, j := 0, len(dirs)-1; i < j; i, j = i+1, j-1 {
+		dirs[i], dirs[j] = dirs[j], dirs[i]
+	}
+	for _, dir := range dirs {
+		nodes[dir] = true
+	}
+	nodes[path] = true
+	return dirs, nil
+}
+
+//parentDir 获取父节点路径
+func parentDir(path string) string {
+	i := strings.LastIndex(strings.TrimRight(path, "/"), "/")
+	if i <= 0 {
+		return "/"
+	}
+	return path[:i]
+}
+
+//getConn 获取执行事务的连接
+func (z *zookeeper) getConn() (*gozk.Conn, error) {
+	z.lock.Lock()
+	defer z.lock.Unlock()
+	if z.conn != nil && z.conn.State() != gozk.StateDisconnected {
+		return z.conn, nil
+	}
+	if z.conn != nil {
+		z.conn.Close()
+	}
+	conn, _, err := gozk.Connect(z.opts.Addrs, time.Second, gozk.WithLogger(z.Log))
+	if err != nil {
+		return nil, err
+	}
+	if z.opts.Auth != nil && z.opts.Auth.Username != "" {
+		auth := fmt.Sprintf("%s:%s", z.opts.Auth.Username, z.opts.Auth.Password)
+		if err := conn.AddAuth("digest", []byte(auth)); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	z.conn = conn
+	return conn, nil
+}
+
+//Close 关闭事务连接与客户端
+func (z *zookeeper) Close() error {
+	z.lock.Lock()
+	if z.conn != nil {
+		z.conn.Close()
+		z.conn = nil
+	}
+	z.lock.Unlock()
+	return z.ZookeeperClient.Close()
+}
+
+//convertError 将版本不一致、节点已存在、节点不存在转换为ErrVersionConflict
+func convertError(path string, err error) error {
+	switch {
+	case errors.Is(err, gozk.ErrBadVersion), errors.Is(err, gozk.ErrNodeExists), errors.Is(err, gozk.ErrNoNode):
+		return registry.NewConflictError(path, err.Error())
+	default:
+		return fmt.Errorf("执行事务失败(%s):%w", path, err)
+	}
+}
@@ -0,0 +1,177 @@
+package zookeeper
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	gozk "github.com/samuel/go-zookeeper/zk"
+
+	r "github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/lib4go/assert"
+	"github.com/micro-plat/lib4go/zk"
+)
+
+type fakeNode struct {
+	data    []byte
+	version int32
+	mtime   int64
+}
+
+//fakeConn 内存中的zookeeper节点树，multi请求在副本上执行，全部成功后替换
+type fakeConn struct {
+	lock        sync.Mutex
+	nodes       map[string]*fakeNode
+	clock       int64
+	multi       int
+	beforeMulti func()
+}
+
+func newFakeConn() *fakeConn {
+	return &fakeConn{nodes: map[string]*fakeNode{"/": {}}, clock: 1000}
+}
+
+func (f *fakeConn) Exists(path string) (bool, *gozk.Stat, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	n, ok := f.nodes[path]
+	if !ok {
+		return false, nil, nil
+	}
+	return true, &gozk.Stat{Version: n.version, Mtime: n.mtime}, nil
+}
+
+//getValue 与客户端一致，返回由修改时间计算的版本
+func (f *fakeConn) getValue(path string) ([]byte, int32, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	n, ok := f.nodes[path]
+	if !ok {
+		return nil, 0, gozk.ErrNoNode
+	}
+	return n.data, int32(n.mtime), nil
+}
+
+func (f *fakeConn) set(path string, data string) {
+	f.Multi(&gozk.SetDataRequest{Path: path, Data: []byte(data), Version: -1})
+}
+
+func (f *fakeConn) Multi(ops ...interface{}) ([]gozk.MultiResponse, error) {
+	if f.beforeMulti != nil {
+		h := f.beforeMulti
+		f.beforeMulti = nil
+		h()
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.multi++
+	nodes := make(map[string]*fakeNode, len(f.nodes))
+	for k, v := range f.nodes {
+		nodes[k] = v
+	}
+	responses := make([]gozk.MultiResponse, len(ops))
+	for i, op := range ops {
+		if err := f.apply(nodes, op); err != nil {
+			responses[i].Error = err
+			return responses, err
+		}
+	}
+	f.nodes = nodes
+	return responses, nil
+}
+
+func (f *fakeConn) apply(nodes map[string]*fakeNode, op interface{}) error {
+	f.clock++
+	switch req := op.(type) {
+	case *gozk.CreateRequest:
+		if _, ok := nodes[req.Path]; ok {
+			return gozk.ErrNodeExists
+		}
+		if _, ok := nodes[parentDir(req.Path)]; !ok {
+			return gozk.ErrNoNode
+		}
+		nodes[req.Path] = &fakeNode{data: req.Data, mtime: f.clock}
+	case *gozk.SetDataRequest:
+		n, err := checkVersion(nodes, req.Path, req.Version)
+		if err != nil {
+			return err
+		}
+		nodes[req.Path] = &fakeNode{data: req.Data, version: n.version + 1, mtime: f.clock}
+	case *gozk.DeleteRequest:
+		if _, err := checkVersion(nodes, req.Path, req.Version); err != nil {
+			return err
+		}
+		for k := range nodes {
+			if strings.HasPrefix(k, req.Path+"/") {
+				return gozk.ErrNotEmpty
+			}
+		}
+		delete(nodes, req.Path)
+	case *gozk.CheckVersionRequest:
+		_, err := checkVersion(nodes, req.Path, req.Version)
+		return err
+	}
+	return nil
+}
+
+func checkVersion(nodes map[string]*fakeNode, path string, version int32) (*fakeNode, error) {
+	n, ok := nodes[path]
+	if !ok {
+		return nil, gozk.ErrNoNode
+	}
+	if version != -1 && version != n.version {
+		return nil, gozk.ErrBadVersion
+	}
+	return n, nil
+}
+
+func newZookeeperForTest() (*zookeeper, *fakeConn) {
+	f := newFakeConn()
+	z := &zookeeper{
+		ZookeeperClient: &zk.ZookeeperClient{ACL: gozk.WorldACL(gozk.PermAll)},
+		getValue:        f.getValue,
+	}
+	return z, f
+}
+
+func TestZookeeper_Txn(t *testing.T) {
+	z, f := newZookeeperForTest()
+
+	err := z.txn(f, r.CreateOp("/hydra/txn/a", "1"), r.CreateOp("/hydra/txn/b", "2"))
+	assert.Equal(t, nil, err, "事务创建节点")
+	assert.Equal(t, 1, f.multi, "父节点与节点在同一multi请求中创建")
+	ok, _, _ := f.Exists("/hydra/txn")
+	assert.Equal(t, true, ok, "父节点已创建")
+
+	err = z.txn(f, r.CreateOp("/hydra/txn/a", "1"))
+	assert.Equal(t, true, r.IsConflict(err), "节点已存在时创建失败")
+
+	data, version, _ := f.getValue("/hydra/txn/a")
+	assert.Equal(t, "1", string(data), "节点值")
+	assert.Equal(t, nil, z.txn(f, r.UpdateOp("/hydra/txn/a", "3", version)), "版本一致时更新")
+	err = z.txn(f, r.UpdateOp("/hydra/txn/a", "4", version))
+	assert.Equal(t, true, r.IsConflict(err), "版本不一致时更新失败")
+	data, _, _ = f.getValue("/hydra/txn/a")
+	assert.Equal(t, "3", string(data), "版本冲突时节点值不变")
+
+	//检查版本后、执行multi前节点被其它客户端修改
+	_, version, _ = f.getValue("/hydra/txn/b")
+	f.beforeMulti = func() { f.set("/hydra/txn/b", "5") }
+	err = z.txn(f, r.CreateOp("/hydra/new/c", "1"), r.UpdateOp("/hydra/txn/b", "6", version))
+	assert.Equal(t, true, r.IsConflict(err), "版本在执行multi前变化时事务失败")
+	ok, _, _ = f.Exists("/hydra/new")
+	assert.Equal(t, false, ok, "事务失败时父节点不会被创建")
+
+	//父节点被其它客户端同时创建
+	f.beforeMulti = func() { f.Multi(&gozk.CreateRequest{Path: "/hydra/new", Data: []byte{}}) }
+	err = z.txn(f, r.CreateOp("/hydra/new/c", "1"))
+	assert.Equal(t, true, r.IsConflict(err), "父节点被同时创建时返回冲突")
+	assert.Equal(t, true, strings.Contains(err.Error(), "/hydra/new"), "冲突节点为父节点")
+	assert.Equal(t, nil, z.txn(f, r.CreateOp("/hydra/new/c", "1")), "重试后创建成功")
+
+	_, va, _ := f.getValue("/hydra/txn/a")
+	err = z.txn(f, r.CheckOp("/hydra/txn/b", r.AnyVersion), r.DeleteOp("/hydra/txn/a", va))
+	assert.Equal(t, nil, err, "事务删除节点")
+	ok, _, _ = f.Exists("/hydra/txn/a")
+	assert.Equal(t, false, ok, "节点已删除")
+}
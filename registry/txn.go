@@ -0,0 +1,99 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+)
+
+//AnyVersion 不检查节点版本
+const AnyVersion int32 = -1
+
+//MaxTxnOps 单个事务允许的最大操作数，consul限制为64，etcd默认限制为128
+const MaxTxnOps = 64
+
+//ErrVersionConflict 节点已被其它客户端修改，与期望的版本不一致
+var ErrVersionConflict = errors.New("registry: 节点版本冲突")
+
+//OpType 事务操作类型
+type OpType int
+
+const (
+	//OpCreate 创建永久节点，节点已存在时事务失败
+	OpCreate OpType = iota + 1
+
+	//OpUpdate 更新节点值
+	OpUpdate
+
+	//OpDelete 删除节点
+	OpDelete
+
+	//OpCheck 仅检查节点版本
+	OpCheck
+)
+
+//Op 事务中的单个操作
+type Op struct {
+	Type    OpType
+	Path    string
+	Data    string
+	Version int32
+}
+
+//CreateOp 创建永久节点
+func CreateOp(path string, data string) Op {
+	return Op{Type: OpCreate, Path: path, Data: data}
+}
+
+//UpdateOp 节点版本为version时更新节点值，version为AnyVersion时不检查版本
+func UpdateOp(path string, data string, version int32) Op {
+	return Op{Type: OpUpdate, Path: path, Data: data, Version: version}
+}
+
+//DeleteOp 节点版本为version时删除节点，version为AnyVersion时不检查版本
+func DeleteOp(path string, version int32) Op {
+	return Op{Type: OpDelete, Path: path, Version: version}
+}
+
+//CheckOp 检查节点版本为version，version为AnyVersion时仅检查节点存在
+func CheckOp(path string, version int32) Op {
+	return Op{Type: OpCheck, Path: path, Version: version}
+}
+
+//Validate 根据节点当前状态检查操作能否执行，
+//创建已存在的节点，或修改、删除、检查不存在或版本不一致的节点时返回ErrVersionConflict
+func (o Op) Validate(exists bool, version int32) error {
+	switch {
+	case o.Type == OpCreate && exists:
+		return NewConflictError(o.Path, "节点已存在")
+	case o.Type != OpCreate && !exists:
+		return NewConflictError(o.Path, "节点不存在")
+	case o.Type != OpCreate && o.Version != AnyVersion && o.Version != version:
+		return NewConflictError(o.Path, fmt.Sprintf("期望版本%d,当前版本%d", o.Version, version))
+	}
+	return nil
+}
+
+//NewConflictError 构建节点版本冲突错误
+func NewConflictError(path string, reason string) error {
+	return fmt.Errorf("%w:%s(%s)", ErrVersionConflict, path, reason)
+}
+
+//IsConflict 是否是节点版本冲突错误
+func IsConflict(err error) bool {
+	return errors.Is(err, ErrVersionConflict)
+}
+
+//TxnBatch 按MaxTxnOps将操作分批提交，每批为一个事务，某批失败时不再提交后续批次，
+//返回已提交的操作数
+func TxnBatch(r IRegistry, ops ...Op) (int, error) {
+	for i := 0; i < len(ops); i += MaxTxnOps {
+		end := i + MaxTxnOps
+		if end > len(ops) {
+			end = len(ops)
+		}
+		if err := r.Txn(ops[i:end]...); err != nil {
+			return i, err
+		}
+	}
+	return len(ops), nil
+}
@@ -0,0 +1,222 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+//Version OpenAPI规范版本
+const Version = "3.0.3"
+
+//Doc 服务文档说明，由处理对象的[Name]Doc() *openapi.Doc函数提供
+type Doc struct {
+	Summary     string
+	Description string
+	Tags        []string
+	Deprecated  bool
+
+	//Request 请求参数结构体，GET,DELETE请求生成为查询参数，其它请求生成为请求体
+	Request interface{}
+
+	//Response 响应结构体
+	Response interface{}
+}
+
+//Document OpenAPI文档
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       *Info               `json:"info"`
+	Servers    []*Server           `json:"servers,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components *Components         `json:"components,omitempty"`
+	Tags       []*Tag              `json:"tags,omitempty"`
+	schemas    *schemaBuilder      `json:"-"`
+	tags       map[string]bool     `json:"-"`
+	operations map[string]bool     `json:"-"`
+}
+
+//Info 文档信息
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+//Server 服务地址
+type Server struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+//Tag 分组标签
+type Tag struct {
+	Name string `json:"name"`
+}
+
+//Components 公共组件
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+//PathItem 路径下各请求方式的操作
+type PathItem map[string]*Operation
+
+//Operation 接口操作
+type Operation struct {
+	Tags        []string             `json:"tags,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	OperationID string               `json:"operationId"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+	Deprecated  bool                 `json:"deprecated,omitempty"`
+}
+
+//Parameter 请求参数
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+//RequestBody 请求体
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+//Response 响应内容
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+//MediaType 内容类型
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+//New 构建OpenAPI文档
+func New(title string, version string, description ...string) *Document {
+	info := &Info{Title: title, Version: version}
+	if len(description) > 0 {
+		info.Description = description[0]
+	}
+	return &Document{
+		OpenAPI:    Version,
+		Info:       info,
+		Paths:      make(map[string]PathItem),
+		schemas:    newSchemaBuilder(),
+		tags:       make(map[string]bool),
+		operations: make(map[string]bool),
+	}
+}
+
+var pathParam = regexp.MustCompile(`[:*]([^/]+)`)
+
+//Add 添加接口，路径中的:name,*name转换为{name}路径参数，OPTIONS,HEAD请求不生成文档，重复的路径与请求方式被忽略
+func (d *Document) Add(path string, actions []string, service string, encoding string, doc *Doc) {
+	params := pathParam.FindAllStringSubmatch(path, -1)
+	npath := pathParam.ReplaceAllString(path, "{$1}")
+	if doc == nil {
+		doc = &Doc{}
+	}
+	for _, action := range actions {
+		if action == http.MethodOptions || action == http.MethodHead {
+			continue
+		}
+		method := strings.ToLower(action)
+		item, ok := d.Paths[npath]
+		if !ok {
+			item = make(PathItem)
+			d.Paths[npath] = item
+		}
+		if _, ok := item[method]; ok {
+			continue
+		}
+		op := &Operation{
+			Tags:        doc.Tags,
+			Summary:     doc.Summary,
+			Description: doc.Description,
+			OperationID: d.operationID(method, service),
+			Deprecated:  doc.Deprecated,
+			Responses:   map[string]*Response{"200": {Description: "成功"}},
+		}
+		if len(op.Tags) == 0 {
+			op.Tags = []string{getTag(service)}
+		}
+		for _, p := range params {
+			op.Parameters = append(op.Parameters, &Parameter{Name: p[1], In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+		if doc.Request != nil {
+			if action == http.MethodGet || action == http.MethodDelete {
+				op.Parameters = append(op.Parameters, d.schemas.parameters(doc.Request)...)
+			} else {
+				schema := d.schemas.schema(doc.Request)
+				op.RequestBody = &RequestBody{
+					Required: true,
+					Content: map[string]*MediaType{
+						getContentType("application/x-www-form-urlencoded", encoding): {Schema: schema},
+						getContentType("application/json", encoding):                  {Schema: schema},
+					},
+				}
+			}
+		}
+		if doc.Response != nil {
+			op.Responses["200"].Content = map[string]*MediaType{
+				getContentType("application/json", encoding): {Schema: d.schemas.schema(doc.Response)},
+			}
+		}
+		for _, t := range op.Tags {
+			if !d.tags[t] {
+				d.tags[t] = true
+				d.Tags = append(d.Tags, &Tag{Name: t})
+			}
+		}
+		item[method] = op
+	}
+	if len(d.schemas.components) > 0 {
+		d.Components = &Components{Schemas: d.schemas.components}
+	}
+	sort.Slice(d.Tags, func(i, j int) bool { return d.Tags[i].Name < d.Tags[j].Name })
+}
+
+//operationID 根据请求方式与服务名生成唯一的操作编号
+func (d *Document) operationID(method string, service string) string {
+	var sb strings.Builder
+	sb.WriteString(method)
+	for _, s := range strings.FieldsFunc(service, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}) {
+		sb.WriteString(strings.ToUpper(s[:1]))
+		sb.WriteString(s[1:])
+	}
+	id := sb.String()
+	for i := 2; d.operations[id]; i++ {
+		id = fmt.Sprintf("%s%d", sb.String(), i)
+	}
+	d.operations[id] = true
+	return id
+}
+
+//getTag 使用服务的第一级路径作为默认分组
+func getTag(service string) string {
+	parts := strings.Split(strings.Trim(strings.Split(service, "$")[0], "/"), "/")
+	if parts[0] == "" {
+		return "default"
+	}
+	return parts[0]
+}
+
+func getContentType(tp string, encoding string) string {
+	if encoding == "" || strings.EqualFold(encoding, "utf-8") {
+		return tp
+	}
+	return tp + "; charset=" + encoding
+}
@@ -0,0 +1,75 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/micro-plat/lib4go/assert"
+)
+
+type address struct {
+	City string `json:"city"`
+}
+
+type orderQuery struct {
+	OrderNo string `json:"order_no" valid:"required" label:"订单编号"`
+	Page    int    `form:"pi"`
+	ignore  string
+}
+
+type order struct {
+	OrderNo string            `json:"order_no"`
+	Amount  float64           `json:"amount"`
+	Items   []string          `json:"items"`
+	Ext     map[string]int64  `json:"ext"`
+	Address *address          `json:"address"`
+	Parent  *order            `json:"parent,omitempty"`
+	Skip    string            `json:"-"`
+	Attrs   map[string]string `json:"attrs"`
+}
+
+func TestDocument_Add(t *testing.T) {
+	doc := New("order", "1.0.0")
+	doc.Add("/order/:id", []string{"GET", "POST", "OPTIONS"}, "/order/:id", "gbk", &Doc{
+		Summary:  "订单",
+		Request:  &orderQuery{},
+		Response: &order{},
+	})
+	doc.Add("/order/:id", []string{"GET"}, "/order/:id", "", nil)
+	doc.Add("/user/login", []string{"POST"}, "/user/login$post", "", nil)
+
+	item := doc.Paths["/order/{id}"]
+	assert.Equal(t, 2, len(item), "忽略OPTIONS请求")
+	get := item["get"]
+	assert.Equal(t, "订单", get.Summary, "重复添加被忽略")
+	assert.Equal(t, "getOrderId", get.OperationID, "操作编号")
+	assert.Equal(t, []string{"order"}, get.Tags, "默认分组")
+	assert.Equal(t, 3, len(get.Parameters), "路径参数与查询参数")
+	assert.Equal(t, "path", get.Parameters[0].In, "路径参数")
+	assert.Equal(t, "order_no", get.Parameters[1].Name, "json标签")
+	assert.Equal(t, true, get.Parameters[1].Required, "必须参数")
+	assert.Equal(t, "订单编号", get.Parameters[1].Description, "参数说明")
+	assert.Equal(t, "pi", get.Parameters[2].Name, "form标签")
+
+	post := item["post"]
+	assert.Equal(t, 2, len(post.RequestBody.Content), "表单与json请求体")
+	assert.Equal(t, "#/components/schemas/orderQuery", post.RequestBody.Content["application/json; charset=gbk"].Schema.Ref, "请求体引用")
+	assert.Equal(t, "#/components/schemas/order", post.Responses["200"].Content["application/json; charset=gbk"].Schema.Ref, "响应引用")
+
+	o := doc.Components.Schemas["order"]
+	assert.Equal(t, 7, len(o.Properties), "结构体字段")
+	assert.Equal(t, "#/components/schemas/order", o.Properties["parent"].Ref, "递归引用")
+	assert.Equal(t, "#/components/schemas/address", o.Properties["address"].Ref, "嵌套结构体")
+	assert.Equal(t, "array", o.Properties["items"].Type, "数组")
+	assert.Equal(t, "int64", o.Properties["ext"].AdditionalProperties.Format, "字典")
+
+	login := doc.Paths["/user/login"]["post"]
+	assert.Equal(t, "postUserLoginPost", login.OperationID, "操作编号")
+	assert.Equal(t, 2, len(doc.Tags), "分组")
+
+	buff, err := json.Marshal(doc)
+	assert.Equal(t, nil, err, "序列化")
+	m := map[string]interface{}{}
+	json.Unmarshal(buff, &m)
+	assert.Equal(t, Version, m["openapi"], "版本号")
+}
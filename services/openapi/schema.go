@@ -0,0 +1,176 @@
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+//Schema 数据结构定义
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+//schemaBuilder 通过反射生成结构定义，命名结构体放入公共组件中引用
+type schemaBuilder struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{
+		components: make(map[string]*Schema),
+		names:      make(map[reflect.Type]string),
+	}
+}
+
+//schema 获取对象的结构定义
+func (b *schemaBuilder) schema(v interface{}) *Schema {
+	if s, ok := v.(*Schema); ok {
+		return s
+	}
+	return b.typeSchema(reflect.TypeOf(v))
+}
+
+//parameters 将结构体字段转换为查询参数
+func (b *schemaBuilder) parameters(v interface{}) []*Parameter {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	params := make([]*Parameter, 0, t.NumField())
+	b.fields(t, func(name string, f reflect.StructField, required bool) {
+		s := b.typeSchema(f.Type)
+		params = append(params, &Parameter{
+			Name:        name,
+			In:          "query",
+			Description: f.Tag.Get("label"),
+			Required:    required,
+			Schema:      s,
+		})
+	})
+	return params
+}
+
+func (b *schemaBuilder) typeSchema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: b.typeSchema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.typeSchema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+		name, ok := b.names[t]
+		if !ok {
+			name = b.componentName(t)
+			b.names[t] = name
+			b.components[name] = &Schema{Type: "object"} //先占用名称，避免递归引用时重复生成
+			b.components[name] = b.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	default:
+		return &Schema{}
+	}
+}
+
+func (b *schemaBuilder) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	b.fields(t, func(name string, f reflect.StructField, required bool) {
+		fs := b.typeSchema(f.Type)
+		if label := f.Tag.Get("label"); label != "" && fs.Ref == "" {
+			fs.Description = label
+		}
+		s.Properties[name] = fs
+		if required {
+			s.Required = append(s.Required, name)
+		}
+	})
+	return s
+}
+
+//fields 遍历结构体的导出字段，字段名依次取json,form标签或字段名，valid标签包含required时为必须字段
+func (b *schemaBuilder) fields(t reflect.Type, f func(name string, field reflect.StructField, required bool)) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := getFieldName(field)
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				b.fields(ft, f)
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+		f(name, field, strings.Contains(field.Tag.Get("valid"), "required"))
+	}
+}
+
+func getFieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		if v, ok := field.Tag.Lookup(tag); ok {
+			if name := strings.Split(v, ",")[0]; name != "" {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+//componentName 获取公共组件名称，不同包中的同名结构体增加序号区分
+func (b *schemaBuilder) componentName(t reflect.Type) string {
+	name := t.Name()
+	for i := 2; ; i++ {
+		if _, ok := b.components[name]; !ok {
+			return name
+		}
+		name = fmt.Sprintf("%s%d", t.Name(), i)
+	}
+}
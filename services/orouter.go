@@ -6,6 +6,7 @@ package services
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/micro-plat/hydra/conf/server/router"
@@ -105,6 +106,24 @@ func (s *ORouter) BuildRouters(prefix string) (*router.Routers, error) {
 	return s.routers, nil
 }
 
+//GetRoutersBy 根据前缀获取路由列表，不改变已生成的路由配置
+func (s *ORouter) GetRoutersBy(prefix string) ([]*router.Router, error) {
+	list := make([]*router.Router, 0, len(s.pathRouters))
+	for _, prouter := range s.pathRouters {
+		routers, err := prouter.GetRouters()
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range routers {
+			t := *r
+			t.Path = fmt.Sprintf("%s%s", prefix, r.Path)
+			list = append(list, &t)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Path < list[j].Path })
+	return list, nil
+}
+
 func (s *ORouter) fillActs(r *router.Router) {
 	array, ok := s.mapPath[r.Path]
 	if !ok {
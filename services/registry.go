@@ -18,6 +18,7 @@ const defHandler = "Handle"
 const defHandled = "Handled"
 const defFallback = "Fallback"
 const defClose = "Close"
+const defDoc = "Doc"
 
 //IService 服务注册接口
 
@@ -143,7 +144,7 @@ func (s *regist) Web(name string, h interface{}, ext ...router.Option) IService
 	return s.Custom(global.Web, name, h, v...)
 }
 
-//RPC 注册为rpc服务
+//RPC 注册为rpc服务，处理函数为func(context.IContext, context.IStream) interface{}时作为流式服务
 func (s *regist) RPC(name string, h interface{}, ext ...router.Option) IService {
 	v := make([]interface{}, 0, len(ext))
 	for _, e := range ext {
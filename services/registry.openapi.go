@@ -0,0 +1,39 @@
+package services
+
+import (
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/services/openapi"
+)
+
+//GetDoc 获取服务的文档说明
+func (s *regist) GetDoc(serverType string, service string) (*openapi.Doc, bool) {
+	return s.get(serverType).GetDoc(service)
+}
+
+//GetOpenAPI 根据已注册的服务与路由生成OpenAPI文档，未指定服务器类型时包含api与web服务
+func (s *regist) GetOpenAPI(prefix string, tps ...string) (*openapi.Document, error) {
+	return s.GetOpenAPIBy(func(string) (string, error) { return prefix, nil }, tps...)
+}
+
+//GetOpenAPIBy 根据已注册的服务与路由生成OpenAPI文档，每种服务器的路径前缀由getPrefix获取
+func (s *regist) GetOpenAPIBy(getPrefix func(tp string) (string, error), tps ...string) (*openapi.Document, error) {
+	if len(tps) == 0 {
+		tps = []string{global.API, global.Web}
+	}
+	doc := openapi.New(global.Def.GetSysName(), global.Version, global.Usage)
+	for _, tp := range tps {
+		prefix, err := getPrefix(tp)
+		if err != nil {
+			return nil, err
+		}
+		routers, err := GetRouter(tp).GetRoutersBy(prefix)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range routers {
+			d, _ := s.GetDoc(tp, r.Service)
+			doc.Add(r.Path, r.Action, r.Service, r.Encoding, d)
+		}
+	}
+	return doc, nil
+}
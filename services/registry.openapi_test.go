@@ -0,0 +1,56 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/micro-plat/hydra/context"
+	"github.com/micro-plat/hydra/services/openapi"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+type docRequest struct {
+	ID string `json:"id" valid:"required"`
+}
+
+type docHandler struct{}
+
+func (docHandler) QueryHandle(context.IContext) interface{} { return nil }
+func (docHandler) QueryDoc() *openapi.Doc {
+	return &openapi.Doc{Summary: "查询", Request: &docRequest{}}
+}
+func (docHandler) GetHandle(context.IContext) interface{} { return nil }
+func (docHandler) GetDoc() *openapi.Doc                   { return &openapi.Doc{Summary: "获取"} }
+func (docHandler) PostDoc() *openapi.Doc                  { return &openapi.Doc{Summary: "未注册处理函数"} }
+
+func Test_regist_GetOpenAPI(t *testing.T) {
+	g, err := reflectHandle("/doc", docHandler{})
+	assert.Equal(t, nil, err, "注册对象")
+	assert.Equal(t, "查询", g.Services["/doc/query"].Doc.Summary, "普通服务文档")
+	assert.Equal(t, "获取", g.Services["/doc/$get"].Doc.Summary, "RESTful服务文档")
+	_, ok := g.Services["/doc/$post"]
+	assert.Equal(t, false, ok, "没有处理函数的文档被忽略")
+
+	API.Remove("/doc")
+	defer API.Remove("/doc")
+	Def.API("/doc", docHandler{})
+	defer Def.Remove("/doc")
+	d, ok := Def.GetDoc("api", "/doc/query")
+	assert.Equal(t, true, ok, "获取服务文档")
+	assert.Equal(t, "查询", d.Summary, "服务文档")
+
+	doc, err := Def.GetOpenAPI("/v1", "api")
+	assert.Equal(t, nil, err, "生成文档")
+	assert.Equal(t, "查询", doc.Paths["/v1/doc/query"]["post"].Summary, "带前缀的路径")
+	assert.Equal(t, "获取", doc.Paths["/v1/doc"]["get"].Summary, "RESTful路径")
+
+	doc, err = Def.GetOpenAPIBy(func(tp string) (string, error) {
+		assert.Equal(t, "api", tp, "按服务器类型获取前缀")
+		return "/v2", nil
+	}, "api")
+	assert.Equal(t, nil, err, "生成文档")
+	assert.Equal(t, "查询", doc.Paths["/v2/doc/query"]["post"].Summary, "使用服务器配置的前缀")
+
+	_, err = Def.GetOpenAPIBy(func(string) (string, error) { return "", fmt.Errorf("配置有误") }, "api")
+	assert.Equal(t, "配置有误", fmt.Sprint(err), "获取前缀失败")
+}
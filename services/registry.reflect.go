@@ -7,6 +7,7 @@ import (
 
 	"github.com/micro-plat/hydra/context"
 	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/services/openapi"
 )
 
 var suffixList = []string{defHandling, defHandler, defHandled, defFallback}
@@ -52,6 +53,7 @@ func reflectHandle(path string, h interface{}) (g *UnitGroup, err error) {
 	}
 
 	//reflect所有函数，检查函数签名
+	docs := make(map[string]*openapi.Doc)
 	for i := 0; i < typ.NumMethod(); i++ {
 
 		//检查函数参数是否符合接口要求
@@ -63,6 +65,12 @@ func reflectHandle(path string, h interface{}) (g *UnitGroup, err error) {
 			continue
 		}
 
+		//处理服务文档函数
+		if f, ok := method.Interface().(func() *openapi.Doc); ok && strings.HasSuffix(mName, defDoc) {
+			docs[strings.ToLower(mName[0:len(mName)-len(defDoc)])] = f()
+			continue
+		}
+
 		hasSuffix := checkSuffix(mName)
 		if !hasSuffix {
 			continue
@@ -71,7 +79,7 @@ func reflectHandle(path string, h interface{}) (g *UnitGroup, err error) {
 		//转换函数签名
 		nf, ok := swapFunc(method.Interface())
 		if !ok {
-			err = fmt.Errorf("函数【%s】是钩子类型（%v）,但签名不是func(context.IContext) interface{}或者func(context.IContext)", mName, suffixList)
+			err = fmt.Errorf("函数【%s】是钩子类型（%v）,但签名不是func(context.IContext) interface{}、func(context.IContext)或者func(context.IContext, context.IStream) interface{}", mName, suffixList)
 			return
 		}
 		switch {
@@ -92,6 +100,9 @@ func reflectHandle(path string, h interface{}) (g *UnitGroup, err error) {
 	if len(current.Services) == 0 {
 		return nil, fmt.Errorf("%s中，未找到可用于注册的处理函数", path)
 	}
+	for name, doc := range docs {
+		current.AddDoc(name, doc)
+	}
 	for _, u := range current.Services {
 		if u.Handle == nil {
 			return nil, fmt.Errorf("%s中,未指定[%s]的Handle函数", path, u.Service)
@@ -120,5 +131,10 @@ func swapFunc(i interface{}) (context.Handler, bool) {
 	if ok {
 		return context.VoidHandler(vnfx).Handle, true
 	}
+	//处理流式请求
+	snfx, ok := i.(func(context.IContext, context.IStream) interface{})
+	if ok {
+		return context.StreamHandler(snfx).Handle, true
+	}
 	return nil, false
 }
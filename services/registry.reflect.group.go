@@ -7,6 +7,7 @@ import (
 	"github.com/micro-plat/hydra/conf/server/router"
 	"github.com/micro-plat/hydra/context"
 	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/hydra/services/openapi"
 )
 
 type handlerType int
@@ -64,6 +65,14 @@ func (g *UnitGroup) AddFallback(name string, h context.IHandler) {
 	g.storeService(name, h, fallback)
 }
 
+//AddDoc 添加服务文档说明，服务未注册处理函数时忽略
+func (g *UnitGroup) AddDoc(name string, doc *openapi.Doc) {
+	_, service, _, _ := g.getPaths(g.Path, name)
+	if unit, ok := g.Services[service]; ok {
+		unit.Doc = doc
+	}
+}
+
 func (g *UnitGroup) storeService(name string, handler context.IHandler, htype handlerType) {
 	path, service, actions, restfulOption := g.getPaths(g.Path, name)
 	if _, ok := g.Services[restfulOption]; restfulOption != "" && !ok {
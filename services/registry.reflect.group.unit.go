@@ -2,6 +2,7 @@ package services
 
 import (
 	"github.com/micro-plat/hydra/context"
+	"github.com/micro-plat/hydra/services/openapi"
 )
 
 type rawUnit struct {
@@ -16,6 +17,7 @@ type Unit struct {
 	Handled  context.IHandler
 	Handle   context.IHandler
 	Fallback context.IHandler
+	Doc      *openapi.Doc
 	*rawUnit
 	Actions []string
 	Group   *UnitGroup
@@ -8,6 +8,7 @@ import (
 	"github.com/micro-plat/hydra/conf/server/router"
 	"github.com/micro-plat/hydra/context"
 	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/hydra/services/openapi"
 )
 
 type metaServices struct {
@@ -16,6 +17,7 @@ type metaServices struct {
 	groups     map[string]string
 	handlers   map[string]context.IHandler
 	fallbacks  map[string]context.IHandler
+	docs       map[string]*openapi.Doc
 }
 
 func newService() *metaServices {
@@ -25,6 +27,7 @@ func newService() *metaServices {
 		groups:     make(map[string]string),
 		handlers:   make(map[string]context.IHandler),
 		fallbacks:  make(map[string]context.IHandler),
+		docs:       make(map[string]*openapi.Doc),
 	}
 }
 
@@ -48,6 +51,14 @@ func (s *metaServices) AddFallback(service string, h context.IHandler) error {
 	return nil
 }
 
+//AddDoc 添加服务文档说明
+func (s *metaServices) AddDoc(service string, doc *openapi.Doc) {
+	if doc == nil {
+		return
+	}
+	s.docs[service] = doc
+}
+
 func (s *metaServices) cachePathActs(service string) {
 	parties := strings.Split(service, "$")
 	methods := router.DefMethods
@@ -118,12 +129,19 @@ func (s *metaServices) GetFallback(service string) (h context.IHandler, ok bool)
 	return
 }
 
+//GetDoc 获取服务文档说明
+func (s *metaServices) GetDoc(service string) (doc *openapi.Doc, ok bool) {
+	doc, ok = s.docs[service]
+	return
+}
+
 //GetFallback 获取服务对应的降级函数
 func (s *metaServices) Remove(service string) {
 	delete(s.handlers, service)
 	delete(s.rawService, service)
 	delete(s.groups, service)
 	delete(s.fallbacks, service)
+	delete(s.docs, service)
 
 	parties := strings.Split(service, "$")
 	for _, m := range s.pathActs[parties[0]] {
@@ -132,5 +150,6 @@ func (s *metaServices) Remove(service string) {
 		delete(s.rawService, rservice)
 		delete(s.groups, rservice)
 		delete(s.fallbacks, rservice)
+		delete(s.docs, rservice)
 	}
 }
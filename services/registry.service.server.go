@@ -100,6 +100,9 @@ func (s *serverServices) addGroup(g *UnitGroup, group string, ext ...interface{}
 			return err
 		}
 
+		//添加服务文档说明
+		s.metaServices.AddDoc(u.Service, u.Doc)
+
 		//添加降级函数
 		if err := s.metaServices.AddFallback(u.Service, u.Fallback); err != nil {
 			return err